@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -174,6 +175,22 @@ func AlertTest(c *models.ReqContext, dto dtos.AlertTestCommand) response.Respons
 	return response.JSON(200, dtoRes)
 }
 
+// POST /api/alerts/lint
+func AlertLint(c *models.ReqContext, dto dtos.AlertLintCommand) response.Response {
+	backendCmd := alerting.AlertLintCommand{
+		OrgID:     c.OrgId,
+		Dashboard: models.NewDashboardFromJson(dto.Dashboard),
+		User:      c.SignedInUser,
+		Timeout:   setting.AlertingLintTimeout,
+	}
+
+	if err := bus.DispatchCtx(c.Req.Context(), &backendCmd); err != nil {
+		return response.Error(500, "Failed to lint alert rules", err)
+	}
+
+	return response.JSON(200, &dtos.AlertLintResult{Warnings: backendCmd.Result})
+}
+
 // GET /api/alerts/:id
 func GetAlert(c *models.ReqContext) response.Response {
 	id := c.ParamsInt64(":alertId")
@@ -272,6 +289,37 @@ func GetAlertNotificationByUID(c *models.ReqContext) response.Response {
 	return response.JSON(200, dtos.NewAlertNotification(query.Result))
 }
 
+func GetAlertNotificationHistoryByUID(c *models.ReqContext) response.Response {
+	query := &models.GetAlertNotificationsWithUidQuery{
+		OrgId: c.OrgId,
+		Uid:   c.Params("uid"),
+	}
+
+	if query.Uid == "" {
+		return response.Error(404, "Alert notification not found", nil)
+	}
+
+	if err := bus.Dispatch(query); err != nil {
+		return response.Error(500, "Failed to get alert notifications", err)
+	}
+
+	if query.Result == nil {
+		return response.Error(404, "Alert notification not found", nil)
+	}
+
+	historyQuery := &models.GetAlertNotificationDeliveryHistoryQuery{
+		OrgId:      c.OrgId,
+		NotifierId: query.Result.Id,
+		Limit:      c.QueryInt("limit"),
+	}
+
+	if err := bus.Dispatch(historyQuery); err != nil {
+		return response.Error(500, "Failed to get alert notification history", err)
+	}
+
+	return response.JSON(200, dtos.NewAlertNotificationHistory(query.Result, historyQuery.Result))
+}
+
 func CreateAlertNotification(c *models.ReqContext, cmd models.CreateAlertNotificationCommand) response.Response {
 	cmd.OrgId = c.OrgId
 
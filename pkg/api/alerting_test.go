@@ -1,15 +1,20 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/search"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -149,6 +154,81 @@ func TestAlertingAPIEndpoint(t *testing.T) {
 			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
 			assert.Equal(t, 404, sc.resp.Code)
 		})
+
+	loggedInUserScenarioWithRole(t, "When calling GET on", "GET", "/api/alert-notifications/abc/history?limit=10",
+		"/api/alert-notifications/:uid/history", models.ROLE_EDITOR, func(sc *scenarioContext) {
+			setUp()
+
+			bus.AddHandler("test", func(query *models.GetAlertNotificationsWithUidQuery) error {
+				query.Result = &models.AlertNotification{Id: 3, Uid: query.Uid, Name: "ops", Type: "webhook"}
+				return nil
+			})
+
+			lastFailure := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+			lastSuccess := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			var historyQuery *models.GetAlertNotificationDeliveryHistoryQuery
+			bus.AddHandler("test", func(query *models.GetAlertNotificationDeliveryHistoryQuery) error {
+				historyQuery = query
+				query.Result = []*models.AlertNotificationDelivery{
+					{AlertId: 2, Success: false, StatusCode: 502, Error: "Webhook response status 502 Bad Gateway", SentAt: lastFailure},
+					{AlertId: 1, Success: true, LatencyMs: 20, SentAt: lastSuccess},
+				}
+				return nil
+			})
+
+			sc.handlerFunc = GetAlertNotificationHistoryByUID
+			sc.fakeReqWithParams("GET", sc.url, map[string]string{}).exec()
+			require.Equal(t, 200, sc.resp.Code)
+
+			require.NotNil(t, historyQuery)
+			assert.Equal(t, int64(3), historyQuery.NotifierId)
+			assert.Equal(t, 10, historyQuery.Limit)
+
+			var result dtos.AlertNotificationHistory
+			require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &result))
+			assert.Equal(t, "abc", result.Uid)
+			require.NotNil(t, result.LastSuccess)
+			assert.True(t, lastSuccess.Equal(*result.LastSuccess))
+			require.NotNil(t, result.LastFailure)
+			assert.True(t, lastFailure.Equal(*result.LastFailure))
+			require.Len(t, result.Deliveries, 2)
+			assert.Equal(t, 502, result.Deliveries[0].StatusCode)
+		})
+}
+
+func TestAlertLintAPIEndpoint(t *testing.T) {
+	defer bus.ClearBusHandlers()
+
+	var lintCmd *alerting.AlertLintCommand
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *alerting.AlertLintCommand) error {
+		lintCmd = cmd
+		cmd.Result = []*alerting.LintWarning{{PanelID: 2, Code: alerting.LintNoSeries, Message: "Query returned no series"}}
+		return nil
+	})
+
+	sc := setupScenarioContext(t, "/api/alerts/lint")
+	sc.defaultHandler = routing.Wrap(func(c *models.ReqContext) response.Response {
+		sc.context = c
+		sc.context.OrgId = testOrgID
+
+		return AlertLint(c, dtos.AlertLintCommand{
+			Dashboard: simplejson.NewFromAny(map[string]interface{}{"title": "Lint me"}),
+		})
+	})
+	sc.m.Post("/api/alerts/lint", sc.defaultHandler)
+
+	sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+	require.Equal(t, 200, sc.resp.Code)
+
+	require.NotNil(t, lintCmd)
+	assert.Equal(t, testOrgID, lintCmd.OrgID)
+	assert.Equal(t, "Lint me", lintCmd.Dashboard.Title)
+
+	var result dtos.AlertLintResult
+	require.NoError(t, json.Unmarshal(sc.resp.Body.Bytes(), &result))
+	require.Len(t, result.Warnings, 1)
+	assert.Equal(t, int64(2), result.Warnings[0].PanelID)
+	assert.Equal(t, alerting.LintNoSeries, result.Warnings[0].Code)
 }
 
 func callPauseAlert(sc *scenarioContext) {
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -321,14 +322,44 @@ func (hs *HTTPServer) PostDashboard(c *models.ReqContext, cmd models.SaveDashboa
 	}
 
 	c.TimeRequest(metrics.MApiDashboardSave)
-	return response.JSON(200, util.DynMap{
+	result := util.DynMap{
 		"status":  "success",
 		"slug":    dashboard.Slug,
 		"version": dashboard.Version,
 		"id":      dashboard.Id,
 		"uid":     dashboard.Uid,
 		"url":     dashboard.GetUrl(),
-	})
+	}
+
+	if warnings := hs.lintDashboardAlerts(c, dashboard); len(warnings) > 0 {
+		result["alertWarnings"] = warnings
+	}
+
+	return response.JSON(200, result)
+}
+
+// lintDashboardAlerts executes the alert rules of a saved dashboard once
+// so that problems are reported at save time rather than on first evaluation.
+func (hs *HTTPServer) lintDashboardAlerts(c *models.ReqContext, dashboard *models.Dashboard) []*alerting.LintWarning {
+	if !setting.AlertingEnabled || setting.AlertingLintTimeout <= 0 {
+		return nil
+	}
+
+	cmd := alerting.AlertLintCommand{
+		OrgID:     c.OrgId,
+		Dashboard: dashboard,
+		User:      c.SignedInUser,
+		Timeout:   setting.AlertingLintTimeout,
+		// saving a dashboard waits for at most one timeout, however many alerts it has
+		TotalTimeout: setting.AlertingLintTimeout,
+	}
+
+	if err := hs.Bus.DispatchCtx(c.Req.Context(), &cmd); err != nil {
+		hs.log.Warn("Failed to lint dashboard alerts", "dashboard", dashboard.Uid, "error", err)
+		return nil
+	}
+
+	return cmd.Result
 }
 
 func dashboardSaveErrorToApiResponse(err error) response.Response {
@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
 )
 
 func formatShort(interval time.Duration) string {
@@ -90,11 +91,68 @@ type AlertNotificationLookup struct {
 	IsDefault bool   `json:"isDefault"`
 }
 
+func NewAlertNotificationHistory(notification *models.AlertNotification, deliveries []*models.AlertNotificationDelivery) *AlertNotificationHistory {
+	dto := &AlertNotificationHistory{
+		Uid:        notification.Uid,
+		Name:       notification.Name,
+		Type:       notification.Type,
+		Deliveries: make([]*AlertNotificationDelivery, 0, len(deliveries)),
+	}
+
+	// deliveries are ordered newest first
+	for _, d := range deliveries {
+		sentAt := d.SentAt
+		if d.Success && dto.LastSuccess == nil {
+			dto.LastSuccess = &sentAt
+		}
+		if !d.Success && dto.LastFailure == nil {
+			dto.LastFailure = &sentAt
+		}
+
+		dto.Deliveries = append(dto.Deliveries, &AlertNotificationDelivery{
+			AlertId:    d.AlertId,
+			Success:    d.Success,
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			LatencyMs:  d.LatencyMs,
+			SentAt:     d.SentAt,
+		})
+	}
+
+	return dto
+}
+
+type AlertNotificationHistory struct {
+	Uid         string                       `json:"uid"`
+	Name        string                       `json:"name"`
+	Type        string                       `json:"type"`
+	LastSuccess *time.Time                   `json:"lastSuccess"`
+	LastFailure *time.Time                   `json:"lastFailure"`
+	Deliveries  []*AlertNotificationDelivery `json:"deliveries"`
+}
+
+type AlertNotificationDelivery struct {
+	AlertId    int64     `json:"alertId"`
+	Success    bool      `json:"success"`
+	StatusCode int       `json:"statusCode"`
+	Error      string    `json:"error"`
+	LatencyMs  int64     `json:"latencyMs"`
+	SentAt     time.Time `json:"sentAt"`
+}
+
 type AlertTestCommand struct {
 	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
 	PanelId   int64            `json:"panelId" binding:"Required"`
 }
 
+type AlertLintCommand struct {
+	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
+}
+
+type AlertLintResult struct {
+	Warnings []*alerting.LintWarning `json:"warnings"`
+}
+
 type AlertTestResult struct {
 	Firing         bool                  `json:"firing"`
 	State          models.AlertStateType `json:"state"`
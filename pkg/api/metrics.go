@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/expr"
@@ -12,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/querycache"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -72,11 +74,12 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 		return response.Error(http.StatusForbidden, "Access denied", err)
 	}
 
-	resp, err := tsdb.HandleRequest(c.Req.Context(), ds, request)
+	result, err := hs.queryDataSource(c, ds, request)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Metric request error", err)
 	}
 
+	resp := result.Response
 	statusCode := http.StatusOK
 	for _, res := range resp.Results {
 		if res.Error != nil {
@@ -86,7 +89,11 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDTO dtos.MetricReq
 		}
 	}
 
-	return response.JSONStreaming(statusCode, resp)
+	streamingResp := response.JSONStreaming(statusCode, resp)
+	for key, value := range queryCacheHeaders(result) {
+		streamingResp = streamingResp.Header(key, value)
+	}
+	return streamingResp
 }
 
 // handleExpressions handles POST /api/ds/query when there is an expression.
@@ -194,11 +201,12 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 		})
 	}
 
-	resp, err := tsdb.HandleRequest(c.Req.Context(), ds, request)
+	result, err := hs.queryDataSource(c, ds, request)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Metric request error", err)
 	}
 
+	resp := result.Response
 	statusCode := http.StatusOK
 	for _, res := range resp.Results {
 		if res.Error != nil {
@@ -208,7 +216,40 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 		}
 	}
 
-	return response.JSON(statusCode, &resp)
+	normalResp := response.JSON(statusCode, &resp)
+	for key, value := range queryCacheHeaders(result) {
+		normalResp.Header(key, value)
+	}
+	return normalResp
+}
+
+// queryDataSource executes a request through the query cache. The
+// X-Grafana-NoCache header forces the queries to be executed.
+func (hs *HTTPServer) queryDataSource(c *models.ReqContext, ds *models.DataSource, request *tsdb.TsdbQuery) (*querycache.Result, error) {
+	request.ConcurrentQueryLimit = hs.Cfg.DataSourceConcurrentQueryLimit
+	if hs.QueryCacheService == nil {
+		resp, err := tsdb.HandleRequest(c.Req.Context(), ds, request)
+		if err != nil {
+			return nil, err
+		}
+		return &querycache.Result{Response: resp, Status: querycache.StatusBypass}, nil
+	}
+
+	return hs.QueryCacheService.HandleRequest(c.Req.Context(), ds, request, c.SkipCache)
+}
+
+// queryCacheHeaders tells clients and proxies whether a query response was
+// served from the cache and how long it stays cached.
+func queryCacheHeaders(result *querycache.Result) map[string]string {
+	if result.Status == querycache.StatusBypass {
+		return nil
+	}
+
+	headers := map[string]string{querycache.HeaderName: string(result.Status)}
+	if result.MaxAge > 0 {
+		headers["Cache-Control"] = fmt.Sprintf("private, max-age=%d", int64(result.MaxAge.Seconds()))
+	}
+	return headers
 }
 
 // GET /api/tsdb/testdata/gensql
@@ -94,6 +94,12 @@ func (r StreamingResponse) Body() []byte {
 	return nil
 }
 
+// Header sets a header on the response.
+func (r StreamingResponse) Header(key, value string) StreamingResponse {
+	r.header.Set(key, value)
+	return r
+}
+
 // WriteTo writes the response to the provided context.
 // Required to implement api.Response.
 func (r StreamingResponse) WriteTo(ctx *models.ReqContext) {
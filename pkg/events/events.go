@@ -46,3 +46,19 @@ type UserUpdated struct {
 	Login     string    `json:"login"`
 	Email     string    `json:"email"`
 }
+
+type AlertNotificationDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	Uid       string    `json:"uid"`
+	Type      string    `json:"type"`
+	OrgId     int64     `json:"orgId"`
+}
+
+type DataSourceDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	Uid       string    `json:"uid"`
+	Name      string    `json:"name"`
+	OrgId     int64     `json:"orgId"`
+}
@@ -84,6 +84,15 @@ var (
 	// MAlertingNotificationSent is a metric counter for how many alert notifications that failed
 	MAlertingNotificationFailed *prometheus.CounterVec
 
+	// MAlertingNotificationChannelDelivery is a metric counter for alert notification delivery attempts per channel
+	MAlertingNotificationChannelDelivery *prometheus.CounterVec
+
+	// MAlertingNotificationChannelDuration is a metric summary for alert notification delivery duration per channel
+	MAlertingNotificationChannelDuration *prometheus.SummaryVec
+
+	// MAlertingNotificationChannelLastSuccess is a metric gauge for the last successful alert notification delivery per channel
+	MAlertingNotificationChannelLastSuccess *prometheus.GaugeVec
+
 	// MAwsCloudWatchGetMetricStatistics is a metric counter for getting metric statistics from aws
 	MAwsCloudWatchGetMetricStatistics prometheus.Counter
 
@@ -104,6 +113,9 @@ var (
 
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
+
+	// MDataSourceQueryCacheRequestTotal is a metric counter for data source query cache lookups
+	MDataSourceQueryCacheRequestTotal *prometheus.CounterVec
 )
 
 // Timers
@@ -116,6 +128,9 @@ var (
 
 	// MRenderingSummary is a metric summary for image rendering request duration
 	MRenderingSummary *prometheus.SummaryVec
+
+	// MDataSourceQueryDuration is a metric summary for backend data source query duration
+	MDataSourceQueryDuration *prometheus.SummaryVec
 )
 
 // StatTotals
@@ -333,6 +348,25 @@ func init() {
 		Namespace: ExporterName,
 	}, []string{"type"})
 
+	MAlertingNotificationChannelDelivery = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "alerting_notification_channel_delivery_total",
+		Help:      "counter for alert notification delivery attempts per notification channel",
+		Namespace: ExporterName,
+	}, []string{"org_id", "uid", "type", "status"})
+
+	MAlertingNotificationChannelDuration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Name:       "alerting_notification_channel_duration_seconds",
+		Help:       "summary of alert notification delivery duration per notification channel",
+		Objectives: objectiveMap,
+		Namespace:  ExporterName,
+	}, []string{"org_id", "uid", "type"})
+
+	MAlertingNotificationChannelLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "alerting_notification_channel_last_success_timestamp_seconds",
+		Help:      "unix timestamp of the last successful alert notification delivery per notification channel",
+		Namespace: ExporterName,
+	}, []string{"org_id", "uid", "type"})
+
 	MAwsCloudWatchGetMetricStatistics = newCounterStartingAtZero(prometheus.CounterOpts{
 		Name:      "aws_cloudwatch_get_metric_statistics_total",
 		Help:      "counter for getting metric statistics from aws",
@@ -373,6 +407,12 @@ func init() {
 		[]string{"status"},
 	)
 
+	MDataSourceQueryCacheRequestTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "datasource_query_cache_request_total",
+		Help:      "counter for data source query cache lookups",
+		Namespace: ExporterName,
+	}, []string{"datasource_type", "result"})
+
 	MRenderingSummary = prometheus.NewSummaryVec(
 		prometheus.SummaryOpts{
 			Name:       "rendering_request_duration_milliseconds",
@@ -389,6 +429,13 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MDataSourceQueryDuration = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Name:       "datasource_query_duration_seconds",
+		Help:       "summary of backend data source query duration",
+		Objectives: objectiveMap,
+		Namespace:  ExporterName,
+	}, []string{"datasource_type", "status"})
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -561,6 +608,7 @@ func initMetricVars() {
 		MApiDashboardGet,
 		MApiDashboardSearch,
 		MDataSourceProxyReqTimer,
+		MDataSourceQueryDuration,
 		MAlertingExecutionTime,
 		MApiAdminUserCreate,
 		MApiLoginPost,
@@ -574,12 +622,16 @@ func initMetricVars() {
 		MAlertingResultState,
 		MAlertingNotificationSent,
 		MAlertingNotificationFailed,
+		MAlertingNotificationChannelDelivery,
+		MAlertingNotificationChannelDuration,
+		MAlertingNotificationChannelLastSuccess,
 		MAwsCloudWatchGetMetricStatistics,
 		MAwsCloudWatchListMetrics,
 		MAwsCloudWatchGetMetricData,
 		MDBDataSourceQueryByID,
 		LDAPUsersSyncExecutionTime,
 		MRenderingRequestTotal,
+		MDataSourceQueryCacheRequestTotal,
 		MRenderingSummary,
 		MRenderingQueue,
 		MAlertingActiveAlerts,
@@ -0,0 +1,54 @@
+package remotecache
+
+import (
+	"time"
+
+	gocache "github.com/patrickmn/go-cache"
+)
+
+const memoryCacheType = "memory"
+
+// memoryStorage keeps items in the memory of the Grafana process. Items are
+// gob encoded like in the other storages so that callers never share values.
+type memoryStorage struct {
+	c *gocache.Cache
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		c: gocache.New(defaultMaxCacheExpiration, 10*time.Minute),
+	}
+}
+
+// Set sets value to given key in the cache.
+func (s *memoryStorage) Set(key string, val interface{}, expires time.Duration) error {
+	item := &cachedItem{Val: val}
+	bytes, err := encodeGob(item)
+	if err != nil {
+		return err
+	}
+
+	s.c.Set(key, bytes, expires)
+	return nil
+}
+
+// Get gets value by given key in the cache.
+func (s *memoryStorage) Get(key string) (interface{}, error) {
+	bytes, found := s.c.Get(key)
+	if !found {
+		return nil, ErrCacheItemNotFound
+	}
+
+	item := &cachedItem{}
+	if err := decodeGob(bytes.([]byte), item); err != nil {
+		return nil, err
+	}
+
+	return item.Val, nil
+}
+
+// Delete delete a key from the cache
+func (s *memoryStorage) Delete(key string) error {
+	s.c.Delete(key)
+	return nil
+}
@@ -0,0 +1,13 @@
+package remotecache
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestMemoryCacheStorage(t *testing.T) {
+	opts := &setting.RemoteCacheOptions{Name: memoryCacheType}
+	client := createTestClient(t, opts, nil)
+	runTestsForClient(t, client)
+}
@@ -111,6 +111,10 @@ func createClient(opts *setting.RemoteCacheOptions, sqlstore *sqlstore.SQLStore)
 		return newDatabaseCache(sqlstore), nil
 	}
 
+	if opts.Name == memoryCacheType {
+		return newMemoryStorage(), nil
+	}
+
 	return nil, ErrInvalidCacheType
 }
 
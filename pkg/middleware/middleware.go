@@ -32,7 +32,9 @@ func AddDefaultResponseHeaders(cfg *setting.Cfg) macaron.Handler {
 				return
 			}
 
-			if !strings.HasPrefix(c.Req.URL.Path, "/api/datasources/proxy/") {
+			// Handlers that set their own Cache-Control header, like the data
+			// source query API when serving cached results, keep it.
+			if !strings.HasPrefix(c.Req.URL.Path, "/api/datasources/proxy/") && w.Header().Get("Cache-Control") == "" {
 				addNoCacheHeaders(c.Resp)
 			}
 
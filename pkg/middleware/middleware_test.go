@@ -100,6 +100,18 @@ func TestMiddlewareContext(t *testing.T) {
 		assert.Empty(t, sc.resp.Header().Get("Expires"))
 	})
 
+	middlewareScenario(t, "middleware should not replace Cache-Control header set by handler", func(
+		t *testing.T, sc *scenarioContext) {
+		sc.handlerFunc = func(c *models.ReqContext) {
+			c.Resp.Header().Set("Cache-Control", "private, max-age=60")
+			c.JSON(200, map[string]interface{}{})
+		}
+		sc.fakeReq("GET", "/").exec()
+		assert.Equal(t, "private, max-age=60", sc.resp.Header().Get("Cache-Control"))
+		assert.Empty(t, sc.resp.Header().Get("Pragma"))
+		assert.Empty(t, sc.resp.Header().Get("Expires"))
+	})
+
 	middlewareScenario(t, "middleware should add Cache-Control header for requests with HTML response", func(
 		t *testing.T, sc *scenarioContext) {
 		sc.handlerFunc = func(c *models.ReqContext) {
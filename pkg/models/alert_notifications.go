@@ -167,6 +167,43 @@ type GetOrCreateNotificationStateQuery struct {
 	Result *AlertNotificationState
 }
 
+// AlertNotificationDelivery is a single attempt to deliver
+// an alert notification through a notification channel.
+type AlertNotificationDelivery struct {
+	Id         int64
+	OrgId      int64
+	NotifierId int64
+	AlertId    int64
+	Success    bool
+	StatusCode int
+	Error      string
+	LatencyMs  int64
+	SentAt     time.Time
+}
+
+type RecordAlertNotificationDeliveryCommand struct {
+	OrgId      int64
+	NotifierId int64
+	AlertId    int64
+	Success    bool
+	StatusCode int
+	Error      string
+	Latency    time.Duration
+	SentAt     time.Time
+
+	// MaxEntries is the number of delivery attempts kept per
+	// notification channel, older attempts are removed.
+	MaxEntries int
+}
+
+type GetAlertNotificationDeliveryHistoryQuery struct {
+	OrgId      int64
+	NotifierId int64
+	Limit      int
+
+	Result []*AlertNotificationDelivery
+}
+
 // decryptedValue returns decrypted value from secureSettings
 func (an *AlertNotification) DecryptedValue(field string, fallback string) string {
 	if value, ok := an.SecureSettings.DecryptedValue(field); ok {
@@ -21,6 +21,15 @@ type ChannelHandlerFactory interface {
 	GetHandlerForPath(path string) (ChannelHandler, error)
 }
 
+// UncachedChannelHandlerFactory is a ChannelHandlerFactory whose handlers
+// should not be cached, e.g. because it supports an unbounded number of paths.
+type UncachedChannelHandlerFactory interface {
+	ChannelHandlerFactory
+
+	// UncachedHandlers marks the factory as uncached.
+	UncachedHandlers()
+}
+
 // DashboardActivityChannel is a service to advertise dashboard activity
 type DashboardActivityChannel interface {
 	DashboardSaved(uid string, userID int64) error
@@ -1,6 +1,9 @@
 package models
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var ErrInvalidEmailCode = errors.New("invalid or expired email code")
 var ErrSmtpNotEnabled = errors.New("SMTP not configured, check your grafana.ini config file's [smtp] section")
@@ -37,6 +40,20 @@ type SendWebhookSync struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+
+	// Result is the body of the response.
+	Result []byte
+}
+
+// WebhookResponseError is returned when a webhook
+// receiver responds with a non 2xx status code.
+type WebhookResponseError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e WebhookResponseError) Error() string {
+	return fmt.Sprintf("Webhook response status %v", e.Status)
 }
 
 type SendResetPasswordEmailCommand struct {
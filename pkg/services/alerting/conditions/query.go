@@ -171,7 +171,7 @@ func (c *QueryCondition) executeQuery(context *alerting.EvalContext, timeRange *
 
 	for _, v := range resp.Results {
 		if v.Error != nil {
-			return nil, fmt.Errorf("tsdb.HandleRequest() response error %v", v)
+			return nil, toCustomError(v.Error)
 		}
 
 		// If there are dataframes but no series on the result
@@ -218,6 +218,75 @@ func (c *QueryCondition) executeQuery(context *alerting.EvalContext, timeRange *
 	return result, nil
 }
 
+// Lint executes the condition's query once and reports problems
+// that would otherwise only surface when the rule is evaluated.
+func (c *QueryCondition) Lint(context *alerting.EvalContext) []*alerting.LintWarning {
+	index := c.Index
+	warning := func(code string, format string, args ...interface{}) []*alerting.LintWarning {
+		return []*alerting.LintWarning{{
+			ConditionIndex: &index,
+			Code:           code,
+			Message:        fmt.Sprintf(format, args...),
+		}}
+	}
+
+	getDsInfo := &models.GetDataSourceQuery{
+		Id:    c.Query.DatasourceID,
+		OrgId: context.Rule.OrgID,
+	}
+
+	if err := bus.Dispatch(getDsInfo); err != nil {
+		return warning(alerting.LintDatasourceNotFound, "Data source with id %d could not be found: %v", c.Query.DatasourceID, err)
+	}
+
+	timeRange := tsdb.NewTimeRange(c.Query.From, c.Query.To)
+	req := c.getRequestForAlertRule(getDsInfo.Result, timeRange, false)
+
+	resp, err := c.HandleRequest(context.Ctx, getDsInfo.Result, req)
+	if err != nil {
+		if errors.Is(err, gocontext.DeadlineExceeded) || errors.Is(context.Ctx.Err(), gocontext.DeadlineExceeded) {
+			return warning(alerting.LintQueryTimeout, "Query did not finish within the lint timeout")
+		}
+		return warning(alerting.LintQueryFailed, "%v", toCustomError(err))
+	}
+
+	seriesCount := 0
+	tableCount := 0
+	for _, v := range resp.Results {
+		if v.Error != nil {
+			return warning(alerting.LintQueryFailed, "Query returned an error: %v", v.Error)
+		}
+
+		seriesCount += len(v.Series)
+		tableCount += len(v.Tables)
+
+		if v.Dataframes != nil && len(v.Series) == 0 {
+			frames, err := v.Dataframes.Decoded()
+			if err != nil {
+				return warning(alerting.LintUnsupportedDataFormat, "Query returned data frames that could not be decoded: %v", err)
+			}
+
+			for _, frame := range frames {
+				ss, err := FrameToSeriesSlice(frame)
+				if err != nil {
+					return warning(alerting.LintUnsupportedDataFormat, "Query returned a data frame %q that is not a time series", frame.Name)
+				}
+				seriesCount += len(ss)
+			}
+		}
+	}
+
+	if seriesCount == 0 && tableCount > 0 {
+		return warning(alerting.LintTableData, "Query returns table data, the %q reducer can only be applied to time series", c.Reducer.Type)
+	}
+
+	if seriesCount == 0 {
+		return warning(alerting.LintNoSeries, "Query returned no series for the time range %s to %s", c.Query.From, c.Query.To)
+	}
+
+	return nil
+}
+
 func (c *QueryCondition) getRequestForAlertRule(datasource *models.DataSource, timeRange *tsdb.TimeRange, debug bool) *tsdb.TsdbQuery {
 	queryModel := c.Query.Model
 	req := &tsdb.TsdbQuery{
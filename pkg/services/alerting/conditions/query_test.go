@@ -2,6 +2,8 @@ package conditions
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math"
 	"testing"
 	"time"
@@ -368,3 +370,123 @@ func TestFrameToSeriesSlice(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryConditionLint(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		if query.Id != 1 {
+			return models.ErrDataSourceNotFound
+		}
+		query.Result = &models.DataSource{Id: 1, Type: "graphite"}
+		return nil
+	})
+
+	newCondition := func(t *testing.T, datasourceID int64, resp *tsdb.Response, respErr error) *QueryCondition {
+		t.Helper()
+
+		jsonModel, err := simplejson.NewJson([]byte(fmt.Sprintf(`{
+			"type": "query",
+			"query":  {
+				"params": ["A", "5m", "now"],
+				"datasourceId": %d,
+				"model": {"target": "stats.requests"}
+			},
+			"reducer": {"type": "avg"},
+			"evaluator": {"type": "gt", "params": [100]}
+		}`, datasourceID)))
+		require.NoError(t, err)
+
+		condition, err := newQueryCondition(jsonModel, 0)
+		require.NoError(t, err)
+
+		condition.HandleRequest = func(context context.Context, dsInfo *models.DataSource, req *tsdb.TsdbQuery) (*tsdb.Response, error) {
+			return resp, respErr
+		}
+		return condition
+	}
+
+	newEvalContext := func() *alerting.EvalContext {
+		return alerting.NewEvalContext(context.Background(), &alerting.Rule{}, &validations.OSSPluginRequestValidator{})
+	}
+
+	tcs := []struct {
+		name         string
+		datasourceID int64
+		result       *tsdb.QueryResult
+		err          error
+		expectedCode string
+	}{
+		{
+			name:         "time series result should not return warnings",
+			datasourceID: 1,
+			result: &tsdb.QueryResult{
+				Series: tsdb.TimeSeriesSlice{{Name: "test", Points: newTimeSeriesPointsFromArgs(120, 0)}},
+			},
+		},
+		{
+			name:         "missing data source should return warning",
+			datasourceID: 2,
+			result:       &tsdb.QueryResult{},
+			expectedCode: alerting.LintDatasourceNotFound,
+		},
+		{
+			name:         "request error should return warning",
+			datasourceID: 1,
+			err:          errors.New("connection refused"),
+			expectedCode: alerting.LintQueryFailed,
+		},
+		{
+			name:         "timeout should return warning",
+			datasourceID: 1,
+			err:          context.DeadlineExceeded,
+			expectedCode: alerting.LintQueryTimeout,
+		},
+		{
+			name:         "query result error should return warning",
+			datasourceID: 1,
+			result:       &tsdb.QueryResult{Error: errors.New("bad query")},
+			expectedCode: alerting.LintQueryFailed,
+		},
+		{
+			name:         "empty result should return warning",
+			datasourceID: 1,
+			result:       &tsdb.QueryResult{},
+			expectedCode: alerting.LintNoSeries,
+		},
+		{
+			name:         "table result should return warning",
+			datasourceID: 1,
+			result: &tsdb.QueryResult{
+				Tables: []*tsdb.Table{{Columns: []tsdb.TableColumn{{Text: "host"}}}},
+			},
+			expectedCode: alerting.LintTableData,
+		},
+		{
+			name:         "data frame that is not a time series should return warning",
+			datasourceID: 1,
+			result: &tsdb.QueryResult{
+				Dataframes: tsdb.NewDecodedDataFrames(data.Frames{
+					data.NewFrame("hosts", data.NewField("host", nil, []string{"a"})),
+				}),
+			},
+			expectedCode: alerting.LintUnsupportedDataFormat,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &tsdb.Response{Results: map[string]*tsdb.QueryResult{"A": tc.result}}
+			condition := newCondition(t, tc.datasourceID, resp, tc.err)
+
+			warnings := condition.Lint(newEvalContext())
+			if tc.expectedCode == "" {
+				require.Empty(t, warnings)
+				return
+			}
+
+			require.Len(t, warnings, 1)
+			require.Equal(t, tc.expectedCode, warnings[0].Code)
+			require.Equal(t, 0, *warnings[0].ConditionIndex)
+		})
+	}
+}
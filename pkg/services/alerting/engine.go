@@ -27,13 +27,14 @@ type AlertEngine struct {
 	Bus              bus.Bus                       `inject:""`
 	RequestValidator models.PluginRequestValidator `inject:""`
 
-	execQueue     chan *Job
-	ticker        *Ticker
-	scheduler     scheduler
-	evalHandler   evalHandler
-	ruleReader    ruleReader
-	log           log.Logger
-	resultHandler resultHandler
+	execQueue           chan *Job
+	ticker              *Ticker
+	scheduler           scheduler
+	evalHandler         evalHandler
+	ruleReader          ruleReader
+	log                 log.Logger
+	notificationService *notificationService
+	resultHandler       resultHandler
 }
 
 func init() {
@@ -53,7 +54,8 @@ func (e *AlertEngine) Init() error {
 	e.evalHandler = NewEvalHandler()
 	e.ruleReader = newRuleReader()
 	e.log = log.New("alerting.engine")
-	e.resultHandler = newResultHandler(e.RenderService)
+	e.notificationService = newNotificationService(e.RenderService)
+	e.resultHandler = newResultHandler(e.notificationService)
 	return nil
 }
 
@@ -62,6 +64,7 @@ func (e *AlertEngine) Run(ctx context.Context) error {
 	alertGroup, ctx := errgroup.WithContext(ctx)
 	alertGroup.Go(func() error { return e.alertingTicker(ctx) })
 	alertGroup.Go(func() error { return e.runJobDispatcher(ctx) })
+	alertGroup.Go(func() error { return e.notificationService.run(ctx) })
 
 	err := alertGroup.Wait()
 	return err
@@ -33,6 +33,13 @@ type EvalContext struct {
 	NoDataFound     bool
 	PrevAlertState  models.AlertStateType
 
+	// ImageData is the rendered panel image, it is only set when
+	// the image is within the configured attachment size limit.
+	ImageData []byte
+
+	// Grouped holds the notifications batched into a digest notification.
+	Grouped []*EvalContext
+
 	RequestValidator models.PluginRequestValidator
 
 	Ctx context.Context
@@ -124,6 +131,10 @@ func (c *EvalContext) GetRuleURL() (string, error) {
 		return setting.AppUrl, nil
 	}
 
+	if len(c.Grouped) > 0 {
+		return setting.AppUrl + "alerting/list", nil
+	}
+
 	ref, err := c.GetDashboardUID()
 	if err != nil {
 		return "", err
@@ -210,6 +221,31 @@ func (c *EvalContext) evaluateNotificationTemplateFields() error {
 	return nil
 }
 
+// limitImageToMode restricts the rendered image passed to a notifier to
+// the delivery configured for its notification channel. The returned
+// function restores the image for the next notifier.
+func (c *EvalContext) limitImageToMode(mode string) func() {
+	publicURL, onDiskPath, data := c.ImagePublicURL, c.ImageOnDiskPath, c.ImageData
+	restore := func() {
+		c.ImagePublicURL, c.ImageOnDiskPath, c.ImageData = publicURL, onDiskPath, data
+	}
+
+	switch mode {
+	case ImageModeNone:
+		c.ImagePublicURL, c.ImageOnDiskPath, c.ImageData = "", "", nil
+		return restore
+	case ImageModeAttachment:
+		c.ImagePublicURL = ""
+	}
+
+	// notifiers reading the image from disk must not attach images exceeding the size limit.
+	if c.ImageData == nil {
+		c.ImageOnDiskPath = ""
+	}
+
+	return restore
+}
+
 func evaluateTemplate(s string, m map[string]string) (string, error) {
 	for k, v := range m {
 		re, err := regexp.Compile(fmt.Sprintf(`\${%s}`, regexp.QuoteMeta(k)))
@@ -340,3 +340,59 @@ func TestEvaluateTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestLimitImageToMode(t *testing.T) {
+	image := []byte("png")
+	tcs := []struct {
+		name               string
+		mode               string
+		data               []byte
+		expectedPublicURL  string
+		expectedOnDiskPath string
+		expectedData       []byte
+	}{
+		{
+			name:               "url mode keeps the image",
+			mode:               ImageModeURL,
+			data:               image,
+			expectedPublicURL:  "https://images.local/graph.png",
+			expectedOnDiskPath: "/tmp/graph.png",
+			expectedData:       image,
+		},
+		{
+			name:               "attachment mode removes the public url",
+			mode:               ImageModeAttachment,
+			data:               image,
+			expectedOnDiskPath: "/tmp/graph.png",
+			expectedData:       image,
+		},
+		{
+			name:              "image exceeding the size limit is not attached",
+			mode:              ImageModeURL,
+			expectedPublicURL: "https://images.local/graph.png",
+		},
+		{
+			name: "none mode removes the image",
+			mode: ImageModeNone,
+			data: image,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			evalContext := NewEvalContext(context.Background(), &Rule{}, &validations.OSSPluginRequestValidator{})
+			evalContext.ImagePublicURL = "https://images.local/graph.png"
+			evalContext.ImageOnDiskPath = "/tmp/graph.png"
+			evalContext.ImageData = tc.data
+
+			restore := evalContext.limitImageToMode(tc.mode)
+			assert.Equal(t, tc.expectedPublicURL, evalContext.ImagePublicURL)
+			assert.Equal(t, tc.expectedOnDiskPath, evalContext.ImageOnDiskPath)
+			assert.Equal(t, tc.expectedData, evalContext.ImageData)
+
+			restore()
+			assert.Equal(t, "https://images.local/graph.png", evalContext.ImagePublicURL)
+			assert.Equal(t, "/tmp/graph.png", evalContext.ImageOnDiskPath)
+			assert.Equal(t, tc.data, evalContext.ImageData)
+		})
+	}
+}
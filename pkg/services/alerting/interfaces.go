@@ -21,6 +21,9 @@ type Notifier interface {
 	Notify(evalContext *EvalContext) error
 	GetType() string
 	NeedsImage() bool
+	// GetImageMode returns how the rendered panel image is delivered,
+	// one of ImageModeURL, ImageModeAttachment or ImageModeNone.
+	GetImageMode() string
 
 	// ShouldNotify checks this evaluation should send an alert notification
 	ShouldNotify(ctx context.Context, evalContext *EvalContext, notificationState *models.AlertNotificationState) bool
@@ -30,6 +33,12 @@ type Notifier interface {
 	GetSendReminder() bool
 	GetDisableResolveMessage() bool
 	GetFrequency() time.Duration
+	// GetGroupingWindow returns for how long notifications are
+	// collected and sent as a single digest message.
+	GetGroupingWindow() time.Duration
+	// GetDigestInterval returns the interval of digest messages,
+	// it takes precedence over the grouping window.
+	GetDigestInterval() time.Duration
 }
 
 type notifierState struct {
@@ -49,6 +58,18 @@ func (notifiers notifierStateSlice) ShouldUploadImage() bool {
 	return false
 }
 
+// ShouldPublishImage returns true if any notifier links the image
+// uploaded to the external image store.
+func (notifiers notifierStateSlice) ShouldPublishImage() bool {
+	for _, ns := range notifiers {
+		if ns.notifier.NeedsImage() && ns.notifier.GetImageMode() == ImageModeURL {
+			return true
+		}
+	}
+
+	return false
+}
+
 // ConditionResult is the result of a condition evaluation.
 type ConditionResult struct {
 	Firing      bool
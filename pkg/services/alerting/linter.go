@@ -0,0 +1,207 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Lint warning codes.
+const (
+	LintInvalidRule           = "invalid_rule"
+	LintNotificationNotFound  = "notification_not_found"
+	LintDatasourceNotFound    = "datasource_not_found"
+	LintQueryFailed           = "query_failed"
+	LintQueryTimeout          = "query_timeout"
+	LintNoSeries              = "no_series"
+	LintTableData             = "table_data"
+	LintUnsupportedDataFormat = "unsupported_data_format"
+)
+
+const (
+	defaultLintTimeout = 5 * time.Second
+
+	// maxConcurrentLints caps how many alert rules of a dashboard are linted at once.
+	maxConcurrentLints = 4
+)
+
+// LintWarning describes a problem with an alert rule that would
+// only surface once the rule is evaluated.
+type LintWarning struct {
+	PanelID        int64  `json:"panelId,omitempty"`
+	AlertName      string `json:"alertName,omitempty"`
+	ConditionIndex *int   `json:"conditionIndex,omitempty"`
+	Code           string `json:"code"`
+	Message        string `json:"message"`
+}
+
+// ConditionLinter is implemented by conditions that can be
+// checked against their data source without evaluating the rule.
+type ConditionLinter interface {
+	Lint(evalContext *EvalContext) []*LintWarning
+}
+
+// AlertLintCommand validates the alert rules of a dashboard
+// and executes their conditions once against the data sources.
+type AlertLintCommand struct {
+	Dashboard *models.Dashboard
+	OrgID     int64
+	User      *models.SignedInUser
+	// Timeout is the maximum time a single condition may take to execute.
+	Timeout time.Duration
+	// TotalTimeout is the maximum time linting all alert rules may take,
+	// no limit if zero.
+	TotalTimeout time.Duration
+
+	Result []*LintWarning
+}
+
+func init() {
+	bus.AddHandlerCtx("alerting", handleAlertLintCommand)
+}
+
+func handleAlertLintCommand(ctx context.Context, cmd *AlertLintCommand) error {
+	extractor := NewDashAlertExtractor(cmd.Dashboard, cmd.OrgID, cmd.User)
+
+	cmd.Result = make([]*LintWarning, 0)
+
+	// the dashboard may not have been saved yet, so the dashboard id is not required.
+	alerts, err := extractor.extractAlerts(func(alert *models.Alert) bool { return alert.OrgId != 0 && alert.PanelId != 0 }, false)
+	if err != nil {
+		cmd.Result = append(cmd.Result, &LintWarning{Code: LintInvalidRule, Message: err.Error()})
+		return nil
+	}
+
+	timeout := cmd.Timeout
+	if timeout <= 0 {
+		timeout = defaultLintTimeout
+	}
+
+	if cmd.TotalTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cmd.TotalTimeout)
+		defer cancel()
+	}
+
+	// the alerts are linted concurrently, the results keep the order of the alerts
+	results := make([][]*LintWarning, len(alerts))
+	errs := make([]error, len(alerts))
+	sem := make(chan struct{}, maxConcurrentLints)
+	var wg sync.WaitGroup
+	for i, alert := range alerts {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, alert *models.Alert) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i], errs[i] = lintAlert(ctx, alert, timeout)
+		}(i, alert)
+	}
+	wg.Wait()
+
+	for i := range alerts {
+		if errs[i] != nil {
+			return errs[i]
+		}
+		cmd.Result = append(cmd.Result, results[i]...)
+	}
+
+	return nil
+}
+
+func lintAlert(ctx context.Context, alert *models.Alert, timeout time.Duration) ([]*LintWarning, error) {
+	warnings := make([]*LintWarning, 0)
+	addWarning := func(w *LintWarning) {
+		w.PanelID = alert.PanelId
+		w.AlertName = alert.Name
+		warnings = append(warnings, w)
+	}
+
+	rule, err := NewRuleFromDBAlert(alert, false)
+	if err != nil {
+		addWarning(&LintWarning{Code: LintInvalidRule, Message: err.Error()})
+		return warnings, nil
+	}
+
+	notificationWarnings, err := lintNotifications(alert)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range notificationWarnings {
+		addWarning(w)
+	}
+
+	// the conditions are executed concurrently so that the alert takes at most one timeout
+	conditionWarnings := make([][]*LintWarning, len(rule.Conditions))
+	var wg sync.WaitGroup
+	for i, condition := range rule.Conditions {
+		linter, ok := condition.(ConditionLinter)
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, linter ConditionLinter) {
+			defer wg.Done()
+
+			conditionCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			evalContext := NewEvalContext(conditionCtx, rule, fakeRequestValidator{})
+			evalContext.IsTestRun = true
+
+			conditionWarnings[i] = linter.Lint(evalContext)
+		}(i, linter)
+	}
+	wg.Wait()
+
+	for _, ws := range conditionWarnings {
+		for _, w := range ws {
+			addWarning(w)
+		}
+	}
+
+	return warnings, nil
+}
+
+func lintNotifications(alert *models.Alert) ([]*LintWarning, error) {
+	warnings := make([]*LintWarning, 0)
+
+	for _, v := range alert.Settings.Get("notifications").MustArray() {
+		jsonModel := simplejson.NewFromAny(v)
+
+		if id, err := jsonModel.Get("id").Int64(); err == nil {
+			query := &models.GetAlertNotificationsQuery{OrgId: alert.OrgId, Id: id}
+			if err := bus.Dispatch(query); err != nil {
+				return nil, err
+			}
+			if query.Result == nil {
+				warnings = append(warnings, &LintWarning{
+					Code:    LintNotificationNotFound,
+					Message: fmt.Sprintf("Notification channel with id %d does not exist", id),
+				})
+			}
+			continue
+		}
+
+		uid := jsonModel.Get("uid").MustString()
+		query := &models.GetAlertNotificationsWithUidQuery{OrgId: alert.OrgId, Uid: uid}
+		if err := bus.Dispatch(query); err != nil {
+			return nil, err
+		}
+		if query.Result == nil {
+			warnings = append(warnings, &LintWarning{
+				Code:    LintNotificationNotFound,
+				Message: fmt.Sprintf("Notification channel with uid %q does not exist", uid),
+			})
+		}
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,119 @@
+package alerting
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+type fakeLintCondition struct {
+	FakeCondition
+	warnings []*LintWarning
+	ctx      context.Context
+}
+
+func (f *fakeLintCondition) Lint(evalContext *EvalContext) []*LintWarning {
+	f.ctx = evalContext.Ctx
+	return f.warnings
+}
+
+func TestAlertLint(t *testing.T) {
+	condition := &fakeLintCondition{
+		warnings: []*LintWarning{{Code: LintNoSeries, Message: "Query returned no series"}},
+	}
+	RegisterCondition("query", func(model *simplejson.Json, index int) (Condition, error) {
+		return condition, nil
+	})
+
+	influxDBDs := &models.DataSource{Id: 16, OrgId: 1, Name: "InfluxDB"}
+	bus.AddHandler("test", func(query *models.GetDataSourceQuery) error {
+		if query.Name == influxDBDs.Name {
+			query.Result = influxDBDs
+			return nil
+		}
+		return models.ErrDataSourceNotFound
+	})
+
+	bus.AddHandler("test", func(query *models.GetAlertNotificationUidQuery) error {
+		query.Result = "notifier1"
+		return nil
+	})
+
+	bus.AddHandler("test", func(query *models.GetAlertNotificationsQuery) error {
+		if query.Id == 1 {
+			query.Result = &models.AlertNotification{Id: 1, Uid: "notifier1"}
+		}
+		return nil
+	})
+
+	bus.AddHandler("test", func(query *models.GetAlertNotificationsWithUidQuery) error {
+		return nil
+	})
+
+	json, err := ioutil.ReadFile("./testdata/influxdb-alert.json")
+	require.NoError(t, err)
+
+	t.Run("reports missing notification channels and condition warnings", func(t *testing.T) {
+		dashJSON, err := simplejson.NewJson(json)
+		require.NoError(t, err)
+
+		cmd := &AlertLintCommand{
+			Dashboard: models.NewDashboardFromJson(dashJSON),
+			OrgID:     1,
+		}
+		require.NoError(t, handleAlertLintCommand(context.Background(), cmd))
+
+		require.Len(t, cmd.Result, 2)
+		assert.Equal(t, LintNotificationNotFound, cmd.Result[0].Code)
+		assert.Equal(t, `Notification channel with uid "notifier2" does not exist`, cmd.Result[0].Message)
+		assert.Equal(t, "Influxdb", cmd.Result[0].AlertName)
+		assert.Equal(t, int64(1), cmd.Result[0].PanelID)
+
+		assert.Equal(t, LintNoSeries, cmd.Result[1].Code)
+		assert.Equal(t, "Influxdb", cmd.Result[1].AlertName)
+
+		_, hasDeadline := condition.ctx.Deadline()
+		assert.True(t, hasDeadline, "expected condition to be linted with a timeout")
+	})
+
+	t.Run("conditions are linted within the total timeout", func(t *testing.T) {
+		dashJSON, err := simplejson.NewJson(json)
+		require.NoError(t, err)
+
+		cmd := &AlertLintCommand{
+			Dashboard:    models.NewDashboardFromJson(dashJSON),
+			OrgID:        1,
+			Timeout:      time.Hour,
+			TotalTimeout: time.Second,
+		}
+		require.NoError(t, handleAlertLintCommand(context.Background(), cmd))
+
+		deadline, hasDeadline := condition.ctx.Deadline()
+		require.True(t, hasDeadline)
+		assert.WithinDuration(t, time.Now(), deadline, time.Second)
+	})
+
+	t.Run("reports alert rules that cannot be extracted", func(t *testing.T) {
+		dashJSON, err := simplejson.NewJson(json)
+		require.NoError(t, err)
+		dashJSON.Get("rows").GetIndex(0).Get("panels").GetIndex(0).Set("datasource", "deleted")
+
+		cmd := &AlertLintCommand{
+			Dashboard: models.NewDashboardFromJson(dashJSON),
+			OrgID:     1,
+		}
+		require.NoError(t, handleAlertLintCommand(context.Background(), cmd))
+
+		require.Len(t, cmd.Result, 1)
+		assert.Equal(t, LintInvalidRule, cmd.Result[0].Code)
+		assert.Contains(t, cmd.Result[0].Message, "Data source used by alert rule not found")
+	})
+}
@@ -0,0 +1,227 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Digest intervals batch all notifications of a notification channel
+// and send them as a single message at the start of every hour or day.
+const (
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+)
+
+// maxDigestEntries limits the number of alerts listed in a digest message.
+const maxDigestEntries = 100
+
+// GetNotificationGrouping returns the grouping window and the digest interval
+// configured in the settings of a notification channel. Zero values disable
+// grouping, the digest interval takes precedence over the grouping window.
+func GetNotificationGrouping(settings *simplejson.Json) (time.Duration, time.Duration, error) {
+	var window, digest time.Duration
+
+	if value := settings.Get("groupingWindow").MustString(); value != "" {
+		var err error
+		window, err = time.ParseDuration(value)
+		if err != nil || window < 0 {
+			return 0, 0, ValidationError{Reason: fmt.Sprintf("Invalid value for groupingWindow: %q", value)}
+		}
+	}
+
+	switch value := settings.Get("digest").MustString(); value {
+	case "":
+	case DigestHourly:
+		digest = time.Hour
+	case DigestDaily:
+		digest = 24 * time.Hour
+	default:
+		return 0, 0, ValidationError{Reason: fmt.Sprintf("Invalid value for digest: %q", value)}
+	}
+
+	return window, digest, nil
+}
+
+// DigestNotifier is implemented by notifiers rendering the notifications
+// of a digest themselves instead of the generic digest message.
+type DigestNotifier interface {
+	// NotifyDigest sends the digest, the grouped notifications are
+	// available in evalContext.Grouped.
+	NotifyDigest(evalContext *EvalContext) error
+}
+
+// isGrouped returns true if the notifier batches its notifications.
+func isGrouped(notifier Notifier) bool {
+	return notifier.GetGroupingWindow() > 0 || notifier.GetDigestInterval() > 0
+}
+
+// notificationGroupDelay returns how long notifications of the notifier are
+// collected, starting with the first notification of a group.
+func notificationGroupDelay(notifier Notifier, now time.Time) time.Duration {
+	if interval := notifier.GetDigestInterval(); interval > 0 {
+		return now.Truncate(interval).Add(interval).Sub(now)
+	}
+
+	return notifier.GetGroupingWindow()
+}
+
+type groupedNotification struct {
+	evalContext   *EvalContext
+	notifierState *notifierState
+}
+
+type notificationGroup struct {
+	notifier      Notifier
+	notifications []*groupedNotification
+	timer         *time.Timer
+}
+
+// notificationGrouper collects the notifications of grouped notification
+// channels until their grouping window or digest interval ends.
+type notificationGrouper struct {
+	mu      sync.Mutex
+	groups  map[string]*notificationGroup
+	stopped bool
+	send    func(group *notificationGroup)
+}
+
+func newNotificationGrouper(send func(group *notificationGroup)) *notificationGrouper {
+	return &notificationGrouper{
+		groups: make(map[string]*notificationGroup),
+		send:   send,
+	}
+}
+
+func (g *notificationGrouper) add(evalContext *EvalContext, notifierState *notifierState) {
+	key := fmt.Sprintf("%d-%d", evalContext.Rule.OrgID, notifierState.state.NotifierId)
+	notification := &groupedNotification{
+		evalContext:   evalContext,
+		notifierState: notifierState,
+	}
+
+	g.mu.Lock()
+	if g.stopped {
+		g.mu.Unlock()
+		// the alerting service is stopping, nothing would send the group later.
+		g.send(&notificationGroup{notifier: notifierState.notifier, notifications: []*groupedNotification{notification}})
+		return
+	}
+	defer g.mu.Unlock()
+
+	group, ok := g.groups[key]
+	if !ok {
+		group = &notificationGroup{}
+		g.groups[key] = group
+		group.timer = time.AfterFunc(notificationGroupDelay(notifierState.notifier, time.Now()), func() {
+			g.flush(key)
+		})
+	}
+
+	// use the latest notifier in case the notification channel has been updated.
+	group.notifier = notifierState.notifier
+
+	// a later notification of an alert rule replaces the queued one,
+	// its notification state has the current version.
+	for i, queued := range group.notifications {
+		if queued.evalContext.Rule.ID == evalContext.Rule.ID {
+			group.notifications[i] = notification
+			return
+		}
+	}
+	group.notifications = append(group.notifications, notification)
+}
+
+func (g *notificationGrouper) flush(key string) {
+	g.mu.Lock()
+	group, ok := g.groups[key]
+	delete(g.groups, key)
+	g.mu.Unlock()
+
+	if ok {
+		g.send(group)
+	}
+}
+
+// run sends all queued notifications once ctx is done, so that they are not
+// lost when the alerting service stops. Notifications added afterwards are
+// sent right away.
+func (g *notificationGrouper) run(ctx context.Context) error {
+	<-ctx.Done()
+
+	g.mu.Lock()
+	g.stopped = true
+	groups := g.groups
+	g.groups = make(map[string]*notificationGroup)
+	g.mu.Unlock()
+
+	for _, group := range groups {
+		group.timer.Stop()
+		g.send(group)
+	}
+	return nil
+}
+
+// newDigestEvalContext creates the evaluation context of a digest notification
+// listing the grouped notifications, only the latest notification of each
+// alert rule is kept.
+func newDigestEvalContext(ctx context.Context, evalContexts []*EvalContext) *EvalContext {
+	grouped := make([]*EvalContext, 0, len(evalContexts))
+	indexes := make(map[int64]int)
+	for _, c := range evalContexts {
+		if i, ok := indexes[c.Rule.ID]; ok {
+			grouped[i] = c
+			continue
+		}
+		indexes[c.Rule.ID] = len(grouped)
+		grouped = append(grouped, c)
+	}
+
+	counts := make(map[models.AlertStateType]int)
+	lines := make([]string, 0, len(grouped))
+	for i, c := range grouped {
+		counts[c.Rule.State]++
+		if i < maxDigestEntries {
+			lines = append(lines, c.GetNotificationTitle())
+		}
+	}
+	if len(grouped) > maxDigestEntries {
+		lines = append(lines, fmt.Sprintf("and %d more", len(grouped)-maxDigestEntries))
+	}
+
+	state := grouped[0].Rule.State
+	summary := make([]string, 0, len(counts))
+	for _, s := range []models.AlertStateType{
+		models.AlertStateAlerting,
+		models.AlertStateNoData,
+		models.AlertStatePending,
+		models.AlertStateOK,
+		models.AlertStatePaused,
+		models.AlertStateUnknown,
+	} {
+		if counts[s] == 0 {
+			continue
+		}
+		if len(summary) == 0 {
+			state = s
+		}
+		summary = append(summary, fmt.Sprintf("%d %s", counts[s], s))
+	}
+
+	rule := &Rule{
+		OrgID:   grouped[0].Rule.OrgID,
+		Name:    fmt.Sprintf("%d alerts (%s)", len(grouped), strings.Join(summary, ", ")),
+		Message: strings.Join(lines, "\n"),
+		State:   state,
+	}
+
+	digest := NewEvalContext(ctx, rule, grouped[0].RequestValidator)
+	digest.Firing = state == models.AlertStateAlerting
+	digest.Grouped = grouped
+	return digest
+}
@@ -0,0 +1,230 @@
+package alerting
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/validations"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNotificationGrouping(t *testing.T) {
+	tcs := []struct {
+		name           string
+		settings       map[string]interface{}
+		expectedWindow time.Duration
+		expectedDigest time.Duration
+		expectedErr    bool
+	}{
+		{
+			name:     "grouping disabled by default",
+			settings: map[string]interface{}{},
+		},
+		{
+			name:           "grouping window",
+			settings:       map[string]interface{}{"groupingWindow": "30s"},
+			expectedWindow: 30 * time.Second,
+		},
+		{
+			name:           "daily digest",
+			settings:       map[string]interface{}{"digest": "daily"},
+			expectedDigest: 24 * time.Hour,
+		},
+		{
+			name:        "invalid grouping window",
+			settings:    map[string]interface{}{"groupingWindow": "soon"},
+			expectedErr: true,
+		},
+		{
+			name:        "negative grouping window",
+			settings:    map[string]interface{}{"groupingWindow": "-1m"},
+			expectedErr: true,
+		},
+		{
+			name:        "invalid digest",
+			settings:    map[string]interface{}{"digest": "weekly"},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			window, digest, err := GetNotificationGrouping(simplejson.NewFromAny(tc.settings))
+			if tc.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedWindow, window)
+			assert.Equal(t, tc.expectedDigest, digest)
+		})
+	}
+}
+
+func TestNotificationGroupDelay(t *testing.T) {
+	now := time.Date(2021, 3, 4, 10, 45, 0, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, notificationGroupDelay(&testNotifier{GroupingWindow: 30 * time.Second}, now))
+	assert.Equal(t, 15*time.Minute, notificationGroupDelay(&testNotifier{DigestInterval: time.Hour}, now))
+	assert.Equal(t, 13*time.Hour+15*time.Minute, notificationGroupDelay(&testNotifier{
+		GroupingWindow: 30 * time.Second,
+		DigestInterval: 24 * time.Hour,
+	}, now))
+}
+
+func TestNewDigestEvalContext(t *testing.T) {
+	newEvalContext := func(id int64, name string, state models.AlertStateType) *EvalContext {
+		return NewEvalContext(context.Background(), &Rule{ID: id, OrgID: 1, Name: name, State: state}, &validations.OSSPluginRequestValidator{})
+	}
+
+	digest := newDigestEvalContext(context.Background(), []*EvalContext{
+		newEvalContext(1, "cpu", models.AlertStateAlerting),
+		newEvalContext(2, "memory", models.AlertStateOK),
+		newEvalContext(3, "disk", models.AlertStateAlerting),
+		newEvalContext(2, "memory", models.AlertStateAlerting),
+	})
+
+	require.Len(t, digest.Grouped, 3)
+	assert.Equal(t, "memory", digest.Grouped[1].Rule.Name)
+	assert.Equal(t, models.AlertStateAlerting, digest.Grouped[1].Rule.State)
+	assert.Equal(t, int64(1), digest.Rule.OrgID)
+	assert.Equal(t, models.AlertStateAlerting, digest.Rule.State)
+	assert.True(t, digest.Firing)
+	assert.Equal(t, "[Alerting] 3 alerts (3 alerting)", digest.GetNotificationTitle())
+	assert.Equal(t, "[Alerting] cpu\n[Alerting] memory\n[Alerting] disk", digest.Rule.Message)
+
+	ruleURL, err := digest.GetRuleURL()
+	require.NoError(t, err)
+	assert.Equal(t, setting.AppUrl+"alerting/list", ruleURL)
+}
+
+type digestTestNotifier struct {
+	testNotifier
+	mu      sync.Mutex
+	digests []*EvalContext
+}
+
+func (n *digestTestNotifier) NotifyDigest(evalContext *EvalContext) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.digests = append(n.digests, evalContext)
+	return nil
+}
+
+func (n *digestTestNotifier) getDigests() []*EvalContext {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.digests
+}
+
+func TestNotificationServiceGroupsNotifications(t *testing.T) {
+	var mu sync.Mutex
+	completed := []*models.SetAlertNotificationStateToCompleteCommand{}
+	recorded := []int64{}
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SetAlertNotificationStateToPendingCommand) error {
+		cmd.ResultVersion = cmd.Version + 1
+		return nil
+	})
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SetAlertNotificationStateToCompleteCommand) error {
+		mu.Lock()
+		defer mu.Unlock()
+		completed = append(completed, cmd)
+		return nil
+	})
+	bus.AddHandler("test", func(cmd *models.RecordAlertNotificationDeliveryCommand) error {
+		mu.Lock()
+		defer mu.Unlock()
+		recorded = append(recorded, cmd.AlertId)
+		return nil
+	})
+
+	origHistoryMaxEntries := setting.AlertingNotificationHistoryMaxEntries
+	setting.AlertingNotificationHistoryMaxEntries = 10
+	t.Cleanup(func() {
+		setting.AlertingNotificationHistoryMaxEntries = origHistoryMaxEntries
+	})
+
+	notifier := &digestTestNotifier{testNotifier: testNotifier{Type: "test", GroupingWindow: 50 * time.Millisecond}}
+	service := newNotificationService(nil)
+
+	for i, name := range []string{"cpu", "memory", "cpu"} {
+		id := int64(i%2 + 1)
+		rule := &Rule{ID: id, OrgID: 1, Name: name, State: models.AlertStateAlerting}
+		evalContext := NewEvalContext(context.Background(), rule, &validations.OSSPluginRequestValidator{})
+		state := &notifierState{
+			notifier: notifier,
+			state:    &models.AlertNotificationState{Id: id, NotifierId: 1, OrgId: 1, AlertId: id, Version: int64(i)},
+		}
+
+		require.NoError(t, service.sendNotifications(evalContext, notifierStateSlice{state}))
+		// later evaluations must not change the queued notification.
+		rule.State = models.AlertStateOK
+	}
+
+	require.Empty(t, notifier.getDigests())
+	require.Eventually(t, func() bool { return len(notifier.getDigests()) == 1 }, time.Second, 10*time.Millisecond)
+
+	digest := notifier.getDigests()[0]
+	require.Len(t, digest.Grouped, 2)
+	assert.Equal(t, "[Alerting] 2 alerts (2 alerting)", digest.GetNotificationTitle())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, completed, 2)
+	// the notification state of cpu is completed with the version of its latest notification.
+	assert.Equal(t, int64(1), completed[0].Id)
+	assert.Equal(t, int64(3), completed[0].Version)
+	assert.Equal(t, int64(2), completed[1].Id)
+	assert.Equal(t, int64(2), completed[1].Version)
+	assert.Equal(t, []int64{1, 2}, recorded)
+}
+
+func TestNotificationGrouperRun(t *testing.T) {
+	var mu sync.Mutex
+	sent := []*notificationGroup{}
+	grouper := newNotificationGrouper(func(group *notificationGroup) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, group)
+	})
+
+	notifier := &testNotifier{Type: "test", DigestInterval: 24 * time.Hour}
+	add := func(alertID int64) {
+		evalContext := NewEvalContext(context.Background(), &Rule{ID: alertID, OrgID: 1}, &validations.OSSPluginRequestValidator{})
+		grouper.add(evalContext, &notifierState{
+			notifier: notifier,
+			state:    &models.AlertNotificationState{Id: alertID, NotifierId: 1, OrgId: 1, AlertId: alertID},
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- grouper.run(ctx) }()
+
+	add(1)
+	add(2)
+
+	// the queued notifications are sent when the alerting service stops.
+	cancel()
+	require.NoError(t, <-done)
+
+	mu.Lock()
+	require.Len(t, sent, 1)
+	assert.Len(t, sent[0].notifications, 2)
+	mu.Unlock()
+
+	// notifications of stopping alert jobs are sent right away.
+	add(3)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, sent, 2)
+	require.Len(t, sent[1].notifications, 1)
+	assert.Equal(t, int64(3), sent[1].notifications[0].evalContext.Rule.ID)
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
@@ -21,6 +25,34 @@ var newImageUploaderProvider = func() (imguploader.ImageUploader, error) {
 	return imguploader.NewImageUploader()
 }
 
+// Image modes control how a notifier delivers the rendered panel image.
+const (
+	// ImageModeURL links the image uploaded to the external image store,
+	// notifiers attach the image when no image store is configured.
+	ImageModeURL = "url"
+	// ImageModeAttachment attaches the image to the notification and
+	// never uploads it to the external image store.
+	ImageModeAttachment = "attachment"
+	// ImageModeNone sends notifications without an image.
+	ImageModeNone = "none"
+)
+
+func init() {
+	bus.AddEventListener(handleAlertNotificationDeleted)
+}
+
+// handleAlertNotificationDeleted removes the delivery metrics of a deleted
+// notification channel so that their labels don't accumulate.
+func handleAlertNotificationDeleted(event *events.AlertNotificationDeleted) error {
+	orgID := strconv.FormatInt(event.OrgId, 10)
+	for _, status := range []string{"success", "failure"} {
+		metrics.MAlertingNotificationChannelDelivery.DeleteLabelValues(orgID, event.Uid, event.Type, status)
+	}
+	metrics.MAlertingNotificationChannelDuration.DeleteLabelValues(orgID, event.Uid, event.Type)
+	metrics.MAlertingNotificationChannelLastSuccess.DeleteLabelValues(orgID, event.Uid, event.Type)
+	return nil
+}
+
 // NotifierPlugin holds meta information about a notifier.
 type NotifierPlugin struct {
 	Type        string           `json:"type"`
@@ -84,15 +116,23 @@ type ShowWhen struct {
 }
 
 func newNotificationService(renderService rendering.Service) *notificationService {
-	return &notificationService{
+	n := &notificationService{
 		log:           log.New("alerting.notifier"),
 		renderService: renderService,
 	}
+	n.grouper = newNotificationGrouper(n.sendGroup)
+	return n
 }
 
 type notificationService struct {
 	log           log.Logger
 	renderService rendering.Service
+	grouper       *notificationGrouper
+}
+
+// run sends the queued notifications of grouped notification channels when ctx is done.
+func (n *notificationService) run(ctx context.Context) error {
+	return n.grouper.run(ctx)
 }
 
 func (n *notificationService) SendIfNeeded(evalCtx *EvalContext) error {
@@ -114,12 +154,13 @@ func (n *notificationService) SendIfNeeded(evalCtx *EvalContext) error {
 		uploadEvalCtx.Ctx, uploadCtxCancel = context.WithTimeout(evalCtx.Ctx, timeout)
 
 		// Try to upload the image without consuming all the time allocated for EvalContext
-		if err = n.renderAndUploadImage(&uploadEvalCtx, timeout); err != nil {
+		if err = n.renderAndUploadImage(&uploadEvalCtx, timeout, notifierStates.ShouldPublishImage()); err != nil {
 			n.log.Error("Failed to render and upload alert panel image.", "ruleId", uploadEvalCtx.Rule.ID, "error", err)
 		}
 		uploadCtxCancel()
 		evalCtx.ImageOnDiskPath = uploadEvalCtx.ImageOnDiskPath
 		evalCtx.ImagePublicURL = uploadEvalCtx.ImagePublicURL
+		evalCtx.ImageData = uploadEvalCtx.ImageData
 	}
 
 	return n.sendNotifications(evalCtx, notifierStates)
@@ -135,7 +176,12 @@ func (n *notificationService) sendAndMarkAsComplete(evalContext *EvalContext, no
 		n.log.Error("failed trying to evaluate notification template fields", "uid", notifier.GetNotifierUID(), "error", err)
 	}
 
-	if err := notifier.Notify(evalContext); err != nil {
+	restoreImage := evalContext.limitImageToMode(notifier.GetImageMode())
+	sentAt := time.Now()
+	err := notifier.Notify(evalContext)
+	restoreImage()
+	n.recordDelivery(evalContext, notifierState, []int64{evalContext.Rule.ID}, sentAt, err)
+	if err != nil {
 		n.log.Error("failed to send notification", "uid", notifier.GetNotifierUID(), "error", err)
 		metrics.MAlertingNotificationFailed.WithLabelValues(notifier.GetType()).Inc()
 		return err
@@ -153,6 +199,59 @@ func (n *notificationService) sendAndMarkAsComplete(evalContext *EvalContext, no
 	return bus.DispatchCtx(evalContext.Ctx, cmd)
 }
 
+// recordDelivery updates the per channel metrics and stores the delivery
+// attempt in the notification channel history of each notified alert rule.
+func (n *notificationService) recordDelivery(evalContext *EvalContext, notifierState *notifierState, alertIDs []int64, sentAt time.Time, deliveryErr error) {
+	if evalContext.IsTestRun {
+		return
+	}
+
+	notifier := notifierState.notifier
+	latency := time.Since(sentAt)
+	orgID := strconv.FormatInt(evalContext.Rule.OrgID, 10)
+
+	status := "success"
+	if deliveryErr != nil {
+		status = "failure"
+	}
+	metrics.MAlertingNotificationChannelDelivery.WithLabelValues(orgID, notifier.GetNotifierUID(), notifier.GetType(), status).Inc()
+	metrics.MAlertingNotificationChannelDuration.WithLabelValues(orgID, notifier.GetNotifierUID(), notifier.GetType()).Observe(latency.Seconds())
+	if deliveryErr == nil {
+		metrics.MAlertingNotificationChannelLastSuccess.WithLabelValues(orgID, notifier.GetNotifierUID(), notifier.GetType()).SetToCurrentTime()
+	}
+
+	if setting.AlertingNotificationHistoryMaxEntries <= 0 {
+		return
+	}
+
+	for _, alertID := range alertIDs {
+		cmd := &models.RecordAlertNotificationDeliveryCommand{
+			OrgId:      evalContext.Rule.OrgID,
+			NotifierId: notifierState.state.NotifierId,
+			AlertId:    alertID,
+			Success:    deliveryErr == nil,
+			Latency:    latency,
+			SentAt:     sentAt,
+			MaxEntries: setting.AlertingNotificationHistoryMaxEntries,
+		}
+
+		if deliveryErr != nil {
+			cmd.Error = deliveryErr.Error()
+
+			var webhookErr models.WebhookResponseError
+			if errors.As(deliveryErr, &webhookErr) {
+				cmd.StatusCode = webhookErr.StatusCode
+			}
+		}
+
+		// the evaluation context may already be cancelled when delivery timed out,
+		// the attempt should be recorded regardless.
+		if err := bus.Dispatch(cmd); err != nil {
+			n.log.Error("Failed to record notification delivery", "uid", notifier.GetNotifierUID(), "alertId", alertID, "error", err)
+		}
+	}
+}
+
 func (n *notificationService) sendNotification(evalContext *EvalContext, notifierState *notifierState) error {
 	if !evalContext.IsTestRun {
 		setPendingCmd := &models.SetAlertNotificationStateToPendingCommand{
@@ -173,11 +272,80 @@ func (n *notificationService) sendNotification(evalContext *EvalContext, notifie
 		// We need to update state version to be able to log
 		// unexpected version conflicts when marking notifications as ok
 		notifierState.state.Version = setPendingCmd.ResultVersion
+
+		if isGrouped(notifierState.notifier) {
+			n.groupNotification(evalContext, notifierState)
+			return nil
+		}
 	}
 
 	return n.sendAndMarkAsComplete(evalContext, notifierState)
 }
 
+// groupNotification queues the notification until the grouping window or
+// digest interval of the notification channel ends.
+func (n *notificationService) groupNotification(evalContext *EvalContext, notifierState *notifierState) {
+	if err := evalContext.evaluateNotificationTemplateFields(); err != nil {
+		n.log.Error("failed trying to evaluate notification template fields", "uid", notifierState.notifier.GetNotifierUID(), "error", err)
+	}
+
+	// the rule is updated by later evaluations, keep the state it had when the notification was queued.
+	queued := *evalContext
+	rule := *evalContext.Rule
+	queued.Rule = &rule
+
+	n.log.Debug("Queuing notification", "type", notifierState.notifier.GetType(), "uid", notifierState.notifier.GetNotifierUID(), "ruleId", rule.ID)
+	n.grouper.add(&queued, notifierState)
+}
+
+// sendGroup sends the queued notifications of a notification channel as a
+// single digest notification.
+func (n *notificationService) sendGroup(group *notificationGroup) {
+	notifier := group.notifier
+
+	// the evaluation contexts of the queued notifications have already expired.
+	ctx, cancel := context.WithTimeout(context.Background(), setting.AlertingNotificationTimeout)
+	defer cancel()
+
+	evalContexts := make([]*EvalContext, 0, len(group.notifications))
+	alertIDs := make([]int64, 0, len(group.notifications))
+	for _, gn := range group.notifications {
+		evalContexts = append(evalContexts, gn.evalContext)
+		alertIDs = append(alertIDs, gn.evalContext.Rule.ID)
+	}
+	digest := newDigestEvalContext(ctx, evalContexts)
+
+	n.log.Debug("Sending notification digest", "type", notifier.GetType(), "uid", notifier.GetNotifierUID(), "notifications", len(group.notifications))
+	metrics.MAlertingNotificationSent.WithLabelValues(notifier.GetType()).Inc()
+
+	var err error
+	sentAt := time.Now()
+	if digestNotifier, ok := notifier.(DigestNotifier); ok {
+		err = digestNotifier.NotifyDigest(digest)
+	} else {
+		err = notifier.Notify(digest)
+	}
+	n.recordDelivery(digest, group.notifications[0].notifierState, alertIDs, sentAt, err)
+	if err != nil {
+		n.log.Error("failed to send notification digest", "uid", notifier.GetNotifierUID(), "error", err)
+		metrics.MAlertingNotificationFailed.WithLabelValues(notifier.GetType()).Inc()
+		return
+	}
+
+	// the group holds the latest notification of each alert rule, with the
+	// version its notification state was set to pending with.
+	for _, gn := range group.notifications {
+		state := gn.notifierState.state
+		cmd := &models.SetAlertNotificationStateToCompleteCommand{
+			Id:      state.Id,
+			Version: state.Version,
+		}
+		if err := bus.DispatchCtx(ctx, cmd); err != nil {
+			n.log.Error("Failed to complete notification state", "uid", notifier.GetNotifierUID(), "alertId", state.AlertId, "error", err)
+		}
+	}
+}
+
 func (n *notificationService) sendNotifications(evalContext *EvalContext, notifierStates notifierStateSlice) error {
 	for _, notifierState := range notifierStates {
 		err := n.sendNotification(evalContext, notifierState)
@@ -191,12 +359,7 @@ func (n *notificationService) sendNotifications(evalContext *EvalContext, notifi
 	return nil
 }
 
-func (n *notificationService) renderAndUploadImage(evalCtx *EvalContext, timeout time.Duration) (err error) {
-	uploader, err := newImageUploaderProvider()
-	if err != nil {
-		return err
-	}
-
+func (n *notificationService) renderAndUploadImage(evalCtx *EvalContext, timeout time.Duration, publish bool) (err error) {
 	renderOpts := rendering.Opts{
 		Width:           1000,
 		Height:          500,
@@ -224,6 +387,16 @@ func (n *notificationService) renderAndUploadImage(evalCtx *EvalContext, timeout
 	n.log.Debug("Rendered alert panel image", "ruleId", evalCtx.Rule.ID, "path", result.FilePath, "took", took)
 
 	evalCtx.ImageOnDiskPath = result.FilePath
+	n.readImageData(evalCtx)
+
+	if !publish {
+		return nil
+	}
+
+	uploader, err := newImageUploaderProvider()
+	if err != nil {
+		return err
+	}
 
 	n.log.Debug("Uploading alert panel image to external image store", "ruleId", evalCtx.Rule.ID, "path", evalCtx.ImageOnDiskPath)
 
@@ -241,6 +414,31 @@ func (n *notificationService) renderAndUploadImage(evalCtx *EvalContext, timeout
 	return nil
 }
 
+// readImageData loads the rendered image for notifiers attaching it,
+// images exceeding the attachment size limit are skipped.
+func (n *notificationService) readImageData(evalCtx *EvalContext) {
+	info, err := os.Stat(evalCtx.ImageOnDiskPath)
+	if err != nil {
+		n.log.Warn("Failed to read rendered alert panel image", "ruleId", evalCtx.Rule.ID, "path", evalCtx.ImageOnDiskPath, "error", err)
+		return
+	}
+
+	if setting.AlertingImageAttachmentMaxBytes > 0 && info.Size() > setting.AlertingImageAttachmentMaxBytes {
+		n.log.Warn("Rendered alert panel image exceeds the attachment size limit and will not be attached",
+			"ruleId", evalCtx.Rule.ID, "size", info.Size(), "limit", setting.AlertingImageAttachmentMaxBytes)
+		return
+	}
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because the path is returned by the rendering service.
+	data, err := ioutil.ReadFile(evalCtx.ImageOnDiskPath)
+	if err != nil {
+		n.log.Warn("Failed to read rendered alert panel image", "ruleId", evalCtx.Rule.ID, "path", evalCtx.ImageOnDiskPath, "error", err)
+		return
+	}
+	evalCtx.ImageData = data
+}
+
 func (n *notificationService) getNeededNotifiers(orgID int64, notificationUids []string, evalContext *EvalContext) (notifierStateSlice, error) {
 	query := &models.GetAlertNotificationsWithUidToSendQuery{OrgId: orgID, Uids: notificationUids}
 
@@ -286,6 +484,18 @@ func InitNotifier(model *models.AlertNotification) (Notifier, error) {
 		return nil, fmt.Errorf("unsupported notification type %q", model.Type)
 	}
 
+	if model.Settings != nil {
+		switch mode := model.Settings.Get("imageMode").MustString(); mode {
+		case "", ImageModeURL, ImageModeAttachment, ImageModeNone:
+		default:
+			return nil, ValidationError{Reason: fmt.Sprintf("Invalid value for imageMode: %q", mode)}
+		}
+
+		if _, _, err := GetNotificationGrouping(model.Settings); err != nil {
+			return nil, err
+		}
+	}
+
 	return notifierPlugin.Factory(model)
 }
 
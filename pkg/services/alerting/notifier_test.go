@@ -2,6 +2,8 @@ package alerting
 
 import (
 	"context"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -16,6 +18,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 )
 
@@ -151,6 +155,87 @@ func TestNotificationService(t *testing.T) {
 			assert.Equal(t, evalCtxWithoutMatch.Rule.Name, ctx.Rule.Name)
 			assert.Equal(t, evalCtxWithoutMatch.Rule.Message, ctx.Rule.Message)
 		})
+
+	notificationServiceScenario(t, "Given alert rule with attachment image mode should render but not upload image",
+		evalCtx, true, func(sc *scenarioContext) {
+			sc.imageMode = ImageModeAttachment
+			err := sc.notificationService.SendIfNeeded(evalCtx)
+			require.NoError(sc.t, err)
+
+			require.Equalf(sc.t, 1, sc.renderCount, "expected render to be called, but wasn't")
+			require.Equalf(sc.t, 0, sc.imageUploadCount, "expected image not to be uploaded, but it was")
+		})
+
+	notificationServiceScenario(t, "Given alert rule with no image mode should not render and upload image",
+		evalCtx, true, func(sc *scenarioContext) {
+			sc.imageMode = ImageModeNone
+			err := sc.notificationService.SendIfNeeded(evalCtx)
+			require.NoError(sc.t, err)
+
+			require.Equalf(sc.t, 0, sc.renderCount, "expected render not to be called, but it was")
+			require.Equalf(sc.t, 0, sc.imageUploadCount, "expected image not to be uploaded, but it was")
+		})
+
+	notificationServiceScenario(t, "Given alert rule should record notification delivery in history",
+		evalCtx, false, func(sc *scenarioContext) {
+			origHistoryMaxEntries := setting.AlertingNotificationHistoryMaxEntries
+			sc.t.Cleanup(func() {
+				setting.AlertingNotificationHistoryMaxEntries = origHistoryMaxEntries
+			})
+			setting.AlertingNotificationHistoryMaxEntries = 50
+			err := sc.notificationService.SendIfNeeded(evalCtx)
+			require.NoError(sc.t, err)
+
+			require.Len(sc.t, sc.deliveries, 1)
+			delivery := sc.deliveries[0]
+			assert.Equal(sc.t, int64(1), delivery.NotifierId)
+			assert.True(sc.t, delivery.Success)
+			assert.Empty(sc.t, delivery.Error)
+			assert.Equal(sc.t, 50, delivery.MaxEntries)
+		})
+
+	notificationServiceScenario(t, "Given notification history is disabled should not record notification delivery",
+		evalCtx, false, func(sc *scenarioContext) {
+			origHistoryMaxEntries := setting.AlertingNotificationHistoryMaxEntries
+			sc.t.Cleanup(func() {
+				setting.AlertingNotificationHistoryMaxEntries = origHistoryMaxEntries
+			})
+			setting.AlertingNotificationHistoryMaxEntries = 0
+			err := sc.notificationService.SendIfNeeded(evalCtx)
+			require.NoError(sc.t, err)
+
+			require.Empty(sc.t, sc.deliveries)
+		})
+}
+
+func TestReadImageData(t *testing.T) {
+	imagePath := filepath.Join(t.TempDir(), "image.png")
+	require.NoError(t, ioutil.WriteFile(imagePath, []byte("rendered image"), 0600))
+
+	origMaxBytes := setting.AlertingImageAttachmentMaxBytes
+	t.Cleanup(func() {
+		setting.AlertingImageAttachmentMaxBytes = origMaxBytes
+	})
+
+	service := newNotificationService(nil)
+
+	t.Run("Should read image within the size limit", func(t *testing.T) {
+		setting.AlertingImageAttachmentMaxBytes = 1024
+		evalCtx := NewEvalContext(context.Background(), &Rule{}, &validations.OSSPluginRequestValidator{})
+		evalCtx.ImageOnDiskPath = imagePath
+
+		service.readImageData(evalCtx)
+		require.Equal(t, []byte("rendered image"), evalCtx.ImageData)
+	})
+
+	t.Run("Should skip image exceeding the size limit", func(t *testing.T) {
+		setting.AlertingImageAttachmentMaxBytes = 4
+		evalCtx := NewEvalContext(context.Background(), &Rule{}, &validations.OSSPluginRequestValidator{})
+		evalCtx.ImageOnDiskPath = imagePath
+
+		service.readImageData(evalCtx)
+		require.Nil(t, evalCtx.ImageData)
+	})
 }
 
 type scenarioContext struct {
@@ -162,10 +247,29 @@ type scenarioContext struct {
 	uploadProvider      func(ctx context.Context, path string) (string, error)
 	renderProvider      func(ctx context.Context, opts rendering.Opts) (*rendering.RenderResult, error)
 	rendererAvailable   bool
+	deliveries          []*models.RecordAlertNotificationDeliveryCommand
+	imageMode           string
 }
 
 type scenarioFunc func(c *scenarioContext)
 
+func TestHandleAlertNotificationDeleted(t *testing.T) {
+	metrics.MAlertingNotificationChannelDelivery.WithLabelValues("1", "deleted-uid", "test", "success").Inc()
+	metrics.MAlertingNotificationChannelDelivery.WithLabelValues("1", "deleted-uid", "test", "failure").Inc()
+	metrics.MAlertingNotificationChannelDuration.WithLabelValues("1", "deleted-uid", "test").Observe(1)
+	metrics.MAlertingNotificationChannelLastSuccess.WithLabelValues("1", "deleted-uid", "test").SetToCurrentTime()
+	metrics.MAlertingNotificationChannelLastSuccess.WithLabelValues("1", "other-uid", "test").SetToCurrentTime()
+
+	err := handleAlertNotificationDeleted(&events.AlertNotificationDeleted{OrgId: 1, Uid: "deleted-uid", Type: "test"})
+	require.NoError(t, err)
+
+	assert.False(t, metrics.MAlertingNotificationChannelDelivery.DeleteLabelValues("1", "deleted-uid", "test", "success"))
+	assert.False(t, metrics.MAlertingNotificationChannelDelivery.DeleteLabelValues("1", "deleted-uid", "test", "failure"))
+	assert.False(t, metrics.MAlertingNotificationChannelDuration.DeleteLabelValues("1", "deleted-uid", "test"))
+	assert.False(t, metrics.MAlertingNotificationChannelLastSuccess.DeleteLabelValues("1", "deleted-uid", "test"))
+	assert.True(t, metrics.MAlertingNotificationChannelLastSuccess.DeleteLabelValues("1", "other-uid", "test"))
+}
+
 func notificationServiceScenario(t *testing.T, name string, evalCtx *EvalContext, uploadImage bool, fn scenarioFunc) {
 	t.Run(name, func(t *testing.T) {
 		RegisterNotifier(&NotifierPlugin{
@@ -177,6 +281,11 @@ func notificationServiceScenario(t *testing.T, name string, evalCtx *EvalContext
 
 		evalCtx.dashboardRef = &models.DashboardRef{Uid: "db-uid"}
 
+		scenarioCtx := &scenarioContext{
+			t:       t,
+			evalCtx: evalCtx,
+		}
+
 		bus.AddHandlerCtx("test", func(ctx context.Context, query *models.GetAlertNotificationsWithUidToSendQuery) error {
 			query.Result = []*models.AlertNotification{
 				{
@@ -184,6 +293,7 @@ func notificationServiceScenario(t *testing.T, name string, evalCtx *EvalContext
 					Type: "test",
 					Settings: simplejson.NewFromAny(map[string]interface{}{
 						"uploadImage": uploadImage,
+						"imageMode":   scenarioCtx.imageMode,
 					}),
 				},
 			}
@@ -195,6 +305,7 @@ func notificationServiceScenario(t *testing.T, name string, evalCtx *EvalContext
 				AlertId:                      evalCtx.Rule.ID,
 				AlertRuleStateUpdatedVersion: 1,
 				Id:                           1,
+				NotifierId:                   1,
 				OrgId:                        evalCtx.Rule.OrgID,
 				State:                        models.AlertNotificationStateUnknown,
 			}
@@ -211,10 +322,10 @@ func notificationServiceScenario(t *testing.T, name string, evalCtx *EvalContext
 
 		setting.AlertingNotificationTimeout = 30 * time.Second
 
-		scenarioCtx := &scenarioContext{
-			t:       t,
-			evalCtx: evalCtx,
-		}
+		bus.AddHandler("test", func(cmd *models.RecordAlertNotificationDeliveryCommand) error {
+			scenarioCtx.deliveries = append(scenarioCtx.deliveries, cmd)
+			return nil
+		})
 
 		uploadProvider := func(ctx context.Context, path string) (string, error) {
 			scenarioCtx.imageUploadCount++
@@ -274,9 +385,12 @@ type testNotifier struct {
 	UID                   string
 	IsDefault             bool
 	UploadImage           bool
+	ImageMode             string
 	SendReminder          bool
 	DisableResolveMessage bool
 	Frequency             time.Duration
+	GroupingWindow        time.Duration
+	DigestInterval        time.Duration
 }
 
 func newTestNotifier(model *models.AlertNotification) (Notifier, error) {
@@ -285,6 +399,9 @@ func newTestNotifier(model *models.AlertNotification) (Notifier, error) {
 	if exist {
 		uploadImage = value.MustBool()
 	}
+	if model.Settings.Get("imageMode").MustString() == ImageModeNone {
+		uploadImage = false
+	}
 
 	return &testNotifier{
 		UID:                   model.Uid,
@@ -292,6 +409,7 @@ func newTestNotifier(model *models.AlertNotification) (Notifier, error) {
 		IsDefault:             model.IsDefault,
 		Type:                  model.Type,
 		UploadImage:           uploadImage,
+		ImageMode:             model.Settings.Get("imageMode").MustString(),
 		SendReminder:          model.SendReminder,
 		DisableResolveMessage: model.DisableResolveMessage,
 		Frequency:             model.Frequency,
@@ -317,6 +435,24 @@ func (n *testNotifier) NeedsImage() bool {
 	return n.UploadImage
 }
 
+func (n *testNotifier) GetGroupingWindow() time.Duration {
+	return n.GroupingWindow
+}
+
+func (n *testNotifier) GetDigestInterval() time.Duration {
+	return n.DigestInterval
+}
+
+func (n *testNotifier) GetImageMode() string {
+	if !n.UploadImage {
+		return ImageModeNone
+	}
+	if n.ImageMode == "" {
+		return ImageModeURL
+	}
+	return n.ImageMode
+}
+
 func (n *testNotifier) GetNotifierUID() string {
 	return n.UID
 }
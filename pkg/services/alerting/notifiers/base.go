@@ -20,9 +20,12 @@ type NotifierBase struct {
 	UID                   string
 	IsDefault             bool
 	UploadImage           bool
+	ImageMode             string
 	SendReminder          bool
 	DisableResolveMessage bool
 	Frequency             time.Duration
+	GroupingWindow        time.Duration
+	DigestInterval        time.Duration
 
 	log log.Logger
 }
@@ -35,15 +38,29 @@ func NewNotifierBase(model *models.AlertNotification) NotifierBase {
 		uploadImage = value.MustBool()
 	}
 
+	imageMode := model.Settings.Get("imageMode").MustString()
+	switch imageMode {
+	case "":
+		imageMode = alerting.ImageModeURL
+	case alerting.ImageModeNone:
+		uploadImage = false
+	}
+
+	// invalid settings are rejected when the notifier is initialized.
+	groupingWindow, digestInterval, _ := alerting.GetNotificationGrouping(model.Settings)
+
 	return NotifierBase{
 		UID:                   model.Uid,
 		Name:                  model.Name,
 		IsDefault:             model.IsDefault,
 		Type:                  model.Type,
 		UploadImage:           uploadImage,
+		ImageMode:             imageMode,
 		SendReminder:          model.SendReminder,
 		DisableResolveMessage: model.DisableResolveMessage,
 		Frequency:             model.Frequency,
+		GroupingWindow:        groupingWindow,
+		DigestInterval:        digestInterval,
 		log:                   log.New("alerting.notifier." + model.Name),
 	}
 }
@@ -99,6 +116,12 @@ func (n *NotifierBase) ShouldNotify(ctx context.Context, context *alerting.EvalC
 		if lastUpdated.Add(1 * time.Minute).After(time.Now()) {
 			return false
 		}
+
+		// Do not notify if the notification is queued until the group of a grouped notifier is sent,
+		// only state changes replace queued notifications
+		if prevState == newState && lastUpdated.Add(n.groupDelay()+time.Minute).After(time.Now()) {
+			return false
+		}
 	}
 
 	// Do not notify when state is OK if DisableResolveMessage is set to true
@@ -109,6 +132,14 @@ func (n *NotifierBase) ShouldNotify(ctx context.Context, context *alerting.EvalC
 	return true
 }
 
+// groupDelay returns how long notifications are queued at most before their group is sent.
+func (n *NotifierBase) groupDelay() time.Duration {
+	if n.DigestInterval > 0 {
+		return n.DigestInterval
+	}
+	return n.GroupingWindow
+}
+
 // GetType returns the notifier type.
 func (n *NotifierBase) GetType() string {
 	return n.Type
@@ -119,6 +150,14 @@ func (n *NotifierBase) NeedsImage() bool {
 	return n.UploadImage
 }
 
+// GetImageMode returns how the image is delivered, see alerting.ImageModeURL.
+func (n *NotifierBase) GetImageMode() string {
+	if !n.UploadImage {
+		return alerting.ImageModeNone
+	}
+	return n.ImageMode
+}
+
 // GetNotifierUID returns the notifier `uid`.
 func (n *NotifierBase) GetNotifierUID() string {
 	return n.UID
@@ -146,3 +185,14 @@ func (n *NotifierBase) GetDisableResolveMessage() bool {
 func (n *NotifierBase) GetFrequency() time.Duration {
 	return n.Frequency
 }
+
+// GetGroupingWindow returns for how long notifications are collected
+// and sent as a single digest message.
+func (n *NotifierBase) GetGroupingWindow() time.Duration {
+	return n.GroupingWindow
+}
+
+// GetDigestInterval returns the interval digest messages are sent at.
+func (n *NotifierBase) GetDigestInterval() time.Duration {
+	return n.DigestInterval
+}
@@ -2,13 +2,19 @@ package notifiers
 
 import (
 	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/validations"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
@@ -19,12 +25,13 @@ func TestShouldSendAlertNotification(t *testing.T) {
 	tnow := time.Now()
 
 	tcs := []struct {
-		name         string
-		prevState    models.AlertStateType
-		newState     models.AlertStateType
-		sendReminder bool
-		frequency    time.Duration
-		state        *models.AlertNotificationState
+		name           string
+		prevState      models.AlertStateType
+		newState       models.AlertStateType
+		sendReminder   bool
+		frequency      time.Duration
+		groupingWindow time.Duration
+		state          *models.AlertNotificationState
 
 		expect bool
 	}{
@@ -131,6 +138,26 @@ func TestShouldSendAlertNotification(t *testing.T) {
 
 			expect: true,
 		},
+		{
+			name:           "alerting -> alerting reminder with notification state pending in the grouping window should not trigger",
+			newState:       models.AlertStateAlerting,
+			prevState:      models.AlertStateAlerting,
+			sendReminder:   true,
+			frequency:      time.Minute,
+			groupingWindow: time.Hour,
+			state:          &models.AlertNotificationState{State: models.AlertNotificationStatePending, UpdatedAt: tnow.Add(-10 * time.Minute).Unix()},
+
+			expect: false,
+		},
+		{
+			name:           "OK -> alerting with notification state pending in the grouping window should trigger",
+			newState:       models.AlertStateAlerting,
+			prevState:      models.AlertStateOK,
+			groupingWindow: time.Hour,
+			state:          &models.AlertNotificationState{State: models.AlertNotificationStatePending, UpdatedAt: tnow.Add(-10 * time.Minute).Unix()},
+
+			expect: true,
+		},
 		{
 			name:      "unknown -> ok",
 			prevState: models.AlertStateUnknown,
@@ -178,7 +205,7 @@ func TestShouldSendAlertNotification(t *testing.T) {
 		}
 
 		evalContext.Rule.State = tc.newState
-		nb := &NotifierBase{SendReminder: tc.sendReminder, Frequency: tc.frequency}
+		nb := &NotifierBase{SendReminder: tc.sendReminder, Frequency: tc.frequency, GroupingWindow: tc.groupingWindow}
 
 		r := nb.ShouldNotify(evalContext.Ctx, evalContext, tc.state)
 		assert.Equal(t, r, tc.expect, "failed test %s. expected %+v to return: %v", tc.name, tc, tc.expect)
@@ -219,5 +246,82 @@ func TestBaseNotifier(t *testing.T) {
 			base := NewNotifierBase(model)
 			So(base.DisableResolveMessage, ShouldBeFalse)
 		})
+
+		Convey("image mode should default to url", func() {
+			base := NewNotifierBase(model)
+			So(base.GetImageMode(), ShouldEqual, alerting.ImageModeURL)
+		})
+
+		Convey("can parse attachment image mode", func() {
+			bJSON.Set("imageMode", "attachment")
+
+			base := NewNotifierBase(model)
+			So(base.GetImageMode(), ShouldEqual, alerting.ImageModeAttachment)
+			So(base.NeedsImage(), ShouldBeTrue)
+		})
+
+		Convey("none image mode should disable the image", func() {
+			bJSON.Set("imageMode", "none")
+
+			base := NewNotifierBase(model)
+			So(base.GetImageMode(), ShouldEqual, alerting.ImageModeNone)
+			So(base.NeedsImage(), ShouldBeFalse)
+		})
+
+		Convey("disabled image should use none image mode", func() {
+			bJSON.Set("uploadImage", false)
+			bJSON.Set("imageMode", "attachment")
+
+			base := NewNotifierBase(model)
+			So(base.GetImageMode(), ShouldEqual, alerting.ImageModeNone)
+		})
 	})
 }
+
+// receivedWebhook is a request captured by a webhook test server.
+type receivedWebhook struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// newWebhookTestServer starts an httptest server standing in for the
+// notifier's remote end and routes SendWebhookSync commands to it. The
+// returned URL should be used as the notifier's target URL.
+func newWebhookTestServer(t *testing.T) (string, *[]receivedWebhook) {
+	t.Helper()
+
+	received := []receivedWebhook{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = append(received, receivedWebhook{
+			Method: r.Method,
+			Path:   r.URL.EscapedPath(),
+			Header: r.Header,
+			Body:   body,
+		})
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+		req, err := http.NewRequestWithContext(ctx, cmd.HttpMethod, cmd.Url, strings.NewReader(cmd.Body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", cmd.ContentType)
+		for k, v := range cmd.HttpHeader {
+			req.Header.Set(k, v)
+		}
+		resp, err := server.Client().Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	return server.URL, &received
+}
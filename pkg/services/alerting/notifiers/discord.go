@@ -3,9 +3,7 @@ package notifiers
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"mime/multipart"
-	"os"
 	"strconv"
 	"strings"
 
@@ -121,7 +119,7 @@ func (dn *DiscordNotifier) Notify(evalContext *alerting.EvalContext) error {
 				"url": evalContext.ImagePublicURL,
 			}
 			embed.Set("image", image)
-		} else {
+		} else if len(evalContext.ImageData) > 0 {
 			image = map[string]interface{}{
 				"url": "attachment://graph.png",
 			}
@@ -143,7 +141,7 @@ func (dn *DiscordNotifier) Notify(evalContext *alerting.EvalContext) error {
 	if !embeddedImage {
 		cmd.Body = string(json)
 	} else {
-		err := dn.embedImage(cmd, evalContext.ImageOnDiskPath, json)
+		err := dn.embedImage(cmd, evalContext.ImageData, json)
 		if err != nil {
 			dn.log.Error("failed to embed image", "error", err)
 			return err
@@ -158,26 +156,7 @@ func (dn *DiscordNotifier) Notify(evalContext *alerting.EvalContext) error {
 	return nil
 }
 
-func (dn *DiscordNotifier) embedImage(cmd *models.SendWebhookSync, imagePath string, existingJSONBody []byte) error {
-	// nolint:gosec
-	// We can ignore the gosec G304 warning on this one because `imagePath` comes
-	// from the alert `evalContext` that generates the images.
-	f, err := os.Open(imagePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			cmd.Body = string(existingJSONBody)
-			return nil
-		}
-		if !os.IsNotExist(err) {
-			return err
-		}
-	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			dn.log.Warn("Failed to close file", "path", imagePath, "err", err)
-		}
-	}()
-
+func (dn *DiscordNotifier) embedImage(cmd *models.SendWebhookSync, image []byte, existingJSONBody []byte) error {
 	var b bytes.Buffer
 	w := multipart.NewWriter(&b)
 	defer func() {
@@ -200,7 +179,7 @@ func (dn *DiscordNotifier) embedImage(cmd *models.SendWebhookSync, imagePath str
 		return err
 	}
 
-	if _, err = io.Copy(fw, f); err != nil {
+	if _, err = fw.Write(image); err != nil {
 		return err
 	}
 
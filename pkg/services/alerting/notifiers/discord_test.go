@@ -1,11 +1,19 @@
 package notifiers
 
 import (
+	"context"
+	"mime"
+	"mime/multipart"
+	"strings"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/validations"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDiscordNotifier(t *testing.T) {
@@ -51,3 +59,34 @@ func TestDiscordNotifier(t *testing.T) {
 		})
 	})
 }
+
+func TestDiscordNotifierAttachesImage(t *testing.T) {
+	url, received := newWebhookTestServer(t)
+
+	settingsJSON := simplejson.New()
+	settingsJSON.Set("url", url)
+	settingsJSON.Set("imageMode", "attachment")
+	not, err := newDiscordNotifier(&models.AlertNotification{Name: "ops", Type: "discord", Settings: settingsJSON})
+	require.NoError(t, err)
+
+	evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+		Name:  "someRule",
+		State: models.AlertStateAlerting,
+	}, &validations.OSSPluginRequestValidator{})
+	evalContext.IsTestRun = true
+	evalContext.ImageData = []byte("rendered image")
+
+	require.NoError(t, not.Notify(evalContext))
+	require.Len(t, *received, 1)
+
+	mediaType, params, err := mime.ParseMediaType((*received)[0].Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/form-data", mediaType)
+
+	form, err := multipart.NewReader(strings.NewReader(string((*received)[0].Body)), params["boundary"]).ReadForm(1024)
+	require.NoError(t, err)
+	assert.Contains(t, form.Value["payload_json"][0], "attachment://graph.png")
+	require.Len(t, form.File["file"], 1)
+	assert.Equal(t, "graph.png", form.File["file"][0].Filename)
+	assert.Equal(t, int64(len("rendered image")), form.File["file"][0].Size)
+}
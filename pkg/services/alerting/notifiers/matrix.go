@@ -0,0 +1,203 @@
+package notifiers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const matrixSendMessagePath = "/_matrix/client/r0/rooms/%s/send/m.room.message/%s"
+
+func init() {
+	alerting.RegisterNotifier(&alerting.NotifierPlugin{
+		Type:        "matrix",
+		Name:        "Matrix",
+		Description: "Sends notifications to a Matrix room using the client-server API",
+		Heading:     "Matrix settings",
+		Factory:     NewMatrixNotifier,
+		Options: []alerting.NotifierOption{
+			{
+				Label:        "Homeserver URL",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "https://matrix.example.com",
+				PropertyName: "homeserverUrl",
+				Required:     true,
+			},
+			{
+				Label:        "Access Token",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Access token of the user sending the notifications, the user has to be a member of the room",
+				PropertyName: "accessToken",
+				Required:     true,
+				Secure:       true,
+			},
+			{
+				Label:        "Room ID",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "!roomid:example.com",
+				PropertyName: "roomId",
+				Required:     true,
+			},
+			{
+				Label:        "Mention Users",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Mention one or more users (comma separated) by Matrix ID, e.g. @alice:example.com",
+				PropertyName: "mentionUsers",
+			},
+			{
+				Label:        "Send as notice",
+				Element:      alerting.ElementTypeCheckbox,
+				Description:  "Send the message as m.notice, which clients usually render less prominently and bots do not respond to",
+				PropertyName: "notice",
+			},
+		},
+	})
+}
+
+// NewMatrixNotifier is the constructor for the Matrix notifier
+func NewMatrixNotifier(model *models.AlertNotification) (alerting.Notifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	homeserverURL := strings.TrimSuffix(model.Settings.Get("homeserverUrl").MustString(), "/")
+	if homeserverURL == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find homeserverUrl property in settings"}
+	}
+
+	accessToken := model.DecryptedValue("accessToken", model.Settings.Get("accessToken").MustString())
+	if accessToken == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find accessToken property in settings"}
+	}
+
+	roomID := strings.TrimSpace(model.Settings.Get("roomId").MustString())
+	if roomID == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find roomId property in settings"}
+	}
+
+	return &MatrixNotifier{
+		NotifierBase:  NewNotifierBase(model),
+		HomeserverURL: homeserverURL,
+		AccessToken:   accessToken,
+		RoomID:        roomID,
+		MentionUsers:  splitCommaSeparated(model.Settings.Get("mentionUsers").MustString()),
+		Notice:        model.Settings.Get("notice").MustBool(),
+		log:           log.New("alerting.notifier.matrix"),
+	}, nil
+}
+
+// MatrixNotifier is responsible for sending
+// alert notifications to a Matrix room.
+type MatrixNotifier struct {
+	NotifierBase
+	HomeserverURL string
+	AccessToken   string
+	RoomID        string
+	MentionUsers  []string
+	Notice        bool
+	log           log.Logger
+}
+
+// Notify sends an alert notification to Matrix.
+func (mn *MatrixNotifier) Notify(evalContext *alerting.EvalContext) error {
+	mn.log.Info("Executing matrix notification", "ruleId", evalContext.Rule.ID, "notification", mn.Name)
+
+	ruleURL, err := evalContext.GetRuleURL()
+	if err != nil {
+		mn.log.Error("Failed get rule link", "error", err)
+		return err
+	}
+
+	var plain, formatted strings.Builder
+
+	if len(mn.MentionUsers) > 0 {
+		mentions := make([]string, 0, len(mn.MentionUsers))
+		links := make([]string, 0, len(mn.MentionUsers))
+		for _, u := range mn.MentionUsers {
+			mentions = append(mentions, u)
+			links = append(links, fmt.Sprintf(`<a href="https://matrix.to/#/%s">%s</a>`, html.EscapeString(u), html.EscapeString(u)))
+		}
+		plain.WriteString(strings.Join(mentions, " ") + "\n")
+		formatted.WriteString(strings.Join(links, " ") + "<br>")
+	}
+
+	title := evalContext.GetNotificationTitle()
+	plain.WriteString(title + "\n")
+	formatted.WriteString(fmt.Sprintf(`<strong><a href="%s">%s</a></strong><br>`, html.EscapeString(ruleURL), html.EscapeString(title)))
+
+	if evalContext.Rule.State != models.AlertStateOK && evalContext.Rule.Message != "" {
+		plain.WriteString(evalContext.Rule.Message + "\n")
+		formatted.WriteString(html.EscapeString(evalContext.Rule.Message) + "<br>")
+	}
+
+	if len(evalContext.EvalMatches) > 0 {
+		formatted.WriteString("<ul>")
+		fieldLimitCount := 4
+		for index, evt := range evalContext.EvalMatches {
+			if index >= fieldLimitCount {
+				break
+			}
+			plain.WriteString(fmt.Sprintf("%s: %s\n", evt.Metric, evt.Value.FullString()))
+			formatted.WriteString(fmt.Sprintf("<li>%s: %s</li>", html.EscapeString(evt.Metric), html.EscapeString(evt.Value.FullString())))
+		}
+		formatted.WriteString("</ul>")
+	}
+
+	if evalContext.Error != nil {
+		plain.WriteString("Error message: " + evalContext.Error.Error() + "\n")
+		formatted.WriteString("Error message: " + html.EscapeString(evalContext.Error.Error()) + "<br>")
+	}
+
+	// Matrix clients only render images hosted on the homeserver, so
+	// images uploaded to the external image store are linked instead.
+	if mn.NeedsImage() && evalContext.ImagePublicURL != "" {
+		plain.WriteString("Image: " + evalContext.ImagePublicURL + "\n")
+		formatted.WriteString(fmt.Sprintf(`<a href="%s">Image</a><br>`, html.EscapeString(evalContext.ImagePublicURL)))
+	}
+
+	msgType := "m.text"
+	if mn.Notice {
+		msgType = "m.notice"
+	}
+
+	body := map[string]interface{}{
+		"msgtype":        msgType,
+		"body":           strings.TrimSuffix(plain.String(), "\n"),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": strings.TrimSuffix(formatted.String(), "<br>"),
+	}
+
+	data, err := json.Marshal(&body)
+	if err != nil {
+		return err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         mn.HomeserverURL + fmt.Sprintf(matrixSendMessagePath, url.PathEscape(mn.RoomID), util.GenerateShortUID()),
+		Body:        string(data),
+		HttpMethod:  "PUT",
+		ContentType: "application/json",
+		HttpHeader: map[string]string{
+			"Authorization": "Bearer " + mn.AccessToken,
+		},
+	}
+
+	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
+		mn.log.Error("Failed to send matrix notification", "error", err, "webhook", mn.Name)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/validations"
+)
+
+func TestMatrixNotifier(t *testing.T) {
+	t.Run("empty settings should return error", func(t *testing.T) {
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "matrix",
+			Settings: simplejson.New(),
+		}
+
+		_, err := NewMatrixNotifier(model)
+		require.Error(t, err)
+	})
+
+	t.Run("missing room should return error", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{"homeserverUrl": "https://matrix.local", "accessToken": "secret"}`))
+		require.NoError(t, err)
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "matrix",
+			Settings: settingsJSON,
+		}
+
+		_, err = NewMatrixNotifier(model)
+		require.Error(t, err)
+	})
+
+	t.Run("from settings", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`
+		{
+			"homeserverUrl": "https://matrix.local/",
+			"accessToken": "secret",
+			"roomId": "!abc:matrix.local",
+			"mentionUsers": "@alice:matrix.local",
+			"notice": true
+		}`))
+		require.NoError(t, err)
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "matrix",
+			Settings: settingsJSON,
+		}
+
+		not, err := NewMatrixNotifier(model)
+		require.NoError(t, err)
+		matrixNotifier := not.(*MatrixNotifier)
+
+		assert.Equal(t, "ops", matrixNotifier.Name)
+		assert.Equal(t, "matrix", matrixNotifier.Type)
+		assert.Equal(t, "https://matrix.local", matrixNotifier.HomeserverURL)
+		assert.Equal(t, "secret", matrixNotifier.AccessToken)
+		assert.Equal(t, "!abc:matrix.local", matrixNotifier.RoomID)
+		assert.Equal(t, []string{"@alice:matrix.local"}, matrixNotifier.MentionUsers)
+		assert.True(t, matrixNotifier.Notice)
+	})
+
+	t.Run("sends message to room", func(t *testing.T) {
+		url, received := newWebhookTestServer(t)
+
+		settingsJSON := simplejson.New()
+		settingsJSON.Set("homeserverUrl", url)
+		settingsJSON.Set("accessToken", "secret")
+		settingsJSON.Set("roomId", "!abc:matrix.local")
+		settingsJSON.Set("mentionUsers", "@alice:matrix.local")
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "matrix",
+			Settings: settingsJSON,
+		}
+
+		not, err := NewMatrixNotifier(model)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+			Name:    "someRule",
+			Message: "someMessage <b>",
+			State:   models.AlertStateAlerting,
+		}, &validations.OSSPluginRequestValidator{})
+		evalContext.IsTestRun = true
+		evalContext.ImagePublicURL = "https://images.local/graph.png"
+		evalContext.EvalMatches = []*alerting.EvalMatch{
+			{Metric: "cpu", Value: null.FloatFrom(92)},
+		}
+
+		err = not.Notify(evalContext)
+		require.NoError(t, err)
+		require.Len(t, *received, 1)
+
+		req := (*received)[0]
+		assert.Equal(t, "PUT", req.Method)
+		assert.True(t, strings.HasPrefix(req.Path, "/_matrix/client/r0/rooms/%21abc:matrix.local/send/m.room.message/"), req.Path)
+		assert.Equal(t, "Bearer secret", req.Header.Get("Authorization"))
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(req.Body, &body))
+		assert.Equal(t, "m.text", body["msgtype"])
+		assert.Equal(t, "org.matrix.custom.html", body["format"])
+		assert.Equal(t, "@alice:matrix.local\n[Alerting] someRule\nsomeMessage <b>\ncpu: 92.000000\nImage: https://images.local/graph.png", body["body"])
+		assert.Contains(t, body["formatted_body"], "someMessage &lt;b&gt;")
+		assert.Contains(t, body["formatted_body"], `<a href="https://matrix.to/#/@alice:matrix.local">@alice:matrix.local</a>`)
+		assert.Contains(t, body["formatted_body"], `<a href="https://images.local/graph.png">Image</a>`)
+	})
+
+	t.Run("sends at most four eval matches", func(t *testing.T) {
+		url, received := newWebhookTestServer(t)
+
+		settingsJSON := simplejson.New()
+		settingsJSON.Set("homeserverUrl", url)
+		settingsJSON.Set("accessToken", "secret")
+		settingsJSON.Set("roomId", "!abc:matrix.local")
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "matrix",
+			Settings: settingsJSON,
+		}
+
+		not, err := NewMatrixNotifier(model)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+			Name:  "someRule",
+			State: models.AlertStateAlerting,
+		}, &validations.OSSPluginRequestValidator{})
+		evalContext.IsTestRun = true
+		for i := 0; i < 6; i++ {
+			evalContext.EvalMatches = append(evalContext.EvalMatches, &alerting.EvalMatch{
+				Metric: fmt.Sprintf("cpu%d", i),
+				Value:  null.FloatFrom(92),
+			})
+		}
+
+		err = not.Notify(evalContext)
+		require.NoError(t, err)
+		require.Len(t, *received, 1)
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal((*received)[0].Body, &body))
+		assert.Equal(t, "[Alerting] someRule\ncpu0: 92.000000\ncpu1: 92.000000\ncpu2: 92.000000\ncpu3: 92.000000", body["body"])
+		assert.Equal(t, 4, strings.Count(body["formatted_body"], "<li>"))
+	})
+}
@@ -0,0 +1,235 @@
+package notifiers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func init() {
+	alerting.RegisterNotifier(&alerting.NotifierPlugin{
+		Type:        "mattermost",
+		Name:        "Mattermost",
+		Description: "Sends notifications to Mattermost via incoming webhooks",
+		Heading:     "Mattermost settings",
+		Factory:     NewMattermostNotifier,
+		Options: []alerting.NotifierOption{
+			{
+				Label:        "Url",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "Mattermost incoming webhook url",
+				PropertyName: "url",
+				Required:     true,
+				Secure:       true,
+			},
+			{
+				Label:        "Channel",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Override the default channel of the webhook, use the channel name (e.g. town-square) or @username for a direct message",
+				PropertyName: "channel",
+			},
+			{
+				Label:        "Username",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Set the username for the bot's message, requires username overrides to be enabled in Mattermost",
+				PropertyName: "username",
+			},
+			{
+				Label:        "Icon URL",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Provide a URL to an image to use as the icon for the bot's message",
+				PropertyName: "iconUrl",
+			},
+			{
+				Label:        "Mention Users",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Mention one or more users (comma separated) by username when notifying in a channel",
+				PropertyName: "mentionUsers",
+			},
+			{
+				Label:   "Mention Channel",
+				Element: alerting.ElementTypeSelect,
+				SelectOptions: []alerting.SelectOption{
+					{
+						Value: "",
+						Label: "Disabled",
+					},
+					{
+						Value: "here",
+						Label: "Every active channel member",
+					},
+					{
+						Value: "channel",
+						Label: "Every channel member",
+					},
+				},
+				Description:  "Mention whole channel or just active members when notifying",
+				PropertyName: "mentionChannel",
+			},
+		},
+	})
+}
+
+// NewMattermostNotifier is the constructor for the Mattermost notifier
+func NewMattermostNotifier(model *models.AlertNotification) (alerting.Notifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	url := model.DecryptedValue("url", model.Settings.Get("url").MustString())
+	if url == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
+	}
+
+	mentionChannel := model.Settings.Get("mentionChannel").MustString()
+	if mentionChannel != "" && mentionChannel != "here" && mentionChannel != "channel" {
+		return nil, alerting.ValidationError{
+			Reason: fmt.Sprintf("Invalid value for mentionChannel: %q", mentionChannel),
+		}
+	}
+
+	return &MattermostNotifier{
+		NotifierBase:   NewNotifierBase(model),
+		URL:            url,
+		Channel:        strings.TrimSpace(model.Settings.Get("channel").MustString()),
+		Username:       model.Settings.Get("username").MustString(),
+		IconURL:        model.Settings.Get("iconUrl").MustString(),
+		MentionUsers:   splitCommaSeparated(model.Settings.Get("mentionUsers").MustString()),
+		MentionChannel: mentionChannel,
+		log:            log.New("alerting.notifier.mattermost"),
+	}, nil
+}
+
+// MattermostNotifier is responsible for sending
+// alert notifications to Mattermost.
+type MattermostNotifier struct {
+	NotifierBase
+	URL            string
+	Channel        string
+	Username       string
+	IconURL        string
+	MentionUsers   []string
+	MentionChannel string
+	log            log.Logger
+}
+
+// Notify sends an alert notification to Mattermost.
+func (mn *MattermostNotifier) Notify(evalContext *alerting.EvalContext) error {
+	mn.log.Info("Executing mattermost notification", "ruleId", evalContext.Rule.ID, "notification", mn.Name)
+
+	ruleURL, err := evalContext.GetRuleURL()
+	if err != nil {
+		mn.log.Error("Failed get rule link", "error", err)
+		return err
+	}
+
+	fields := make([]map[string]interface{}, 0)
+	fieldLimitCount := 4
+	for index, evt := range evalContext.EvalMatches {
+		if index >= fieldLimitCount {
+			break
+		}
+		fields = append(fields, map[string]interface{}{
+			"title": evt.Metric,
+			"value": evt.Value.FullString(),
+			"short": true,
+		})
+	}
+
+	if evalContext.Error != nil {
+		fields = append(fields, map[string]interface{}{
+			"title": "Error message",
+			"value": evalContext.Error.Error(),
+			"short": false,
+		})
+	}
+
+	msg := ""
+	if evalContext.Rule.State != models.AlertStateOK { // don't add message when going back to alert state ok.
+		msg = evalContext.Rule.Message
+	}
+
+	attachment := map[string]interface{}{
+		"color":       evalContext.GetStateModel().Color,
+		"title":       evalContext.GetNotificationTitle(),
+		"title_link":  ruleURL,
+		"text":        msg,
+		"fallback":    evalContext.GetNotificationTitle(),
+		"fields":      fields,
+		"footer":      "Grafana v" + setting.BuildVersion,
+		"footer_icon": "https://grafana.com/assets/img/fav32.png",
+		"ts":          time.Now().Unix(),
+	}
+	if mn.NeedsImage() && evalContext.ImagePublicURL != "" {
+		attachment["image_url"] = evalContext.ImagePublicURL
+	}
+
+	body := map[string]interface{}{
+		"text":        mn.mentions(),
+		"attachments": []map[string]interface{}{attachment},
+	}
+	if mn.Channel != "" {
+		body["channel"] = mn.Channel
+	}
+	if mn.Username != "" {
+		body["username"] = mn.Username
+	}
+	if mn.IconURL != "" {
+		body["icon_url"] = mn.IconURL
+	}
+
+	data, err := json.Marshal(&body)
+	if err != nil {
+		return err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         mn.URL,
+		Body:        string(data),
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+	}
+
+	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
+		mn.log.Error("Failed to send mattermost notification", "error", err, "webhook", mn.Name)
+		return err
+	}
+
+	return nil
+}
+
+func (mn *MattermostNotifier) mentions() string {
+	mentions := make([]string, 0, len(mn.MentionUsers)+1)
+	if mn.MentionChannel != "" {
+		mentions = append(mentions, "@"+mn.MentionChannel)
+	}
+	for _, u := range mn.MentionUsers {
+		mentions = append(mentions, "@"+strings.TrimPrefix(u, "@"))
+	}
+	return strings.Join(mentions, " ")
+}
+
+// splitCommaSeparated splits a comma separated setting into its
+// trimmed, non-empty parts.
+func splitCommaSeparated(value string) []string {
+	parts := []string{}
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
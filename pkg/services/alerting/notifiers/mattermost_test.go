@@ -0,0 +1,182 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/validations"
+)
+
+func TestMattermostNotifier(t *testing.T) {
+	t.Run("empty settings should return error", func(t *testing.T) {
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "mattermost",
+			Settings: simplejson.New(),
+		}
+
+		_, err := NewMattermostNotifier(model)
+		require.Error(t, err)
+	})
+
+	t.Run("nil settings should return error", func(t *testing.T) {
+		_, err := NewMattermostNotifier(&models.AlertNotification{Name: "ops", Type: "mattermost"})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid mention channel should return error", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{"url": "http://mattermost.local/hooks/abc", "mentionChannel": "everyone"}`))
+		require.NoError(t, err)
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "mattermost",
+			Settings: settingsJSON,
+		}
+
+		_, err = NewMattermostNotifier(model)
+		require.Error(t, err)
+	})
+
+	t.Run("from settings", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`
+		{
+			"url": "http://mattermost.local/hooks/abc",
+			"channel": " alerts ",
+			"username": "grafana",
+			"iconUrl": "https://grafana.com/assets/img/fav32.png",
+			"mentionUsers": "alice, @bob,,",
+			"mentionChannel": "here"
+		}`))
+		require.NoError(t, err)
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "mattermost",
+			Settings: settingsJSON,
+		}
+
+		not, err := NewMattermostNotifier(model)
+		require.NoError(t, err)
+		mattermostNotifier := not.(*MattermostNotifier)
+
+		assert.Equal(t, "ops", mattermostNotifier.Name)
+		assert.Equal(t, "mattermost", mattermostNotifier.Type)
+		assert.Equal(t, "http://mattermost.local/hooks/abc", mattermostNotifier.URL)
+		assert.Equal(t, "alerts", mattermostNotifier.Channel)
+		assert.Equal(t, "grafana", mattermostNotifier.Username)
+		assert.Equal(t, []string{"alice", "@bob"}, mattermostNotifier.MentionUsers)
+		assert.Equal(t, "@here @alice @bob", mattermostNotifier.mentions())
+	})
+
+	t.Run("sends payload to webhook", func(t *testing.T) {
+		url, received := newWebhookTestServer(t)
+
+		settingsJSON := simplejson.New()
+		settingsJSON.Set("url", url+"/hooks/abc")
+		settingsJSON.Set("channel", "alerts")
+		settingsJSON.Set("mentionChannel", "channel")
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "mattermost",
+			Settings: settingsJSON,
+		}
+
+		not, err := NewMattermostNotifier(model)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+			Name:    "someRule",
+			Message: "someMessage",
+			State:   models.AlertStateAlerting,
+		}, &validations.OSSPluginRequestValidator{})
+		evalContext.IsTestRun = true
+		evalContext.ImagePublicURL = "https://images.local/graph.png"
+		evalContext.EvalMatches = []*alerting.EvalMatch{
+			{Metric: "cpu", Value: null.FloatFrom(92)},
+		}
+
+		err = not.Notify(evalContext)
+		require.NoError(t, err)
+		require.Len(t, *received, 1)
+
+		req := (*received)[0]
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "/hooks/abc", req.Path)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		var body struct {
+			Text        string `json:"text"`
+			Channel     string `json:"channel"`
+			Attachments []struct {
+				Title    string `json:"title"`
+				Text     string `json:"text"`
+				ImageURL string `json:"image_url"`
+				Fields   []struct {
+					Title string `json:"title"`
+					Value string `json:"value"`
+				} `json:"fields"`
+			} `json:"attachments"`
+		}
+		require.NoError(t, json.Unmarshal(req.Body, &body))
+		assert.Equal(t, "@channel", body.Text)
+		assert.Equal(t, "alerts", body.Channel)
+		require.Len(t, body.Attachments, 1)
+		assert.Equal(t, "[Alerting] someRule", body.Attachments[0].Title)
+		assert.Equal(t, "someMessage", body.Attachments[0].Text)
+		assert.Equal(t, "https://images.local/graph.png", body.Attachments[0].ImageURL)
+		require.Len(t, body.Attachments[0].Fields, 1)
+		assert.Equal(t, "cpu", body.Attachments[0].Fields[0].Title)
+		assert.Equal(t, "92.000000", body.Attachments[0].Fields[0].Value)
+	})
+
+	t.Run("sends at most four metric fields", func(t *testing.T) {
+		url, received := newWebhookTestServer(t)
+
+		settingsJSON := simplejson.New()
+		settingsJSON.Set("url", url+"/hooks/abc")
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "mattermost",
+			Settings: settingsJSON,
+		}
+
+		not, err := NewMattermostNotifier(model)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+			Name:  "someRule",
+			State: models.AlertStateAlerting,
+		}, &validations.OSSPluginRequestValidator{})
+		evalContext.IsTestRun = true
+		for i := 0; i < 6; i++ {
+			evalContext.EvalMatches = append(evalContext.EvalMatches, &alerting.EvalMatch{
+				Metric: fmt.Sprintf("cpu%d", i),
+				Value:  null.FloatFrom(92),
+			})
+		}
+
+		err = not.Notify(evalContext)
+		require.NoError(t, err)
+		require.Len(t, *received, 1)
+
+		var body struct {
+			Attachments []struct {
+				Fields []struct {
+					Title string `json:"title"`
+				} `json:"fields"`
+			} `json:"attachments"`
+		}
+		require.NoError(t, json.Unmarshal((*received)[0].Body, &body))
+		require.Len(t, body.Attachments, 1)
+		require.Len(t, body.Attachments[0].Fields, 4)
+		assert.Equal(t, "cpu3", body.Attachments[0].Fields[3].Title)
+	})
+}
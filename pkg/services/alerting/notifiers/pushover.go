@@ -3,9 +3,7 @@ package notifiers
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"mime/multipart"
-	"os"
 	"strconv"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -298,23 +296,13 @@ func (pn *PushoverNotifier) genPushoverBody(evalContext *alerting.EvalContext, m
 	w := multipart.NewWriter(&b)
 
 	// Add image only if requested and available
-	if pn.Upload && evalContext.ImageOnDiskPath != "" {
-		f, err := os.Open(evalContext.ImageOnDiskPath)
+	if pn.Upload && len(evalContext.ImageData) > 0 {
+		fw, err := w.CreateFormFile("attachment", "graph.png")
 		if err != nil {
 			return nil, b, err
 		}
-		defer func() {
-			if err := f.Close(); err != nil {
-				pn.log.Warn("Failed to close file", "path", evalContext.ImageOnDiskPath, "err", err)
-			}
-		}()
 
-		fw, err := w.CreateFormFile("attachment", evalContext.ImageOnDiskPath)
-		if err != nil {
-			return nil, b, err
-		}
-
-		_, err = io.Copy(fw, f)
+		_, err = fw.Write(evalContext.ImageData)
 		if err != nil {
 			return nil, b, err
 		}
@@ -0,0 +1,221 @@
+package notifiers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+)
+
+func init() {
+	alerting.RegisterNotifier(&alerting.NotifierPlugin{
+		Type:        "rocketchat",
+		Name:        "Rocket.Chat",
+		Description: "Sends notifications to Rocket.Chat via incoming webhooks",
+		Heading:     "Rocket.Chat settings",
+		Factory:     NewRocketChatNotifier,
+		Options: []alerting.NotifierOption{
+			{
+				Label:        "Url",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Placeholder:  "Rocket.Chat incoming webhook url",
+				PropertyName: "url",
+				Required:     true,
+				Secure:       true,
+			},
+			{
+				Label:        "Channel",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Override the default channel of the webhook, use #channel-name or @username",
+				PropertyName: "channel",
+			},
+			{
+				Label:        "Alias",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Set the name displayed for the bot's message",
+				PropertyName: "alias",
+			},
+			{
+				Label:        "Avatar URL",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Provide a URL to an image to use as the avatar for the bot's message",
+				PropertyName: "avatarUrl",
+			},
+			{
+				Label:        "Mention Users",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "Mention one or more users (comma separated) by username when notifying in a channel",
+				PropertyName: "mentionUsers",
+			},
+			{
+				Label:   "Mention Channel",
+				Element: alerting.ElementTypeSelect,
+				SelectOptions: []alerting.SelectOption{
+					{
+						Value: "",
+						Label: "Disabled",
+					},
+					{
+						Value: "here",
+						Label: "Every active channel member",
+					},
+					{
+						Value: "all",
+						Label: "Every channel member",
+					},
+				},
+				Description:  "Mention whole channel or just active members when notifying",
+				PropertyName: "mentionChannel",
+			},
+		},
+	})
+}
+
+// NewRocketChatNotifier is the constructor for the Rocket.Chat notifier
+func NewRocketChatNotifier(model *models.AlertNotification) (alerting.Notifier, error) {
+	if model.Settings == nil {
+		return nil, alerting.ValidationError{Reason: "No Settings Supplied"}
+	}
+
+	url := model.DecryptedValue("url", model.Settings.Get("url").MustString())
+	if url == "" {
+		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
+	}
+
+	mentionChannel := model.Settings.Get("mentionChannel").MustString()
+	if mentionChannel != "" && mentionChannel != "here" && mentionChannel != "all" {
+		return nil, alerting.ValidationError{
+			Reason: fmt.Sprintf("Invalid value for mentionChannel: %q", mentionChannel),
+		}
+	}
+
+	return &RocketChatNotifier{
+		NotifierBase:   NewNotifierBase(model),
+		URL:            url,
+		Channel:        strings.TrimSpace(model.Settings.Get("channel").MustString()),
+		Alias:          model.Settings.Get("alias").MustString(),
+		AvatarURL:      model.Settings.Get("avatarUrl").MustString(),
+		MentionUsers:   splitCommaSeparated(model.Settings.Get("mentionUsers").MustString()),
+		MentionChannel: mentionChannel,
+		log:            log.New("alerting.notifier.rocketchat"),
+	}, nil
+}
+
+// RocketChatNotifier is responsible for sending
+// alert notifications to Rocket.Chat.
+type RocketChatNotifier struct {
+	NotifierBase
+	URL            string
+	Channel        string
+	Alias          string
+	AvatarURL      string
+	MentionUsers   []string
+	MentionChannel string
+	log            log.Logger
+}
+
+// Notify sends an alert notification to Rocket.Chat.
+func (rn *RocketChatNotifier) Notify(evalContext *alerting.EvalContext) error {
+	rn.log.Info("Executing rocket.chat notification", "ruleId", evalContext.Rule.ID, "notification", rn.Name)
+
+	ruleURL, err := evalContext.GetRuleURL()
+	if err != nil {
+		rn.log.Error("Failed get rule link", "error", err)
+		return err
+	}
+
+	fields := make([]map[string]interface{}, 0)
+	fieldLimitCount := 4
+	for index, evt := range evalContext.EvalMatches {
+		if index >= fieldLimitCount {
+			break
+		}
+		fields = append(fields, map[string]interface{}{
+			"title": evt.Metric,
+			"value": evt.Value.FullString(),
+			"short": true,
+		})
+	}
+
+	if evalContext.Error != nil {
+		fields = append(fields, map[string]interface{}{
+			"title": "Error message",
+			"value": evalContext.Error.Error(),
+			"short": false,
+		})
+	}
+
+	msg := ""
+	if evalContext.Rule.State != models.AlertStateOK { // don't add message when going back to alert state ok.
+		msg = evalContext.Rule.Message
+	}
+
+	attachment := map[string]interface{}{
+		"color":      evalContext.GetStateModel().Color,
+		"title":      evalContext.GetNotificationTitle(),
+		"title_link": ruleURL,
+		"text":       msg,
+		"fields":     fields,
+	}
+	if rn.NeedsImage() && evalContext.ImagePublicURL != "" {
+		attachment["image_url"] = evalContext.ImagePublicURL
+	}
+
+	text := evalContext.GetNotificationTitle()
+	if mentions := rn.mentions(); mentions != "" {
+		text = mentions + " " + text
+	}
+
+	body := map[string]interface{}{
+		"text":        text,
+		"attachments": []map[string]interface{}{attachment},
+	}
+	if rn.Channel != "" {
+		body["channel"] = rn.Channel
+	}
+	if rn.Alias != "" {
+		body["alias"] = rn.Alias
+	}
+	if rn.AvatarURL != "" {
+		body["avatar"] = rn.AvatarURL
+	}
+
+	data, err := json.Marshal(&body)
+	if err != nil {
+		return err
+	}
+
+	cmd := &models.SendWebhookSync{
+		Url:         rn.URL,
+		Body:        string(data),
+		HttpMethod:  "POST",
+		ContentType: "application/json",
+	}
+
+	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
+		rn.log.Error("Failed to send rocket.chat notification", "error", err, "webhook", rn.Name)
+		return err
+	}
+
+	return nil
+}
+
+func (rn *RocketChatNotifier) mentions() string {
+	mentions := make([]string, 0, len(rn.MentionUsers)+1)
+	if rn.MentionChannel != "" {
+		mentions = append(mentions, "@"+rn.MentionChannel)
+	}
+	for _, u := range rn.MentionUsers {
+		mentions = append(mentions, "@"+strings.TrimPrefix(u, "@"))
+	}
+	return strings.Join(mentions, " ")
+}
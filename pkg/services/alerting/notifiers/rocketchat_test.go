@@ -0,0 +1,170 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/validations"
+)
+
+func TestRocketChatNotifier(t *testing.T) {
+	t.Run("empty settings should return error", func(t *testing.T) {
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "rocketchat",
+			Settings: simplejson.New(),
+		}
+
+		_, err := NewRocketChatNotifier(model)
+		require.Error(t, err)
+	})
+
+	t.Run("nil settings should return error", func(t *testing.T) {
+		_, err := NewRocketChatNotifier(&models.AlertNotification{Name: "ops", Type: "rocketchat"})
+		require.Error(t, err)
+	})
+
+	t.Run("invalid mention channel should return error", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`{"url": "http://rocket.local/hooks/abc", "mentionChannel": "channel"}`))
+		require.NoError(t, err)
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "rocketchat",
+			Settings: settingsJSON,
+		}
+
+		_, err = NewRocketChatNotifier(model)
+		require.Error(t, err)
+	})
+
+	t.Run("from settings", func(t *testing.T) {
+		settingsJSON, err := simplejson.NewJson([]byte(`
+		{
+			"url": "http://rocket.local/hooks/abc",
+			"channel": "#alerts",
+			"alias": "Grafana",
+			"avatarUrl": "https://grafana.com/assets/img/fav32.png",
+			"mentionUsers": "alice,bob",
+			"mentionChannel": "all"
+		}`))
+		require.NoError(t, err)
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "rocketchat",
+			Settings: settingsJSON,
+		}
+
+		not, err := NewRocketChatNotifier(model)
+		require.NoError(t, err)
+		rocketChatNotifier := not.(*RocketChatNotifier)
+
+		assert.Equal(t, "ops", rocketChatNotifier.Name)
+		assert.Equal(t, "rocketchat", rocketChatNotifier.Type)
+		assert.Equal(t, "http://rocket.local/hooks/abc", rocketChatNotifier.URL)
+		assert.Equal(t, "#alerts", rocketChatNotifier.Channel)
+		assert.Equal(t, "Grafana", rocketChatNotifier.Alias)
+		assert.Equal(t, "https://grafana.com/assets/img/fav32.png", rocketChatNotifier.AvatarURL)
+		assert.Equal(t, "@all @alice @bob", rocketChatNotifier.mentions())
+	})
+
+	t.Run("sends payload to webhook", func(t *testing.T) {
+		url, received := newWebhookTestServer(t)
+
+		settingsJSON := simplejson.New()
+		settingsJSON.Set("url", url+"/hooks/abc")
+		settingsJSON.Set("alias", "Grafana")
+		settingsJSON.Set("mentionUsers", "alice")
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "rocketchat",
+			Settings: settingsJSON,
+		}
+
+		not, err := NewRocketChatNotifier(model)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+			Name:    "someRule",
+			Message: "someMessage",
+			State:   models.AlertStateAlerting,
+		}, &validations.OSSPluginRequestValidator{})
+		evalContext.IsTestRun = true
+		evalContext.ImagePublicURL = "https://images.local/graph.png"
+
+		err = not.Notify(evalContext)
+		require.NoError(t, err)
+		require.Len(t, *received, 1)
+
+		req := (*received)[0]
+		assert.Equal(t, "POST", req.Method)
+		assert.Equal(t, "/hooks/abc", req.Path)
+
+		var body struct {
+			Text        string `json:"text"`
+			Alias       string `json:"alias"`
+			Attachments []struct {
+				Title    string `json:"title"`
+				Text     string `json:"text"`
+				ImageURL string `json:"image_url"`
+			} `json:"attachments"`
+		}
+		require.NoError(t, json.Unmarshal(req.Body, &body))
+		assert.Equal(t, "@alice [Alerting] someRule", body.Text)
+		assert.Equal(t, "Grafana", body.Alias)
+		require.Len(t, body.Attachments, 1)
+		assert.Equal(t, "someMessage", body.Attachments[0].Text)
+		assert.Equal(t, "https://images.local/graph.png", body.Attachments[0].ImageURL)
+	})
+
+	t.Run("sends at most four metric fields", func(t *testing.T) {
+		url, received := newWebhookTestServer(t)
+
+		settingsJSON := simplejson.New()
+		settingsJSON.Set("url", url+"/hooks/abc")
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "rocketchat",
+			Settings: settingsJSON,
+		}
+
+		not, err := NewRocketChatNotifier(model)
+		require.NoError(t, err)
+
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+			Name:  "someRule",
+			State: models.AlertStateAlerting,
+		}, &validations.OSSPluginRequestValidator{})
+		evalContext.IsTestRun = true
+		for i := 0; i < 6; i++ {
+			evalContext.EvalMatches = append(evalContext.EvalMatches, &alerting.EvalMatch{
+				Metric: fmt.Sprintf("cpu%d", i),
+				Value:  null.FloatFrom(92),
+			})
+		}
+
+		err = not.Notify(evalContext)
+		require.NoError(t, err)
+		require.Len(t, *received, 1)
+
+		var body struct {
+			Attachments []struct {
+				Fields []struct {
+					Title string `json:"title"`
+				} `json:"fields"`
+			} `json:"attachments"`
+		}
+		require.NoError(t, json.Unmarshal((*received)[0].Body, &body))
+		require.Len(t, body.Attachments, 1)
+		require.Len(t, body.Attachments[0].Fields, 4)
+		assert.Equal(t, "cpu3", body.Attachments[0].Fields[3].Title)
+	})
+}
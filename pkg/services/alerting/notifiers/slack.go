@@ -4,11 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -20,6 +17,10 @@ import (
 	"github.com/grafana/grafana/pkg/setting"
 )
 
+// slackMaxDigestAttachments limits the alerts listed in a digest message,
+// Slack recommends to not send more than 20 attachments per message.
+const slackMaxDigestAttachments = 20
+
 func init() {
 	alerting.RegisterNotifier(&alerting.NotifierPlugin{
 		Type:        "slack",
@@ -218,28 +219,6 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 		})
 	}
 
-	mentionsBuilder := strings.Builder{}
-	appendSpace := func() {
-		if mentionsBuilder.Len() > 0 {
-			mentionsBuilder.WriteString(" ")
-		}
-	}
-	mentionChannel := strings.TrimSpace(sn.MentionChannel)
-	if mentionChannel != "" {
-		mentionsBuilder.WriteString(fmt.Sprintf("<!%s|%s>", mentionChannel, mentionChannel))
-	}
-	if len(sn.MentionGroups) > 0 {
-		appendSpace()
-		for _, g := range sn.MentionGroups {
-			mentionsBuilder.WriteString(fmt.Sprintf("<!subteam^%s>", g))
-		}
-	}
-	if len(sn.MentionUsers) > 0 {
-		appendSpace()
-		for _, u := range sn.MentionUsers {
-			mentionsBuilder.WriteString(fmt.Sprintf("<@%s>", u))
-		}
-	}
 	msg := ""
 	if evalContext.Rule.State != models.AlertStateOK { // don't add message when going back to alert state ok.
 		msg = evalContext.Rule.Message
@@ -250,18 +229,6 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 		imageURL = evalContext.ImagePublicURL
 	}
 
-	var blocks []map[string]interface{}
-	if mentionsBuilder.Len() > 0 {
-		blocks = []map[string]interface{}{
-			{
-				"type": "section",
-				"text": map[string]interface{}{
-					"type": "mrkdwn",
-					"text": mentionsBuilder.String(),
-				},
-			},
-		}
-	}
 	attachment := map[string]interface{}{
 		"color":       evalContext.GetStateModel().Color,
 		"title":       evalContext.GetNotificationTitle(),
@@ -283,8 +250,75 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 		},
 		"parse": "full", // to linkify urls, users and channels in alert message.
 	}
-	if len(blocks) > 0 {
-		body["blocks"] = blocks
+
+	if err := sn.sendMessage(evalContext, body); err != nil {
+		return err
+	}
+	if sn.Token != "" && sn.UploadImage {
+		err = sn.slackFileUpload(evalContext, sn.log, "https://slack.com/api/files.upload", sn.Recipient, sn.Token)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NotifyDigest sends the grouped notifications as a single Slack
+// message with one attachment per alert.
+func (sn *SlackNotifier) NotifyDigest(evalContext *alerting.EvalContext) error {
+	sn.log.Info("Executing slack digest notification", "alerts", len(evalContext.Grouped), "notification", sn.Name)
+
+	attachments := make([]map[string]interface{}, 0, slackMaxDigestAttachments+1)
+	for index, c := range evalContext.Grouped {
+		if index == slackMaxDigestAttachments {
+			attachments = append(attachments, map[string]interface{}{
+				"text": fmt.Sprintf("and %d more", len(evalContext.Grouped)-slackMaxDigestAttachments),
+			})
+			break
+		}
+
+		ruleURL, err := c.GetRuleURL()
+		if err != nil {
+			sn.log.Error("Failed get rule link", "error", err)
+			return err
+		}
+
+		msg := ""
+		if c.Rule.State != models.AlertStateOK {
+			msg = c.Rule.Message
+		}
+
+		attachments = append(attachments, map[string]interface{}{
+			"color":      c.GetStateModel().Color,
+			"title":      c.GetNotificationTitle(),
+			"title_link": ruleURL,
+			"text":       msg,
+			"fallback":   c.GetNotificationTitle(),
+		})
+	}
+
+	body := map[string]interface{}{
+		"text":        evalContext.GetNotificationTitle(),
+		"attachments": attachments,
+		"parse":       "full",
+	}
+
+	return sn.sendMessage(evalContext, body)
+}
+
+// sendMessage adds the mentions and channel overrides to the
+// message body and posts it to Slack.
+func (sn *SlackNotifier) sendMessage(evalContext *alerting.EvalContext, body map[string]interface{}) error {
+	if mentions := sn.mentions(); mentions != "" {
+		body["blocks"] = []map[string]interface{}{
+			{
+				"type": "section",
+				"text": map[string]interface{}{
+					"type": "mrkdwn",
+					"text": mentions,
+				},
+			},
+		}
 	}
 
 	// recipient override
@@ -320,23 +354,42 @@ func (sn *SlackNotifier) Notify(evalContext *alerting.EvalContext) error {
 		sn.log.Error("Failed to send slack notification", "error", err, "webhook", sn.Name)
 		return err
 	}
-	if sn.Token != "" && sn.UploadImage {
-		err = sn.slackFileUpload(evalContext, sn.log, "https://slack.com/api/files.upload", sn.Recipient, sn.Token)
-		if err != nil {
-			return err
+	return nil
+}
+
+func (sn *SlackNotifier) mentions() string {
+	mentionsBuilder := strings.Builder{}
+	appendSpace := func() {
+		if mentionsBuilder.Len() > 0 {
+			mentionsBuilder.WriteString(" ")
 		}
 	}
-	return nil
+	mentionChannel := strings.TrimSpace(sn.MentionChannel)
+	if mentionChannel != "" {
+		mentionsBuilder.WriteString(fmt.Sprintf("<!%s|%s>", mentionChannel, mentionChannel))
+	}
+	if len(sn.MentionGroups) > 0 {
+		appendSpace()
+		for _, g := range sn.MentionGroups {
+			mentionsBuilder.WriteString(fmt.Sprintf("<!subteam^%s>", g))
+		}
+	}
+	if len(sn.MentionUsers) > 0 {
+		appendSpace()
+		for _, u := range sn.MentionUsers {
+			mentionsBuilder.WriteString(fmt.Sprintf("<@%s>", u))
+		}
+	}
+	return mentionsBuilder.String()
 }
 
 func (sn *SlackNotifier) slackFileUpload(evalContext *alerting.EvalContext, log log.Logger, url string, recipient string, token string) error {
-	if evalContext.ImageOnDiskPath == "" {
-		// nolint:gosec
-		// We can ignore the gosec G304 warning on this one because `setting.HomePath` comes from Grafana's configuration file.
-		evalContext.ImageOnDiskPath = filepath.Join(setting.HomePath, "public/img/mixed_styles.png")
+	if len(evalContext.ImageData) == 0 {
+		log.Debug("No image to upload to slack")
+		return nil
 	}
 	log.Info("Uploading to slack via file.upload API")
-	headers, uploadBody, err := sn.generateSlackBody(evalContext.ImageOnDiskPath, token, recipient)
+	headers, uploadBody, err := sn.generateSlackBody(evalContext.ImageData, token, recipient)
 	if err != nil {
 		return err
 	}
@@ -348,7 +401,7 @@ func (sn *SlackNotifier) slackFileUpload(evalContext *alerting.EvalContext, log
 	return nil
 }
 
-func (sn *SlackNotifier) generateSlackBody(path string, token string, recipient string) (map[string]string, bytes.Buffer, error) {
+func (sn *SlackNotifier) generateSlackBody(image []byte, token string, recipient string) (map[string]string, bytes.Buffer, error) {
 	// Slack requires all POSTs to files.upload to present
 	// an "application/x-www-form-urlencoded" encoded querystring
 	// See https://api.slack.com/methods/files.upload
@@ -362,24 +415,11 @@ func (sn *SlackNotifier) generateSlackBody(path string, token string, recipient
 	}()
 
 	// Add the generated image file
-	// We can ignore the gosec G304 warning on this one because `imagePath` comes
-	// from the alert `evalContext` that generates the images. `evalContext` in turn derives the root of the file
-	// path from configuration variables.
-	// nolint:gosec
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, b, err
-	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			sn.log.Warn("Failed to close file", "path", path, "err", err)
-		}
-	}()
-	fw, err := w.CreateFormFile("file", path)
+	fw, err := w.CreateFormFile("file", "graph.png")
 	if err != nil {
 		return nil, b, err
 	}
-	if _, err := io.Copy(fw, f); err != nil {
+	if _, err := fw.Write(image); err != nil {
 		return nil, b, err
 	}
 	// Add the authorization token
@@ -1,12 +1,15 @@
 package notifiers
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSlackNotifier(t *testing.T) {
@@ -224,3 +227,37 @@ func TestSlackNotifier(t *testing.T) {
 		})
 	})
 }
+
+func TestSlackNotifierDigest(t *testing.T) {
+	url, received := newWebhookTestServer(t)
+
+	settingsJSON := simplejson.New()
+	settingsJSON.Set("url", url)
+	settingsJSON.Set("mentionChannel", "here")
+	not, err := NewSlackNotifier(&models.AlertNotification{Name: "ops", Type: "slack", Settings: settingsJSON})
+	require.NoError(t, err)
+
+	err = not.(*SlackNotifier).NotifyDigest(newTestDigestEvalContext("cpu", "memory"))
+	require.NoError(t, err)
+	require.Len(t, *received, 1)
+
+	var body struct {
+		Text   string `json:"text"`
+		Blocks []struct {
+			Text struct {
+				Text string `json:"text"`
+			} `json:"text"`
+		} `json:"blocks"`
+		Attachments []struct {
+			Title string `json:"title"`
+			Text  string `json:"text"`
+		} `json:"attachments"`
+	}
+	require.NoError(t, json.Unmarshal((*received)[0].Body, &body))
+	assert.Equal(t, "[Alerting] 2 alerts (2 alerting)", body.Text)
+	require.Len(t, body.Blocks, 1)
+	assert.Equal(t, "<!here|here>", body.Blocks[0].Text.Text)
+	require.Len(t, body.Attachments, 2)
+	assert.Equal(t, "[Alerting] cpu", body.Attachments[0].Title)
+	assert.Equal(t, "cpu is too high", body.Attachments[0].Text)
+}
@@ -1,6 +1,7 @@
 package notifiers
 
 import (
+	"encoding/base64"
 	"encoding/json"
 
 	"github.com/grafana/grafana/pkg/bus"
@@ -9,6 +10,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/alerting"
 )
 
+// teamsMaxMessageBytes is the size limit of incoming webhook messages, Teams
+// rejects larger messages. Images embedded as data URI count towards it, so
+// only small images can be attached.
+const teamsMaxMessageBytes = 28 * 1024
+
 func init() {
 	alerting.RegisterNotifier(&alerting.NotifierPlugin{
 		Type:        "teams",
@@ -86,9 +92,42 @@ func (tn *TeamsNotifier) Notify(evalContext *alerting.EvalContext) error {
 	}
 
 	images := make([]map[string]interface{}, 0)
-	if tn.NeedsImage() && evalContext.ImagePublicURL != "" {
-		images = append(images, map[string]interface{}{
-			"image": evalContext.ImagePublicURL,
+	embeddedImage := false
+	if tn.NeedsImage() {
+		if evalContext.ImagePublicURL != "" {
+			images = append(images, map[string]interface{}{
+				"image": evalContext.ImagePublicURL,
+			})
+		} else if len(evalContext.ImageData) > 0 {
+			images = append(images, map[string]interface{}{
+				"image": "data:image/png;base64," + base64.StdEncoding.EncodeToString(evalContext.ImageData),
+			})
+			embeddedImage = true
+		}
+	}
+
+	potentialActions := []map[string]interface{}{
+		{
+			"@context": "http://schema.org",
+			"@type":    "OpenUri",
+			"name":     "View Rule",
+			"targets": []map[string]interface{}{
+				{
+					"os": "default", "uri": ruleURL,
+				},
+			},
+		},
+	}
+	if evalContext.ImagePublicURL != "" {
+		potentialActions = append(potentialActions, map[string]interface{}{
+			"@context": "http://schema.org",
+			"@type":    "OpenUri",
+			"name":     "View Graph",
+			"targets": []map[string]interface{}{
+				{
+					"os": "default", "uri": evalContext.ImagePublicURL,
+				},
+			},
 		})
 	}
 
@@ -108,31 +147,15 @@ func (tn *TeamsNotifier) Notify(evalContext *alerting.EvalContext) error {
 				"text":   message,
 			},
 		},
-		"potentialAction": []map[string]interface{}{
-			{
-				"@context": "http://schema.org",
-				"@type":    "OpenUri",
-				"name":     "View Rule",
-				"targets": []map[string]interface{}{
-					{
-						"os": "default", "uri": ruleURL,
-					},
-				},
-			},
-			{
-				"@context": "http://schema.org",
-				"@type":    "OpenUri",
-				"name":     "View Graph",
-				"targets": []map[string]interface{}{
-					{
-						"os": "default", "uri": evalContext.ImagePublicURL,
-					},
-				},
-			},
-		},
+		"potentialAction": potentialActions,
 	}
 
 	data, _ := json.Marshal(&body)
+	if embeddedImage && len(data) > teamsMaxMessageBytes {
+		tn.log.Warn("Image is too large to be embedded in a teams notification", "size", len(evalContext.ImageData), "messageSize", len(data), "limit", teamsMaxMessageBytes)
+		body["sections"].([]map[string]interface{})[0]["images"] = []map[string]interface{}{}
+		data, _ = json.Marshal(&body)
+	}
 	cmd := &models.SendWebhookSync{Url: tn.URL, Body: string(data)}
 
 	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
@@ -1,11 +1,18 @@
 package notifiers
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/validations"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTeamsNotifier(t *testing.T) {
@@ -71,3 +78,74 @@ func TestTeamsNotifier(t *testing.T) {
 		})
 	})
 }
+
+func TestTeamsNotifierImage(t *testing.T) {
+	image := []byte("rendered image")
+
+	tcs := []struct {
+		name           string
+		publicURL      string
+		data           []byte
+		expectedImages []string
+	}{
+		{
+			name:           "links public image",
+			publicURL:      "https://images.local/graph.png",
+			data:           image,
+			expectedImages: []string{"https://images.local/graph.png"},
+		},
+		{
+			name:           "embeds attached image as data uri",
+			data:           image,
+			expectedImages: []string{"data:image/png;base64," + base64.StdEncoding.EncodeToString(image)},
+		},
+		{
+			name:           "embeds attached image up to the message size",
+			data:           make([]byte, 20*1024),
+			expectedImages: []string{"data:image/png;base64," + base64.StdEncoding.EncodeToString(make([]byte, 20*1024))},
+		},
+		{
+			name:           "skips attached image exceeding the message size",
+			data:           make([]byte, 21*1024),
+			expectedImages: []string{},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			url, received := newWebhookTestServer(t)
+
+			settingsJSON := simplejson.New()
+			settingsJSON.Set("url", url)
+			not, err := NewTeamsNotifier(&models.AlertNotification{Name: "ops", Type: "teams", Settings: settingsJSON})
+			require.NoError(t, err)
+
+			evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+				Name:  "someRule",
+				State: models.AlertStateAlerting,
+			}, &validations.OSSPluginRequestValidator{})
+			evalContext.IsTestRun = true
+			evalContext.ImagePublicURL = tc.publicURL
+			evalContext.ImageData = tc.data
+
+			require.NoError(t, not.Notify(evalContext))
+			require.Len(t, *received, 1)
+			assert.LessOrEqual(t, len((*received)[0].Body), teamsMaxMessageBytes)
+
+			var body struct {
+				Sections []struct {
+					Images []struct {
+						Image string `json:"image"`
+					} `json:"images"`
+				} `json:"sections"`
+			}
+			require.NoError(t, json.Unmarshal((*received)[0].Body, &body))
+			require.Len(t, body.Sections, 1)
+
+			images := []string{}
+			for _, i := range body.Sections[0].Images {
+				images = append(images, i.Image)
+			}
+			assert.Equal(t, tc.expectedImages, images)
+		})
+	}
+}
@@ -3,9 +3,7 @@ package notifiers
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"mime/multipart"
-	"os"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -131,21 +129,6 @@ func (tn *TelegramNotifier) buildMessageLinkedImage(evalContext *alerting.EvalCo
 }
 
 func (tn *TelegramNotifier) buildMessageInlineImage(evalContext *alerting.EvalContext) (*models.SendWebhookSync, error) {
-	var imageFile *os.File
-	var err error
-
-	imageFile, err = os.Open(evalContext.ImageOnDiskPath)
-	if err != nil {
-		return nil, err
-	}
-
-	defer func() {
-		err := imageFile.Close()
-		if err != nil {
-			tn.log.Error("Could not close Telegram inline image.", "err", err)
-		}
-	}()
-
 	ruleURL, err := evalContext.GetRuleURL()
 	if err != nil {
 		return nil, err
@@ -155,13 +138,13 @@ func (tn *TelegramNotifier) buildMessageInlineImage(evalContext *alerting.EvalCo
 	message := generateImageCaption(evalContext, ruleURL, metrics)
 
 	return tn.generateTelegramCmd(message, "caption", "sendPhoto", func(w *multipart.Writer) {
-		fw, err := w.CreateFormFile("photo", evalContext.ImageOnDiskPath)
+		fw, err := w.CreateFormFile("photo", "graph.png")
 		if err != nil {
 			tn.log.Error("Failed to create form file", "err", err)
 			return
 		}
 
-		if _, err := io.Copy(fw, imageFile); err != nil {
+		if _, err := fw.Write(evalContext.ImageData); err != nil {
 			tn.log.Error("Failed to write to form file", "err", err)
 		}
 	})
@@ -260,7 +243,7 @@ func appendIfPossible(message string, extra string, sizeLimit int) string {
 func (tn *TelegramNotifier) Notify(evalContext *alerting.EvalContext) error {
 	var cmd *models.SendWebhookSync
 	var err error
-	if evalContext.ImagePublicURL == "" && tn.UploadImage {
+	if evalContext.ImagePublicURL == "" && tn.UploadImage && len(evalContext.ImageData) > 0 {
 		cmd, err = tn.buildMessage(evalContext, true)
 	} else {
 		cmd, err = tn.buildMessage(evalContext, false)
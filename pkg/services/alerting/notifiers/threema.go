@@ -1,10 +1,19 @@
 package notifiers
 
 import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"mime/multipart"
+	"net/http"
 	"net/url"
 	"strings"
 
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -15,14 +24,23 @@ var (
 	threemaGwBaseURL = "https://msgapi.threema.ch/%s"
 )
 
+// Types of end-to-end encrypted Threema messages.
+const (
+	threemaTextMessage byte = 0x01
+	threemaFileMessage byte = 0x17
+)
+
+// threemaBlobNonce is the nonce of encrypted files, each file is encrypted with a new key.
+var threemaBlobNonce = [24]byte{23: 1}
+
 func init() {
 	alerting.RegisterNotifier(&alerting.NotifierPlugin{
 		Type:        "threema",
 		Name:        "Threema Gateway",
 		Description: "Sends notifications to Threema using the Threema Gateway",
 		Heading:     "Threema Gateway settings",
-		Info: "Notifications can be configured for any Threema Gateway ID of type \"Basic\". End-to-End IDs are not currently supported." +
-			"The Threema Gateway ID can be set up at https://gateway.threema.ch/.",
+		Info: "Notifications can be configured for any Threema Gateway ID of type \"Basic\", or of type \"End-to-End\" with its private key. " +
+			"Images can only be attached with End-to-End IDs. The Threema Gateway ID can be set up at https://gateway.threema.ch/.",
 		Factory: NewThreemaNotifier,
 		Options: []alerting.NotifierOption{
 			{
@@ -54,6 +72,14 @@ func init() {
 				Required:     true,
 				Secure:       true,
 			},
+			{
+				Label:        "Private Key",
+				Element:      alerting.ElementTypeInput,
+				InputType:    alerting.InputTypeText,
+				Description:  "The private key of an End-to-End Threema Gateway ID, as 64 hex characters. Leave empty for Basic IDs.",
+				PropertyName: "private_key",
+				Secure:       true,
+			},
 		},
 	})
 }
@@ -65,7 +91,9 @@ type ThreemaNotifier struct {
 	GatewayID   string
 	RecipientID string
 	APISecret   string
-	log         log.Logger
+	// PrivateKey of an end-to-end Gateway ID, nil for basic IDs.
+	PrivateKey *[32]byte
+	log        log.Logger
 }
 
 // NewThreemaNotifier is the constructor for the Threema notifier
@@ -98,11 +126,22 @@ func NewThreemaNotifier(model *models.AlertNotification) (alerting.Notifier, err
 		return nil, alerting.ValidationError{Reason: "Could not find Threema API secret in settings"}
 	}
 
+	var privateKey *[32]byte
+	if value := model.DecryptedValue("private_key", model.Settings.Get("private_key").MustString()); value != "" {
+		key, err := hex.DecodeString(strings.TrimPrefix(value, "private:"))
+		if err != nil || len(key) != 32 {
+			return nil, alerting.ValidationError{Reason: "Invalid Threema private key: Must be 64 hex characters"}
+		}
+		privateKey = new([32]byte)
+		copy(privateKey[:], key)
+	}
+
 	return &ThreemaNotifier{
 		NotifierBase: NewNotifierBase(model),
 		GatewayID:    gatewayID,
 		RecipientID:  recipientID,
 		APISecret:    apiSecret,
+		PrivateKey:   privateKey,
 		log:          log.New("alerting.notifier.threema"),
 	}, nil
 }
@@ -112,12 +151,6 @@ func (notifier *ThreemaNotifier) Notify(evalContext *alerting.EvalContext) error
 	notifier.log.Info("Sending alert notification from", "threema_id", notifier.GatewayID)
 	notifier.log.Info("Sending alert notification to", "threema_id", notifier.RecipientID)
 
-	// Set up basic API request data
-	data := url.Values{}
-	data.Set("from", notifier.GatewayID)
-	data.Set("to", notifier.RecipientID)
-	data.Set("secret", notifier.APISecret)
-
 	// Determine emoji
 	stateEmoji := ""
 	switch evalContext.Rule.State {
@@ -142,24 +175,164 @@ func (notifier *ThreemaNotifier) Notify(evalContext *alerting.EvalContext) error
 	if notifier.NeedsImage() && evalContext.ImagePublicURL != "" {
 		message += fmt.Sprintf("*Image:* %s\n", evalContext.ImagePublicURL)
 	}
+
+	if notifier.PrivateKey != nil {
+		if err := notifier.sendEndToEnd(evalContext, message); err != nil {
+			notifier.log.Error("Failed to send webhook", "error", err, "webhook", notifier.Name)
+			return err
+		}
+		return nil
+	}
+
+	// Set up basic API request data
+	data := url.Values{}
+	data.Set("from", notifier.GatewayID)
+	data.Set("to", notifier.RecipientID)
+	data.Set("secret", notifier.APISecret)
 	data.Set("text", message)
 
-	// Prepare and send request
-	url := fmt.Sprintf(threemaGwBaseURL, "send_simple")
-	body := data.Encode()
-	headers := map[string]string{
-		"Content-Type": "application/x-www-form-urlencoded",
+	if err := notifier.send(evalContext, "send_simple", data); err != nil {
+		notifier.log.Error("Failed to send webhook", "error", err, "webhook", notifier.Name)
+		return err
+	}
+
+	return nil
+}
+
+// sendEndToEnd sends the message encrypted for the recipient, with the
+// rendered image attached if there is one.
+func (notifier *ThreemaNotifier) sendEndToEnd(evalContext *alerting.EvalContext, message string) error {
+	publicKey, err := notifier.lookupPublicKey(evalContext)
+	if err != nil {
+		return err
+	}
+
+	content := append([]byte{threemaTextMessage}, message...)
+	// a linked image is already part of the message
+	if notifier.NeedsImage() && evalContext.ImagePublicURL == "" && len(evalContext.ImageData) > 0 {
+		fileMessage, err := notifier.uploadImage(evalContext, message)
+		if err != nil {
+			return err
+		}
+		content = append([]byte{threemaFileMessage}, fileMessage...)
+	}
+
+	padded, err := threemaPad(content)
+	if err != nil {
+		return err
+	}
+
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return err
 	}
+
+	data := url.Values{}
+	data.Set("from", notifier.GatewayID)
+	data.Set("to", notifier.RecipientID)
+	data.Set("secret", notifier.APISecret)
+	data.Set("nonce", hex.EncodeToString(nonce[:]))
+	data.Set("box", hex.EncodeToString(box.Seal(nil, padded, &nonce, publicKey, notifier.PrivateKey)))
+
+	return notifier.send(evalContext, "send_e2e", data)
+}
+
+// lookupPublicKey fetches the public key of the recipient.
+func (notifier *ThreemaNotifier) lookupPublicKey(evalContext *alerting.EvalContext) (*[32]byte, error) {
+	query := url.Values{}
+	query.Set("from", notifier.GatewayID)
+	query.Set("secret", notifier.APISecret)
+
 	cmd := &models.SendWebhookSync{
-		Url:        url,
-		Body:       body,
-		HttpMethod: "POST",
-		HttpHeader: headers,
+		Url:        fmt.Sprintf(threemaGwBaseURL, "pubkeys/"+url.PathEscape(notifier.RecipientID)) + "?" + query.Encode(),
+		HttpMethod: http.MethodGet,
 	}
 	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
-		notifier.log.Error("Failed to send webhook", "error", err, "webhook", notifier.Name)
-		return err
+		return nil, err
 	}
 
-	return nil
+	key, err := hex.DecodeString(strings.TrimSpace(string(cmd.Result)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("invalid public key of Threema ID %s", notifier.RecipientID)
+	}
+	publicKey := new([32]byte)
+	copy(publicKey[:], key)
+	return publicKey, nil
+}
+
+// uploadImage uploads the encrypted image and returns the file message
+// referencing it, with the message as caption.
+func (notifier *ThreemaNotifier) uploadImage(evalContext *alerting.EvalContext, message string) ([]byte, error) {
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	w := multipart.NewWriter(&b)
+	fw, err := w.CreateFormFile("blob", "blob")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fw.Write(secretbox.Seal(nil, evalContext.ImageData, &threemaBlobNonce, &key)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+
+	query := url.Values{}
+	query.Set("from", notifier.GatewayID)
+	query.Set("secret", notifier.APISecret)
+	cmd := &models.SendWebhookSync{
+		Url:         fmt.Sprintf(threemaGwBaseURL, "upload_blob") + "?" + query.Encode(),
+		Body:        b.String(),
+		HttpMethod:  http.MethodPost,
+		ContentType: w.FormDataContentType(),
+	}
+	if err := bus.DispatchCtx(evalContext.Ctx, cmd); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"b": strings.TrimSpace(string(cmd.Result)),
+		"k": hex.EncodeToString(key[:]),
+		"m": "image/png",
+		"n": "graph.png",
+		"s": len(evalContext.ImageData),
+		"d": message,
+		// render the file as image
+		"j": 1,
+		"i": 1,
+	})
+}
+
+// send posts the form to an endpoint of the Threema Gateway.
+func (notifier *ThreemaNotifier) send(evalContext *alerting.EvalContext, endpoint string, data url.Values) error {
+	cmd := &models.SendWebhookSync{
+		Url:        fmt.Sprintf(threemaGwBaseURL, endpoint),
+		Body:       data.Encode(),
+		HttpMethod: http.MethodPost,
+		HttpHeader: map[string]string{
+			"Content-Type": "application/x-www-form-urlencoded",
+		},
+	}
+	return bus.DispatchCtx(evalContext.Ctx, cmd)
+}
+
+// threemaPad adds 1 to 255 bytes of PKCS#7 padding to a message, so that
+// the length of the content is hidden, up to at least 32 bytes.
+func threemaPad(content []byte) ([]byte, error) {
+	var n [1]byte
+	if _, err := rand.Read(n[:]); err != nil {
+		return nil, err
+	}
+	padding := int(n[0])
+	if padding == 0 {
+		padding = 1
+	}
+	if len(content)+padding < 32 {
+		padding = 32 - len(content)
+	}
+	return append(content, bytes.Repeat([]byte{byte(padding)}, padding)...), nil
 }
@@ -1,13 +1,29 @@
 package notifiers
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/validations"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
 )
 
 func TestThreemaNotifier(t *testing.T) {
@@ -98,6 +114,29 @@ func TestThreemaNotifier(t *testing.T) {
 				So(valErr.Reason, ShouldEqual, "Invalid Threema Gateway ID: Must be 8 characters long")
 			})
 
+			Convey("invalid private keys should be rejected", func() {
+				json := `
+				{
+					"gateway_id": "*3MAGWID",
+					"recipient_id": "ECHOECHO",
+					"api_secret": "1234",
+					"private_key": "private:1234"
+				}`
+
+				settingsJSON, _ := simplejson.NewJson([]byte(json))
+				model := &models.AlertNotification{
+					Name:     "threema_testing",
+					Type:     "threema",
+					Settings: settingsJSON,
+				}
+
+				not, err := NewThreemaNotifier(model)
+				So(not, ShouldBeNil)
+				var valErr alerting.ValidationError
+				So(errors.As(err, &valErr), ShouldBeTrue)
+				So(valErr.Reason, ShouldEqual, "Invalid Threema private key: Must be 64 hex characters")
+			})
+
 			Convey("invalid Threema Recipient IDs should be rejected (length)", func() {
 				json := `
 				{
@@ -122,3 +161,127 @@ func TestThreemaNotifier(t *testing.T) {
 		})
 	})
 }
+
+func TestThreemaNotifierEndToEnd(t *testing.T) {
+	gatewayPublic, gatewayPrivate, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	recipientPublic, recipientPrivate, err := box.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// the gateway keeps the uploaded blobs and the sent messages
+	blobs := map[string][]byte{}
+	var sent []url.Values
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *models.SendWebhookSync) error {
+		u, err := url.Parse(cmd.Url)
+		require.NoError(t, err)
+		switch {
+		case u.Path == "/pubkeys/ECHOECHO":
+			assert.Equal(t, "GET", cmd.HttpMethod)
+			assert.Equal(t, "*3MAGWID", u.Query().Get("from"))
+			cmd.Result = []byte(hex.EncodeToString(recipientPublic[:]))
+		case u.Path == "/upload_blob":
+			_, params, err := mime.ParseMediaType(cmd.ContentType)
+			require.NoError(t, err)
+			form, err := multipart.NewReader(strings.NewReader(cmd.Body), params["boundary"]).ReadForm(1 << 20)
+			require.NoError(t, err)
+			f, err := form.File["blob"][0].Open()
+			require.NoError(t, err)
+			blob, err := ioutil.ReadAll(f)
+			require.NoError(t, err)
+			blobs["0123456789abcdef0123456789abcdef"] = blob
+			cmd.Result = []byte("0123456789abcdef0123456789abcdef")
+		case u.Path == "/send_e2e":
+			values, err := url.ParseQuery(cmd.Body)
+			require.NoError(t, err)
+			sent = append(sent, values)
+		default:
+			t.Fatalf("unexpected request to %s", cmd.Url)
+		}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	origBaseURL := threemaGwBaseURL
+	threemaGwBaseURL = "https://msgapi.local/%s"
+	t.Cleanup(func() { threemaGwBaseURL = origBaseURL })
+
+	settingsJSON := simplejson.New()
+	settingsJSON.Set("gateway_id", "*3MAGWID")
+	settingsJSON.Set("recipient_id", "ECHOECHO")
+	settingsJSON.Set("api_secret", "1234")
+	settingsJSON.Set("private_key", "private:"+hex.EncodeToString(gatewayPrivate[:]))
+	settingsJSON.Set("uploadImage", true)
+	not, err := NewThreemaNotifier(&models.AlertNotification{Name: "threema", Type: "threema", Settings: settingsJSON})
+	require.NoError(t, err)
+
+	// decrypt returns the type and the content of the last sent message.
+	decrypt := func(t *testing.T) (byte, []byte) {
+		t.Helper()
+
+		require.NotEmpty(t, sent)
+		values := sent[len(sent)-1]
+		assert.Equal(t, "ECHOECHO", values.Get("to"))
+		nonce, err := hex.DecodeString(values.Get("nonce"))
+		require.NoError(t, err)
+		sealed, err := hex.DecodeString(values.Get("box"))
+		require.NoError(t, err)
+
+		var n [24]byte
+		copy(n[:], nonce)
+		padded, ok := box.Open(nil, sealed, &n, gatewayPublic, recipientPrivate)
+		require.True(t, ok, "message should be encrypted for the recipient")
+		require.GreaterOrEqual(t, len(padded), 32)
+
+		padding := int(padded[len(padded)-1])
+		assert.Equal(t, bytes.Repeat([]byte{byte(padding)}, padding), padded[len(padded)-padding:])
+		content := padded[:len(padded)-padding]
+		return content[0], content[1:]
+	}
+
+	newEvalContext := func() *alerting.EvalContext {
+		evalContext := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+			Name:    "someRule",
+			Message: "someMessage",
+			State:   models.AlertStateAlerting,
+		}, &validations.OSSPluginRequestValidator{})
+		evalContext.IsTestRun = true
+		return evalContext
+	}
+
+	t.Run("sends text messages without an image", func(t *testing.T) {
+		require.NoError(t, not.Notify(newEvalContext()))
+
+		messageType, content := decrypt(t)
+		assert.Equal(t, threemaTextMessage, messageType)
+		assert.Contains(t, string(content), "*Message:* someMessage")
+	})
+
+	t.Run("attaches the image as file message", func(t *testing.T) {
+		evalContext := newEvalContext()
+		evalContext.ImageData = []byte("rendered image")
+		require.NoError(t, not.Notify(evalContext))
+
+		messageType, content := decrypt(t)
+		require.Equal(t, threemaFileMessage, messageType)
+
+		var file struct {
+			Blob    string `json:"b"`
+			Key     string `json:"k"`
+			Type    string `json:"m"`
+			Size    int    `json:"s"`
+			Caption string `json:"d"`
+		}
+		require.NoError(t, json.Unmarshal(content, &file))
+		assert.Equal(t, "image/png", file.Type)
+		assert.Equal(t, len("rendered image"), file.Size)
+		assert.Contains(t, file.Caption, "*Message:* someMessage")
+
+		key, err := hex.DecodeString(file.Key)
+		require.NoError(t, err)
+		var k [32]byte
+		copy(k[:], key)
+		image, ok := secretbox.Open(nil, blobs[file.Blob], &threemaBlobNonce, &k)
+		require.True(t, ok, "blob should be encrypted with the key of the message")
+		assert.Equal(t, []byte("rendered image"), image)
+	})
+}
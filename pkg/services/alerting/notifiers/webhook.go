@@ -63,6 +63,11 @@ func NewWebHookNotifier(model *models.AlertNotification) (alerting.Notifier, err
 		return nil, alerting.ValidationError{Reason: "Could not find url property in settings"}
 	}
 
+	httpMethod := model.Settings.Get("httpMethod").MustString("POST")
+	if httpMethod != "POST" && httpMethod != "PUT" {
+		return nil, alerting.ValidationError{Reason: "Http Method must be POST or PUT"}
+	}
+
 	password := model.DecryptedValue("password", model.Settings.Get("password").MustString())
 
 	return &WebhookNotifier{
@@ -70,7 +75,7 @@ func NewWebHookNotifier(model *models.AlertNotification) (alerting.Notifier, err
 		URL:          url,
 		User:         model.Settings.Get("username").MustString(),
 		Password:     password,
-		HTTPMethod:   model.Settings.Get("httpMethod").MustString("POST"),
+		HTTPMethod:   httpMethod,
 		log:          log.New("alerting.notifier.webhook"),
 	}, nil
 }
@@ -91,6 +96,39 @@ type WebhookNotifier struct {
 func (wn *WebhookNotifier) Notify(evalContext *alerting.EvalContext) error {
 	wn.log.Info("Sending webhook")
 
+	bodyJSON := wn.alertBody(evalContext)
+
+	if wn.NeedsImage() && evalContext.ImagePublicURL != "" {
+		bodyJSON.Set("imageUrl", evalContext.ImagePublicURL)
+	}
+
+	return wn.send(evalContext, bodyJSON)
+}
+
+// NotifyDigest sends the grouped notifications as a single webhook
+// listing each alert in the same format as single notifications.
+func (wn *WebhookNotifier) NotifyDigest(evalContext *alerting.EvalContext) error {
+	wn.log.Info("Sending webhook digest", "alerts", len(evalContext.Grouped))
+
+	alerts := make([]*simplejson.Json, 0, len(evalContext.Grouped))
+	for _, c := range evalContext.Grouped {
+		alerts = append(alerts, wn.alertBody(c))
+	}
+
+	bodyJSON := simplejson.New()
+	bodyJSON.Set("title", evalContext.GetNotificationTitle())
+	bodyJSON.Set("state", evalContext.Rule.State)
+	bodyJSON.Set("orgId", evalContext.Rule.OrgID)
+	bodyJSON.Set("alerts", alerts)
+
+	if ruleURL, err := evalContext.GetRuleURL(); err == nil {
+		bodyJSON.Set("ruleUrl", ruleURL)
+	}
+
+	return wn.send(evalContext, bodyJSON)
+}
+
+func (wn *WebhookNotifier) alertBody(evalContext *alerting.EvalContext) *simplejson.Json {
 	bodyJSON := simplejson.New()
 	bodyJSON.Set("title", evalContext.GetNotificationTitle())
 	bodyJSON.Set("ruleId", evalContext.Rule.ID)
@@ -114,14 +152,14 @@ func (wn *WebhookNotifier) Notify(evalContext *alerting.EvalContext) error {
 		bodyJSON.Set("ruleUrl", ruleURL)
 	}
 
-	if wn.NeedsImage() && evalContext.ImagePublicURL != "" {
-		bodyJSON.Set("imageUrl", evalContext.ImagePublicURL)
-	}
-
 	if evalContext.Rule.Message != "" {
 		bodyJSON.Set("message", evalContext.Rule.Message)
 	}
 
+	return bodyJSON
+}
+
+func (wn *WebhookNotifier) send(evalContext *alerting.EvalContext, bodyJSON *simplejson.Json) error {
 	body, _ := bodyJSON.MarshalJSON()
 
 	cmd := &models.SendWebhookSync{
@@ -1,10 +1,14 @@
 package notifiers
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/alerting"
+	"github.com/grafana/grafana/pkg/services/validations"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +29,21 @@ func TestWebhookNotifier_parsingFromSettings(t *testing.T) {
 		require.Error(t, err)
 	})
 
+	t.Run("GET http method should cause error", func(t *testing.T) {
+		const json = `{"url": "http://google.com", "httpMethod": "GET"}`
+
+		settingsJSON, err := simplejson.NewJson([]byte(json))
+		require.NoError(t, err)
+		model := &models.AlertNotification{
+			Name:     "ops",
+			Type:     "webhook",
+			Settings: settingsJSON,
+		}
+
+		_, err = NewWebHookNotifier(model)
+		require.Error(t, err)
+	})
+
 	t.Run("Valid settings should result in a valid notifier", func(t *testing.T) {
 		const json = `{"url": "http://google.com"}`
 
@@ -45,3 +64,57 @@ func TestWebhookNotifier_parsingFromSettings(t *testing.T) {
 		assert.Equal(t, "http://google.com", webhookNotifier.URL)
 	})
 }
+
+// newTestDigestEvalContext returns a digest evaluation context
+// grouping a firing notification for each of the rule names.
+func newTestDigestEvalContext(names ...string) *alerting.EvalContext {
+	digest := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+		OrgID: 1,
+		Name:  "2 alerts (2 alerting)",
+		State: models.AlertStateAlerting,
+	}, &validations.OSSPluginRequestValidator{})
+
+	for i, name := range names {
+		c := alerting.NewEvalContext(context.Background(), &alerting.Rule{
+			ID:      int64(i + 1),
+			OrgID:   1,
+			Name:    name,
+			Message: name + " is too high",
+			State:   models.AlertStateAlerting,
+		}, &validations.OSSPluginRequestValidator{})
+		c.IsTestRun = true
+		digest.Grouped = append(digest.Grouped, c)
+	}
+
+	return digest
+}
+
+func TestWebhookNotifier_NotifyDigest(t *testing.T) {
+	url, received := newWebhookTestServer(t)
+
+	settingsJSON := simplejson.New()
+	settingsJSON.Set("url", url)
+	not, err := NewWebHookNotifier(&models.AlertNotification{Name: "ops", Type: "webhook", Settings: settingsJSON})
+	require.NoError(t, err)
+
+	err = not.(alerting.DigestNotifier).NotifyDigest(newTestDigestEvalContext("cpu", "memory"))
+	require.NoError(t, err)
+	require.Len(t, *received, 1)
+
+	var body struct {
+		Title  string `json:"title"`
+		State  string `json:"state"`
+		Alerts []struct {
+			RuleID   int64  `json:"ruleId"`
+			RuleName string `json:"ruleName"`
+			Message  string `json:"message"`
+		} `json:"alerts"`
+	}
+	require.NoError(t, json.Unmarshal((*received)[0].Body, &body))
+	assert.Equal(t, "[Alerting] 2 alerts (2 alerting)", body.Title)
+	assert.Equal(t, "alerting", body.State)
+	require.Len(t, body.Alerts, 2)
+	assert.Equal(t, int64(2), body.Alerts[1].RuleID)
+	assert.Equal(t, "memory", body.Alerts[1].RuleName)
+	assert.Equal(t, "memory is too high", body.Alerts[1].Message)
+}
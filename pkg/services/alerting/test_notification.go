@@ -3,7 +3,9 @@ package alerting
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"path/filepath"
 
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 // NotificationTestCommand initiates an test
@@ -87,6 +90,12 @@ func createTestEvalContext(cmd *NotificationTestCommand) *EvalContext {
 	ctx := NewEvalContext(context.Background(), testRule, fakeRequestValidator{})
 	if cmd.Settings.Get("uploadImage").MustBool(true) {
 		ctx.ImagePublicURL = "https://grafana.com/assets/img/blog/mixed_styles.png"
+
+		// notifiers attaching the image send the same picture from the Grafana installation.
+		ctx.ImageOnDiskPath = filepath.Join(setting.HomePath, "public/img/mixed_styles.png")
+		if data, err := ioutil.ReadFile(ctx.ImageOnDiskPath); err == nil {
+			ctx.ImageData = data
+		}
 	}
 	ctx.IsTestRun = true
 	ctx.Firing = true
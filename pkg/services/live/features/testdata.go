@@ -3,111 +3,197 @@ package features
 import (
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/centrifugal/centrifuge"
 	"github.com/grafana/grafana/pkg/models"
 )
 
-// testDataRunner manages all the `grafana/dashboard/*` channels.
-type testDataRunner struct {
-	publisher   models.ChannelPublisher
-	running     bool
-	speedMillis int
-	dropPercent float64
-	channel     string
-	name        string
-}
+const (
+	// testDataIdleTimeout is how long a stream keeps running without subscribers.
+	testDataIdleTimeout = 10 * time.Second
+	// testDataMaxStreams is the maximum number of streams running at the same time.
+	testDataMaxStreams = 100
+)
 
-// TestDataSupplier manages all the `grafana/testdata/*` channels.
+// TestDataSupplier manages all the `grafana/testdata/*` channels. Channels
+// have arbitrary stream settings, so the supplier keeps the runners of the
+// streams only while they have subscribers, and its handlers are not cached.
 type TestDataSupplier struct {
 	Publisher models.ChannelPublisher
+	// NumSubscribers returns the number of subscribers of a channel, streams
+	// stop when their channel has had no subscribers for a while.
+	NumSubscribers func(channel string) int
+
+	mu      sync.Mutex
+	runners map[string]*testDataRunner
+}
+
+// testDataSubscriber is a client subscribed to a channel.
+type testDataSubscriber interface {
+	ID() string
+	Unsubscribe(channel string, opts ...centrifuge.UnsubscribeOption) error
 }
 
 // GetHandlerForPath gets the channel handler for a path.
-// Called on init.
 func (s *TestDataSupplier) GetHandlerForPath(path string) (models.ChannelHandler, error) {
-	channel := "grafana/testdata/" + path
-
-	if path == "random-2s-stream" {
-		return &testDataRunner{
-			publisher:   s.Publisher,
-			running:     false,
-			speedMillis: 2000,
-			dropPercent: 0,
-			channel:     channel,
-			name:        path,
-		}, nil
+	stream, err := parseTestDataStream(path)
+	if err != nil {
+		return nil, err
 	}
 
-	if path == "random-flakey-stream" {
-		return &testDataRunner{
-			publisher:   s.Publisher,
-			running:     false,
-			speedMillis: 400,
-			dropPercent: .6,
+	return &testDataChannel{
+		supplier: s,
+		channel:  "grafana/testdata/" + path,
+		stream:   stream,
+	}, nil
+}
+
+// UncachedHandlers implements models.UncachedChannelHandlerFactory.
+func (s *TestDataSupplier) UncachedHandlers() {}
+
+// subscribe adds a subscriber to the runner of a channel, starting it unless
+// it is running already.
+func (s *TestDataSupplier) subscribe(channel string, stream *testDataStream, subscriber testDataSubscriber) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.runners[channel]
+	if !ok {
+		if len(s.runners) >= testDataMaxStreams {
+			return fmt.Errorf("too many testdata streams, at most %d can run at the same time", testDataMaxStreams)
+		}
+		if s.runners == nil {
+			s.runners = make(map[string]*testDataRunner)
+		}
+
+		r = &testDataRunner{
+			supplier:    s,
 			channel:     channel,
-		}, nil
+			stream:      stream,
+			subscribers: make(map[string]testDataSubscriber),
+		}
+		s.runners[channel] = r
+
+		// Run in the background
+		go r.run()
 	}
 
-	return nil, fmt.Errorf("unknown channel")
+	r.subscribed = time.Now()
+	// Only streams with downtimes disconnect their subscribers.
+	if stream.hasDowntime() && subscriber != nil {
+		r.subscribers[subscriber.ID()] = subscriber
+	}
+	return nil
 }
 
-// OnSubscribe will let anyone connect to the path
-func (r *testDataRunner) OnSubscribe(c *centrifuge.Client, e centrifuge.SubscribeEvent) (centrifuge.SubscribeReply, error) {
-	if !r.running {
-		r.running = true
+// testDataChannel is the handler of a `grafana/testdata/*` channel.
+type testDataChannel struct {
+	supplier *TestDataSupplier
+	channel  string
+	stream   *testDataStream
+}
 
-		// Run in the background
-		go r.runRandomCSV()
+// OnSubscribe will let anyone connect to the path
+func (c *testDataChannel) OnSubscribe(client *centrifuge.Client, e centrifuge.SubscribeEvent) (centrifuge.SubscribeReply, error) {
+	var subscriber testDataSubscriber
+	if client != nil {
+		subscriber = client
+	}
+	if err := c.supplier.subscribe(c.channel, c.stream, subscriber); err != nil {
+		return centrifuge.SubscribeReply{}, err
 	}
-
 	return centrifuge.SubscribeReply{}, nil
 }
 
 // OnPublish checks if a message from the websocket can be broadcast on this channel
-func (r *testDataRunner) OnPublish(c *centrifuge.Client, e centrifuge.PublishEvent) (centrifuge.PublishReply, error) {
+func (c *testDataChannel) OnPublish(client *centrifuge.Client, e centrifuge.PublishEvent) (centrifuge.PublishReply, error) {
 	return centrifuge.PublishReply{}, fmt.Errorf("can not publish to testdata")
 }
 
-// runRandomCSV is just for an example.
-func (r *testDataRunner) runRandomCSV() {
-	spread := 50.0
+// testDataRunner publishes the measurements of a single stream. Its
+// subscription state is guarded by the mutex of the supplier.
+type testDataRunner struct {
+	supplier *TestDataSupplier
+	channel  string
+	stream   *testDataStream
 
-	walker := rand.Float64() * 100
-	ticker := time.NewTicker(time.Duration(r.speedMillis) * time.Millisecond)
+	// subscribed is the last time the channel had subscribers.
+	subscribed  time.Time
+	subscribers map[string]testDataSubscriber
+}
 
-	measurement := models.Measurement{
-		Name:   r.name,
-		Time:   0,
-		Values: make(map[string]interface{}, 5),
-	}
-	msg := models.MeasurementBatch{
-		Measurements: []models.Measurement{measurement}, // always a single measurement
-	}
+// run publishes the measurements of the stream until the channel has no
+// subscribers.
+func (r *testDataRunner) run() {
+	ticker := time.NewTicker(r.stream.interval)
+	defer ticker.Stop()
+
+	producer := r.stream.newProducer(time.Now())
+	down := false
 
 	for t := range ticker.C {
-		if rand.Float64() <= r.dropPercent {
+		if r.stop(t) {
+			return
+		}
+
+		if producer.down(t) {
+			if !down {
+				down = true
+				r.disconnect()
+			}
 			continue
 		}
-		delta := rand.Float64() - 0.5
-		walker += delta
+		down = false
 
-		measurement.Time = t.UnixNano() / int64(time.Millisecond)
-		measurement.Values["value"] = walker
-		measurement.Values["min"] = walker - ((rand.Float64() * spread) + 0.01)
-		measurement.Values["max"] = walker + ((rand.Float64() * spread) + 0.01)
+		measurements := producer.next(t)
+		if len(measurements) == 0 {
+			continue
+		}
 
-		bytes, err := json.Marshal(&msg)
+		bytes, err := json.Marshal(&models.MeasurementBatch{Measurements: measurements})
 		if err != nil {
-			logger.Warn("unable to marshal line", "error", err)
+			logger.Warn("unable to marshal measurements", "error", err)
 			continue
 		}
 
-		err = r.publisher(r.channel, bytes)
-		if err != nil {
-			logger.Warn("write", "channel", r.channel, "measurement", measurement)
+		if err := r.supplier.Publisher(r.channel, bytes); err != nil {
+			logger.Warn("write", "channel", r.channel, "error", err)
+		}
+	}
+}
+
+// stop removes the runner from the supplier when the channel has had no
+// subscribers for the idle timeout.
+func (r *testDataRunner) stop(t time.Time) bool {
+	s := r.supplier
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.NumSubscribers == nil || s.NumSubscribers(r.channel) > 0 {
+		r.subscribed = t
+		return false
+	}
+	if t.Sub(r.subscribed) <= testDataIdleTimeout {
+		return false
+	}
+
+	delete(s.runners, r.channel)
+	return true
+}
+
+// disconnect unsubscribes the subscribers from the channel, as if their
+// connection dropped. They resubscribe by themselves.
+func (r *testDataRunner) disconnect() {
+	r.supplier.mu.Lock()
+	subscribers := r.subscribers
+	r.subscribers = make(map[string]testDataSubscriber)
+	r.supplier.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		if err := subscriber.Unsubscribe(r.channel, centrifuge.WithResubscribe(true)); err != nil {
+			logger.Warn("unable to unsubscribe", "channel", r.channel, "error", err)
 		}
 	}
 }
@@ -0,0 +1,251 @@
+package features
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// testDataMaxPointsPerSecond is the maximum rate of the points of a stream.
+const testDataMaxPointsPerSecond = 1000
+
+// testDataStream is the configuration of a TestData stream. It is parsed from
+// the path of the channel, which is the kind of the stream optionally followed
+// by its settings as query parameters, e.g. `signal?shape=square&interval=100ms`.
+//
+// Supported kinds are:
+//   - `signal`: a sine, square or noise wave
+//   - `logs`: simulated log lines
+//   - `flakey`: a signal that drops points and periodically unsubscribes its
+//     subscribers
+//   - `random-2s-stream` and `random-flakey-stream`: random walks
+//
+// All streams support the settings:
+//   - `interval`: the interval between messages, default 1s
+//   - `burst`: the number of points per message, default 1
+//   - `drop`: the probability between 0 and 1 of dropping a message
+//   - `uptime` and `downtime`: the producer stops for `downtime` after each
+//     `uptime` when both are set, and unsubscribes its subscribers
+//
+// Streams produce at most 1000 points per second, i.e. `burst` points per
+// `interval`.
+//
+// Signals additionally support `shape` (sine, square or noise), `period`,
+// `amplitude`, `offset` and `noise`, the amplitude of the noise added to the
+// signal.
+type testDataStream struct {
+	kind     string
+	interval time.Duration
+	burst    int
+	drop     float64
+	uptime   time.Duration
+	downtime time.Duration
+
+	shape     string
+	period    time.Duration
+	amplitude float64
+	offset    float64
+	noise     float64
+}
+
+func parseTestDataStream(path string) (*testDataStream, error) {
+	kind, rawQuery := path, ""
+	if i := strings.Index(path, "?"); i >= 0 {
+		kind, rawQuery = path[:i], path[i+1:]
+	}
+
+	stream := &testDataStream{
+		kind:      kind,
+		interval:  time.Second,
+		burst:     1,
+		shape:     "sine",
+		period:    time.Minute,
+		amplitude: 1,
+	}
+
+	switch kind {
+	case "random-2s-stream":
+		stream.interval = 2 * time.Second
+		return stream, nil
+	case "random-flakey-stream":
+		stream.interval = 400 * time.Millisecond
+		stream.drop = 0.6
+		return stream, nil
+	case "signal", "logs":
+	case "flakey":
+		stream.drop = 0.2
+		stream.uptime = 30 * time.Second
+		stream.downtime = 10 * time.Second
+	default:
+		return nil, fmt.Errorf("unknown channel")
+	}
+
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stream settings: %w", err)
+	}
+
+	for key := range params {
+		value := params.Get(key)
+		switch key {
+		case "interval":
+			stream.interval, err = time.ParseDuration(value)
+			if err == nil && stream.interval <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "burst":
+			stream.burst, err = strconv.Atoi(value)
+			if err == nil && stream.burst < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		case "drop":
+			stream.drop, err = strconv.ParseFloat(value, 64)
+			if err == nil && (stream.drop < 0 || stream.drop > 1) {
+				err = fmt.Errorf("must be between 0 and 1")
+			}
+		case "uptime":
+			stream.uptime, err = time.ParseDuration(value)
+		case "downtime":
+			stream.downtime, err = time.ParseDuration(value)
+		case "shape":
+			stream.shape = value
+			if value != "sine" && value != "square" && value != "noise" {
+				err = fmt.Errorf("must be sine, square or noise")
+			}
+		case "period":
+			stream.period, err = time.ParseDuration(value)
+			if err == nil && stream.period <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "amplitude":
+			stream.amplitude, err = strconv.ParseFloat(value, 64)
+		case "offset":
+			stream.offset, err = strconv.ParseFloat(value, 64)
+		case "noise":
+			stream.noise, err = strconv.ParseFloat(value, 64)
+		default:
+			err = fmt.Errorf("unknown setting")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid stream setting %q: %w", key, err)
+		}
+	}
+
+	if float64(stream.burst)/stream.interval.Seconds() > testDataMaxPointsPerSecond {
+		return nil, fmt.Errorf("invalid stream settings: at most %d points per second are supported", testDataMaxPointsPerSecond)
+	}
+
+	return stream, nil
+}
+
+// hasDowntime returns whether the stream periodically stops.
+func (s *testDataStream) hasDowntime() bool {
+	return s.uptime > 0 && s.downtime > 0
+}
+
+// newProducer returns a producer of the measurements of the stream, starting
+// at a time.
+func (s *testDataStream) newProducer(start time.Time) *testDataProducer {
+	return &testDataProducer{
+		stream: s,
+		start:  start,
+		rand:   rand.New(rand.NewSource(start.UnixNano())),
+		walker: rand.Float64() * 100,
+	}
+}
+
+// testDataProducer produces the measurements of a stream.
+type testDataProducer struct {
+	stream *testDataStream
+	start  time.Time
+	rand   *rand.Rand
+	walker float64
+}
+
+// down returns whether the stream is in a downtime at a time.
+func (p *testDataProducer) down(t time.Time) bool {
+	s := p.stream
+	if !s.hasDowntime() {
+		return false
+	}
+	return t.Sub(p.start)%(s.uptime+s.downtime) >= s.uptime
+}
+
+// next returns the measurements of the message sent at a time, if any.
+func (p *testDataProducer) next(t time.Time) []models.Measurement {
+	s := p.stream
+	if p.down(t) {
+		return nil
+	}
+	if s.drop > 0 && p.rand.Float64() < s.drop {
+		return nil
+	}
+
+	measurements := make([]models.Measurement, s.burst)
+	step := s.interval / time.Duration(s.burst)
+	for i := range measurements {
+		// The points of a burst are spread over the interval preceding it.
+		pointTime := t.Add(-step * time.Duration(s.burst-1-i))
+		measurements[i] = models.Measurement{
+			Name:   s.kind,
+			Time:   pointTime.UnixNano() / int64(time.Millisecond),
+			Values: p.values(pointTime),
+		}
+	}
+	return measurements
+}
+
+var testDataLogLevels = []string{"debug", "info", "info", "info", "warn", "error"}
+
+var testDataLogWords = []string{
+	"request", "completed", "user", "dashboard", "query", "timeout", "cache", "miss",
+	"connection", "reset", "retrying", "saved", "alert", "firing", "resolved", "panel",
+}
+
+func (p *testDataProducer) values(t time.Time) map[string]interface{} {
+	s := p.stream
+	switch s.kind {
+	case "random-2s-stream", "random-flakey-stream":
+		const spread = 50.0
+		p.walker += p.rand.Float64() - 0.5
+		return map[string]interface{}{
+			"value": p.walker,
+			"min":   p.walker - ((p.rand.Float64() * spread) + 0.01),
+			"max":   p.walker + ((p.rand.Float64() * spread) + 0.01),
+		}
+	case "logs":
+		words := make([]string, 4+p.rand.Intn(8))
+		for i := range words {
+			words[i] = testDataLogWords[p.rand.Intn(len(testDataLogWords))]
+		}
+		return map[string]interface{}{
+			"level": testDataLogLevels[p.rand.Intn(len(testDataLogLevels))],
+			"line":  strings.Join(words, " "),
+		}
+	}
+
+	phase := 2 * math.Pi * float64(t.UnixNano()%int64(s.period)) / float64(s.period)
+	var value float64
+	switch s.shape {
+	case "sine":
+		value = math.Sin(phase)
+	case "square":
+		value = 1
+		if phase >= math.Pi {
+			value = -1
+		}
+	case "noise":
+		value = p.rand.Float64()*2 - 1
+	}
+	value = s.offset + s.amplitude*value
+	if s.noise > 0 {
+		value += s.noise * (p.rand.Float64()*2 - 1)
+	}
+	return map[string]interface{}{"value": value}
+}
@@ -0,0 +1,177 @@
+package features
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTestDataStream(t *testing.T) {
+	t.Run("parses stream settings", func(t *testing.T) {
+		stream, err := parseTestDataStream("signal?shape=square&interval=100ms&burst=5&period=10s&amplitude=2&offset=1")
+		require.NoError(t, err)
+		require.Equal(t, "signal", stream.kind)
+		require.Equal(t, "square", stream.shape)
+		require.Equal(t, 100*time.Millisecond, stream.interval)
+		require.Equal(t, 5, stream.burst)
+		require.Equal(t, 10*time.Second, stream.period)
+		require.Equal(t, 2.0, stream.amplitude)
+		require.Equal(t, 1.0, stream.offset)
+	})
+
+	t.Run("limits the points per second", func(t *testing.T) {
+		stream, err := parseTestDataStream("signal?interval=1ms")
+		require.NoError(t, err)
+		require.Equal(t, time.Millisecond, stream.interval)
+
+		stream, err = parseTestDataStream("signal?interval=1s&burst=1000")
+		require.NoError(t, err)
+		require.Equal(t, 1000, stream.burst)
+	})
+
+	t.Run("supports the random walk streams", func(t *testing.T) {
+		stream, err := parseTestDataStream("random-2s-stream")
+		require.NoError(t, err)
+		require.Equal(t, 2*time.Second, stream.interval)
+	})
+
+	t.Run("rejects invalid streams", func(t *testing.T) {
+		for _, path := range []string{
+			"unknown",
+			"signal?shape=triangle",
+			"signal?interval=0s",
+			"signal?interval=500us",
+			"signal?interval=10ms&burst=20",
+			"signal?burst=0",
+			"logs?drop=2",
+			"signal?speed=1",
+		} {
+			_, err := parseTestDataStream(path)
+			require.Error(t, err, path)
+		}
+	})
+}
+
+func TestTestDataProducer(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("produces signals", func(t *testing.T) {
+		stream, err := parseTestDataStream("signal?shape=square&period=4s&amplitude=2&offset=1")
+		require.NoError(t, err)
+		producer := stream.newProducer(start)
+
+		measurements := producer.next(start)
+		require.Len(t, measurements, 1)
+		require.Equal(t, start.UnixNano()/int64(time.Millisecond), measurements[0].Time)
+		require.Equal(t, 3.0, measurements[0].Values["value"])
+
+		measurements = producer.next(start.Add(2 * time.Second))
+		require.Equal(t, -1.0, measurements[0].Values["value"])
+	})
+
+	t.Run("spreads bursts over the interval", func(t *testing.T) {
+		stream, err := parseTestDataStream("signal?interval=1s&burst=4")
+		require.NoError(t, err)
+
+		measurements := stream.newProducer(start).next(start)
+		require.Len(t, measurements, 4)
+		require.Equal(t, start.Add(-750*time.Millisecond).UnixNano()/int64(time.Millisecond), measurements[0].Time)
+		require.Equal(t, start.UnixNano()/int64(time.Millisecond), measurements[3].Time)
+	})
+
+	t.Run("produces log lines", func(t *testing.T) {
+		stream, err := parseTestDataStream("logs")
+		require.NoError(t, err)
+
+		measurements := stream.newProducer(start).next(start)
+		require.Len(t, measurements, 1)
+		require.NotEmpty(t, measurements[0].Values["line"])
+		require.Contains(t, testDataLogLevels, measurements[0].Values["level"])
+	})
+
+	t.Run("disconnects flakey producers", func(t *testing.T) {
+		stream, err := parseTestDataStream("flakey?drop=0&uptime=10s&downtime=5s")
+		require.NoError(t, err)
+		producer := stream.newProducer(start)
+
+		require.False(t, producer.down(start.Add(9*time.Second)))
+		require.Len(t, producer.next(start.Add(9*time.Second)), 1)
+		require.True(t, producer.down(start.Add(12*time.Second)))
+		require.Empty(t, producer.next(start.Add(12*time.Second)))
+		require.Len(t, producer.next(start.Add(15*time.Second)), 1)
+	})
+
+	t.Run("drops messages", func(t *testing.T) {
+		stream, err := parseTestDataStream("signal?drop=1")
+		require.NoError(t, err)
+		require.Empty(t, stream.newProducer(start).next(start))
+	})
+}
+
+type fakeTestDataSubscriber struct {
+	id           string
+	unsubscribed chan string
+}
+
+func (s *fakeTestDataSubscriber) ID() string {
+	return s.id
+}
+
+func (s *fakeTestDataSubscriber) Unsubscribe(channel string, opts ...centrifuge.UnsubscribeOption) error {
+	s.unsubscribed <- channel
+	return nil
+}
+
+func TestTestDataSupplier(t *testing.T) {
+	noSubscribers := func(channel string) int { return 0 }
+
+	t.Run("limits the number of streams", func(t *testing.T) {
+		s := &TestDataSupplier{Publisher: func(string, []byte) error { return nil }, NumSubscribers: noSubscribers}
+		stream, err := parseTestDataStream("signal?interval=1h")
+		require.NoError(t, err)
+
+		for i := 0; i < testDataMaxStreams; i++ {
+			require.NoError(t, s.subscribe(fmt.Sprintf("grafana/testdata/signal?interval=1h&offset=%d", i), stream, nil))
+		}
+		require.Error(t, s.subscribe("grafana/testdata/signal?interval=1h&offset=-1", stream, nil))
+		// Existing streams accept more subscribers
+		require.NoError(t, s.subscribe("grafana/testdata/signal?interval=1h&offset=0", stream, nil))
+	})
+
+	t.Run("removes streams without subscribers", func(t *testing.T) {
+		s := &TestDataSupplier{NumSubscribers: noSubscribers}
+		stream, err := parseTestDataStream("signal")
+		require.NoError(t, err)
+		r := &testDataRunner{supplier: s, channel: "grafana/testdata/signal", stream: stream}
+		s.runners = map[string]*testDataRunner{r.channel: r}
+
+		now := time.Now()
+		r.subscribed = now
+		require.False(t, r.stop(now.Add(testDataIdleTimeout)))
+		require.Len(t, s.runners, 1)
+		require.True(t, r.stop(now.Add(testDataIdleTimeout+time.Second)))
+		require.Empty(t, s.runners)
+	})
+
+	t.Run("unsubscribes the subscribers of flakey streams", func(t *testing.T) {
+		s := &TestDataSupplier{
+			Publisher:      func(string, []byte) error { return nil },
+			NumSubscribers: func(channel string) int { return 1 },
+		}
+		stream, err := parseTestDataStream("flakey?interval=10ms&uptime=50ms&downtime=50ms")
+		require.NoError(t, err)
+
+		subscriber := &fakeTestDataSubscriber{id: "client", unsubscribed: make(chan string, 1)}
+		require.NoError(t, s.subscribe("grafana/testdata/flakey", stream, subscriber))
+
+		select {
+		case channel := <-subscriber.unsubscribed:
+			require.Equal(t, "grafana/testdata/flakey", channel)
+		case <-time.After(5 * time.Second):
+			t.Fatal("subscriber was not unsubscribed")
+		}
+	})
+}
@@ -92,7 +92,8 @@ func (g *GrafanaLive) Init() error {
 	g.GrafanaScope.Dashboards = dash
 	g.GrafanaScope.Features["dashboard"] = dash
 	g.GrafanaScope.Features["testdata"] = &features.TestDataSupplier{
-		Publisher: g.Publish,
+		Publisher:      g.Publish,
+		NumSubscribers: node.Hub().NumSubscribers,
 	}
 	g.GrafanaScope.Features["broadcast"] = &features.BroadcastRunner{}
 	g.GrafanaScope.Features["measurements"] = &features.MeasurementsRunner{}
@@ -215,6 +216,11 @@ func (g *GrafanaLive) GetChannelHandler(channel string) (models.ChannelHandler,
 		return nil, err
 	}
 
+	// Features with arbitrary paths manage their own handlers
+	if _, ok := getter.(models.UncachedChannelHandlerFactory); ok {
+		return c, nil
+	}
+
 	g.channels[channel] = c
 	return c, nil
 }
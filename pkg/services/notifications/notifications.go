@@ -101,7 +101,7 @@ func (ns *NotificationService) Run(ctx context.Context) error {
 }
 
 func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *models.SendWebhookSync) error {
-	return ns.sendWebRequestSync(ctx, &Webhook{
+	webhook := &Webhook{
 		Url:         cmd.Url,
 		User:        cmd.User,
 		Password:    cmd.Password,
@@ -109,7 +109,13 @@ func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *models.
 		HttpMethod:  cmd.HttpMethod,
 		HttpHeader:  cmd.HttpHeader,
 		ContentType: cmd.ContentType,
-	})
+	}
+	if err := ns.sendWebRequestSync(ctx, webhook); err != nil {
+		return err
+	}
+
+	cmd.Result = webhook.Response
+	return nil
 }
 
 func subjectTemplateFunc(obj map[string]interface{}, value string) string {
@@ -9,10 +9,12 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
 	"time"
 
 	"golang.org/x/net/context/ctxhttp"
 
+	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -24,8 +26,14 @@ type Webhook struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+
+	// Response is the body of a successful response, up to maxWebhookResponseBytes.
+	Response []byte
 }
 
+// maxWebhookResponseBytes caps how much of the response body is kept.
+const maxWebhookResponseBytes = 1024 * 1024
+
 var netTransport = &http.Transport{
 	TLSClientConfig: &tls.Config{
 		Renegotiation: tls.RenegotiateFreelyAsClient,
@@ -42,13 +50,20 @@ var netClient = &http.Client{
 }
 
 func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *Webhook) error {
-	ns.log.Debug("Sending webhook", "url", webhook.Url, "http method", webhook.HttpMethod)
+	ns.log.Debug("Sending webhook", "url", redactURL(webhook.Url), "http method", webhook.HttpMethod)
 
 	if webhook.HttpMethod == "" {
 		webhook.HttpMethod = http.MethodPost
 	}
 
-	if webhook.HttpMethod != http.MethodPost && webhook.HttpMethod != http.MethodPut {
+	switch webhook.HttpMethod {
+	case http.MethodPost, http.MethodPut:
+	case http.MethodGet:
+		// GET is only used to look up data from the receiver, never to send a notification
+		if webhook.Body != "" {
+			return fmt.Errorf("webhook does not support a body with HTTP method GET")
+		}
+	default:
 		return fmt.Errorf("webhook only supports HTTP methods PUT or POST")
 	}
 
@@ -83,7 +98,11 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 	}()
 
 	if resp.StatusCode/100 == 2 {
-		ns.log.Debug("Webhook succeeded", "url", webhook.Url, "statuscode", resp.Status)
+		ns.log.Debug("Webhook succeeded", "url", redactURL(webhook.Url), "statuscode", resp.Status)
+		webhook.Response, err = ioutil.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBytes))
+		if err != nil {
+			return err
+		}
 		// flushing the body enables the transport to reuse the same connection
 		if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
 			ns.log.Error("Failed to copy resp.Body to ioutil.Discard", "err", err)
@@ -96,6 +115,16 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 		return err
 	}
 
-	ns.log.Debug("Webhook failed", "url", webhook.Url, "statuscode", resp.Status, "body", string(body))
-	return fmt.Errorf("Webhook response status %v", resp.Status)
+	ns.log.Debug("Webhook failed", "url", redactURL(webhook.Url), "statuscode", resp.Status, "body", string(body))
+	return models.WebhookResponseError{StatusCode: resp.StatusCode, Status: resp.Status}
+}
+
+// redactURL strips the credentials, query and fragment of a URL, which may
+// contain secrets, so that it can be logged.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host + u.Path
 }
@@ -0,0 +1,358 @@
+// Package querycache caches the results of data source queries in the
+// remote cache so that identical requests, for example from several viewers
+// of the same dashboard, only reach the data source once.
+package querycache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// Status tells whether a response was served from the cache.
+type Status string
+
+const (
+	// StatusHit means the response was read from the cache.
+	StatusHit Status = "HIT"
+	// StatusMiss means the queries were executed because the response was
+	// not cached.
+	StatusMiss Status = "MISS"
+	// StatusBypass means the cache is not used for the data source or the
+	// request.
+	StatusBypass Status = "BYPASS"
+)
+
+// HeaderName is the response header that carries the cache Status.
+const HeaderName = "X-Grafana-Cache"
+
+const keyPrefix = "query-cache:"
+
+// volatileQueryFields are removed from the query models before building the
+// cache key since they differ between otherwise identical requests.
+var volatileQueryFields = []string{"requestId", "key"}
+
+// handleRequest executes a request. Stubbed by tests.
+var handleRequest = tsdb.HandleRequest
+
+func init() {
+	registry.RegisterService(&Service{})
+}
+
+// Service executes data source queries through the remote cache.
+type Service struct {
+	RemoteCache *remotecache.RemoteCache `inject:""`
+	Cfg         *setting.Cfg             `inject:""`
+
+	log   log.Logger
+	cache remotecache.CacheStorage
+}
+
+// Init initializes the service.
+func (s *Service) Init() error {
+	s.log = log.New("querycache")
+	if s.RemoteCache != nil {
+		s.cache = s.RemoteCache
+	}
+	return nil
+}
+
+// Result is the response of a request together with how it was served.
+type Result struct {
+	Response *tsdb.Response
+	Status   Status
+	// MaxAge is how much longer the response stays cached. It is zero if the
+	// response is not cached.
+	MaxAge time.Duration
+}
+
+type cachedResponse struct {
+	Expires int64          `json:"expires"`
+	Results []cachedResult `json:"results"`
+}
+
+type cachedResult struct {
+	RefID      string               `json:"refId"`
+	Meta       json.RawMessage      `json:"meta,omitempty"`
+	Series     tsdb.TimeSeriesSlice `json:"series,omitempty"`
+	Tables     []*tsdb.Table        `json:"tables,omitempty"`
+	Dataframes [][]byte             `json:"dataframes,omitempty"`
+}
+
+// HandleRequest executes the queries of a request, unless an identical
+// request has been cached. The time range of the request is rounded to the
+// query interval so that requests for relative time ranges made at
+// slightly different times share the same cache entry. skipCache forces the
+// queries to be executed; the fresh response is still cached.
+func (s *Service) HandleRequest(ctx context.Context, ds *models.DataSource, query *tsdb.TsdbQuery, skipCache bool) (*Result, error) {
+	if !s.isEnabled(ds, query) {
+		resp, err := handleRequest(ctx, ds, query)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Response: resp, Status: StatusBypass}, nil
+	}
+
+	to, err := alignTimeRange(query)
+	if err != nil {
+		s.log.Debug("Failed to align time range, skipping cache", "error", err)
+		resp, err := handleRequest(ctx, ds, query)
+		if err != nil {
+			return nil, err
+		}
+		return &Result{Response: resp, Status: StatusBypass}, nil
+	}
+
+	key, err := cacheKey(ds, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if !skipCache {
+		if result, ok := s.get(key); ok {
+			metrics.MDataSourceQueryCacheRequestTotal.WithLabelValues(ds.Type, "hit").Inc()
+			return result, nil
+		}
+	}
+	metrics.MDataSourceQueryCacheRequestTotal.WithLabelValues(ds.Type, "miss").Inc()
+
+	resp, err := handleRequest(ctx, ds, query)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Response: resp, Status: StatusMiss}
+	if !isCacheable(resp) {
+		return result, nil
+	}
+
+	ttl := s.ttl(to)
+	if err := s.set(key, resp, ttl); err != nil {
+		s.log.Warn("Failed to cache query response", "datasource", ds.Name, "error", err)
+		return result, nil
+	}
+	result.MaxAge = ttl
+
+	return result, nil
+}
+
+func (s *Service) isEnabled(ds *models.DataSource, query *tsdb.TsdbQuery) bool {
+	if s.cache == nil || !s.Cfg.QueryCaching.Enabled || query.Debug || ds.JsonData == nil {
+		return false
+	}
+
+	// Responses of data sources that query as the signed in user are
+	// specific to that user.
+	if ds.JsonData.Get("oauthPassThru").MustBool(false) {
+		return false
+	}
+
+	return ds.JsonData.Get("queryCachingEnabled").MustBool(false)
+}
+
+// ttl returns how long a response is cached. Data that ended well in the
+// past rarely changes and is cached longer than recent data.
+func (s *Service) ttl(to time.Time) time.Duration {
+	if time.Since(to) >= s.Cfg.QueryCaching.HistoricalThreshold {
+		return s.Cfg.QueryCaching.HistoricalTTL
+	}
+	return s.Cfg.QueryCaching.TTL
+}
+
+func (s *Service) get(key string) (*Result, bool) {
+	value, err := s.cache.Get(key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			s.log.Warn("Failed to read cached query response", "error", err)
+		}
+		return nil, false
+	}
+
+	data, ok := value.([]byte)
+	if !ok {
+		return nil, false
+	}
+
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil {
+		s.log.Warn("Failed to decode cached query response", "error", err)
+		return nil, false
+	}
+
+	maxAge := time.Until(time.Unix(cached.Expires, 0))
+	if maxAge <= 0 {
+		return nil, false
+	}
+
+	resp := &tsdb.Response{Results: make(map[string]*tsdb.QueryResult, len(cached.Results))}
+	for _, r := range cached.Results {
+		queryResult := &tsdb.QueryResult{
+			RefId:  r.RefID,
+			Series: r.Series,
+			Tables: r.Tables,
+		}
+		if len(r.Meta) > 0 {
+			meta, err := simplejson.NewJson(r.Meta)
+			if err != nil {
+				s.log.Warn("Failed to decode cached query response", "error", err)
+				return nil, false
+			}
+			queryResult.Meta = meta
+		}
+		if r.Dataframes != nil {
+			queryResult.Dataframes = tsdb.NewEncodedDataFrames(r.Dataframes)
+		}
+		resp.Results[r.RefID] = queryResult
+	}
+
+	return &Result{Response: resp, Status: StatusHit, MaxAge: maxAge.Round(time.Second)}, true
+}
+
+func (s *Service) set(key string, resp *tsdb.Response, ttl time.Duration) error {
+	cached := cachedResponse{
+		Expires: time.Now().Add(ttl).Unix(),
+		Results: make([]cachedResult, 0, len(resp.Results)),
+	}
+
+	for refID, queryResult := range resp.Results {
+		r := cachedResult{
+			RefID:  refID,
+			Series: queryResult.Series,
+			Tables: queryResult.Tables,
+		}
+		if queryResult.Meta != nil {
+			meta, err := queryResult.Meta.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			r.Meta = meta
+		}
+		if queryResult.Dataframes != nil {
+			encoded, err := queryResult.Dataframes.Encoded()
+			if err != nil {
+				return err
+			}
+			r.Dataframes = encoded
+		}
+		cached.Results = append(cached.Results, r)
+	}
+
+	data, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Set(key, data, ttl)
+}
+
+// isCacheable reports whether all queries of a response succeeded. Failed
+// queries are retried on the next request.
+func isCacheable(resp *tsdb.Response) bool {
+	if resp == nil || resp.Message != "" {
+		return false
+	}
+
+	for _, queryResult := range resp.Results {
+		if queryResult.Error != nil || queryResult.ErrorString != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// alignTimeRange rounds the start of the time range of a request down and
+// the end up to the largest query interval and replaces the time range with
+// the aligned one. It returns the aligned end of the range.
+func alignTimeRange(query *tsdb.TsdbQuery) (time.Time, error) {
+	from, err := query.TimeRange.ParseFrom()
+	if err != nil {
+		return time.Time{}, err
+	}
+	to, err := query.TimeRange.ParseTo()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	interval := time.Second
+	for _, q := range query.Queries {
+		if d := time.Duration(q.IntervalMs) * time.Millisecond; d > interval {
+			interval = d
+		}
+	}
+
+	from = from.Truncate(interval)
+	if aligned := to.Truncate(interval); !aligned.Equal(to) {
+		to = aligned.Add(interval)
+	}
+
+	query.TimeRange = tsdb.NewTimeRange(
+		strconv.FormatInt(from.UnixNano()/int64(time.Millisecond), 10),
+		strconv.FormatInt(to.UnixNano()/int64(time.Millisecond), 10),
+	)
+
+	return to, nil
+}
+
+type keyQuery struct {
+	RefID         string           `json:"refId"`
+	Model         *simplejson.Json `json:"model"`
+	MaxDataPoints int64            `json:"maxDataPoints"`
+	IntervalMs    int64            `json:"intervalMs"`
+	QueryType     string           `json:"queryType"`
+}
+
+// cacheKey identifies a request by data source, normalized queries and
+// aligned time range. The data source version is part of the key so that
+// changing the data source invalidates its cached responses.
+func cacheKey(ds *models.DataSource, query *tsdb.TsdbQuery) (string, error) {
+	queries := make([]keyQuery, 0, len(query.Queries))
+	for _, q := range query.Queries {
+		model := simplejson.New()
+		if q.Model != nil {
+			for k, v := range q.Model.MustMap() {
+				model.Set(k, v)
+			}
+		}
+		for _, field := range volatileQueryFields {
+			model.Del(field)
+		}
+
+		queries = append(queries, keyQuery{
+			RefID:         q.RefId,
+			Model:         model,
+			MaxDataPoints: q.MaxDataPoints,
+			IntervalMs:    q.IntervalMs,
+			QueryType:     q.QueryType,
+		})
+	}
+
+	// encoding/json sorts map keys, which normalizes the query models.
+	data, err := json.Marshal(struct {
+		OrgID   int64      `json:"orgId"`
+		ID      int64      `json:"id"`
+		Version int        `json:"version"`
+		From    string     `json:"from"`
+		To      string     `json:"to"`
+		Queries []keyQuery `json:"queries"`
+	}{ds.OrgId, ds.Id, ds.Version, query.TimeRange.From, query.TimeRange.To, queries})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	return keyPrefix + hex.EncodeToString(sum[:]), nil
+}
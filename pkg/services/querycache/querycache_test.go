@@ -0,0 +1,202 @@
+package querycache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCacheStorage struct {
+	items map[string]interface{}
+	ttls  map[string]time.Duration
+}
+
+func newFakeCacheStorage() *fakeCacheStorage {
+	return &fakeCacheStorage{items: map[string]interface{}{}, ttls: map[string]time.Duration{}}
+}
+
+func (f *fakeCacheStorage) Get(key string) (interface{}, error) {
+	item, ok := f.items[key]
+	if !ok {
+		return nil, remotecache.ErrCacheItemNotFound
+	}
+	return item, nil
+}
+
+func (f *fakeCacheStorage) Set(key string, value interface{}, expire time.Duration) error {
+	f.items[key] = value
+	f.ttls[key] = expire
+	return nil
+}
+
+func (f *fakeCacheStorage) Delete(key string) error {
+	delete(f.items, key)
+	return nil
+}
+
+func TestService(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.QueryCaching = setting.QueryCachingSettings{
+		Enabled:             true,
+		TTL:                 time.Minute,
+		HistoricalTTL:       time.Hour,
+		HistoricalThreshold: time.Hour,
+	}
+
+	ds := &models.DataSource{
+		Id:       1,
+		OrgId:    1,
+		Type:     "test",
+		JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCachingEnabled": true}),
+	}
+
+	var calls int
+	var queryErr error
+	origHandleRequest := handleRequest
+	t.Cleanup(func() { handleRequest = origHandleRequest })
+	handleRequest = func(ctx context.Context, ds *models.DataSource, query *tsdb.TsdbQuery) (*tsdb.Response, error) {
+		calls++
+		frame := data.NewFrame("test", data.NewField("value", nil, []float64{float64(calls)}))
+		return &tsdb.Response{Results: map[string]*tsdb.QueryResult{
+			"A": {
+				RefId:      "A",
+				Meta:       simplejson.NewFromAny(map[string]interface{}{"executedQueryString": "up"}),
+				Dataframes: tsdb.NewDecodedDataFrames(data.Frames{frame}),
+				Error:      queryErr,
+			},
+		}}, nil
+	}
+
+	newService := func() (*Service, *fakeCacheStorage) {
+		storage := newFakeCacheStorage()
+		return &Service{Cfg: cfg, log: log.New("test"), cache: storage}, storage
+	}
+
+	newQuery := func(from, to time.Time, model map[string]interface{}) *tsdb.TsdbQuery {
+		return &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange(
+				strconv.FormatInt(from.UnixNano()/int64(time.Millisecond), 10),
+				strconv.FormatInt(to.UnixNano()/int64(time.Millisecond), 10),
+			),
+			Queries: []*tsdb.Query{{RefId: "A", IntervalMs: 60000, Model: simplejson.NewFromAny(model)}},
+		}
+	}
+
+	now := time.Now().Truncate(time.Minute)
+
+	t.Run("serves identical requests from the cache", func(t *testing.T) {
+		calls = 0
+		s, storage := newService()
+
+		result, err := s.HandleRequest(context.Background(), ds, newQuery(now.Add(-time.Hour), now.Add(10*time.Second), map[string]interface{}{"expr": "up", "requestId": "1"}), false)
+		require.NoError(t, err)
+		assert.Equal(t, StatusMiss, result.Status)
+		assert.Equal(t, time.Minute, result.MaxAge)
+
+		// Made a few seconds later, within the same interval.
+		result, err = s.HandleRequest(context.Background(), ds, newQuery(now.Add(-time.Hour+20*time.Second), now.Add(30*time.Second), map[string]interface{}{"expr": "up", "requestId": "2"}), false)
+		require.NoError(t, err)
+		assert.Equal(t, StatusHit, result.Status)
+		assert.Equal(t, 1, calls)
+		assert.Len(t, storage.items, 1)
+
+		res := result.Response.Results["A"]
+		require.NotNil(t, res)
+		assert.Equal(t, "up", res.Meta.Get("executedQueryString").MustString())
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, 1.0, frames[0].Fields[0].At(0))
+	})
+
+	t.Run("executes different queries", func(t *testing.T) {
+		calls = 0
+		s, _ := newService()
+
+		_, err := s.HandleRequest(context.Background(), ds, newQuery(now.Add(-time.Hour), now, map[string]interface{}{"expr": "up"}), false)
+		require.NoError(t, err)
+		result, err := s.HandleRequest(context.Background(), ds, newQuery(now.Add(-time.Hour), now, map[string]interface{}{"expr": "down"}), false)
+		require.NoError(t, err)
+		assert.Equal(t, StatusMiss, result.Status)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("does not cache failed queries", func(t *testing.T) {
+		calls = 0
+		queryErr = errors.New("query failed")
+		t.Cleanup(func() { queryErr = nil })
+		s, storage := newService()
+
+		result, err := s.HandleRequest(context.Background(), ds, newQuery(now.Add(-time.Hour), now, map[string]interface{}{"expr": "up"}), false)
+		require.NoError(t, err)
+		assert.Equal(t, StatusMiss, result.Status)
+		assert.Zero(t, result.MaxAge)
+		assert.Empty(t, storage.items)
+	})
+
+	t.Run("refreshes the cache when skipping it", func(t *testing.T) {
+		calls = 0
+		s, _ := newService()
+
+		for i := 0; i < 2; i++ {
+			result, err := s.HandleRequest(context.Background(), ds, newQuery(now.Add(-time.Hour), now, map[string]interface{}{"expr": "up"}), true)
+			require.NoError(t, err)
+			assert.Equal(t, StatusMiss, result.Status)
+		}
+		assert.Equal(t, 2, calls)
+
+		result, err := s.HandleRequest(context.Background(), ds, newQuery(now.Add(-time.Hour), now, map[string]interface{}{"expr": "up"}), false)
+		require.NoError(t, err)
+		assert.Equal(t, StatusHit, result.Status)
+	})
+
+	t.Run("caches historical data longer", func(t *testing.T) {
+		s, storage := newService()
+
+		result, err := s.HandleRequest(context.Background(), ds, newQuery(now.Add(-48*time.Hour), now.Add(-24*time.Hour), map[string]interface{}{"expr": "up"}), false)
+		require.NoError(t, err)
+		assert.Equal(t, time.Hour, result.MaxAge)
+		for _, ttl := range storage.ttls {
+			assert.Equal(t, time.Hour, ttl)
+		}
+	})
+
+	t.Run("bypasses the cache unless the data source opts in", func(t *testing.T) {
+		calls = 0
+		s, storage := newService()
+		other := &models.DataSource{Id: 2, Type: "test", JsonData: simplejson.New()}
+
+		for i := 0; i < 2; i++ {
+			result, err := s.HandleRequest(context.Background(), other, newQuery(now.Add(-time.Hour), now, map[string]interface{}{"expr": "up"}), false)
+			require.NoError(t, err)
+			assert.Equal(t, StatusBypass, result.Status)
+		}
+		assert.Equal(t, 2, calls)
+		assert.Empty(t, storage.items)
+	})
+}
+
+func TestAlignTimeRange(t *testing.T) {
+	query := &tsdb.TsdbQuery{
+		TimeRange: tsdb.NewTimeRange("1000", "125000"),
+		Queries:   []*tsdb.Query{{IntervalMs: 1000}, {IntervalMs: 60000}},
+	}
+
+	to, err := alignTimeRange(query)
+	require.NoError(t, err)
+	assert.Equal(t, "0", query.TimeRange.From)
+	assert.Equal(t, "180000", query.TimeRange.To)
+	assert.Equal(t, int64(180000), to.UnixNano()/int64(time.Millisecond))
+}
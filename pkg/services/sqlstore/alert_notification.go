@@ -10,6 +10,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -32,24 +33,35 @@ func init() {
 
 func DeleteAlertNotification(cmd *models.DeleteAlertNotificationCommand) error {
 	return inTransaction(func(sess *DBSession) error {
-		sql := "DELETE FROM alert_notification WHERE alert_notification.org_id = ? AND alert_notification.id = ?"
-		res, err := sess.Exec(sql, cmd.OrgId, cmd.Id)
+		notification := models.AlertNotification{}
+		has, err := sess.Where("org_id = ? AND id = ?", cmd.OrgId, cmd.Id).Get(&notification)
 		if err != nil {
 			return err
 		}
-		rowsAffected, err := res.RowsAffected()
-		if err != nil {
-			return err
+		if !has {
+			return models.ErrAlertNotificationNotFound
 		}
 
-		if rowsAffected == 0 {
-			return models.ErrAlertNotificationNotFound
+		sql := "DELETE FROM alert_notification WHERE alert_notification.org_id = ? AND alert_notification.id = ?"
+		if _, err := sess.Exec(sql, cmd.OrgId, cmd.Id); err != nil {
+			return err
 		}
 
 		if _, err := sess.Exec("DELETE FROM alert_notification_state WHERE alert_notification_state.org_id = ? AND alert_notification_state.notifier_id = ?", cmd.OrgId, cmd.Id); err != nil {
 			return err
 		}
 
+		if _, err := sess.Exec("DELETE FROM alert_notification_delivery WHERE alert_notification_delivery.org_id = ? AND alert_notification_delivery.notifier_id = ?", cmd.OrgId, cmd.Id); err != nil {
+			return err
+		}
+
+		sess.publishAfterCommit(&events.AlertNotificationDeleted{
+			Timestamp: time.Now(),
+			Id:        notification.Id,
+			Uid:       notification.Uid,
+			Type:      notification.Type,
+			OrgId:     notification.OrgId,
+		})
 		return nil
 	})
 }
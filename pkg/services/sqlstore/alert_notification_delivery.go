@@ -0,0 +1,61 @@
+package sqlstore
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", RecordAlertNotificationDelivery)
+	bus.AddHandler("sql", GetAlertNotificationDeliveryHistory)
+}
+
+func RecordAlertNotificationDelivery(cmd *models.RecordAlertNotificationDeliveryCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		delivery := &models.AlertNotificationDelivery{
+			OrgId:      cmd.OrgId,
+			NotifierId: cmd.NotifierId,
+			AlertId:    cmd.AlertId,
+			Success:    cmd.Success,
+			StatusCode: cmd.StatusCode,
+			Error:      cmd.Error,
+			LatencyMs:  cmd.Latency.Milliseconds(),
+			SentAt:     cmd.SentAt,
+		}
+
+		if _, err := sess.Insert(delivery); err != nil {
+			return err
+		}
+
+		if cmd.MaxEntries <= 0 {
+			return nil
+		}
+
+		// find the newest delivery that falls outside of the retention
+		// and remove it together with everything older than it.
+		var ids []int64
+		sql := "SELECT id FROM alert_notification_delivery WHERE org_id = ? AND notifier_id = ? ORDER BY id DESC " +
+			dialect.LimitOffset(1, int64(cmd.MaxEntries))
+		if err := sess.SQL(sql, cmd.OrgId, cmd.NotifierId).Find(&ids); err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		_, err := sess.Exec("DELETE FROM alert_notification_delivery WHERE org_id = ? AND notifier_id = ? AND id <= ?",
+			cmd.OrgId, cmd.NotifierId, ids[0])
+		return err
+	})
+}
+
+func GetAlertNotificationDeliveryHistory(query *models.GetAlertNotificationDeliveryHistoryQuery) error {
+	sess := x.Where("org_id = ? AND notifier_id = ?", query.OrgId, query.NotifierId).Desc("id")
+	if query.Limit > 0 {
+		sess.Limit(query.Limit)
+	}
+
+	query.Result = make([]*models.AlertNotificationDelivery, 0)
+	return sess.Find(&query.Result)
+}
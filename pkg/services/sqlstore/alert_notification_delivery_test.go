@@ -0,0 +1,82 @@
+// +build integration
+
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestAlertNotificationDeliverySQLAccess(t *testing.T) {
+	InitTestDB(t)
+
+	var orgID int64 = 1
+	var notifierID int64 = 3
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	record := func(t *testing.T, notifierID int64, alertID int64, success bool, maxEntries int) {
+		t.Helper()
+
+		cmd := &models.RecordAlertNotificationDeliveryCommand{
+			OrgId:      orgID,
+			NotifierId: notifierID,
+			AlertId:    alertID,
+			Success:    success,
+			Latency:    150 * time.Millisecond,
+			SentAt:     start.Add(time.Duration(alertID) * time.Minute),
+			MaxEntries: maxEntries,
+		}
+		if !success {
+			cmd.StatusCode = 500
+			cmd.Error = "Webhook response status 500 Internal Server Error"
+		}
+		require.NoError(t, RecordAlertNotificationDelivery(cmd))
+	}
+
+	t.Run("history is returned newest first", func(t *testing.T) {
+		record(t, notifierID, 1, true, 10)
+		record(t, notifierID, 2, false, 10)
+
+		query := &models.GetAlertNotificationDeliveryHistoryQuery{OrgId: orgID, NotifierId: notifierID}
+		require.NoError(t, GetAlertNotificationDeliveryHistory(query))
+		require.Len(t, query.Result, 2)
+
+		assert.Equal(t, int64(2), query.Result[0].AlertId)
+		assert.False(t, query.Result[0].Success)
+		assert.Equal(t, 500, query.Result[0].StatusCode)
+		assert.Equal(t, "Webhook response status 500 Internal Server Error", query.Result[0].Error)
+		assert.Equal(t, int64(150), query.Result[0].LatencyMs)
+		assert.Equal(t, int64(1), query.Result[1].AlertId)
+		assert.True(t, query.Result[1].Success)
+	})
+
+	t.Run("history is limited to max entries per channel", func(t *testing.T) {
+		var otherNotifierID int64 = 4
+		record(t, otherNotifierID, 1, true, 2)
+		for alertID := int64(3); alertID <= 6; alertID++ {
+			record(t, notifierID, alertID, true, 3)
+		}
+
+		query := &models.GetAlertNotificationDeliveryHistoryQuery{OrgId: orgID, NotifierId: notifierID}
+		require.NoError(t, GetAlertNotificationDeliveryHistory(query))
+		require.Len(t, query.Result, 3)
+		assert.Equal(t, int64(6), query.Result[0].AlertId)
+		assert.Equal(t, int64(4), query.Result[2].AlertId)
+
+		query = &models.GetAlertNotificationDeliveryHistoryQuery{OrgId: orgID, NotifierId: otherNotifierID}
+		require.NoError(t, GetAlertNotificationDeliveryHistory(query))
+		require.Len(t, query.Result, 1)
+	})
+
+	t.Run("limit restricts the returned history", func(t *testing.T) {
+		query := &models.GetAlertNotificationDeliveryHistoryQuery{OrgId: orgID, NotifierId: notifierID, Limit: 1}
+		require.NoError(t, GetAlertNotificationDeliveryHistory(query))
+		require.Len(t, query.Result, 1)
+		assert.Equal(t, int64(6), query.Result[0].AlertId)
+	})
+}
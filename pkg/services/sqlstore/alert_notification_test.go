@@ -9,7 +9,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -437,12 +439,21 @@ func TestAlertNotificationSQLAccess(t *testing.T) {
 			err := CreateAlertNotificationCommand(cmd)
 			So(err, ShouldBeNil)
 
+			var deleted []*events.AlertNotificationDeleted
+			bus.AddEventListener(func(e *events.AlertNotificationDeleted) error {
+				deleted = append(deleted, e)
+				return nil
+			})
+
 			deleteCmd := &models.DeleteAlertNotificationCommand{
 				Id:    cmd.Result.Id,
 				OrgId: 1,
 			}
 			err = DeleteAlertNotification(deleteCmd)
 			So(err, ShouldBeNil)
+			So(deleted, ShouldHaveLength, 1)
+			So(deleted[0].Uid, ShouldEqual, cmd.Result.Uid)
+			So(deleted[0].Type, ShouldEqual, "email")
 
 			Convey("using UID", func() {
 				err := CreateAlertNotificationCommand(cmd)
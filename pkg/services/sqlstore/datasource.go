@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 )
 
@@ -109,30 +110,44 @@ func (ss *SQLStore) DeleteDataSource(uid string, id int64, name string, orgID in
 // DeleteDataSource removes a datasource by org_id as well as either uid (preferred), id, or name
 // and is added to the bus.
 func DeleteDataSource(cmd *models.DeleteDataSourceCommand) error {
-	params := make([]interface{}, 0)
-
-	makeQuery := func(sql string, p ...interface{}) {
-		params = append(params, sql)
-		params = append(params, p...)
-	}
+	var where string
+	var param interface{}
 
 	switch {
 	case cmd.OrgID == 0:
 		return models.ErrDataSourceIdentifierNotSet
 	case cmd.UID != "":
-		makeQuery("DELETE FROM data_source WHERE uid=? and org_id=?", cmd.UID, cmd.OrgID)
+		where, param = "uid=? and org_id=?", cmd.UID
 	case cmd.ID != 0:
-		makeQuery("DELETE FROM data_source WHERE id=? and org_id=?", cmd.ID, cmd.OrgID)
+		where, param = "id=? and org_id=?", cmd.ID
 	case cmd.Name != "":
-		makeQuery("DELETE FROM data_source WHERE name=? and org_id=?", cmd.Name, cmd.OrgID)
+		where, param = "name=? and org_id=?", cmd.Name
 	default:
 		return models.ErrDataSourceIdentifierNotSet
 	}
 
 	return inTransaction(func(sess *DBSession) error {
-		result, err := sess.Exec(params...)
+		var dataSources []*models.DataSource
+		if err := sess.Where(where, param, cmd.OrgID).Find(&dataSources); err != nil {
+			return err
+		}
+
+		result, err := sess.Exec("DELETE FROM data_source WHERE "+where, param, cmd.OrgID)
+		if err != nil {
+			return err
+		}
 		cmd.DeletedDatasourcesCount, _ = result.RowsAffected()
-		return err
+
+		for _, ds := range dataSources {
+			sess.publishAfterCommit(&events.DataSourceDeleted{
+				Timestamp: time.Now(),
+				Id:        ds.Id,
+				Uid:       ds.Uid,
+				Name:      ds.Name,
+				OrgId:     ds.OrgId,
+			})
+		}
+		return nil
 	})
 }
 
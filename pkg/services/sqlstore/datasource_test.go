@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -204,6 +206,25 @@ func TestDataAccess(t *testing.T) {
 		})
 	})
 
+	t.Run("DeleteDataSource publishes an event per deleted datasource", func(t *testing.T) {
+		InitTestDB(t)
+		ds := initDatasource()
+
+		var deleted []*events.DataSourceDeleted
+		bus.AddEventListener(func(e *events.DataSourceDeleted) error {
+			deleted = append(deleted, e)
+			return nil
+		})
+
+		err := DeleteDataSource(&models.DeleteDataSourceCommand{UID: ds.Uid, OrgID: ds.OrgId})
+		require.NoError(t, err)
+
+		require.Len(t, deleted, 1)
+		require.Equal(t, ds.Id, deleted[0].Id)
+		require.Equal(t, ds.Name, deleted[0].Name)
+		require.Equal(t, ds.OrgId, deleted[0].OrgId)
+	})
+
 	t.Run("DeleteDataSourceByName", func(t *testing.T) {
 		InitTestDB(t)
 		ds := initDatasource()
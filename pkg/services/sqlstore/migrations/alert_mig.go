@@ -212,4 +212,26 @@ func addAlertMigrations(mg *Migrator) {
 	mg.AddMigration("Add non-unique index alert_rule_tag_alert_id", NewAddIndexMigration(alertRuleTagTable, &Index{
 		Cols: []string{"alert_id"}, Type: IndexType,
 	}))
+
+	alertNotificationDelivery := Table{
+		Name: "alert_notification_delivery",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "notifier_id", Type: DB_BigInt, Nullable: false},
+			{Name: "alert_id", Type: DB_BigInt, Nullable: false},
+			{Name: "success", Type: DB_Bool, Nullable: false},
+			{Name: "status_code", Type: DB_Int, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "latency_ms", Type: DB_BigInt, Nullable: false},
+			{Name: "sent_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "notifier_id"}},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_delivery table v1", NewAddTableMigration(alertNotificationDelivery))
+	mg.AddMigration("add index alert_notification_delivery org_id & notifier_id",
+		NewAddIndexMigration(alertNotificationDelivery, alertNotificationDelivery.Indices[0]))
 }
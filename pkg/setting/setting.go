@@ -169,6 +169,10 @@ var (
 	AlertingMaxAttempts         int
 	AlertingMinInterval         int64
 
+	AlertingNotificationHistoryMaxEntries int
+	AlertingLintTimeout                   time.Duration
+	AlertingImageAttachmentMaxBytes       int64
+
 	// Explore UI
 	ExploreEnabled bool
 
@@ -327,6 +331,15 @@ type Cfg struct {
 
 	// Data sources
 	DataSourceLimit int
+	// DataSourceConcurrentQueryLimit is the maximum number of queries of a
+	// request that a data source runs at the same time.
+	DataSourceConcurrentQueryLimit int
+	// SQLiteDatasourceAllowedPaths are the files and directories that SQLite
+	// data sources may read.
+	SQLiteDatasourceAllowedPaths []string
+	// SQLiteDatasourceAllowWrites allows SQLite data sources to disable
+	// read-only mode.
+	SQLiteDatasourceAllowWrites bool
 
 	// Snapshots
 	SnapshotPublicMode bool
@@ -349,6 +362,9 @@ type Cfg struct {
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
+
+	// QueryCaching configures the data source query result cache.
+	QueryCaching QueryCachingSettings
 }
 
 // IsLiveEnabled returns if grafana live should be enabled
@@ -497,6 +513,28 @@ func (cfg *Cfg) readExpressionsSettings() {
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
 }
 
+func (cfg *Cfg) readQueryCachingSettings() {
+	queryCaching := cfg.Raw.Section("query_caching")
+	cfg.QueryCaching = QueryCachingSettings{
+		Enabled:             queryCaching.Key("enabled").MustBool(true),
+		TTL:                 queryCaching.Key("ttl").MustDuration(time.Minute),
+		HistoricalTTL:       queryCaching.Key("historical_ttl").MustDuration(time.Hour),
+		HistoricalThreshold: queryCaching.Key("historical_threshold").MustDuration(time.Hour),
+	}
+}
+
+// QueryCachingSettings configures the cache for results of data source
+// queries. Data sources opt in to the cache individually.
+type QueryCachingSettings struct {
+	Enabled bool
+	// TTL is how long results of queries that include recent data are cached.
+	TTL time.Duration
+	// HistoricalTTL is how long results of queries whose time range ended
+	// more than HistoricalThreshold ago are cached.
+	HistoricalTTL       time.Duration
+	HistoricalThreshold time.Duration
+}
+
 type AnnotationCleanupSettings struct {
 	MaxAge   time.Duration
 	MaxCount int64
@@ -871,6 +909,7 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	cfg.readQuotaSettings()
 	cfg.readAnnotationSettings()
 	cfg.readExpressionsSettings()
+	cfg.readQueryCachingSettings()
 	if err := cfg.readGrafanaEnvironmentMetrics(); err != nil {
 		return err
 	}
@@ -1260,6 +1299,10 @@ func readAlertingSettings(iniFile *ini.File) error {
 	AlertingNotificationTimeout = time.Second * time.Duration(notificationTimeoutSeconds)
 	AlertingMaxAttempts = alerting.Key("max_attempts").MustInt(3)
 	AlertingMinInterval = alerting.Key("min_interval_seconds").MustInt64(1)
+	AlertingNotificationHistoryMaxEntries = alerting.Key("notification_history_max_entries").MustInt(100)
+	lintTimeoutSeconds := alerting.Key("lint_timeout_seconds").MustInt64(5)
+	AlertingLintTimeout = time.Second * time.Duration(lintTimeoutSeconds)
+	AlertingImageAttachmentMaxBytes = alerting.Key("image_attachment_max_bytes").MustInt64(8388608)
 
 	return nil
 }
@@ -1354,4 +1397,7 @@ func (cfg *Cfg) GetContentDeliveryURL(prefix string) string {
 func (cfg *Cfg) readDataSourcesSettings() {
 	datasources := cfg.Raw.Section("datasources")
 	cfg.DataSourceLimit = datasources.Key("datasource_limit").MustInt(5000)
+	cfg.DataSourceConcurrentQueryLimit = datasources.Key("concurrent_query_limit").MustInt(10)
+	cfg.SQLiteDatasourceAllowedPaths = util.SplitString(datasources.Key("sqlite_allowed_paths").MustString(""))
+	cfg.SQLiteDatasourceAllowWrites = datasources.Key("sqlite_allow_writes").MustBool(false)
 }
@@ -49,25 +49,25 @@ type ApplicationInsightsQuery struct {
 	aggregation string
 }
 
-func (e *ApplicationInsightsDatasource) executeTimeSeriesQuery(ctx context.Context, originalQueries []*tsdb.Query, timeRange *tsdb.TimeRange) (*tsdb.Response, error) {
-	result := &tsdb.Response{
-		Results: map[string]*tsdb.QueryResult{},
-	}
-
+func (e *ApplicationInsightsDatasource) executeTimeSeriesQuery(ctx context.Context, originalQueries []*tsdb.Query, timeRange *tsdb.TimeRange, concurrentQueryLimit int) (*tsdb.Response, error) {
 	queries, err := e.buildQueries(originalQueries, timeRange)
 	if err != nil {
 		return nil, err
 	}
 
+	queriesByRefID := make(map[string]*ApplicationInsightsQuery, len(queries))
 	for _, query := range queries {
-		queryRes, err := e.executeQuery(ctx, query)
-		if err != nil {
-			return nil, err
-		}
-		result.Results[query.RefID] = queryRes
+		queriesByRefID[query.RefID] = query
 	}
 
-	return result, nil
+	return tsdb.ExecuteQueries(ctx, e.dsInfo, &tsdb.TsdbQuery{Queries: originalQueries, ConcurrentQueryLimit: concurrentQueryLimit}, func(ctx context.Context, q *tsdb.Query) (*tsdb.QueryResult, error) {
+		query, ok := queriesByRefID[q.RefId]
+		if !ok {
+			return nil, nil
+		}
+
+		return e.executeQuery(ctx, query)
+	}), nil
 }
 
 func (e *ApplicationInsightsDatasource) buildQueries(queries []*tsdb.Query, timeRange *tsdb.TimeRange) ([]*ApplicationInsightsQuery, error) {
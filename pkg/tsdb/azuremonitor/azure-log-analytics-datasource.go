@@ -45,21 +45,25 @@ type AzureLogAnalyticsQuery struct {
 // 1. build the AzureMonitor url and querystring for each query
 // 2. executes each query by calling the Azure Monitor API
 // 3. parses the responses for each query into the timeseries format
-func (e *AzureLogAnalyticsDatasource) executeTimeSeriesQuery(ctx context.Context, originalQueries []*tsdb.Query, timeRange *tsdb.TimeRange) (*tsdb.Response, error) {
-	result := &tsdb.Response{
-		Results: map[string]*tsdb.QueryResult{},
-	}
-
+func (e *AzureLogAnalyticsDatasource) executeTimeSeriesQuery(ctx context.Context, originalQueries []*tsdb.Query, timeRange *tsdb.TimeRange, concurrentQueryLimit int) (*tsdb.Response, error) {
 	queries, err := e.buildQueries(originalQueries, timeRange)
 	if err != nil {
 		return nil, err
 	}
 
+	queriesByRefID := make(map[string]*AzureLogAnalyticsQuery, len(queries))
 	for _, query := range queries {
-		result.Results[query.RefID] = e.executeQuery(ctx, query, originalQueries, timeRange)
+		queriesByRefID[query.RefID] = query
 	}
 
-	return result, nil
+	return tsdb.ExecuteQueries(ctx, e.dsInfo, &tsdb.TsdbQuery{Queries: originalQueries, ConcurrentQueryLimit: concurrentQueryLimit}, func(ctx context.Context, q *tsdb.Query) (*tsdb.QueryResult, error) {
+		query, ok := queriesByRefID[q.RefId]
+		if !ok {
+			return nil, nil
+		}
+
+		return e.executeQuery(ctx, query, originalQueries, timeRange), nil
+	}), nil
 }
 
 func (e *AzureLogAnalyticsDatasource) buildQueries(queries []*tsdb.Query, timeRange *tsdb.TimeRange) ([]*AzureLogAnalyticsQuery, error) {
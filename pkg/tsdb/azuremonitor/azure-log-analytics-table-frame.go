@@ -70,6 +70,12 @@ var converterMap = map[string]data.FieldConverter{
 	"real":     realConverter,
 	"bool":     boolConverter,
 	"decimal":  decimalConverter,
+
+	// Azure Resource Graph column types
+	"integer": longConverter,
+	"number":  realConverter,
+	"boolean": boolConverter,
+	"object":  objectToStringConverter,
 }
 
 var stringConverter = data.FieldConverter{
@@ -88,6 +94,27 @@ var stringConverter = data.FieldConverter{
 	},
 }
 
+// objectToStringConverter converts the JSON objects and arrays of Azure Resource
+// Graph columns, like tags, back to their JSON encoding.
+var objectToStringConverter = data.FieldConverter{
+	OutputFieldType: data.FieldTypeNullableString,
+	Converter: func(v interface{}) (interface{}, error) {
+		var as *string
+		if v == nil {
+			return as, nil
+		}
+		if s, ok := v.(string); ok {
+			return &s, nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		s := string(b)
+		return &s, nil
+	},
+}
+
 var timeConverter = data.FieldConverter{
 	OutputFieldType: data.FieldTypeNullableTime,
 	Converter: func(v interface{}) (interface{}, error) {
@@ -0,0 +1,288 @@
+package azuremonitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/api/pluginproxy"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/opentracing/opentracing-go"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+const argAPIVersion = "2021-03-01"
+
+// AzureResourceGraphDatasource calls the Azure Resource Graph API
+type AzureResourceGraphDatasource struct {
+	httpClient *http.Client
+	dsInfo     *models.DataSource
+}
+
+// AzureResourceGraphQuery is the query request that is built from the saved values
+// from the UI
+type AzureResourceGraphQuery struct {
+	RefID             string
+	URL               string
+	Model             *simplejson.Json
+	Subscriptions     []string
+	InterpolatedQuery string
+}
+
+// argRequest is the body of a request to the Azure Resource Graph API.
+type argRequest struct {
+	Subscriptions []string          `json:"subscriptions"`
+	Query         string            `json:"query"`
+	Options       map[string]string `json:"options"`
+}
+
+// executeTimeSeriesQuery does the following:
+// 1. builds the Azure Resource Graph request body for each query
+// 2. executes each query by calling the Azure Resource Graph API
+// 3. parses the responses for each query into a table frame
+func (e *AzureResourceGraphDatasource) executeTimeSeriesQuery(ctx context.Context, originalQueries []*tsdb.Query, timeRange *tsdb.TimeRange, concurrentQueryLimit int) (*tsdb.Response, error) {
+	queries, err := e.buildQueries(originalQueries, timeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	queriesByRefID := make(map[string]*AzureResourceGraphQuery, len(queries))
+	for _, query := range queries {
+		queriesByRefID[query.RefID] = query
+	}
+
+	return tsdb.ExecuteQueries(ctx, e.dsInfo, &tsdb.TsdbQuery{Queries: originalQueries, ConcurrentQueryLimit: concurrentQueryLimit}, func(ctx context.Context, q *tsdb.Query) (*tsdb.QueryResult, error) {
+		query, ok := queriesByRefID[q.RefId]
+		if !ok {
+			return nil, nil
+		}
+
+		return e.executeQuery(ctx, query, timeRange), nil
+	}), nil
+}
+
+func (e *AzureResourceGraphDatasource) buildQueries(queries []*tsdb.Query, timeRange *tsdb.TimeRange) ([]*AzureResourceGraphQuery, error) {
+	azureResourceGraphQueries := []*AzureResourceGraphQuery{}
+
+	for _, query := range queries {
+		queryBytes, err := query.Model.Encode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-encode the Azure Resource Graph query into JSON: %w", err)
+		}
+
+		queryJSONModel := argJSONQuery{}
+		err = json.Unmarshal(queryBytes, &queryJSONModel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the Azure Resource Graph query object from JSON: %w", err)
+		}
+
+		azureResourceGraphTarget := queryJSONModel.AzureResourceGraph
+		azlog.Debug("AzureResourceGraph", "target", azureResourceGraphTarget)
+
+		interpolatedQuery, err := KqlInterpolate(query, timeRange, azureResourceGraphTarget.Query)
+		if err != nil {
+			return nil, err
+		}
+
+		azureResourceGraphQueries = append(azureResourceGraphQueries, &AzureResourceGraphQuery{
+			RefID:             query.RefId,
+			URL:               "providers/Microsoft.ResourceGraph/resources",
+			Model:             query.Model,
+			Subscriptions:     e.subscriptions(queryJSONModel),
+			InterpolatedQuery: interpolatedQuery,
+		})
+	}
+
+	return azureResourceGraphQueries, nil
+}
+
+// subscriptions returns the subscriptions a query is scoped to: the ones selected in
+// the query, or else the default subscription of the datasource. Queries with no
+// subscriptions are scoped to all subscriptions the credentials have access to.
+func (e *AzureResourceGraphDatasource) subscriptions(query argJSONQuery) []string {
+	subscriptions := []string{}
+	for _, subscription := range query.Subscriptions {
+		if subscription != "" {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	if len(subscriptions) > 0 {
+		return subscriptions
+	}
+
+	if query.Subscription != "" {
+		return []string{query.Subscription}
+	}
+	if e.dsInfo != nil && e.dsInfo.JsonData != nil {
+		if subscription := e.dsInfo.JsonData.Get("subscriptionId").MustString(); subscription != "" {
+			return []string{subscription}
+		}
+	}
+	return subscriptions
+}
+
+func (e *AzureResourceGraphDatasource) executeQuery(ctx context.Context, query *AzureResourceGraphQuery, timeRange *tsdb.TimeRange) *tsdb.QueryResult {
+	queryResult := &tsdb.QueryResult{RefId: query.RefID}
+
+	queryResultErrorWithExecuted := func(err error) *tsdb.QueryResult {
+		queryResult.Error = err
+		frames := data.Frames{
+			&data.Frame{
+				RefID: query.RefID,
+				Meta: &data.FrameMeta{
+					ExecutedQueryString: query.InterpolatedQuery,
+				},
+			},
+		}
+		queryResult.Dataframes = tsdb.NewDecodedDataFrames(frames)
+		return queryResult
+	}
+
+	body, err := json.Marshal(&argRequest{
+		Subscriptions: query.Subscriptions,
+		Query:         query.InterpolatedQuery,
+		Options:       map[string]string{"resultFormat": "table"},
+	})
+	if err != nil {
+		queryResult.Error = err
+		return queryResult
+	}
+
+	req, err := e.createRequest(ctx, e.dsInfo, query.URL, body)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult
+	}
+
+	params := req.URL.Query()
+	params.Set("api-version", argAPIVersion)
+	req.URL.RawQuery = params.Encode()
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "azure resource graph query")
+	span.SetTag("interpolated_query", query.InterpolatedQuery)
+	span.SetTag("from", timeRange.From)
+	span.SetTag("until", timeRange.To)
+	span.SetTag("datasource_id", e.dsInfo.Id)
+	span.SetTag("org_id", e.dsInfo.OrgId)
+
+	defer span.Finish()
+
+	if err := opentracing.GlobalTracer().Inject(
+		span.Context(),
+		opentracing.HTTPHeaders,
+		opentracing.HTTPHeadersCarrier(req.Header)); err != nil {
+		return queryResultErrorWithExecuted(err)
+	}
+
+	azlog.Debug("AzureResourceGraph", "Request ApiURL", req.URL.String())
+	res, err := ctxhttp.Do(ctx, e.httpClient, req)
+	if err != nil {
+		return queryResultErrorWithExecuted(err)
+	}
+
+	argResponse, err := e.unmarshalResponse(res)
+	if err != nil {
+		return queryResultErrorWithExecuted(err)
+	}
+
+	frame, err := LogTableToFrame(&argResponse.Data)
+	if err != nil {
+		return queryResultErrorWithExecuted(err)
+	}
+
+	frame.RefID = query.RefID
+	frame.Meta.ExecutedQueryString = query.InterpolatedQuery
+	if argResponse.ResultTruncated == "true" {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("the result was truncated to %d of %d records, use limit or summarize to reduce the number of records", argResponse.Count, argResponse.TotalRecords),
+		})
+	}
+
+	frames := data.Frames{frame}
+	queryResult.Dataframes = tsdb.NewDecodedDataFrames(frames)
+	return queryResult
+}
+
+func (e *AzureResourceGraphDatasource) createRequest(ctx context.Context, dsInfo *models.DataSource, apiPath string, body []byte) (*http.Request, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "render")
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		azlog.Debug("Failed to create request", "error", err)
+		return nil, errutil.Wrap("failed to create request", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("Grafana/%s", setting.BuildVersion))
+
+	// find plugin
+	plugin, ok := plugins.DataSources[dsInfo.Type]
+	if !ok {
+		return nil, errors.New("unable to find datasource plugin Azure Monitor")
+	}
+	cloudName := dsInfo.JsonData.Get("cloudName").MustString("azuremonitor")
+
+	argRoute, proxypass, err := e.getPluginRoute(plugin, cloudName)
+	if err != nil {
+		return nil, err
+	}
+	pluginproxy.ApplyRoute(ctx, req, fmt.Sprintf("%s/%s", proxypass, apiPath), argRoute, dsInfo)
+
+	return req, nil
+}
+
+// getPluginRoute returns the Azure Resource Manager route of a cloud, which also
+// serves the Azure Resource Graph API.
+func (e *AzureResourceGraphDatasource) getPluginRoute(plugin *plugins.DataSourcePlugin, cloudName string) (*plugins.AppPluginRoute, string, error) {
+	for _, route := range plugin.Routes {
+		if route.Path == cloudName {
+			return route, cloudName, nil
+		}
+	}
+	return nil, "", fmt.Errorf("unable to find the Azure Resource Manager route for cloud %q", cloudName)
+}
+
+func (e *AzureResourceGraphDatasource) unmarshalResponse(res *http.Response) (AzureResourceGraphResponse, error) {
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return AzureResourceGraphResponse{}, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			azlog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		azlog.Debug("Request failed", "status", res.Status, "body", string(body))
+		return AzureResourceGraphResponse{}, fmt.Errorf("request failed, status: %s, body: %s", res.Status, string(body))
+	}
+
+	var data AzureResourceGraphResponse
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	err = d.Decode(&data)
+	if err != nil {
+		azlog.Debug("Failed to unmarshal Azure Resource Graph response", "error", err, "status", res.Status, "body", string(body))
+		return AzureResourceGraphResponse{}, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,277 @@
+package azuremonitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xorcare/pointer"
+)
+
+func TestBuildingAzureResourceGraphQueries(t *testing.T) {
+	fromStart := time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC).In(time.Local)
+	timeRange := &tsdb.TimeRange{
+		From: fmt.Sprintf("%v", fromStart.Unix()*1000),
+		To:   fmt.Sprintf("%v", fromStart.Add(34*time.Minute).Unix()*1000),
+	}
+
+	tests := []struct {
+		name                      string
+		jsonData                  map[string]interface{}
+		queryModel                map[string]interface{}
+		azureResourceGraphQueries []*AzureResourceGraphQuery
+		Err                       require.ErrorAssertionFunc
+	}{
+		{
+			name: "Query with macros should be interpolated",
+			queryModel: map[string]interface{}{
+				"queryType":     "Azure Resource Graph",
+				"subscriptions": []string{"sub-1", "sub-2"},
+				"azureResourceGraph": map[string]interface{}{
+					"query": "resources | where todatetime(properties.timeCreated) between ($__timeFrom .. $__timeTo) | where $__contains(location, 'westeurope','northeurope')",
+				},
+			},
+			azureResourceGraphQueries: []*AzureResourceGraphQuery{
+				{
+					RefID:             "A",
+					URL:               "providers/Microsoft.ResourceGraph/resources",
+					Subscriptions:     []string{"sub-1", "sub-2"},
+					InterpolatedQuery: "resources | where todatetime(properties.timeCreated) between (datetime('2018-03-15T13:00:00Z') .. datetime('2018-03-15T13:34:00Z')) | where ['location'] in ('westeurope','northeurope')",
+				},
+			},
+			Err: require.NoError,
+		},
+		{
+			name:     "Query without subscriptions should be scoped to the default subscription",
+			jsonData: map[string]interface{}{"subscriptionId": "default-sub"},
+			queryModel: map[string]interface{}{
+				"queryType": "Azure Resource Graph",
+				"azureResourceGraph": map[string]interface{}{
+					"query": "resources | summarize count() by type",
+				},
+			},
+			azureResourceGraphQueries: []*AzureResourceGraphQuery{
+				{
+					RefID:             "A",
+					URL:               "providers/Microsoft.ResourceGraph/resources",
+					Subscriptions:     []string{"default-sub"},
+					InterpolatedQuery: "resources | summarize count() by type",
+				},
+			},
+			Err: require.NoError,
+		},
+		{
+			name:     "Query subscription should take precedence over the default subscription",
+			jsonData: map[string]interface{}{"subscriptionId": "default-sub"},
+			queryModel: map[string]interface{}{
+				"queryType":    "Azure Resource Graph",
+				"subscription": "query-sub",
+				"azureResourceGraph": map[string]interface{}{
+					"query": "resources",
+				},
+			},
+			azureResourceGraphQueries: []*AzureResourceGraphQuery{
+				{
+					RefID:             "A",
+					URL:               "providers/Microsoft.ResourceGraph/resources",
+					Subscriptions:     []string{"query-sub"},
+					InterpolatedQuery: "resources",
+				},
+			},
+			Err: require.NoError,
+		},
+		{
+			name: "Query with an invalid macro should fail",
+			queryModel: map[string]interface{}{
+				"queryType": "Azure Resource Graph",
+				"azureResourceGraph": map[string]interface{}{
+					"query": "resources | where $__contains(location)",
+				},
+			},
+			Err: require.Error,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsInfo := &models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{})}
+			if tt.jsonData != nil {
+				dsInfo.JsonData = simplejson.NewFromAny(tt.jsonData)
+			}
+			datasource := &AzureResourceGraphDatasource{dsInfo: dsInfo}
+
+			queries, err := datasource.buildQueries([]*tsdb.Query{
+				{
+					DataSource: dsInfo,
+					Model:      simplejson.NewFromAny(tt.queryModel),
+					RefId:      "A",
+				},
+			}, timeRange)
+			tt.Err(t, err)
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.azureResourceGraphQueries, queries, cmpopts.IgnoreFields(AzureResourceGraphQuery{}, "Model")); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAzureResourceGraphExecuteQuery(t *testing.T) {
+	timeRange := tsdb.NewTimeRange("1h", "now")
+
+	tests := []struct {
+		name          string
+		testFile      string
+		expectedFrame func() *data.Frame
+	}{
+		{
+			name:     "response table",
+			testFile: "azureresourcegraph/1-azure-resource-graph-response-table.json",
+			expectedFrame: func() *data.Frame {
+				frame := data.NewFrame("",
+					data.NewField("name", nil, []*string{
+						pointer.String("grafana-vm_OsDisk_1"),
+						pointer.String("grafana-data"),
+					}),
+					data.NewField("type", nil, []*string{
+						pointer.String("microsoft.compute/disks"),
+						pointer.String("microsoft.compute/disks"),
+					}),
+					data.NewField("location", nil, []*string{
+						pointer.String("westeurope"),
+						pointer.String("northeurope"),
+					}),
+					data.NewField("tags", nil, []*string{
+						pointer.String(`{"environment":"production","team":"observability"}`),
+						nil,
+					}),
+					data.NewField("diskSizeGB", nil, []*int64{
+						pointer.Int64(30),
+						pointer.Int64(8192),
+					}),
+					data.NewField("encrypted", nil, []*bool{
+						pointer.Bool(true),
+						pointer.Bool(false),
+					}),
+					data.NewField("timeCreated", nil, []*time.Time{
+						pointer.Time(time.Date(2020, 10, 1, 8, 15, 42, 123456700, time.UTC)),
+						pointer.Time(time.Date(2021, 2, 11, 17, 3, 0, 0, time.UTC)),
+					}),
+				)
+				frame.RefID = "A"
+				frame.Meta = &data.FrameMeta{
+					ExecutedQueryString: "resources | where type == 'microsoft.compute/disks'",
+					Custom: &LogAnalyticsMeta{ColumnTypes: []string{
+						"string", "string", "string", "object", "integer", "boolean", "datetime",
+					}},
+				}
+				return frame
+			},
+		},
+		{
+			name:     "truncated response",
+			testFile: "azureresourcegraph/2-azure-resource-graph-response-truncated.json",
+			expectedFrame: func() *data.Frame {
+				frame := data.NewFrame("",
+					data.NewField("name", nil, []*string{pointer.String("grafana-vm")}),
+					data.NewField("complianceState", nil, []*string{pointer.String("NonCompliant")}),
+				)
+				frame.RefID = "A"
+				frame.Meta = &data.FrameMeta{
+					ExecutedQueryString: "resources | where type == 'microsoft.compute/disks'",
+					Custom:              &LogAnalyticsMeta{ColumnTypes: []string{"string", "string"}},
+					Notices: []data.Notice{{
+						Severity: data.NoticeSeverityWarning,
+						Text:     "the result was truncated to 1 of 1450 records, use limit or summarize to reduce the number of records",
+					}},
+				}
+				return frame
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var request *http.Request
+			var requestBody argRequest
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				request = r
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&requestBody))
+
+				// Ignore gosec warning G304 since it's a test
+				// nolint:gosec
+				response, err := ioutil.ReadFile(filepath.Join("testdata", tt.testFile))
+				assert.NoError(t, err)
+				_, err = w.Write(response)
+				assert.NoError(t, err)
+			}))
+			defer server.Close()
+			mockPluginRoutes(t, server.URL)
+
+			datasource := &AzureResourceGraphDatasource{
+				httpClient: server.Client(),
+				dsInfo: &models.DataSource{
+					Type:     "grafana-azure-monitor-datasource",
+					JsonData: simplejson.NewFromAny(map[string]interface{}{}),
+				},
+			}
+
+			res := datasource.executeQuery(context.Background(), &AzureResourceGraphQuery{
+				RefID:             "A",
+				URL:               "providers/Microsoft.ResourceGraph/resources",
+				Subscriptions:     []string{"sub-1"},
+				InterpolatedQuery: "resources | where type == 'microsoft.compute/disks'",
+			}, timeRange)
+			require.NoError(t, res.Error)
+
+			require.Equal(t, http.MethodPost, request.Method)
+			require.Equal(t, "/providers/Microsoft.ResourceGraph/resources", request.URL.Path)
+			require.Equal(t, argAPIVersion, request.URL.Query().Get("api-version"))
+			require.Equal(t, argRequest{
+				Subscriptions: []string{"sub-1"},
+				Query:         "resources | where type == 'microsoft.compute/disks'",
+				Options:       map[string]string{"resultFormat": "table"},
+			}, requestBody)
+
+			frames, err := res.Dataframes.Decoded()
+			require.NoError(t, err)
+			require.Len(t, frames, 1)
+			if diff := cmp.Diff(tt.expectedFrame(), frames[0], data.FrameTestCompareOptions()...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func mockPluginRoutes(t *testing.T, url string) {
+	t.Helper()
+
+	origDataSources := plugins.DataSources
+	t.Cleanup(func() {
+		plugins.DataSources = origDataSources
+	})
+	plugins.DataSources = map[string]*plugins.DataSourcePlugin{
+		"grafana-azure-monitor-datasource": {
+			Routes: []*plugins.AppPluginRoute{
+				{Path: "azuremonitor", Method: "GET", URL: url},
+			},
+		},
+	}
+}
@@ -42,17 +42,23 @@ const azureMonitorAPIVersion = "2018-01-01"
 // 1. build the AzureMonitor url and querystring for each query
 // 2. executes each query by calling the Azure Monitor API
 // 3. parses the responses for each query into the timeseries format
-func (e *AzureMonitorDatasource) executeTimeSeriesQuery(ctx context.Context, originalQueries []*tsdb.Query, timeRange *tsdb.TimeRange) (*tsdb.Response, error) {
-	result := &tsdb.Response{
-		Results: map[string]*tsdb.QueryResult{},
-	}
-
+func (e *AzureMonitorDatasource) executeTimeSeriesQuery(ctx context.Context, originalQueries []*tsdb.Query, timeRange *tsdb.TimeRange, concurrentQueryLimit int) (*tsdb.Response, error) {
 	queries, err := e.buildQueries(originalQueries, timeRange)
 	if err != nil {
 		return nil, err
 	}
 
+	queriesByRefID := make(map[string]*AzureMonitorQuery, len(queries))
 	for _, query := range queries {
+		queriesByRefID[query.RefID] = query
+	}
+
+	return tsdb.ExecuteQueries(ctx, e.dsInfo, &tsdb.TsdbQuery{Queries: originalQueries, ConcurrentQueryLimit: concurrentQueryLimit}, func(ctx context.Context, q *tsdb.Query) (*tsdb.QueryResult, error) {
+		query, ok := queriesByRefID[q.RefId]
+		if !ok {
+			return nil, nil
+		}
+
 		queryRes, resp, err := e.executeQuery(ctx, query, originalQueries, timeRange)
 		if err != nil {
 			return nil, err
@@ -62,10 +68,8 @@ func (e *AzureMonitorDatasource) executeTimeSeriesQuery(ctx context.Context, ori
 		if err != nil {
 			queryRes.Error = err
 		}
-		result.Results[query.RefID] = queryRes
-	}
-
-	return result, nil
+		return queryRes, nil
+	}), nil
 }
 
 func (e *AzureMonitorDatasource) buildQueries(queries []*tsdb.Query, timeRange *tsdb.TimeRange) ([]*AzureMonitorQuery, error) {
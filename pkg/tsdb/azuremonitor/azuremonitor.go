@@ -16,7 +16,7 @@ var (
 	legendKeyFormat *regexp.Regexp
 )
 
-// AzureMonitorExecutor executes queries for the Azure Monitor datasource - all four services
+// AzureMonitorExecutor executes queries for the Azure Monitor datasource - all five services
 type AzureMonitorExecutor struct {
 	httpClient *http.Client
 	dsInfo     *models.DataSource
@@ -52,6 +52,7 @@ func (e *AzureMonitorExecutor) Query(ctx context.Context, dsInfo *models.DataSou
 	var applicationInsightsQueries []*tsdb.Query
 	var azureLogAnalyticsQueries []*tsdb.Query
 	var insightsAnalyticsQueries []*tsdb.Query
+	var azureResourceGraphQueries []*tsdb.Query
 
 	for _, query := range tsdbQuery.Queries {
 		queryType := query.Model.Get("queryType").MustString("")
@@ -65,6 +66,8 @@ func (e *AzureMonitorExecutor) Query(ctx context.Context, dsInfo *models.DataSou
 			azureLogAnalyticsQueries = append(azureLogAnalyticsQueries, query)
 		case "Insights Analytics":
 			insightsAnalyticsQueries = append(insightsAnalyticsQueries, query)
+		case "Azure Resource Graph":
+			azureResourceGraphQueries = append(azureResourceGraphQueries, query)
 		default:
 			return nil, fmt.Errorf("alerting not supported for %q", queryType)
 		}
@@ -90,22 +93,32 @@ func (e *AzureMonitorExecutor) Query(ctx context.Context, dsInfo *models.DataSou
 		dsInfo:     e.dsInfo,
 	}
 
-	azResult, err := azDatasource.executeTimeSeriesQuery(ctx, azureMonitorQueries, tsdbQuery.TimeRange)
+	argDatasource := &AzureResourceGraphDatasource{
+		httpClient: e.httpClient,
+		dsInfo:     e.dsInfo,
+	}
+
+	azResult, err := azDatasource.executeTimeSeriesQuery(ctx, azureMonitorQueries, tsdbQuery.TimeRange, tsdbQuery.ConcurrentQueryLimit)
 	if err != nil {
 		return nil, err
 	}
 
-	aiResult, err := aiDatasource.executeTimeSeriesQuery(ctx, applicationInsightsQueries, tsdbQuery.TimeRange)
+	aiResult, err := aiDatasource.executeTimeSeriesQuery(ctx, applicationInsightsQueries, tsdbQuery.TimeRange, tsdbQuery.ConcurrentQueryLimit)
 	if err != nil {
 		return nil, err
 	}
 
-	alaResult, err := alaDatasource.executeTimeSeriesQuery(ctx, azureLogAnalyticsQueries, tsdbQuery.TimeRange)
+	alaResult, err := alaDatasource.executeTimeSeriesQuery(ctx, azureLogAnalyticsQueries, tsdbQuery.TimeRange, tsdbQuery.ConcurrentQueryLimit)
 	if err != nil {
 		return nil, err
 	}
 
-	iaResult, err := iaDatasource.executeTimeSeriesQuery(ctx, insightsAnalyticsQueries, tsdbQuery.TimeRange)
+	iaResult, err := iaDatasource.executeTimeSeriesQuery(ctx, insightsAnalyticsQueries, tsdbQuery.TimeRange, tsdbQuery.ConcurrentQueryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	argResult, err := argDatasource.executeTimeSeriesQuery(ctx, azureResourceGraphQueries, tsdbQuery.TimeRange, tsdbQuery.ConcurrentQueryLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -122,5 +135,9 @@ func (e *AzureMonitorExecutor) Query(ctx context.Context, dsInfo *models.DataSou
 		azResult.Results[k] = v
 	}
 
+	for k, v := range argResult.Results {
+		azResult.Results[k] = v
+	}
+
 	return azResult, nil
 }
@@ -39,21 +39,25 @@ type InsightsAnalyticsQuery struct {
 	Target string
 }
 
-func (e *InsightsAnalyticsDatasource) executeTimeSeriesQuery(ctx context.Context, originalQueries []*tsdb.Query, timeRange *tsdb.TimeRange) (*tsdb.Response, error) {
-	result := &tsdb.Response{
-		Results: map[string]*tsdb.QueryResult{},
-	}
-
+func (e *InsightsAnalyticsDatasource) executeTimeSeriesQuery(ctx context.Context, originalQueries []*tsdb.Query, timeRange *tsdb.TimeRange, concurrentQueryLimit int) (*tsdb.Response, error) {
 	queries, err := e.buildQueries(originalQueries, timeRange)
 	if err != nil {
 		return nil, err
 	}
 
+	queriesByRefID := make(map[string]*InsightsAnalyticsQuery, len(queries))
 	for _, query := range queries {
-		result.Results[query.RefID] = e.executeQuery(ctx, query)
+		queriesByRefID[query.RefID] = query
 	}
 
-	return result, nil
+	return tsdb.ExecuteQueries(ctx, e.dsInfo, &tsdb.TsdbQuery{Queries: originalQueries, ConcurrentQueryLimit: concurrentQueryLimit}, func(ctx context.Context, q *tsdb.Query) (*tsdb.QueryResult, error) {
+		query, ok := queriesByRefID[q.RefId]
+		if !ok {
+			return nil, nil
+		}
+
+		return e.executeQuery(ctx, query), nil
+	}), nil
 }
 
 func (e *InsightsAnalyticsDatasource) buildQueries(queries []*tsdb.Query, timeRange *tsdb.TimeRange) ([]*InsightsAnalyticsQuery, error) {
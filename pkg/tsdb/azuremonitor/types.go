@@ -69,6 +69,14 @@ type AzureLogAnalyticsTable struct {
 	Rows [][]interface{} `json:"rows"`
 }
 
+// AzureResourceGraphResponse is the json response object from the Azure Resource Graph API.
+type AzureResourceGraphResponse struct {
+	Data            AzureLogAnalyticsTable `json:"data"`
+	TotalRecords    int64                  `json:"totalRecords"`
+	Count           int64                  `json:"count"`
+	ResultTruncated string                 `json:"resultTruncated"`
+}
+
 // azureMonitorJSONQuery is the frontend JSON query model for an Azure Monitor query.
 type azureMonitorJSONQuery struct {
 	AzureMonitor struct {
@@ -137,6 +145,15 @@ type logJSONQuery struct {
 	} `json:"azureLogAnalytics"`
 }
 
+// argJSONQuery is the frontend JSON query model for an Azure Resource Graph query.
+type argJSONQuery struct {
+	AzureResourceGraph struct {
+		Query string `json:"query"`
+	} `json:"azureResourceGraph"`
+	Subscriptions []string `json:"subscriptions"`
+	Subscription  string   `json:"subscription"`
+}
+
 // InsightsDimensions will unmarshal from a JSON string, or an array of strings,
 // into a string array. This exists to support an older query format which is updated
 // when a user saves the query or it is sent from the front end, but may not be when
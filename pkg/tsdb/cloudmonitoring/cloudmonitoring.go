@@ -37,6 +37,8 @@ var (
 	metricNameFormat            = regexp.MustCompile(`([\w\d_]+)\.(googleapis\.com|io)/(.+)`)
 	wildcardRegexRe             = regexp.MustCompile(`[-\/^$+?.()|[\]{}]`)
 	alignmentPeriodRe           = regexp.MustCompile("[0-9]+")
+	mqlGraphPeriodRe            = regexp.MustCompile(`\|\s*graph_period\b`)
+	mqlWithinRe                 = regexp.MustCompile(`\|\s*within\b`)
 	cloudMonitoringUnitMappings = map[string]string{
 		"bit":     "bits",
 		"By":      "bytes",
@@ -60,6 +62,10 @@ const (
 	metricQueryType   string = "metrics"
 	sloQueryType      string = "slo"
 	mqlEditorMode     string = "mql"
+
+	sloHealthSelector     string = "select_slo_health"
+	sloBurnRateSelector   string = "select_slo_burn_rate"
+	defaultLookbackPeriod string = "3600s"
 )
 
 // CloudMonitoringExecutor executes queries for the CloudMonitoring datasource
@@ -127,10 +133,6 @@ func (e *CloudMonitoringExecutor) getGCEDefaultProject(ctx context.Context, tsdb
 }
 
 func (e *CloudMonitoringExecutor) executeTimeSeriesQuery(ctx context.Context, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
-	result := &tsdb.Response{
-		Results: make(map[string]*tsdb.QueryResult),
-	}
-
 	queryExecutors, err := e.buildQueryExecutors(tsdbQuery)
 	if err != nil {
 		return nil, err
@@ -138,18 +140,26 @@ func (e *CloudMonitoringExecutor) executeTimeSeriesQuery(ctx context.Context, ts
 
 	unit := e.resolvePanelUnitFromQueries(queryExecutors)
 
+	executorsByRefID := make(map[string]cloudMonitoringQueryExecutor, len(queryExecutors))
 	for _, queryExecutor := range queryExecutors {
+		executorsByRefID[queryExecutor.getRefID()] = queryExecutor
+	}
+
+	return tsdb.ExecuteQueries(ctx, e.dsInfo, tsdbQuery, func(ctx context.Context, query *tsdb.Query) (*tsdb.QueryResult, error) {
+		queryExecutor, ok := executorsByRefID[query.RefId]
+		if !ok {
+			return nil, nil
+		}
+
 		queryRes, resp, executedQueryString, err := queryExecutor.run(ctx, tsdbQuery, e)
 		if err != nil {
-			return nil, err
+			return queryRes, err
 		}
 		err = queryExecutor.parseResponse(queryRes, resp, executedQueryString)
 		if err != nil {
 			queryRes.Error = err
 		}
 
-		result.Results[queryExecutor.getRefID()] = queryRes
-
 		if len(unit) > 0 {
 			frames, _ := queryRes.Dataframes.Decoded()
 			for i := range frames {
@@ -160,10 +170,9 @@ func (e *CloudMonitoringExecutor) executeTimeSeriesQuery(ctx context.Context, ts
 			}
 			queryRes.Dataframes = tsdb.NewDecodedDataFrames(frames)
 		}
-		result.Results[queryExecutor.getRefID()] = queryRes
-	}
 
-	return result, nil
+		return queryRes, nil
+	}), nil
 }
 
 func (e *CloudMonitoringExecutor) resolvePanelUnitFromQueries(executors []cloudMonitoringQueryExecutor) string {
@@ -229,6 +238,7 @@ func (e *CloudMonitoringExecutor) buildQueryExecutors(tsdbQuery *tsdb.TsdbQuery)
 					IntervalMS:  query.IntervalMs,
 					AliasBy:     q.MetricQuery.AliasBy,
 					timeRange:   tsdbQuery.TimeRange,
+					Unit:        q.MetricQuery.Unit,
 				}
 			} else {
 				cmtsf.AliasBy = q.MetricQuery.AliasBy
@@ -248,6 +258,12 @@ func (e *CloudMonitoringExecutor) buildQueryExecutors(tsdbQuery *tsdb.TsdbQuery)
 			cmtsf.Selector = q.SloQuery.SelectorName
 			cmtsf.Service = q.SloQuery.ServiceId
 			cmtsf.Slo = q.SloQuery.SloId
+			if q.SloQuery.SelectorName == sloBurnRateSelector {
+				if q.SloQuery.LookbackPeriod == "" {
+					q.SloQuery.LookbackPeriod = defaultLookbackPeriod
+				}
+				cmtsf.Lookback = q.SloQuery.LookbackPeriod
+			}
 			params.Add("filter", buildSLOFilterExpression(q.SloQuery))
 			setSloAggParams(&params, &q.SloQuery, durationSeconds, query.IntervalMs)
 			queryInterface = cmtsf
@@ -337,8 +353,14 @@ func buildFilterString(metricType string, filterParts []string) string {
 	return strings.Trim(fmt.Sprintf(`metric.type="%s" %s`, metricType, filterString), " ")
 }
 
+// buildSLOFilterExpression builds the time series selector of an SLO query. The
+// burn rate selector also takes the lookback period the rate is computed over.
 func buildSLOFilterExpression(q sloQuery) string {
-	return fmt.Sprintf(`%s("projects/%s/services/%s/serviceLevelObjectives/%s")`, q.SelectorName, q.ProjectName, q.ServiceId, q.SloId)
+	slo := fmt.Sprintf("projects/%s/services/%s/serviceLevelObjectives/%s", q.ProjectName, q.ServiceId, q.SloId)
+	if q.SelectorName == sloBurnRateSelector {
+		return fmt.Sprintf(`%s("%s", "%s")`, q.SelectorName, slo, q.LookbackPeriod)
+	}
+	return fmt.Sprintf(`%s("%s")`, q.SelectorName, slo)
 }
 
 func setMetricAggParams(params *url.Values, query *metricQuery, durationSeconds int, intervalMs int64) {
@@ -361,7 +383,7 @@ func setMetricAggParams(params *url.Values, query *metricQuery, durationSeconds
 
 func setSloAggParams(params *url.Values, query *sloQuery, durationSeconds int, intervalMs int64) {
 	params.Add("aggregation.alignmentPeriod", calculateAlignmentPeriod(query.AlignmentPeriod, intervalMs, durationSeconds))
-	if query.SelectorName == "select_slo_health" {
+	if query.SelectorName == sloHealthSelector {
 		params.Add("aggregation.perSeriesAligner", "ALIGN_MEAN")
 	} else {
 		params.Add("aggregation.perSeriesAligner", "ALIGN_NEXT_OLDER")
@@ -446,6 +468,10 @@ func formatLegendKeys(metricType string, defaultMetricName string, labels map[st
 			return []byte(query.Selector)
 		}
 
+		if metaPartName == "lookback" && query.Lookback != "" {
+			return []byte(query.Lookback)
+		}
+
 		return in
 	})
 
@@ -11,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/tsdb"
 	. "github.com/smartystreets/goconvey/convey"
@@ -617,6 +618,62 @@ func TestCloudMonitoring(t *testing.T) {
 						So(dl, ShouldBeEmpty)
 					})
 				})
+
+				Convey("and selector is burn rate", func() {
+					sloModel := map[string]interface{}{
+						"projectName":     "test-proj",
+						"alignmentPeriod": "stackdriver-auto",
+						"selectorName":    "select_slo_burn_rate",
+						"serviceId":       "test-service",
+						"sloId":           "test-slo",
+					}
+					tsdbQuery.Queries[0].Model = simplejson.NewFromAny(map[string]interface{}{
+						"queryType":   sloQueryType,
+						"metricQuery": map[string]interface{}{},
+						"sloQuery":    sloModel,
+					})
+
+					qes, err := executor.buildQueryExecutors(tsdbQuery)
+					So(err, ShouldBeNil)
+					queries := getCloudMonitoringQueriesFromInterface(qes)
+					So(queries[0].Params["filter"][0], ShouldEqual, `select_slo_burn_rate("projects/test-proj/services/test-service/serviceLevelObjectives/test-slo", "3600s")`)
+					So(queries[0].Params["aggregation.perSeriesAligner"][0], ShouldEqual, "ALIGN_NEXT_OLDER")
+					So(queries[0].Lookback, ShouldEqual, "3600s")
+
+					Convey("and lookback period is set", func() {
+						sloModel["lookbackPeriod"] = "86400s"
+						tsdbQuery.Queries[0].Model = simplejson.NewFromAny(map[string]interface{}{
+							"queryType":   sloQueryType,
+							"metricQuery": map[string]interface{}{},
+							"sloQuery":    sloModel,
+						})
+
+						qes, err := executor.buildQueryExecutors(tsdbQuery)
+						So(err, ShouldBeNil)
+						queries := getCloudMonitoringQueriesFromInterface(qes)
+						So(queries[0].Params["filter"][0], ShouldEqual, `select_slo_burn_rate("projects/test-proj/services/test-service/serviceLevelObjectives/test-slo", "86400s")`)
+					})
+				})
+
+				Convey("and selector is error budget", func() {
+					tsdbQuery.Queries[0].Model = simplejson.NewFromAny(map[string]interface{}{
+						"queryType":   sloQueryType,
+						"metricQuery": map[string]interface{}{},
+						"sloQuery": map[string]interface{}{
+							"projectName":    "test-proj",
+							"selectorName":   "select_slo_budget_fraction",
+							"serviceId":      "test-service",
+							"sloId":          "test-slo",
+							"lookbackPeriod": "86400s",
+						},
+					})
+
+					qes, err := executor.buildQueryExecutors(tsdbQuery)
+					So(err, ShouldBeNil)
+					queries := getCloudMonitoringQueriesFromInterface(qes)
+					So(queries[0].Params["filter"][0], ShouldEqual, `select_slo_budget_fraction("projects/test-proj/services/test-service/serviceLevelObjectives/test-slo")`)
+					So(queries[0].Lookback, ShouldEqual, "")
+				})
 			})
 		})
 
@@ -1023,6 +1080,86 @@ func TestCloudMonitoring(t *testing.T) {
 					So(frames[0].Fields[1].Name, ShouldEqual, "test-proj - asia-northeast1-c - 6724404429462225363")
 				})
 			})
+
+			Convey("when data from query returns MQL with multiple values", func() {
+				response, err := loadTestFile("./test-data/8-series-response-mql-multiple-values.json")
+				So(err, ShouldBeNil)
+				So(len(response.TimeSeriesData), ShouldEqual, 1)
+
+				fromStart := time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC).In(time.Local)
+				query := &cloudMonitoringTimeSeriesQuery{
+					RefID:       "A",
+					ProjectName: "test-proj",
+					Query:       "test-query",
+					timeRange: &tsdb.TimeRange{
+						From: fmt.Sprintf("%v", fromStart.Unix()*1000),
+						To:   fmt.Sprintf("%v", fromStart.Add(34*time.Minute).Unix()*1000),
+					},
+				}
+
+				Convey("each value should be a series with the labels of the time series", func() {
+					res := &tsdb.QueryResult{Meta: simplejson.New(), RefId: "A"}
+					err = query.parseResponse(res, response, "")
+					So(err, ShouldBeNil)
+					frames, _ := res.Dataframes.Decoded()
+					So(len(frames), ShouldEqual, 2)
+
+					So(frames[0].Fields[1].Name, ShouldEqual, "value.request_count")
+					So(frames[0].Fields[1].Len(), ShouldEqual, 2)
+					So(frames[0].Fields[1].At(0), ShouldEqual, 8)
+					So(frames[0].Fields[1].At(1), ShouldEqual, 12)
+					So(frames[0].Fields[1].Labels, ShouldResemble, data.Labels{
+						"resource.label.zone":                 "europe-west1-b",
+						"metric.label.response_code":          "500",
+						"metadata.system_labels.machine_type": "e2-medium",
+						"metric.name":                         "value.request_count",
+					})
+
+					So(frames[1].Fields[1].Name, ShouldEqual, "value.error_ratio")
+					So(frames[1].Fields[1].At(0), ShouldEqual, 0.125)
+					So(frames[1].Fields[1].At(1), ShouldEqual, 0.25)
+					So(frames[1].Fields[1].Labels["metric.name"], ShouldEqual, "value.error_ratio")
+				})
+
+				Convey("and alias by is expanded for each value", func() {
+					res := &tsdb.QueryResult{Meta: simplejson.New(), RefId: "A"}
+					query.AliasBy = "{{metric.name}} {{metric.label.response_code}} {{metadata.system_labels.machine_type}}"
+					err = query.parseResponse(res, response, "")
+					So(err, ShouldBeNil)
+					frames, _ := res.Dataframes.Decoded()
+					So(frames[0].Fields[1].Name, ShouldEqual, "value.request_count 500 e2-medium")
+					So(frames[1].Fields[1].Name, ShouldEqual, "value.error_ratio 500 e2-medium")
+				})
+
+				Convey("and points without a value are skipped", func() {
+					point := &response.TimeSeriesData[0].PointData[0]
+					point.Values = point.Values[:1]
+
+					res := &tsdb.QueryResult{Meta: simplejson.New(), RefId: "A"}
+					err = query.parseResponse(res, response, "")
+					So(err, ShouldBeNil)
+					frames, _ := res.Dataframes.Decoded()
+					So(frames[0].Fields[1].Len(), ShouldEqual, 2)
+					So(frames[1].Rows(), ShouldEqual, 1)
+					So(frames[1].Fields[0].At(0), ShouldEqual, time.Date(2020, 5, 18, 9, 47, 0, 0, time.UTC))
+					So(frames[1].Fields[1].At(0), ShouldEqual, 0.125)
+				})
+			})
+
+			Convey("when building an MQL query", func() {
+				from := time.Date(2018, 3, 15, 13, 0, 0, 0, time.UTC)
+				to := from.Add(34 * time.Minute)
+
+				Convey("the graph period and time range should be added", func() {
+					query := buildMQLQuery("fetch gce_instance::compute.googleapis.com/instance/cpu/utilization", "1m", from, to)
+					So(query, ShouldEqual, "fetch gce_instance::compute.googleapis.com/instance/cpu/utilization | graph_period 1m | within d'2018/03/15-13:00:00', d'2018/03/15-13:34:00'")
+				})
+
+				Convey("the graph period and time range of the query should be kept", func() {
+					query := buildMQLQuery("fetch gce_instance::compute.googleapis.com/instance/cpu/utilization | graph_period 5m | within 1d", "1m", from, to)
+					So(query, ShouldEqual, "fetch gce_instance::compute.googleapis.com/instance/cpu/utilization | graph_period 5m | within 1d")
+				})
+			})
 		})
 
 		Convey("when interpolating filter wildcards", func() {
@@ -28,6 +28,19 @@ func (timeSeriesFilter *cloudMonitoringTimeSeriesFilter) run(ctx context.Context
 		}
 		projectName = defaultProject
 		slog.Info("No project name set on query, using project name from datasource", "projectName", projectName)
+
+		// the SLO selector names the project, so it has to be built again
+		if timeSeriesFilter.Slo != "" {
+			timeSeriesFilter.ProjectName = projectName
+			timeSeriesFilter.Params.Set("filter", buildSLOFilterExpression(sloQuery{
+				ProjectName:    projectName,
+				SelectorName:   timeSeriesFilter.Selector,
+				ServiceId:      timeSeriesFilter.Service,
+				SloId:          timeSeriesFilter.Slo,
+				LookbackPeriod: timeSeriesFilter.Lookback,
+			}))
+			timeSeriesFilter.Target = timeSeriesFilter.Params.Encode()
+		}
 	}
 
 	req, err := e.createRequest(ctx, e.dsInfo, path.Join("cloudmonitoringv3/projects", projectName, "timeSeries"), nil)
@@ -43,8 +43,7 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) run(ctx context.Context, t
 	}
 	intervalCalculator := tsdb.NewIntervalCalculator(&tsdb.IntervalOptions{})
 	interval := intervalCalculator.Calculate(tsdbQuery.TimeRange, time.Duration(timeSeriesQuery.IntervalMS/1000)*time.Second)
-	timeFormat := "2006/01/02-15:04:05"
-	timeSeriesQuery.Query += fmt.Sprintf(" | graph_period %s | within d'%s', d'%s'", interval.Text, from.UTC().Format(timeFormat), to.UTC().Format(timeFormat))
+	timeSeriesQuery.Query = buildMQLQuery(timeSeriesQuery.Query, interval.Text, from, to)
 
 	buf, err := json.Marshal(map[string]interface{}{
 		"query": timeSeriesQuery.Query,
@@ -92,20 +91,29 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) run(ctx context.Context, t
 	return queryResult, data, timeSeriesQuery.Query, nil
 }
 
+// buildMQLQuery sets the graph period and the time range of an MQL query, unless
+// the query sets them itself.
+func buildMQLQuery(query string, graphPeriod string, from time.Time, to time.Time) string {
+	timeFormat := "2006/01/02-15:04:05"
+	if !mqlGraphPeriodRe.MatchString(query) {
+		query += fmt.Sprintf(" | graph_period %s", graphPeriod)
+	}
+	if !mqlWithinRe.MatchString(query) {
+		query += fmt.Sprintf(" | within d'%s', d'%s'", from.UTC().Format(timeFormat), to.UTC().Format(timeFormat))
+	}
+	return query
+}
+
 func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseResponse(queryRes *tsdb.QueryResult, response cloudMonitoringResponse, executedQueryString string) error {
 	labels := make(map[string]map[string]bool)
 	frames := data.Frames{}
 	for _, series := range response.TimeSeriesData {
 		seriesLabels := make(map[string]string)
-		frame := data.NewFrameOfFieldTypes("", len(series.PointData), data.FieldTypeTime, data.FieldTypeFloat64)
-		frame.RefID = timeSeriesQuery.RefID
-		frame.Meta = &data.FrameMeta{
-			ExecutedQueryString: executedQueryString,
-		}
-
 		for n, d := range response.TimeSeriesDescriptor.LabelDescriptors {
-			key := toSnakeCase(d.Key)
-			key = strings.Replace(key, ".", ".label.", 1)
+			if n >= len(series.LabelValues) {
+				break
+			}
+			key := mqlLabelKey(d.Key)
 			if _, ok := labels[key]; !ok {
 				labels[key] = map[string]bool{}
 			}
@@ -117,9 +125,8 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseResponse(queryRes *ts
 				labels[key][strVal] = true
 				seriesLabels[key] = strVal
 			case "INT64":
-				intVal := strconv.FormatInt(labelValue.Int64Value, 10)
-				labels[key][intVal] = true
-				seriesLabels[key] = intVal
+				labels[key][labelValue.Int64Value] = true
+				seriesLabels[key] = labelValue.Int64Value
 			default:
 				labels[key][labelValue.StringValue] = true
 				seriesLabels[key] = labelValue.StringValue
@@ -131,14 +138,29 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseResponse(queryRes *ts
 				labels["metric.name"] = map[string]bool{}
 			}
 			labels["metric.name"][d.Key] = true
-			seriesLabels["metric.name"] = d.Key
 			defaultMetricName := d.Key
 
+			// every value column of the query is a series of its own
+			pointLabels := make(map[string]string, len(seriesLabels)+1)
+			for k, v := range seriesLabels {
+				pointLabels[k] = v
+			}
+			pointLabels["metric.name"] = d.Key
+
 			// process non-distribution series
 			if d.ValueType != "DISTRIBUTION" {
+				frame := data.NewFrameOfFieldTypes("", 0, data.FieldTypeTime, data.FieldTypeFloat64)
+				frame.RefID = timeSeriesQuery.RefID
+				frame.Meta = &data.FrameMeta{
+					ExecutedQueryString: executedQueryString,
+				}
+
 				// reverse the order to be ascending
 				for i := len(series.PointData) - 1; i >= 0; i-- {
 					point := series.PointData[i]
+					if n >= len(point.Values) {
+						continue
+					}
 					value := point.Values[n].DoubleValue
 
 					if d.ValueType == "INT64" {
@@ -154,13 +176,13 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseResponse(queryRes *ts
 						}
 					}
 
-					frame.SetRow(len(series.PointData)-1-i, series.PointData[i].TimeInterval.EndTime, value)
+					frame.AppendRow(point.TimeInterval.EndTime, value)
 				}
 
-				metricName := formatLegendKeys(d.Key, defaultMetricName, seriesLabels, nil, &cloudMonitoringTimeSeriesFilter{ProjectName: timeSeriesQuery.ProjectName, AliasBy: timeSeriesQuery.AliasBy})
+				metricName := formatLegendKeys(d.Key, defaultMetricName, pointLabels, nil, &cloudMonitoringTimeSeriesFilter{ProjectName: timeSeriesQuery.ProjectName, AliasBy: timeSeriesQuery.AliasBy})
 				dataField := frame.Fields[1]
 				dataField.Name = metricName
-				dataField.Labels = seriesLabels
+				dataField.Labels = pointLabels
 				setDisplayNameAsFieldName(dataField)
 
 				frames = append(frames, frame)
@@ -172,7 +194,7 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseResponse(queryRes *ts
 			// reverse the order to be ascending
 			for i := len(series.PointData) - 1; i >= 0; i-- {
 				point := series.PointData[i]
-				if len(point.Values[n].DistributionValue.BucketCounts) == 0 {
+				if n >= len(point.Values) || len(point.Values[n].DistributionValue.BucketCounts) == 0 {
 					continue
 				}
 				maxKey := 0
@@ -192,7 +214,7 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseResponse(queryRes *ts
 
 						frameName := formatLegendKeys(d.Key, defaultMetricName, nil, additionalLabels, &cloudMonitoringTimeSeriesFilter{ProjectName: timeSeriesQuery.ProjectName, AliasBy: timeSeriesQuery.AliasBy})
 						valueField.Name = frameName
-						valueField.Labels = seriesLabels
+						valueField.Labels = pointLabels
 						setDisplayNameAsFieldName(valueField)
 
 						buckets[i] = &data.Frame{
@@ -218,9 +240,9 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseResponse(queryRes *ts
 						additionalLabels := data.Labels{"bucket": bucketBound}
 						timeField := data.NewField(data.TimeSeriesTimeFieldName, nil, []time.Time{})
 						valueField := data.NewField(data.TimeSeriesValueFieldName, nil, []float64{})
-						frameName := formatLegendKeys(d.Key, defaultMetricName, seriesLabels, additionalLabels, &cloudMonitoringTimeSeriesFilter{ProjectName: timeSeriesQuery.ProjectName, AliasBy: timeSeriesQuery.AliasBy})
+						frameName := formatLegendKeys(d.Key, defaultMetricName, pointLabels, additionalLabels, &cloudMonitoringTimeSeriesFilter{ProjectName: timeSeriesQuery.ProjectName, AliasBy: timeSeriesQuery.AliasBy})
 						valueField.Name = frameName
-						valueField.Labels = seriesLabels
+						valueField.Labels = pointLabels
 						setDisplayNameAsFieldName(valueField)
 
 						buckets[i] = &data.Frame{
@@ -258,6 +280,18 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseResponse(queryRes *ts
 	return nil
 }
 
+// mqlLabelKey converts the key of an MQL label descriptor, like `resource.zone`, to
+// the key of the label in filter queries, like `resource.label.zone`.
+func mqlLabelKey(key string) string {
+	key = toSnakeCase(key)
+	for _, prefix := range []string{"metric.", "resource."} {
+		if strings.HasPrefix(key, prefix) {
+			return prefix + "label." + strings.TrimPrefix(key, prefix)
+		}
+	}
+	return key
+}
+
 func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseToAnnotations(queryRes *tsdb.QueryResult, data cloudMonitoringResponse, title string, text string, tags string) error {
 	annotations := make([]map[string]string, 0)
 
@@ -274,8 +308,7 @@ func (timeSeriesQuery cloudMonitoringTimeSeriesQuery) parseToAnnotations(queryRe
 				strVal := strconv.FormatBool(labelValue.BoolValue)
 				value = strVal
 			case "INT64":
-				intVal := strconv.FormatInt(labelValue.Int64Value, 10)
-				value = intVal
+				value = labelValue.Int64Value
 			default:
 				value = labelValue.StringValue
 			}
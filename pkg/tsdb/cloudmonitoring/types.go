@@ -29,6 +29,7 @@ type (
 		Selector    string
 		Service     string
 		Slo         string
+		Lookback    string
 		Unit        string
 	}
 
@@ -66,6 +67,7 @@ type (
 		SelectorName     string
 		ServiceId        string
 		SloId            string
+		LookbackPeriod   string
 	}
 
 	grafanaQuery struct {
@@ -115,7 +117,7 @@ type timeSeriesDescriptor struct {
 type timeSeriesData []struct {
 	LabelValues []struct {
 		BoolValue   bool   `json:"boolValue"`
-		Int64Value  int64  `json:"int64Value"`
+		Int64Value  string `json:"int64Value"`
 		StringValue string `json:"stringValue"`
 	} `json:"labelValues"`
 	PointData []struct {
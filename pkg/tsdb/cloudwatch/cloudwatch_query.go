@@ -19,13 +19,22 @@ type cloudWatchQuery struct {
 	MatchExact              bool
 	UsedExpression          string
 	RequestExceededMaxLimit bool
+	MetricQueryType         metricQueryType
+	SqlExpression           string
+}
+
+func (q *cloudWatchQuery) isMetricsInsightsQuery() bool {
+	return q.MetricQueryType == metricQueryTypeQuery
 }
 
 func (q *cloudWatchQuery) isMathExpression() bool {
-	return q.Expression != "" && !q.isUserDefinedSearchExpression()
+	return !q.isMetricsInsightsQuery() && q.Expression != "" && !q.isUserDefinedSearchExpression()
 }
 
 func (q *cloudWatchQuery) isSearchExpression() bool {
+	if q.isMetricsInsightsQuery() {
+		return false
+	}
 	return q.isUserDefinedSearchExpression() || q.isInferredSearchExpression()
 }
 
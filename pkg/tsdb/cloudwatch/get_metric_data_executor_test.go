@@ -2,12 +2,22 @@ package cloudwatch
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -46,3 +56,215 @@ func TestGetMetricDataExecutorTest(t *testing.T) {
 	assert.Equal(t, 23.5, *res[0].MetricDataResults[0].Values[1])
 	assert.Equal(t, 100.0, *res[1].MetricDataResults[0].Values[0])
 }
+
+// recordingCWClient records GetMetricData requests and replies with the
+// recorded response of each query ID, paginated by pageSize results.
+type recordingCWClient struct {
+	cloudwatchiface.CloudWatchAPI
+	pageSize  int
+	responses map[string][]*cloudwatch.MetricDataResult
+
+	mu       sync.Mutex
+	requests []*cloudwatch.GetMetricDataInput
+}
+
+func (c *recordingCWClient) GetMetricDataWithContext(ctx aws.Context, input *cloudwatch.GetMetricDataInput, opts ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if input.NextToken == nil {
+		c.requests = append(c.requests, input)
+	}
+
+	var results []*cloudwatch.MetricDataResult
+	for _, query := range input.MetricDataQueries {
+		if recorded, ok := c.responses[*query.Id]; ok {
+			results = append(results, recorded...)
+			continue
+		}
+		results = append(results, &cloudwatch.MetricDataResult{
+			Id:         query.Id,
+			Label:      query.Id,
+			StatusCode: aws.String("Complete"),
+			Timestamps: []*time.Time{aws.Time(time.Unix(0, 0))},
+			Values:     []*float64{aws.Float64(1)},
+		})
+	}
+
+	start := 0
+	if input.NextToken != nil {
+		start, _ = strconv.Atoi(*input.NextToken)
+	}
+	end := len(results)
+	output := &cloudwatch.GetMetricDataOutput{}
+	if c.pageSize > 0 && start+c.pageSize < end {
+		end = start + c.pageSize
+		output.NextToken = aws.String(strconv.Itoa(end))
+	}
+	output.MetricDataResults = results[start:end]
+	return output, nil
+}
+
+func TestGetMetricDataBatching(t *testing.T) {
+	origNewCWClient := NewCWClient
+	t.Cleanup(func() {
+		NewCWClient = origNewCWClient
+	})
+
+	var client *recordingCWClient
+	NewCWClient = func(sess *session.Session) cloudwatchiface.CloudWatchAPI {
+		return client
+	}
+
+	metricQuery := func(refID string, region string, statistics ...interface{}) *tsdb.Query {
+		return &tsdb.Query{
+			RefId: refID,
+			Model: simplejson.NewFromAny(map[string]interface{}{
+				"type":       "timeSeriesQuery",
+				"region":     region,
+				"namespace":  "AWS/EC2",
+				"metricName": "CPUUtilization",
+				"dimensions": map[string]interface{}{"InstanceId": []interface{}{"i-" + refID}},
+				"statistics": statistics,
+				"period":     "60",
+				"matchExact": true,
+			}),
+		}
+	}
+
+	t.Run("batches the queries of a region into GetMetricData requests of at most 500 queries", func(t *testing.T) {
+		client = &recordingCWClient{pageSize: 100}
+		var queries []*tsdb.Query
+		for i := 0; i < 600; i++ {
+			queries = append(queries, metricQuery(fmt.Sprintf("Q%d", i), "us-east-1", "Average"))
+		}
+		queries = append(queries, metricQuery("OTHER", "eu-west-1", "Average", "Maximum"))
+
+		executor := newExecutor(nil)
+		resp, err := executor.Query(context.Background(), fakeDataSource(), &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("now-1h", "now"),
+			Queries:   queries,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, client.requests, 3)
+		sizes := []int{}
+		for _, request := range client.requests {
+			sizes = append(sizes, len(request.MetricDataQueries))
+		}
+		sort.Ints(sizes)
+		assert.Equal(t, []int{2, 100, 500}, sizes)
+
+		require.Len(t, resp.Results, 601)
+		for refID, result := range resp.Results {
+			require.NoError(t, result.Error, refID)
+			frames, err := result.Dataframes.Decoded()
+			require.NoError(t, err)
+			require.NotEmpty(t, frames, refID)
+		}
+	})
+
+	t.Run("keeps math expressions with the queries they reference", func(t *testing.T) {
+		queries := map[string]*cloudWatchQuery{}
+		for i := 0; i < 499; i++ {
+			id := fmt.Sprintf("a%d", i)
+			queries[id] = &cloudWatchQuery{Id: id, RefId: id}
+		}
+		queries["b"] = &cloudWatchQuery{Id: "b", RefId: "B"}
+		queries["c"] = &cloudWatchQuery{Id: "c", RefId: "C"}
+		queries["expr"] = &cloudWatchQuery{Id: "expr", RefId: "E", Expression: "b + c"}
+
+		batches, err := batchQueries(queries)
+		require.NoError(t, err)
+		require.Len(t, batches, 2)
+
+		for _, batch := range batches {
+			if _, ok := batch["expr"]; ok {
+				assert.Contains(t, batch, "b")
+				assert.Contains(t, batch, "c")
+			}
+		}
+	})
+
+	t.Run("fails queries referencing more than 500 metrics", func(t *testing.T) {
+		queries := map[string]*cloudWatchQuery{}
+		expression := []string{}
+		for i := 0; i < 500; i++ {
+			id := fmt.Sprintf("m%d", i)
+			queries[id] = &cloudWatchQuery{Id: id, RefId: id}
+			expression = append(expression, id)
+		}
+		queries["sum"] = &cloudWatchQuery{Id: "sum", RefId: "S", Expression: strings.Join(expression, " + ")}
+
+		_, err := batchQueries(queries)
+		require.Error(t, err)
+	})
+
+	t.Run("parses Metrics Insights queries into labelled frames", func(t *testing.T) {
+		client = &recordingCWClient{
+			responses: map[string][]*cloudwatch.MetricDataResult{
+				"queryA": {
+					{
+						Id:         aws.String("queryA"),
+						Label:      aws.String("i-1 t3.small"),
+						StatusCode: aws.String("Complete"),
+						Timestamps: []*time.Time{aws.Time(time.Unix(0, 0)), aws.Time(time.Unix(60, 0))},
+						Values:     []*float64{aws.Float64(10), aws.Float64(20)},
+					},
+					{
+						Id:         aws.String("queryA"),
+						Label:      aws.String("i-2 t3.large"),
+						StatusCode: aws.String("Complete"),
+						Timestamps: []*time.Time{aws.Time(time.Unix(0, 0))},
+						Values:     []*float64{aws.Float64(30)},
+					},
+				},
+			},
+		}
+		sql := `SELECT AVG(CPUUtilization) FROM SCHEMA("AWS/EC2", InstanceId, InstanceType) ` +
+			`GROUP BY InstanceId, InstanceType ORDER BY AVG() DESC LIMIT 10`
+
+		executor := newExecutor(nil)
+		resp, err := executor.Query(context.Background(), fakeDataSource(), &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("now-1h", "now"),
+			Queries: []*tsdb.Query{
+				{
+					RefId: "A",
+					Model: simplejson.NewFromAny(map[string]interface{}{
+						"type":            "timeSeriesQuery",
+						"region":          "us-east-1",
+						"metricQueryType": 1,
+						"sqlExpression":   sql,
+						"period":          "60",
+						"alias":           "{{InstanceId}} ({{InstanceType}})",
+					}),
+				},
+			},
+		})
+		require.NoError(t, err)
+
+		require.Len(t, client.requests, 1)
+		mdq := client.requests[0].MetricDataQueries[0]
+		assert.Equal(t, sql, *mdq.Expression)
+		assert.Equal(t, int64(60), *mdq.Period)
+		assert.Nil(t, mdq.MetricStat)
+
+		require.NoError(t, resp.Results["A"].Error)
+		frames, err := resp.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		assert.Equal(t, "i-1 (t3.small)", frames[0].Name)
+		assert.Equal(t, data.Labels{"InstanceId": "i-1", "InstanceType": "t3.small"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, 2, frames[0].Rows())
+		assert.Equal(t, "i-2 (t3.large)", frames[1].Name)
+	})
+}
+
+func TestMetricsInsightsLabels(t *testing.T) {
+	assert.Equal(t, data.Labels{"InstanceId": "i-1"},
+		metricsInsightsLabels(`SELECT MAX(CPUUtilization) FROM "AWS/EC2" GROUP BY "InstanceId"`, "i-1"))
+	assert.Equal(t, data.Labels{"label": "a b c"},
+		metricsInsightsLabels(`SELECT MAX(CPUUtilization) FROM "AWS/EC2" GROUP BY InstanceId, InstanceType`, "a b c"))
+	assert.Equal(t, data.Labels{},
+		metricsInsightsLabels(`SELECT MAX(CPUUtilization) FROM "AWS/EC2"`, "CPUUtilization"))
+}
@@ -1,12 +1,18 @@
 package cloudwatch
 
 import (
+	"fmt"
+	"regexp"
+	"sort"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 )
 
+// maxMetricDataQueries is the maximum number of queries of a GetMetricData request.
+const maxMetricDataQueries = 500
+
 func (e *cloudWatchExecutor) buildMetricDataInput(startTime time.Time, endTime time.Time,
 	queries map[string]*cloudWatchQuery) (*cloudwatch.GetMetricDataInput, error) {
 	metricDataInput := &cloudwatch.GetMetricDataInput{
@@ -24,3 +30,124 @@ func (e *cloudWatchExecutor) buildMetricDataInput(startTime time.Time, endTime t
 
 	return metricDataInput, nil
 }
+
+// buildMetricDataInputs builds the GetMetricData requests of the queries of a
+// region, batching them into as few requests as the limit of queries per
+// request allows.
+func (e *cloudWatchExecutor) buildMetricDataInputs(startTime time.Time, endTime time.Time,
+	queries map[string]*cloudWatchQuery) ([]*cloudwatch.GetMetricDataInput, error) {
+	batches, err := batchQueries(queries)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]*cloudwatch.GetMetricDataInput, 0, len(batches))
+	for _, batch := range batches {
+		input, err := e.buildMetricDataInput(startTime, endTime, batch)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+var expressionIdentifier = regexp.MustCompile(`[a-zA-Z_][a-zA-Z0-9_]*`)
+
+// batchQueries splits queries into batches of at most maxMetricDataQueries
+// queries. The queries of a query row, and the queries referenced by math
+// expressions, are kept in the same batch.
+func batchQueries(queries map[string]*cloudWatchQuery) ([]map[string]*cloudWatchQuery, error) {
+	ids := make([]string, 0, len(queries))
+	for id := range queries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	// groups links the ids of queries that must be in the same batch.
+	parents := make(map[string]string, len(ids))
+	var find func(id string) string
+	find = func(id string) string {
+		if parent, ok := parents[id]; ok && parent != id {
+			root := find(parent)
+			parents[id] = root
+			return root
+		}
+		return id
+	}
+	union := func(a, b string) {
+		if rootA, rootB := find(a), find(b); rootA != rootB {
+			parents[rootB] = rootA
+		}
+	}
+
+	firstIDByRefID := map[string]string{}
+	for _, id := range ids {
+		query := queries[id]
+		if first, ok := firstIDByRefID[query.RefId]; ok {
+			union(first, id)
+		} else {
+			firstIDByRefID[query.RefId] = id
+		}
+
+		if query.isMathExpression() {
+			for _, identifier := range expressionIdentifier.FindAllString(query.Expression, -1) {
+				if _, ok := queries[identifier]; ok {
+					union(id, identifier)
+				}
+			}
+		}
+	}
+
+	groupsByRoot := map[string][]string{}
+	roots := []string{}
+	for _, id := range ids {
+		root := find(id)
+		if _, ok := groupsByRoot[root]; !ok {
+			roots = append(roots, root)
+		}
+		groupsByRoot[root] = append(groupsByRoot[root], id)
+	}
+
+	groups := make([][]string, 0, len(roots))
+	for _, root := range roots {
+		group := groupsByRoot[root]
+		if len(group) > maxMetricDataQueries {
+			return nil, &queryError{
+				err:   fmt.Errorf("query uses more than %d metrics", maxMetricDataQueries),
+				RefID: queries[group[0]].RefId,
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	// First fit decreasing, which uses close to the minimum number of batches.
+	// Ties are broken by period, so that queries with the same period tend to
+	// share requests.
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i]) != len(groups[j]) {
+			return len(groups[i]) > len(groups[j])
+		}
+		return queries[groups[i][0]].Period < queries[groups[j][0]].Period
+	})
+
+	var batches []map[string]*cloudWatchQuery
+	for _, group := range groups {
+		var batch map[string]*cloudWatchQuery
+		for _, b := range batches {
+			if len(b)+len(group) <= maxMetricDataQueries {
+				batch = b
+				break
+			}
+		}
+		if batch == nil {
+			batch = make(map[string]*cloudWatchQuery)
+			batches = append(batches, batch)
+		}
+		for _, id := range group {
+			batch[id] = queries[id]
+		}
+	}
+
+	return batches, nil
+}
@@ -16,9 +16,13 @@ func (e *cloudWatchExecutor) buildMetricDataQuery(query *cloudWatchQuery) (*clou
 		ReturnData: aws.Bool(query.ReturnData),
 	}
 
-	if query.Expression != "" {
+	switch {
+	case query.isMetricsInsightsQuery():
+		mdq.Expression = aws.String(query.SqlExpression)
+		mdq.Period = aws.Int64(int64(query.Period))
+	case query.Expression != "":
 		mdq.Expression = aws.String(query.Expression)
-	} else {
+	default:
 		if query.isSearchExpression() {
 			mdq.Expression = aws.String(buildSearchExpression(query, query.Stats))
 		} else {
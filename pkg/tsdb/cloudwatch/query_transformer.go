@@ -21,6 +21,28 @@ func (e *cloudWatchExecutor) transformRequestQueriesToCloudWatchQueries(requestQ
 	plog.Debug("Transforming CloudWatch request queries")
 	cloudwatchQueries := make(map[string]*cloudWatchQuery)
 	for _, requestQuery := range requestQueries {
+		if requestQuery.MetricQueryType == metricQueryTypeQuery {
+			// Metrics Insights queries select their statistic in SQL.
+			id := requestQuery.Id
+			if id == "" {
+				id = fmt.Sprintf("query%s", requestQuery.RefId)
+			}
+			if _, ok := cloudwatchQueries[id]; ok {
+				return nil, fmt.Errorf("error in query %q - query ID %q is not unique", requestQuery.RefId, id)
+			}
+			cloudwatchQueries[id] = &cloudWatchQuery{
+				Id:              id,
+				RefId:           requestQuery.RefId,
+				Region:          requestQuery.Region,
+				Period:          requestQuery.Period,
+				Alias:           requestQuery.Alias,
+				ReturnData:      requestQuery.ReturnData,
+				MetricQueryType: requestQuery.MetricQueryType,
+				SqlExpression:   requestQuery.SqlExpression,
+			}
+			continue
+		}
+
 		for _, stat := range requestQuery.Statistics {
 			id := requestQuery.Id
 			if id == "" {
@@ -45,13 +45,23 @@ func parseRequestQuery(model *simplejson.Json, refId string, startTime time.Time
 	if err != nil {
 		return nil, err
 	}
-	namespace, err := model.Get("namespace").String()
-	if err != nil {
-		return nil, err
+
+	queryMode := metricQueryType(model.Get("metricQueryType").MustInt(int(metricQueryTypeSearch)))
+	sqlExpression := model.Get("sqlExpression").MustString("")
+	if queryMode == metricQueryTypeQuery && strings.TrimSpace(sqlExpression) == "" {
+		return nil, errors.New("sql expression is required for Metrics Insights queries")
 	}
-	metricName, err := model.Get("metricName").String()
-	if err != nil {
-		return nil, err
+
+	// Metrics Insights queries select the namespace and metric in SQL.
+	namespace := model.Get("namespace").MustString("")
+	metricName := model.Get("metricName").MustString("")
+	if queryMode == metricQueryTypeSearch {
+		if namespace, err = model.Get("namespace").String(); err != nil {
+			return nil, err
+		}
+		if metricName, err = model.Get("metricName").String(); err != nil {
+			return nil, err
+		}
 	}
 	dimensions, err := parseDimensions(model)
 	if err != nil {
@@ -105,18 +115,20 @@ func parseRequestQuery(model *simplejson.Json, refId string, startTime time.Time
 	matchExact := model.Get("matchExact").MustBool(true)
 
 	return &requestQuery{
-		RefId:      refId,
-		Region:     region,
-		Namespace:  namespace,
-		MetricName: metricName,
-		Dimensions: dimensions,
-		Statistics: aws.StringSlice(statistics),
-		Period:     period,
-		Alias:      alias,
-		Id:         id,
-		Expression: expression,
-		ReturnData: returnData,
-		MatchExact: matchExact,
+		RefId:           refId,
+		Region:          region,
+		Namespace:       namespace,
+		MetricName:      metricName,
+		Dimensions:      dimensions,
+		Statistics:      aws.StringSlice(statistics),
+		Period:          period,
+		Alias:           alias,
+		Id:              id,
+		Expression:      expression,
+		ReturnData:      returnData,
+		MatchExact:      matchExact,
+		MetricQueryType: queryMode,
+		SqlExpression:   sqlExpression,
 	}, nil
 }
 
@@ -209,4 +209,22 @@ func TestRequestParser(t *testing.T) {
 			assert.Equal(t, 86400, res.Period)
 		})
 	})
+	t.Run("Metrics Insights queries don't require a namespace and metric name", func(t *testing.T) {
+		query := simplejson.NewFromAny(map[string]interface{}{
+			"region":          "us-east-1",
+			"metricQueryType": 1,
+			"sqlExpression":   `SELECT AVG(CPUUtilization) FROM "AWS/EC2"`,
+			"period":          "300",
+		})
+
+		res, err := parseRequestQuery(query, "ref1", from, to)
+		require.NoError(t, err)
+		assert.Equal(t, metricQueryTypeQuery, res.MetricQueryType)
+		assert.Equal(t, `SELECT AVG(CPUUtilization) FROM "AWS/EC2"`, res.SqlExpression)
+		assert.Equal(t, 300, res.Period)
+
+		query.Set("sqlExpression", "")
+		_, err = parseRequestQuery(query, "ref1", from, to)
+		require.Error(t, err)
+	})
 }
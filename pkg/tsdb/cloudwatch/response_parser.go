@@ -2,6 +2,7 @@ package cloudwatch
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -121,6 +122,26 @@ func parseMetricResults(results map[string]*cloudwatch.MetricDataResult, labels
 				}
 				frames = append(frames, &emptyFrame)
 			}
+		} else if query.isMetricsInsightsQuery() {
+			timestamps := make([]*time.Time, 0, len(result.Timestamps))
+			points := make([]*float64, 0, len(result.Values))
+			for j, t := range result.Timestamps {
+				timestamps = append(timestamps, t)
+				points = append(points, result.Values[j])
+			}
+
+			tags := metricsInsightsLabels(query.SqlExpression, label)
+			timeField := data.NewField(data.TimeSeriesTimeFieldName, nil, timestamps)
+			valueField := data.NewField(data.TimeSeriesValueFieldName, tags, points)
+
+			frameName := formatAlias(query, query.Stats, tags, label)
+			valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: frameName})
+
+			frames = append(frames, &data.Frame{
+				Name:   frameName,
+				Fields: []*data.Field{timeField, valueField},
+				RefID:  query.RefId,
+			})
 		} else {
 			dims := make([]string, 0, len(query.Dimensions))
 			for k := range query.Dimensions {
@@ -193,6 +214,9 @@ func formatAlias(query *cloudWatchQuery, stat string, dimensions map[string]stri
 		stat = strings.Trim(query.Expression[sIndex+1:pIndex], " '")
 	}
 
+	if len(query.Alias) == 0 && query.isMetricsInsightsQuery() {
+		return label
+	}
 	if len(query.Alias) == 0 && query.isMathExpression() {
 		return query.Id
 	}
@@ -231,3 +255,34 @@ func formatAlias(query *cloudWatchQuery, stat string, dimensions map[string]stri
 
 	return string(result)
 }
+
+var groupByClause = regexp.MustCompile(`(?is)\bGROUP\s+BY\s+(.+?)(?:\s+ORDER\s+BY\b|\s+LIMIT\b|$)`)
+
+// metricsInsightsLabels returns the labels of a series of a Metrics Insights
+// query. The label of a series is the values of the GROUP BY keys of the query
+// separated by spaces.
+func metricsInsightsLabels(sqlExpression string, label string) data.Labels {
+	match := groupByClause.FindStringSubmatch(sqlExpression)
+	if match == nil {
+		return data.Labels{}
+	}
+
+	keys := strings.Split(match[1], ",")
+	for i, key := range keys {
+		keys[i] = strings.Trim(strings.TrimSpace(key), `"`)
+	}
+
+	if len(keys) == 1 {
+		return data.Labels{keys[0]: label}
+	}
+
+	values := strings.Split(label, " ")
+	if len(values) != len(keys) {
+		return data.Labels{"label": label}
+	}
+	labels := data.Labels{}
+	for i, key := range keys {
+		labels[key] = values[i]
+	}
+	return labels
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -68,21 +69,37 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, queryCo
 				return nil
 			}
 
-			metricDataInput, err := e.buildMetricDataInput(startTime, endTime, queries)
+			metricDataInputs, err := e.buildMetricDataInputs(startTime, endTime, queries)
 			if err != nil {
 				return err
 			}
 
 			cloudwatchResponses := make([]*cloudwatchResponse, 0)
-			mdo, err := e.executeRequest(ectx, client, metricDataInput)
-			if err != nil {
+			mdo := make([]*cloudwatch.GetMetricDataOutput, 0)
+			failedRefIDs := map[string]bool{}
+			for _, metricDataInput := range metricDataInputs {
+				batchOutputs, err := e.executeRequest(ectx, client, metricDataInput)
+				if err != nil {
+					// The queries of the other batches still get results.
+					for _, refID := range batchRefIDs(metricDataInput, queries) {
+						failedRefIDs[refID] = true
+						resultChan <- &tsdb.QueryResult{
+							RefId: refID,
+							Error: err,
+						}
+					}
+					continue
+				}
+				mdo = append(mdo, batchOutputs...)
+			}
+			if len(failedRefIDs) > 0 {
+				succeeded := make([]*requestQuery, 0, len(requestQueries))
 				for _, query := range requestQueries {
-					resultChan <- &tsdb.QueryResult{
-						RefId: query.RefId,
-						Error: err,
+					if !failedRefIDs[query.RefId] {
+						succeeded = append(succeeded, query)
 					}
 				}
-				return nil
+				requestQueries = succeeded
 			}
 
 			responses, err := e.parseResponse(mdo, queries)
@@ -127,3 +144,17 @@ func (e *cloudWatchExecutor) executeTimeSeriesQuery(ctx context.Context, queryCo
 	}
 	return results, nil
 }
+
+// batchRefIDs returns the ref IDs of the queries of a GetMetricData request.
+func batchRefIDs(metricDataInput *cloudwatch.GetMetricDataInput, queries map[string]*cloudWatchQuery) []string {
+	refIDs := []string{}
+	seen := map[string]bool{}
+	for _, mdq := range metricDataInput.MetricDataQueries {
+		refID := queries[*mdq.Id].RefId
+		if !seen[refID] {
+			seen[refID] = true
+			refIDs = append(refIDs, refID)
+		}
+	}
+	return refIDs
+}
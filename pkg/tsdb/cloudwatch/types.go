@@ -21,8 +21,21 @@ type requestQuery struct {
 	Period             int
 	Alias              string
 	MatchExact         bool
+	MetricQueryType    metricQueryType
+	SqlExpression      string
 }
 
+// metricQueryType is the mode of a metric query in the query editor.
+type metricQueryType int
+
+const (
+	// metricQueryTypeSearch queries metrics by namespace, metric name and
+	// dimensions, or by math expressions.
+	metricQueryTypeSearch metricQueryType = iota
+	// metricQueryTypeQuery queries metrics with the Metrics Insights SQL syntax.
+	metricQueryTypeQuery
+)
+
 type cloudwatchResponse struct {
 	DataFrames              data.Frames
 	Id                      string
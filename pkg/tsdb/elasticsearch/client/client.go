@@ -31,10 +31,19 @@ var newDatasourceHttpClient = func(ds *models.DataSource) (*http.Client, error)
 	return ds.GetHttpClient()
 }
 
+// ConfiguredFields holds the fields of documents that are configured on the
+// data source.
+type ConfiguredFields struct {
+	TimeField       string
+	LogMessageField string
+	LogLevelField   string
+}
+
 // Client represents a client which can interact with elasticsearch api
 type Client interface {
 	GetVersion() int
 	GetTimeField() string
+	GetConfiguredFields() ConfiguredFields
 	GetMinInterval(queryInterval string) (time.Duration, error)
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
@@ -99,6 +108,14 @@ func (c *baseClientImpl) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *baseClientImpl) GetConfiguredFields() ConfiguredFields {
+	return ConfiguredFields{
+		TimeField:       c.timeField,
+		LogMessageField: c.ds.JsonData.Get("logMessageField").MustString(),
+		LogLevelField:   c.ds.JsonData.Get("logLevelField").MustString(),
+	}
+}
+
 func (c *baseClientImpl) GetMinInterval(queryInterval string) (time.Duration, error) {
 	return tsdb.GetIntervalFrom(c.ds, simplejson.NewFromAny(map[string]interface{}{
 		"interval": queryInterval,
@@ -39,6 +39,9 @@ type SearchRequest struct {
 	Query       *Query
 	Aggs        AggArray
 	CustomProps map[string]interface{}
+
+	// sortFields holds the fields of Sort in the order they were added.
+	sortFields []string
 }
 
 // MarshalJSON returns the JSON encoding of the request.
@@ -46,7 +49,15 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	root := make(map[string]interface{})
 
 	root["size"] = r.Size
-	if len(r.Sort) > 0 {
+	if len(r.sortFields) > 1 {
+		// The order of the keys of a JSON object is not preserved, so
+		// multiple sorts are sent as an array.
+		sort := make([]map[string]interface{}, 0, len(r.sortFields))
+		for _, field := range r.sortFields {
+			sort = append(sort, map[string]interface{}{field: r.Sort[field]})
+		}
+		root["sort"] = sort
+	} else if len(r.Sort) > 0 {
 		root["sort"] = r.Sort
 	}
 
@@ -145,6 +156,19 @@ type RangeFilter struct {
 // DateFormatEpochMS represents a date format of epoch milliseconds (epoch_millis)
 const DateFormatEpochMS = "epoch_millis"
 
+// Sort orders
+const (
+	SortOrderAsc  = "asc"
+	SortOrderDesc = "desc"
+)
+
+// HighlightPreTag and HighlightPostTag surround highlighted terms. They are
+// the same as in the frontend, which turns them into search words.
+const (
+	HighlightPreTag  = "@HIGHLIGHT@"
+	HighlightPostTag = "@/HIGHLIGHT@"
+)
+
 // MarshalJSON returns the JSON encoding of the query string filter.
 func (f *RangeFilter) MarshalJSON() ([]byte, error) {
 	root := map[string]map[string]map[string]interface{}{
@@ -273,6 +297,13 @@ type GeoHashGridAggregation struct {
 	Precision int    `json:"precision"`
 }
 
+// CompositeAggregation represents a composite aggregation
+type CompositeAggregation struct {
+	Size    int                      `json:"size"`
+	Sources []map[string]interface{} `json:"sources"`
+	After   map[string]interface{}   `json:"after,omitempty"`
+}
+
 // MetricAggregation represents a metric aggregation
 type MetricAggregation struct {
 	Field    string
@@ -281,8 +312,10 @@ type MetricAggregation struct {
 
 // MarshalJSON returns the JSON encoding of the metric aggregation
 func (a *MetricAggregation) MarshalJSON() ([]byte, error) {
-	root := map[string]interface{}{
-		"field": a.Field,
+	root := map[string]interface{}{}
+	// Some metrics, like top_metrics, are not computed from a single field.
+	if a.Field != "" {
+		root["field"] = a.Field
 	}
 
 	for k, v := range a.Settings {
@@ -1,6 +1,7 @@
 package es
 
 import (
+	"math"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -13,6 +14,7 @@ type SearchRequestBuilder struct {
 	index        string
 	size         int
 	sort         map[string]interface{}
+	sortFields   []string
 	queryBuilder *QueryBuilder
 	aggBuilders  []AggBuilder
 	customProps  map[string]interface{}
@@ -38,6 +40,7 @@ func (b *SearchRequestBuilder) Build() (*SearchRequest, error) {
 		Size:        b.size,
 		Sort:        b.sort,
 		CustomProps: b.customProps,
+		sortFields:  b.sortFields,
 	}
 
 	if b.queryBuilder != nil {
@@ -71,19 +74,49 @@ func (b *SearchRequestBuilder) Size(size int) *SearchRequestBuilder {
 
 // SortDesc adds a sort to the search request
 func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchRequestBuilder {
+	return b.Sort(SortOrderDesc, field, unmappedType)
+}
+
+// Sort adds a sort to the search request. Sorts are applied in the order
+// they are added.
+func (b *SearchRequestBuilder) Sort(order, field, unmappedType string) *SearchRequestBuilder {
 	props := map[string]string{
-		"order": "desc",
+		"order": order,
 	}
 
 	if unmappedType != "" {
 		props["unmapped_type"] = unmappedType
 	}
 
+	if _, exists := b.sort[field]; !exists {
+		b.sortFields = append(b.sortFields, field)
+	}
 	b.sort[field] = props
 
 	return b
 }
 
+// AddSearchAfter makes the search request return the documents that follow
+// the document with the given sort values.
+func (b *SearchRequestBuilder) AddSearchAfter(values []interface{}) *SearchRequestBuilder {
+	b.customProps["search_after"] = values
+	return b
+}
+
+// AddHighlight adds highlighting of the matched terms in all fields, using
+// HighlightPreTag and HighlightPostTag.
+func (b *SearchRequestBuilder) AddHighlight() *SearchRequestBuilder {
+	b.customProps["highlight"] = map[string]interface{}{
+		"fields": map[string]interface{}{
+			"*": map[string]interface{}{},
+		},
+		"pre_tags":      []string{HighlightPreTag},
+		"post_tags":     []string{HighlightPostTag},
+		"fragment_size": math.MaxInt32,
+	}
+	return b
+}
+
 // AddDocValueField adds a doc value field to the search request
 func (b *SearchRequestBuilder) AddDocValueField(field string) *SearchRequestBuilder {
 	// fields field not supported on version >= 5
@@ -267,6 +300,7 @@ type AggBuilder interface {
 	Terms(key, field string, fn func(a *TermsAggregation, b AggBuilder)) AggBuilder
 	Filters(key string, fn func(a *FiltersAggregation, b AggBuilder)) AggBuilder
 	GeoHashGrid(key, field string, fn func(a *GeoHashGridAggregation, b AggBuilder)) AggBuilder
+	Composite(key string, fn func(a *CompositeAggregation, b AggBuilder)) AggBuilder
 	Metric(key, metricType, field string, fn func(a *MetricAggregation)) AggBuilder
 	Pipeline(key, pipelineType string, bucketPath interface{}, fn func(a *PipelineAggregation)) AggBuilder
 	Build() (AggArray, error)
@@ -419,6 +453,26 @@ func (b *aggBuilderImpl) GeoHashGrid(key, field string, fn func(a *GeoHashGridAg
 	return b
 }
 
+func (b *aggBuilderImpl) Composite(key string, fn func(a *CompositeAggregation, b AggBuilder)) AggBuilder {
+	innerAgg := &CompositeAggregation{
+		Sources: make([]map[string]interface{}, 0),
+	}
+	aggDef := newAggDef(key, &aggContainer{
+		Type:        "composite",
+		Aggregation: innerAgg,
+	})
+
+	if fn != nil {
+		builder := newAggBuilder(b.version)
+		aggDef.builders = append(aggDef.builders, builder)
+		fn(innerAgg, builder)
+	}
+
+	b.aggDefs = append(b.aggDefs, aggDef)
+
+	return b
+}
+
 func (b *aggBuilderImpl) Metric(key, metricType, field string, fn func(a *MetricAggregation)) AggBuilder {
 	innerAgg := &MetricAggregation{
 		Field:    field,
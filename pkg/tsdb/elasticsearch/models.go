@@ -15,6 +15,17 @@ type Query struct {
 	RefID      string
 }
 
+// isDocumentQuery reports whether the query returns documents instead of
+// only aggregations.
+func (q *Query) isDocumentQuery() bool {
+	return len(q.Metrics) > 0 && isDocumentMetric(q.Metrics[0].Type)
+}
+
+// isLogsQuery reports whether the query returns documents as logs.
+func (q *Query) isLogsQuery() bool {
+	return len(q.Metrics) > 0 && q.Metrics[0].Type == logsType
+}
+
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
 type BucketAgg struct {
 	Field    string           `json:"field"`
@@ -51,6 +62,10 @@ var metricAggType = map[string]string{
 	"serial_diff":    "Serial Difference",
 	"bucket_script":  "Bucket Script",
 	"raw_document":   "Raw Document",
+	"raw_data":       "Raw Data",
+	"logs":           "Logs",
+	"top_metrics":    "Top Metrics",
+	"rate":           "Rate",
 }
 
 var extendedStats = map[string]string{
@@ -77,6 +92,19 @@ var pipelineAggWithMultipleBucketPathsType = map[string]string{
 	"bucket_script": "bucket_script",
 }
 
+var documentMetricType = map[string]string{
+	"raw_document": "raw_document",
+	"raw_data":     "raw_data",
+	"logs":         "logs",
+}
+
+func isDocumentMetric(metricType string) bool {
+	if _, ok := documentMetricType[metricType]; ok {
+		return true
+	}
+	return false
+}
+
 func isPipelineAgg(metricType string) bool {
 	if _, ok := pipelineAggType[metricType]; ok {
 		return true
@@ -1,12 +1,16 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -18,25 +22,31 @@ const (
 	countType         = "count"
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
+	logsType          = "logs"
+	topMetricsType    = "top_metrics"
+	movingFnType      = "moving_fn"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
 	filtersType     = "filters"
 	termsType       = "terms"
 	geohashGridType = "geohash_grid"
+	compositeType   = "composite"
 )
 
 type responseParser struct {
-	Responses []*es.SearchResponse
-	Targets   []*Query
-	DebugInfo *es.SearchDebugInfo
+	Responses        []*es.SearchResponse
+	Targets          []*Query
+	DebugInfo        *es.SearchDebugInfo
+	ConfiguredFields es.ConfiguredFields
 }
 
-var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo) *responseParser {
+var newResponseParser = func(responses []*es.SearchResponse, targets []*Query, debugInfo *es.SearchDebugInfo, configuredFields es.ConfiguredFields) *responseParser {
 	return &responseParser{
-		Responses: responses,
-		Targets:   targets,
-		DebugInfo: debugInfo,
+		Responses:        responses,
+		Targets:          targets,
+		DebugInfo:        debugInfo,
+		ConfiguredFields: configuredFields,
 	}
 }
 
@@ -64,6 +74,14 @@ func (rp *responseParser) getTimeSeries() (*tsdb.Response, error) {
 
 		queryRes := tsdb.NewQueryResult()
 		queryRes.Meta = debugInfo
+		if target.isDocumentQuery() {
+			frame, err := rp.processDocuments(res, target)
+			if err != nil {
+				return nil, err
+			}
+			queryRes.Dataframes = tsdb.NewDecodedDataFrames(data.Frames{frame})
+		}
+
 		props := make(map[string]string)
 		table := tsdb.Table{
 			Columns: make([]tsdb.TableColumn, 0),
@@ -73,6 +91,12 @@ func (rp *responseParser) getTimeSeries() (*tsdb.Response, error) {
 		if err != nil {
 			return nil, err
 		}
+		if afterKey := compositeAfterKey(res, target); afterKey != nil {
+			if queryRes.Meta == nil {
+				queryRes.Meta = simplejson.New()
+			}
+			queryRes.Meta.Set("afterKey", afterKey)
+		}
 		rp.nameSeries(&queryRes.Series, target)
 		rp.trimDatapoints(&queryRes.Series, target)
 
@@ -120,7 +144,11 @@ func (rp *responseParser) processBuckets(aggs map[string]interface{}, target *Qu
 					newProps[k] = v
 				}
 
-				if key, err := bucket.Get("key").String(); err == nil {
+				if key, err := bucket.Get("key").Map(); err == nil {
+					for name, value := range key {
+						newProps[name] = formatBucketKey(value)
+					}
+				} else if key, err := bucket.Get("key").String(); err == nil {
 					newProps[aggDef.Field] = key
 				} else if key, err := bucket.Get("key").Int64(); err == nil {
 					newProps[aggDef.Field] = strconv.FormatInt(key, 10)
@@ -196,12 +224,7 @@ func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query,
 			firstBucket := simplejson.NewFromAny(buckets[0])
 			percentiles := firstBucket.GetPath(metric.ID, "values").MustMap()
 
-			percentileKeys := make([]string, 0)
-			for k := range percentiles {
-				percentileKeys = append(percentileKeys, k)
-			}
-			sort.Strings(percentileKeys)
-			for _, percentileName := range percentileKeys {
+			for _, percentileName := range sortedPercentiles(percentiles) {
 				newSeries := tsdb.TimeSeries{
 					Tags: make(map[string]string),
 				}
@@ -258,6 +281,27 @@ func (rp *responseParser) processMetrics(esAgg *simplejson.Json, target *Query,
 				}
 				*series = append(*series, &newSeries)
 			}
+		case topMetricsType:
+			buckets := esAgg.Get("buckets").MustArray()
+
+			for _, field := range metric.Settings.Get("metrics").MustStringArray() {
+				newSeries := tsdb.TimeSeries{
+					Tags: make(map[string]string),
+				}
+				for k, v := range props {
+					newSeries.Tags[k] = v
+				}
+				newSeries.Tags["metric"] = topMetricsType
+				newSeries.Tags["field"] = field
+
+				for _, v := range buckets {
+					bucket := simplejson.NewFromAny(v)
+					key := castToNullFloat(bucket.Get("key"))
+					value := castToNullFloat(bucket.GetPath(metric.ID, "top").GetIndex(0).GetPath("metrics", field))
+					newSeries.Points = append(newSeries.Points, tsdb.TimePoint{value, key})
+				}
+				*series = append(*series, &newSeries)
+			}
 		default:
 			newSeries := tsdb.TimeSeries{
 				Tags: make(map[string]string),
@@ -297,11 +341,25 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 	}
 	sort.Strings(propKeys)
 
+	// The buckets of composite aggregations are keyed by several sources.
+	var sourceNames []string
+	if aggDef.Type == compositeType {
+		for _, source := range compositeSources(aggDef) {
+			sourceNames = append(sourceNames, source.Name)
+		}
+	}
+
 	if len(table.Columns) == 0 {
 		for _, propKey := range propKeys {
 			table.Columns = append(table.Columns, tsdb.TableColumn{Text: propKey})
 		}
-		table.Columns = append(table.Columns, tsdb.TableColumn{Text: aggDef.Field})
+		if sourceNames != nil {
+			for _, name := range sourceNames {
+				table.Columns = append(table.Columns, tsdb.TableColumn{Text: name})
+			}
+		} else {
+			table.Columns = append(table.Columns, tsdb.TableColumn{Text: aggDef.Field})
+		}
 	}
 
 	addMetricValue := func(values *tsdb.RowValues, metricName string, value null.Float) {
@@ -326,7 +384,15 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 			values = append(values, props[propKey])
 		}
 
-		if key, err := bucket.Get("key").String(); err == nil {
+		if sourceNames != nil {
+			for _, name := range sourceNames {
+				if key, err := bucket.GetPath("key", name).String(); err == nil {
+					values = append(values, key)
+				} else {
+					values = append(values, castToNullFloat(bucket.GetPath("key", name)))
+				}
+			}
+		} else if key, err := bucket.Get("key").String(); err == nil {
 			values = append(values, key)
 		} else {
 			values = append(values, castToNullFloat(bucket.Get("key")))
@@ -359,8 +425,17 @@ func (rp *responseParser) processAggregationDocs(esAgg *simplejson.Json, aggDef
 						value = castToNullFloat(bucket.GetPath(metric.ID, statName))
 					}
 
-					addMetricValue(&values, rp.getMetricName(metric.Type), value)
-					break
+					addMetricValue(&values, rp.getMetricName(statName), value)
+				}
+			case percentilesType:
+				percentiles := bucket.GetPath(metric.ID, "values")
+				for _, percentileName := range sortedPercentiles(percentiles.MustMap()) {
+					addMetricValue(&values, "p"+percentileName+" "+metric.Field, castToNullFloat(percentiles.Get(percentileName)))
+				}
+			case topMetricsType:
+				top := bucket.GetPath(metric.ID, "top").GetIndex(0)
+				for _, field := range metric.Settings.Get("metrics").MustStringArray() {
+					addMetricValue(&values, rp.getMetricName(metric.Type)+" "+field, castToNullFloat(top.GetPath("metrics", field)))
 				}
 			default:
 				metricName := rp.getMetricName(metric.Type)
@@ -536,6 +611,52 @@ func (rp *responseParser) getMetricName(metric string) string {
 	return metric
 }
 
+// sortedPercentiles returns the percentiles of a percentiles aggregation
+// value in increasing order.
+func sortedPercentiles(percentiles map[string]interface{}) []string {
+	keys := make([]string, 0, len(percentiles))
+	for k := range percentiles {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, errA := strconv.ParseFloat(keys[i], 64)
+		b, errB := strconv.ParseFloat(keys[j], 64)
+		if errA != nil || errB != nil {
+			return keys[i] < keys[j]
+		}
+		return a < b
+	})
+	return keys
+}
+
+// compositeAfterKey returns the key to request the next page of a composite
+// aggregation with, or nil if the query has no composite aggregation.
+func compositeAfterKey(res *es.SearchResponse, target *Query) map[string]interface{} {
+	if len(target.BucketAggs) == 0 || target.BucketAggs[0].Type != compositeType {
+		return nil
+	}
+
+	afterKey, err := simplejson.NewFromAny(res.Aggregations).GetPath(target.BucketAggs[0].ID, "after_key").Map()
+	if err != nil {
+		return nil
+	}
+	return afterKey
+}
+
+// formatBucketKey formats a value of a composite aggregation bucket key.
+func formatBucketKey(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
 func castToNullFloat(j *simplejson.Json) null.Float {
 	f, err := j.Float64()
 	if err == nil {
@@ -581,3 +702,214 @@ func getErrorFromElasticResponse(response *es.SearchResponse) *tsdb.QueryResult
 
 	return result
 }
+
+// processDocuments returns the hits of a raw document, raw data or logs
+// query as a data frame. The time field comes first, followed by the log
+// message for logs queries, the document metadata and the remaining document
+// properties sorted by name.
+func (rp *responseParser) processDocuments(res *es.SearchResponse, target *Query) (*data.Frame, error) {
+	timeField := rp.ConfiguredFields.TimeField
+	isLogs := target.isLogsQuery()
+
+	docs := make([]map[string]interface{}, 0)
+	searchWords := make(map[string]bool)
+	propNames := make(map[string]bool)
+
+	if res.Hits != nil {
+		for _, hit := range res.Hits.Hits {
+			doc := map[string]interface{}{
+				"_id":    hit["_id"],
+				"_type":  hit["_type"],
+				"_index": hit["_index"],
+			}
+			if sortValues, ok := hit["sort"]; ok {
+				doc["sort"] = sortValues
+			}
+
+			if source, ok := hit["_source"].(map[string]interface{}); ok {
+				flattenDocument(doc, "", source)
+			}
+
+			// Doc value fields hold the formatted value of the time field.
+			if fields, ok := hit["fields"].(map[string]interface{}); ok {
+				if values, ok := fields[timeField].([]interface{}); ok && len(values) > 0 {
+					doc[timeField] = values[0]
+				}
+			}
+
+			if isLogs && rp.ConfiguredFields.LogLevelField != "" {
+				doc["level"] = doc[rp.ConfiguredFields.LogLevelField]
+			}
+
+			if highlight, ok := hit["highlight"].(map[string]interface{}); ok {
+				for _, fragments := range highlight {
+					values, ok := fragments.([]interface{})
+					if !ok {
+						continue
+					}
+					for _, fragment := range values {
+						if s, ok := fragment.(string); ok {
+							for _, word := range highlightedWords(s) {
+								searchWords[word] = true
+							}
+						}
+					}
+				}
+			}
+
+			for name := range doc {
+				propNames[name] = true
+			}
+			docs = append(docs, doc)
+		}
+	}
+
+	fields := make([]*data.Field, 0, len(propNames)+2)
+
+	timeValues := make([]*time.Time, len(docs))
+	for i, doc := range docs {
+		t, err := parseDocumentTime(doc[timeField])
+		if err != nil {
+			return nil, err
+		}
+		timeValues[i] = t
+	}
+	fields = append(fields, data.NewField(timeField, nil, timeValues))
+	delete(propNames, timeField)
+
+	if isLogs && rp.ConfiguredFields.LogMessageField != "" {
+		messageField := rp.ConfiguredFields.LogMessageField
+		fields = append(fields, newDocumentField(messageField, docs))
+		delete(propNames, messageField)
+	}
+
+	names := make([]string, 0, len(propNames))
+	for name := range propNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, newDocumentField(name, docs))
+	}
+
+	frame := data.NewFrame(target.RefID, fields...)
+	frame.RefID = target.RefID
+
+	meta := &data.FrameMeta{}
+	if isLogs {
+		meta.PreferredVisualization = data.VisTypeLogs
+	}
+	if len(searchWords) > 0 {
+		words := make([]string, 0, len(searchWords))
+		for word := range searchWords {
+			words = append(words, word)
+		}
+		sort.Strings(words)
+		meta.Custom = map[string]interface{}{"searchWords": words}
+	}
+	frame.Meta = meta
+
+	return frame, nil
+}
+
+// flattenDocument copies the properties of a document source to doc, joining
+// the names of nested properties with dots.
+func flattenDocument(doc map[string]interface{}, prefix string, source map[string]interface{}) {
+	for k, v := range source {
+		name := prefix + k
+		if nested, ok := v.(map[string]interface{}); ok {
+			flattenDocument(doc, name+".", nested)
+			continue
+		}
+		doc[name] = v
+	}
+}
+
+var highlightPattern = regexp.MustCompile(regexp.QuoteMeta(es.HighlightPreTag) + `(.*?)` + regexp.QuoteMeta(es.HighlightPostTag))
+
+// highlightedWords returns the words wrapped in highlight tags in a fragment.
+func highlightedWords(fragment string) []string {
+	words := make([]string, 0)
+	for _, match := range highlightPattern.FindAllStringSubmatch(fragment, -1) {
+		words = append(words, match[1])
+	}
+	return words
+}
+
+// parseDocumentTime parses a time field value, which is either a formatted
+// date or milliseconds since epoch.
+func parseDocumentTime(value interface{}) (*time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		t := time.Unix(0, int64(v)*int64(time.Millisecond)).UTC()
+		return &t, nil
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return &t, nil
+		}
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse time field value %q", v)
+		}
+		t := time.Unix(0, ms*int64(time.Millisecond)).UTC()
+		return &t, nil
+	default:
+		return nil, fmt.Errorf("unsupported time field value type %T", value)
+	}
+}
+
+// newDocumentField returns the values of a document property as a field.
+// Properties holding only numbers, booleans or strings get a field of that
+// type, any other property is encoded as JSON.
+func newDocumentField(name string, docs []map[string]interface{}) *data.Field {
+	var isNumber, isBool, isString = true, true, true
+	for _, doc := range docs {
+		switch doc[name].(type) {
+		case nil:
+		case float64:
+			isBool, isString = false, false
+		case bool:
+			isNumber, isString = false, false
+		case string:
+			isNumber, isBool = false, false
+		default:
+			isNumber, isBool, isString = false, false, false
+		}
+	}
+
+	switch {
+	case isNumber && !isString:
+		values := make([]*float64, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[name].(float64); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values)
+	case isBool && !isString:
+		values := make([]*bool, len(docs))
+		for i, doc := range docs {
+			if v, ok := doc[name].(bool); ok {
+				values[i] = &v
+			}
+		}
+		return data.NewField(name, nil, values)
+	}
+
+	values := make([]*string, len(docs))
+	for i, doc := range docs {
+		switch v := doc[name].(type) {
+		case nil:
+		case string:
+			values[i] = &v
+		default:
+			if b, err := json.Marshal(v); err == nil {
+				s := string(b)
+				values[i] = &s
+			}
+		}
+	}
+	return data.NewField(name, nil, values)
+}
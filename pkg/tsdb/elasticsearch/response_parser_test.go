@@ -3,9 +3,12 @@ package elasticsearch
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
@@ -994,6 +997,275 @@ func TestResponseParser(t *testing.T) {
 	})
 }
 
+func TestResponseParserDocuments(t *testing.T) {
+	Convey("Elasticsearch response parser documents test", t, func() {
+		Convey("Logs query", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "logs", "id": "1" }]
+				}`,
+			}
+			response := fmt.Sprintf(`{
+				"responses": [
+					{
+						"hits": {
+							"hits": [
+								{
+									"_id": "1",
+									"_index": "logs-2018.05.15",
+									"_type": "_doc",
+									"_source": {
+										"@timestamp": "2018-05-15T17:52:00.000Z",
+										"line": "hello world",
+										"lvl": "error",
+										"host": { "name": "server-1" },
+										"bytes": 1024
+									},
+									"fields": { "@timestamp": ["2018-05-15T17:52:00.000Z"] },
+									"highlight": { "line": ["%[1]shello%[2]s world"] },
+									"sort": [1526406720000, 1]
+								},
+								{
+									"_id": "2",
+									"_index": "logs-2018.05.15",
+									"_type": "_doc",
+									"_source": {
+										"@timestamp": "2018-05-15T17:51:00.000Z",
+										"line": "hello again",
+										"lvl": "info",
+										"host": { "name": "server-2" },
+										"tags": ["a", "b"]
+									},
+									"fields": { "@timestamp": ["2018-05-15T17:51:00.000Z"] },
+									"sort": [1526406660000, 2]
+								}
+							]
+						}
+					}
+				]
+			}`, es.HighlightPreTag, es.HighlightPostTag)
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes, ShouldNotBeNil)
+			So(queryRes.Series, ShouldHaveLength, 0)
+			frames, err := queryRes.Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+			frame := frames[0]
+			So(frame.RefID, ShouldEqual, "A")
+			So(frame.Meta.PreferredVisualization, ShouldEqual, data.VisTypeLogs)
+			So(frame.Meta.Custom, ShouldResemble, map[string]interface{}{"searchWords": []string{"hello"}})
+
+			names := make([]string, 0, len(frame.Fields))
+			for _, f := range frame.Fields {
+				names = append(names, f.Name)
+			}
+			So(names, ShouldResemble, []string{"@timestamp", "line", "_id", "_index", "_type", "bytes", "host.name", "level", "lvl", "sort", "tags"})
+			So(frame.Rows(), ShouldEqual, 2)
+
+			timeField := frame.Fields[0]
+			So(*timeField.At(0).(*time.Time), ShouldEqual, time.Date(2018, 5, 15, 17, 52, 0, 0, time.UTC))
+			So(*frame.Fields[1].At(1).(*string), ShouldEqual, "hello again")
+			So(*frame.Fields[5].At(0).(*float64), ShouldEqual, 1024)
+			So(frame.Fields[5].At(1).(*float64), ShouldBeNil)
+			So(*frame.Fields[6].At(1).(*string), ShouldEqual, "server-2")
+			So(*frame.Fields[7].At(0).(*string), ShouldEqual, "error")
+			So(*frame.Fields[9].At(0).(*string), ShouldEqual, "[1526406720000,1]")
+			So(*frame.Fields[10].At(1).(*string), ShouldEqual, `["a","b"]`)
+		})
+
+		Convey("Raw data query with epoch time field", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "raw_data", "id": "1" }]
+				}`,
+			}
+			response := `{
+				"responses": [
+					{
+						"hits": {
+							"hits": [
+								{
+									"_id": "1",
+									"_source": { "@timestamp": 1526406720000, "ok": true }
+								}
+							]
+						}
+					}
+				]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			frames, err := result.Results["A"].Dataframes.Decoded()
+			So(err, ShouldBeNil)
+			So(frames, ShouldHaveLength, 1)
+			frame := frames[0]
+			So(frame.Meta.PreferredVisualization, ShouldEqual, "")
+			So(*frame.Fields[0].At(0).(*time.Time), ShouldEqual, time.Date(2018, 5, 15, 17, 52, 0, 0, time.UTC))
+			So(frame.Fields[4].Name, ShouldEqual, "ok")
+			So(*frame.Fields[4].At(0).(*bool), ShouldBeTrue)
+		})
+	})
+}
+
+func TestResponseParserFixtures(t *testing.T) {
+	Convey("Elasticsearch response parser fixtures test", t, func() {
+		Convey("Percentiles in date histogram", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "percentiles", "field": "value", "settings": { "percents": ["5", "25", "50", "75", "99"] }, "id": "1" }],
+					"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }]
+				}`,
+			}
+			rp, err := newResponseParserForTest(targets, loadFixture(t, "percentiles.json"))
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes, ShouldNotBeNil)
+			So(queryRes.Series, ShouldHaveLength, 5)
+			names := make([]string, 0, len(queryRes.Series))
+			for _, s := range queryRes.Series {
+				names = append(names, s.Name)
+			}
+			So(names, ShouldResemble, []string{"p5.0 value", "p25.0 value", "p50.0 value", "p75.0 value", "p99.0 value"})
+
+			p99 := queryRes.Series[4]
+			So(p99.Points, ShouldHaveLength, 2)
+			So(p99.Points[0][0].Float64, ShouldEqual, 48.75)
+			So(p99.Points[0][1].Float64, ShouldEqual, 1526406600000)
+			So(p99.Points[1][0].Valid, ShouldBeFalse)
+		})
+
+		Convey("Extended stats and percentiles in terms", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [
+						{ "type": "extended_stats", "field": "value", "meta": { "max": true, "std_deviation_bounds_upper": true, "min": false }, "id": "1" },
+						{ "type": "percentiles", "field": "value", "settings": { "percents": ["50", "95"] }, "id": "2" }
+					],
+					"bucketAggs": [{ "type": "terms", "field": "@host", "id": "3" }]
+				}`,
+			}
+			rp, err := newResponseParserForTest(targets, loadFixture(t, "extended_stats_terms.json"))
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes, ShouldNotBeNil)
+			So(queryRes.Tables, ShouldHaveLength, 1)
+			table := queryRes.Tables[0]
+			columns := make([]string, 0, len(table.Columns))
+			for _, c := range table.Columns {
+				columns = append(columns, c.Text)
+			}
+			So(columns, ShouldResemble, []string{"@host", "Max", "Std Dev Upper", "p50.0 value", "p95.0 value"})
+
+			So(table.Rows, ShouldHaveLength, 2)
+			row := table.Rows[0]
+			So(row, ShouldHaveLength, 5)
+			So(row[0].(string), ShouldEqual, "server-1")
+			So(row[1].(null.Float).Float64, ShouldEqual, 9)
+			So(row[2].(null.Float).Float64, ShouldEqual, 9.47213595499958)
+			So(row[3].(null.Float).Float64, ShouldEqual, 5)
+			So(row[4].(null.Float).Float64, ShouldEqual, 8.5)
+			So(table.Rows[1][0].(string), ShouldEqual, "server-2")
+			So(table.Rows[1][4].(null.Float).Float64, ShouldEqual, 4)
+		})
+
+		Convey("Composite", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "avg", "field": "bytes", "id": "1" }, { "type": "count", "id": "3" }],
+					"bucketAggs": [{
+						"type": "composite",
+						"id": "2",
+						"settings": { "sources": [{ "name": "host", "field": "host.name" }, { "name": "status", "field": "status" }] }
+					}]
+				}`,
+			}
+			rp, err := newResponseParserForTest(targets, loadFixture(t, "composite.json"))
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes, ShouldNotBeNil)
+			So(queryRes.Tables, ShouldHaveLength, 1)
+			table := queryRes.Tables[0]
+			columns := make([]string, 0, len(table.Columns))
+			for _, c := range table.Columns {
+				columns = append(columns, c.Text)
+			}
+			So(columns, ShouldResemble, []string{"host", "status", "Average", "Count"})
+
+			So(table.Rows, ShouldHaveLength, 2)
+			row := table.Rows[1]
+			So(row[0].(string), ShouldEqual, "server-2")
+			So(row[1].(null.Float).Float64, ShouldEqual, 500)
+			So(row[2].(null.Float).Float64, ShouldEqual, 7.25)
+			So(row[3].(null.Float).Float64, ShouldEqual, 15)
+
+			So(queryRes.Meta.Get("afterKey").MustMap(), ShouldResemble, map[string]interface{}{"host": "server-2", "status": 500.0})
+		})
+
+		Convey("Top metrics and rate in date histogram", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [
+						{ "type": "top_metrics", "settings": { "metrics": ["cpu", "memory"], "order": "desc", "orderBy": "@timestamp" }, "id": "1" },
+						{ "type": "rate", "field": "bytes", "settings": { "unit": "minute" }, "id": "3" }
+					],
+					"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }]
+				}`,
+			}
+			rp, err := newResponseParserForTest(targets, loadFixture(t, "top_metrics.json"))
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes, ShouldNotBeNil)
+			So(queryRes.Series, ShouldHaveLength, 3)
+			So(queryRes.Series[0].Name, ShouldEqual, "Top Metrics cpu")
+			So(queryRes.Series[0].Points[0][0].Float64, ShouldEqual, 0.75)
+			So(queryRes.Series[0].Points[1][0].Float64, ShouldEqual, 0.25)
+			So(queryRes.Series[1].Name, ShouldEqual, "Top Metrics memory")
+			So(queryRes.Series[1].Points[1][0].Float64, ShouldEqual, 256)
+			So(queryRes.Series[2].Name, ShouldEqual, "Rate bytes")
+			So(queryRes.Series[2].Points[0][0].Float64, ShouldEqual, 0.5)
+		})
+	})
+}
+
+func loadFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	// Ignore gosec warning G304 since it's a test
+	// nolint:gosec
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
 func newResponseParserForTest(tsdbQueries map[string]string, responseBody string) (*responseParser, error) {
 	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
 	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
@@ -1028,5 +1300,9 @@ func newResponseParserForTest(tsdbQueries map[string]string, responseBody string
 		return nil, err
 	}
 
-	return newResponseParser(response.Responses, queries, nil), nil
+	return newResponseParser(response.Responses, queries, nil, es.ConfiguredFields{
+		TimeField:       "@timestamp",
+		LogMessageField: "line",
+		LogLevelField:   "lvl",
+	}), nil
 }
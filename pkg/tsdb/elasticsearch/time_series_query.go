@@ -10,6 +10,10 @@ import (
 	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 )
 
+// defaultDocumentSize is how many documents raw and logs queries return by
+// default.
+const defaultDocumentSize = 500
+
 type timeSeriesQuery struct {
 	client             es.Client
 	tsdbQuery          *tsdb.TsdbQuery
@@ -54,8 +58,18 @@ func (e *timeSeriesQuery) execute() (*tsdb.Response, error) {
 		return nil, err
 	}
 
-	rp := newResponseParser(res.Responses, queries, res.DebugInfo)
-	return rp.getTimeSeries()
+	rp := newResponseParser(res.Responses, queries, res.DebugInfo, e.client.GetConfiguredFields())
+	response, err := rp.getTimeSeries()
+	if err != nil {
+		return nil, err
+	}
+
+	// Invalid queries are reported instead of their search response.
+	for refID, queryRes := range result.Results {
+		response.Results[refID] = queryRes
+	}
+
+	return response, nil
 }
 
 func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to string,
@@ -75,27 +89,41 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 		filters.AddQueryStringFilter(q.RawQuery, true)
 	}
 
-	if len(q.BucketAggs) == 0 {
-		if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
-			result.Results[q.RefID] = &tsdb.QueryResult{
-				RefId:       q.RefID,
-				Error:       fmt.Errorf("invalid query, missing metrics and aggregations"),
-				ErrorString: "invalid query, missing metrics and aggregations",
-			}
+	if q.isDocumentQuery() {
+		e.addDocumentQuery(b, q.Metrics[0])
+		// Logs queries can also aggregate their documents, for example to
+		// show the log volume.
+		if len(q.BucketAggs) == 0 {
 			return nil
 		}
-		metric := q.Metrics[0]
-		b.Size(metric.Settings.Get("size").MustInt(500))
-		b.SortDesc("@timestamp", "boolean")
-		b.AddDocValueField("@timestamp")
+	}
+
+	if len(q.BucketAggs) == 0 {
+		result.Results[q.RefID] = &tsdb.QueryResult{
+			RefId:       q.RefID,
+			Error:       fmt.Errorf("invalid query, missing metrics and aggregations"),
+			ErrorString: "invalid query, missing metrics and aggregations",
+		}
 		return nil
 	}
 
 	aggBuilder := b.Agg()
 
 	// iterate backwards to create aggregations bottom-down
-	for _, bucketAgg := range q.BucketAggs {
+	for i, bucketAgg := range q.BucketAggs {
 		switch bucketAgg.Type {
+		case compositeType:
+			// Elasticsearch only allows composite aggregations at the top
+			// level.
+			if i > 0 {
+				result.Results[q.RefID] = &tsdb.QueryResult{
+					RefId:       q.RefID,
+					Error:       fmt.Errorf("invalid query, composite aggregation must be the first bucket aggregation"),
+					ErrorString: "invalid query, composite aggregation must be the first bucket aggregation",
+				}
+				return nil
+			}
+			aggBuilder = addCompositeAgg(aggBuilder, bucketAgg)
 		case dateHistType:
 			aggBuilder = addDateHistogramAgg(aggBuilder, bucketAgg, from, to)
 		case histogramType:
@@ -111,7 +139,7 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 
 	for _, m := range q.Metrics {
 		m := m
-		if m.Type == countType {
+		if m.Type == countType || isDocumentMetric(m.Type) {
 			continue
 		}
 
@@ -160,13 +188,17 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 						}
 
 						aggBuilder.Pipeline(m.ID, m.Type, bucketPath, func(a *es.PipelineAggregation) {
-							a.Settings = m.Settings.MustMap()
+							a.Settings = pipelineSettings(m)
 						})
 					}
 				} else {
 					continue
 				}
 			}
+		} else if m.Type == topMetricsType {
+			aggBuilder.Metric(m.ID, m.Type, "", func(a *es.MetricAggregation) {
+				a.Settings = topMetricsSettings(m)
+			})
 		} else {
 			aggBuilder.Metric(m.ID, m.Type, m.Field, func(a *es.MetricAggregation) {
 				a.Settings = m.Settings.MustMap()
@@ -177,6 +209,40 @@ func (e *timeSeriesQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilde
 	return nil
 }
 
+// addDocumentQuery makes the search request return the documents that match
+// the query, newest first unless the metric sorts ascending. Logs metrics
+// also highlight the matched terms.
+func (e *timeSeriesQuery) addDocumentQuery(b *es.SearchRequestBuilder, metric *MetricAgg) {
+	timeField := e.client.GetTimeField()
+
+	sizeSetting := "size"
+	if metric.Type == logsType {
+		sizeSetting = "limit"
+	}
+	b.Size(defaultDocumentSize)
+	if size, err := metric.Settings.Get(sizeSetting).Int(); err == nil && size > 0 {
+		b.Size(size)
+	} else if size, err := strconv.Atoi(metric.Settings.Get(sizeSetting).MustString()); err == nil && size > 0 {
+		b.Size(size)
+	}
+
+	order := es.SortOrderDesc
+	if metric.Settings.Get("sortDirection").MustString() == es.SortOrderAsc {
+		order = es.SortOrderAsc
+	}
+	b.Sort(order, timeField, "boolean")
+	b.Sort(order, "_doc", "")
+	b.AddDocValueField(timeField)
+
+	if searchAfter := metric.Settings.Get("searchAfter").MustArray(); len(searchAfter) > 0 {
+		b.AddSearchAfter(searchAfter)
+	}
+
+	if metric.Type == logsType {
+		b.AddHighlight()
+	}
+}
+
 func addDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeFrom, timeTo string) es.AggBuilder {
 	aggBuilder.DateHistogram(bucketAgg.ID, bucketAgg.Field, func(a *es.DateHistogramAgg, b es.AggBuilder) {
 		a.Interval = bucketAgg.Settings.Get("interval").MustString("auto")
@@ -302,6 +368,119 @@ func addGeoHashGridAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBui
 	return aggBuilder
 }
 
+// compositeSource is a values source of a composite aggregation.
+type compositeSource struct {
+	Name     string
+	Type     string
+	Settings map[string]interface{}
+}
+
+// compositeSources returns the values sources of a composite aggregation.
+// Without configured sources, the buckets are keyed by the terms of the
+// aggregation field.
+func compositeSources(bucketAgg *BucketAgg) []compositeSource {
+	sources := make([]compositeSource, 0)
+	for _, s := range bucketAgg.Settings.Get("sources").MustArray() {
+		json := simplejson.NewFromAny(s)
+		settings := make(map[string]interface{})
+		for k, v := range json.MustMap() {
+			if k != "name" && k != "type" {
+				settings[k] = v
+			}
+		}
+		field := json.Get("field").MustString()
+		sources = append(sources, compositeSource{
+			Name:     json.Get("name").MustString(field),
+			Type:     json.Get("type").MustString(termsType),
+			Settings: settings,
+		})
+	}
+
+	if len(sources) == 0 && bucketAgg.Field != "" {
+		sources = append(sources, compositeSource{
+			Name:     bucketAgg.Field,
+			Type:     termsType,
+			Settings: map[string]interface{}{"field": bucketAgg.Field},
+		})
+	}
+
+	return sources
+}
+
+func addCompositeAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.Composite(bucketAgg.ID, func(a *es.CompositeAggregation, b es.AggBuilder) {
+		a.Size = 500
+		if size, err := bucketAgg.Settings.Get("size").Int(); err == nil && size > 0 {
+			a.Size = size
+		} else if size, err := strconv.Atoi(bucketAgg.Settings.Get("size").MustString()); err == nil && size > 0 {
+			a.Size = size
+		}
+
+		for _, source := range compositeSources(bucketAgg) {
+			a.Sources = append(a.Sources, map[string]interface{}{
+				source.Name: map[string]interface{}{source.Type: source.Settings},
+			})
+		}
+
+		// after holds the after_key of the previous page.
+		if after, err := bucketAgg.Settings.Get("after").Map(); err == nil && len(after) > 0 {
+			a.After = after
+		}
+
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+// topMetricsSettings returns the settings of a top_metrics aggregation, which
+// selects the metrics fields of the top document by the order by field.
+func topMetricsSettings(m *MetricAgg) map[string]interface{} {
+	metrics := make([]map[string]interface{}, 0)
+	for _, field := range m.Settings.Get("metrics").MustStringArray() {
+		metrics = append(metrics, map[string]interface{}{"field": field})
+	}
+
+	settings := map[string]interface{}{
+		"metrics": metrics,
+		"size":    1,
+	}
+	if orderBy := m.Settings.Get("orderBy").MustString(); orderBy != "" {
+		settings["sort"] = map[string]interface{}{
+			orderBy: m.Settings.Get("order").MustString(es.SortOrderDesc),
+		}
+	}
+
+	return settings
+}
+
+// pipelineSettings returns the settings of a pipeline aggregation. The query
+// editor stores numbers as strings, which moving_fn does not accept.
+func pipelineSettings(m *MetricAgg) map[string]interface{} {
+	if m.Type != movingFnType {
+		return m.Settings.MustMap()
+	}
+
+	settings := make(map[string]interface{})
+	for k, v := range m.Settings.MustMap() {
+		settings[k] = v
+	}
+	for _, name := range []string{"window", "shift"} {
+		if v, ok := settings[name].(string); ok {
+			if i, err := strconv.Atoi(v); err == nil {
+				settings[name] = i
+			}
+		}
+	}
+	if script, ok := settings["script"].(map[string]interface{}); ok {
+		if inline, ok := script["inline"].(string); ok {
+			settings["script"] = inline
+		}
+	}
+
+	return settings
+}
+
 type timeSeriesQueryParser struct{}
 
 func newTimeSeriesQueryParser() *timeSeriesQueryParser {
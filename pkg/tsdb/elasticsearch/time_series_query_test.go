@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -376,6 +377,153 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(sr.Size, ShouldEqual, 1337)
 		})
 
+		Convey("With logs metric", func() {
+			c := newFakeClient(7)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "limit": "100", "searchAfter": [1526406600000, 42] } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 100)
+			So(sr.Sort["@timestamp"], ShouldResemble, map[string]string{"order": "desc", "unmapped_type": "boolean"})
+			So(sr.Sort["_doc"], ShouldResemble, map[string]string{"order": "desc"})
+			So(sr.CustomProps["search_after"], ShouldResemble, []interface{}{json.Number("1526406600000"), json.Number("42")})
+			So(sr.CustomProps["highlight"], ShouldNotBeNil)
+
+			body, err := json.Marshal(sr)
+			So(err, ShouldBeNil)
+			sj, err := simplejson.NewJson(body)
+			So(err, ShouldBeNil)
+			sort := sj.Get("sort").MustArray()
+			So(sort, ShouldHaveLength, 2)
+			So(sj.Get("sort").GetIndex(0).Get("@timestamp").Get("order").MustString(), ShouldEqual, "desc")
+			So(sj.Get("sort").GetIndex(1).Get("_doc").Get("order").MustString(), ShouldEqual, "desc")
+		})
+
+		Convey("With raw data metric sorted ascending", func() {
+			c := newFakeClient(7)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": 10, "sortDirection": "asc" } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 10)
+			So(sr.Sort["@timestamp"], ShouldResemble, map[string]string{"order": "asc", "unmapped_type": "boolean"})
+			So(sr.CustomProps["highlight"], ShouldBeNil)
+			So(sr.CustomProps["search_after"], ShouldBeNil)
+		})
+
+		Convey("With composite agg", func() {
+			c := newFakeClient(7)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{
+						"id": "2",
+						"type": "composite",
+						"settings": {
+							"size": "100",
+							"sources": [
+								{ "name": "host", "field": "host.name" },
+								{ "name": "status", "type": "histogram", "field": "status", "interval": 100 }
+							],
+							"after": { "host": "server-1", "status": 200 }
+						}
+					},
+					{ "id": "3", "type": "date_histogram", "field": "@timestamp" }
+				],
+				"metrics": [{ "type": "count", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			firstLevel := sr.Aggs[0]
+			So(firstLevel.Key, ShouldEqual, "2")
+			So(firstLevel.Aggregation.Type, ShouldEqual, "composite")
+			compositeAgg := firstLevel.Aggregation.Aggregation.(*es.CompositeAggregation)
+			So(compositeAgg.Size, ShouldEqual, 100)
+			So(compositeAgg.Sources, ShouldResemble, []map[string]interface{}{
+				{"host": map[string]interface{}{"terms": map[string]interface{}{"field": "host.name"}}},
+				{"status": map[string]interface{}{"histogram": map[string]interface{}{"field": "status", "interval": json.Number("100")}}},
+			})
+			So(compositeAgg.After["host"], ShouldEqual, "server-1")
+			So(firstLevel.Aggregation.Aggs[0].Key, ShouldEqual, "3")
+		})
+
+		Convey("With composite agg not at the top level", func() {
+			c := newFakeClient(7)
+			res, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [
+					{ "id": "2", "type": "terms", "field": "@host" },
+					{ "id": "3", "type": "composite", "field": "status" }
+				],
+				"metrics": [{ "type": "count", "id": "1" }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			So(res.Results[""].ErrorString, ShouldEqual, "invalid query, composite aggregation must be the first bucket aggregation")
+		})
+
+		Convey("With top metrics and rate", func() {
+			c := newFakeClient(7)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [
+					{ "id": "1", "type": "top_metrics", "settings": { "metrics": ["cpu", "memory"], "order": "asc", "orderBy": "@timestamp" } },
+					{ "id": "3", "type": "rate", "field": "bytes", "settings": { "unit": "minute" } }
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			body, err := json.Marshal(sr)
+			So(err, ShouldBeNil)
+			sj, err := simplejson.NewJson(body)
+			So(err, ShouldBeNil)
+			topMetrics := sj.GetPath("aggs", "2", "aggs", "1", "top_metrics")
+			So(topMetrics.Get("field").Interface(), ShouldBeNil)
+			So(topMetrics.Get("size").MustInt(), ShouldEqual, 1)
+			So(topMetrics.GetPath("sort", "@timestamp").MustString(), ShouldEqual, "asc")
+			So(topMetrics.Get("metrics").GetIndex(1).Get("field").MustString(), ShouldEqual, "memory")
+			rate := sj.GetPath("aggs", "2", "aggs", "3", "rate")
+			So(rate.Get("field").MustString(), ShouldEqual, "bytes")
+			So(rate.Get("unit").MustString(), ShouldEqual, "minute")
+		})
+
+		Convey("With moving function", func() {
+			c := newFakeClient(7)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [
+					{ "id": "3", "type": "sum", "field": "@value" },
+					{
+						"id": "4",
+						"type": "moving_fn",
+						"pipelineAgg": "3",
+						"settings": { "window": "5", "script": { "inline": "MovingFunctions.unweightedAvg(values)" } }
+					}
+				]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			movingFnAgg := sr.Aggs[0].Aggregation.Aggs[1]
+			So(movingFnAgg.Key, ShouldEqual, "4")
+			So(movingFnAgg.Aggregation.Type, ShouldEqual, "moving_fn")
+			pl := movingFnAgg.Aggregation.Aggregation.(*es.PipelineAggregation)
+			So(pl.BucketPath, ShouldEqual, "3")
+			So(pl.Settings["window"], ShouldEqual, 5)
+			So(pl.Settings["script"], ShouldEqual, "MovingFunctions.unweightedAvg(values)")
+		})
+
 		Convey("With date histogram agg", func() {
 			c := newFakeClient(5)
 			_, err := executeTsdbQuery(c, `{
@@ -883,6 +1031,10 @@ func (c *fakeClient) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
+	return es.ConfiguredFields{TimeField: c.timeField}
+}
+
 func (c *fakeClient) GetMinInterval(queryInterval string) (time.Duration, error) {
 	return 15 * time.Second, nil
 }
@@ -0,0 +1,121 @@
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// defaultConcurrentQueryLimit is used when neither the data source nor the
+// server configuration sets a limit.
+const defaultConcurrentQueryLimit = 10
+
+var elog = log.New("tsdb.execute")
+
+// QueryFunc runs a single query of a request. Returning an error sets it on
+// the query's result; returning neither a result nor an error leaves the
+// query out of the response.
+type QueryFunc func(ctx context.Context, query *Query) (*QueryResult, error)
+
+// ExecuteQueries runs the queries of a request concurrently, at most
+// ConcurrentQueryLimit(dsInfo, tsdbQuery) at a time, and collects their results by
+// refId. A failing query does not affect the others. Queries that have not
+// started when ctx is cancelled fail with the context's error.
+func ExecuteQueries(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *TsdbQuery, fn QueryFunc) *Response {
+	result := &Response{
+		Results: make(map[string]*QueryResult, len(tsdbQuery.Queries)),
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, ConcurrentQueryLimit(dsInfo, tsdbQuery))
+	)
+	for _, query := range tsdbQuery.Queries {
+		wg.Add(1)
+		go func(query *Query) {
+			defer wg.Done()
+
+			var queryResult *QueryResult
+			select {
+			case sem <- struct{}{}:
+				if err := ctx.Err(); err != nil {
+					queryResult = &QueryResult{RefId: query.RefId, Error: err}
+				} else {
+					queryResult = executeQuery(ctx, dsInfo, query, fn)
+				}
+				<-sem
+			case <-ctx.Done():
+				queryResult = &QueryResult{RefId: query.RefId, Error: ctx.Err()}
+			}
+
+			if queryResult == nil {
+				return
+			}
+
+			mu.Lock()
+			result.Results[query.RefId] = queryResult
+			mu.Unlock()
+		}(query)
+	}
+	wg.Wait()
+
+	return result
+}
+
+func executeQuery(ctx context.Context, dsInfo *models.DataSource, query *Query, fn QueryFunc) (queryResult *QueryResult) {
+	start := time.Now()
+	defer func() {
+		// A panicking query must not take down the server or the other
+		// queries of the request.
+		if r := recover(); r != nil {
+			elog.Error("Query panicked", "datasource", dsInfo.Type, "refId", query.RefId, "error", r)
+			queryResult = &QueryResult{RefId: query.RefId, Error: fmt.Errorf("query failed: %v", r)}
+		}
+
+		if queryResult == nil {
+			return
+		}
+
+		status := "success"
+		if queryResult.Error != nil {
+			status = "error"
+		}
+		metrics.MDataSourceQueryDuration.WithLabelValues(dsInfo.Type, status).Observe(time.Since(start).Seconds())
+	}()
+
+	queryResult, err := fn(ctx, query)
+	if err != nil {
+		if queryResult == nil {
+			queryResult = NewQueryResult()
+		}
+		queryResult.Error = err
+	}
+	if queryResult != nil && queryResult.RefId == "" {
+		queryResult.RefId = query.RefId
+	}
+
+	return queryResult
+}
+
+// ConcurrentQueryLimit returns how many queries of a request may run at the
+// same time against the data source. The concurrentQueryLimit field of the
+// data source's JSON data overrides the server-wide limit of the request.
+func ConcurrentQueryLimit(dsInfo *models.DataSource, tsdbQuery *TsdbQuery) int {
+	if dsInfo.JsonData != nil {
+		if limit := dsInfo.JsonData.Get("concurrentQueryLimit").MustInt(0); limit > 0 {
+			return limit
+		}
+	}
+
+	if tsdbQuery.ConcurrentQueryLimit > 0 {
+		return tsdbQuery.ConcurrentQueryLimit
+	}
+
+	return defaultConcurrentQueryLimit
+}
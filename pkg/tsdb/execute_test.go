@@ -0,0 +1,108 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteQueries(t *testing.T) {
+	dsInfo := &models.DataSource{Type: "test", JsonData: simplejson.New()}
+
+	newRequest := func(refIDs ...string) *TsdbQuery {
+		req := &TsdbQuery{}
+		for _, refID := range refIDs {
+			req.Queries = append(req.Queries, &Query{RefId: refID, Model: simplejson.New()})
+		}
+		return req
+	}
+
+	t.Run("collects results and errors per refId", func(t *testing.T) {
+		resp := ExecuteQueries(context.Background(), dsInfo, newRequest("A", "B", "C"), func(ctx context.Context, query *Query) (*QueryResult, error) {
+			switch query.RefId {
+			case "A":
+				return &QueryResult{Series: TimeSeriesSlice{{Name: "a"}}}, nil
+			case "B":
+				return nil, errors.New("query B failed")
+			default:
+				return nil, nil
+			}
+		})
+
+		require.Len(t, resp.Results, 2)
+		assert.Equal(t, "A", resp.Results["A"].RefId)
+		assert.NoError(t, resp.Results["A"].Error)
+		assert.Len(t, resp.Results["A"].Series, 1)
+		assert.Equal(t, "B", resp.Results["B"].RefId)
+		assert.EqualError(t, resp.Results["B"].Error, "query B failed")
+	})
+
+	t.Run("recovers from panics", func(t *testing.T) {
+		resp := ExecuteQueries(context.Background(), dsInfo, newRequest("A", "B"), func(ctx context.Context, query *Query) (*QueryResult, error) {
+			if query.RefId == "A" {
+				panic("boom")
+			}
+			return NewQueryResult(), nil
+		})
+
+		assert.EqualError(t, resp.Results["A"].Error, "query failed: boom")
+		assert.NoError(t, resp.Results["B"].Error)
+	})
+
+	t.Run("limits concurrency", func(t *testing.T) {
+		ds := &models.DataSource{Type: "test", JsonData: simplejson.NewFromAny(map[string]interface{}{"concurrentQueryLimit": 2})}
+
+		var running, maxRunning int32
+		resp := ExecuteQueries(context.Background(), ds, newRequest("A", "B", "C", "D", "E", "F"), func(ctx context.Context, query *Query) (*QueryResult, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return NewQueryResult(), nil
+		})
+
+		assert.Len(t, resp.Results, 6)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+	})
+
+	t.Run("fails queries that did not start when the context is cancelled", func(t *testing.T) {
+		ds := &models.DataSource{Type: "test", JsonData: simplejson.NewFromAny(map[string]interface{}{"concurrentQueryLimit": 1})}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var once sync.Once
+		resp := ExecuteQueries(ctx, ds, newRequest("A", "B", "C"), func(ctx context.Context, query *Query) (*QueryResult, error) {
+			once.Do(cancel)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		require.Len(t, resp.Results, 3)
+		for _, res := range resp.Results {
+			assert.True(t, errors.Is(res.Error, context.Canceled))
+		}
+	})
+}
+
+func TestConcurrentQueryLimit(t *testing.T) {
+	assert.Equal(t, defaultConcurrentQueryLimit, ConcurrentQueryLimit(&models.DataSource{}, &TsdbQuery{}))
+
+	request := &TsdbQuery{ConcurrentQueryLimit: 4}
+	assert.Equal(t, 4, ConcurrentQueryLimit(&models.DataSource{JsonData: simplejson.New()}, request))
+
+	ds := &models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{"concurrentQueryLimit": 2})}
+	assert.Equal(t, 2, ConcurrentQueryLimit(ds, request))
+}
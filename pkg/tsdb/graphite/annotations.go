@@ -0,0 +1,143 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+// annotationsQueryType is the query type of annotation queries. They either
+// render a target and turn its non-null data points into annotations, or
+// return the Graphite events with the query tags.
+const annotationsQueryType = "annotations"
+
+var tagsSeparator = regexp.MustCompile(`[\s,]+`)
+
+func (e *GraphiteExecutor) runAnnotationQuery(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, query *tsdb.Query, from, until string) (*tsdb.QueryResult, error) {
+	tags := annotationTags(query.Model.Get("tags").Interface())
+
+	frame := data.NewFrame("annotations",
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("title", nil, []string{}),
+		data.NewField("text", nil, []string{}),
+		data.NewField("tags", nil, []string{}),
+	)
+
+	if target := query.Model.Get("target").MustString(); target != "" {
+		series, err := e.render(ctx, httpClient, dsInfo, url.Values{
+			"from":   []string{from},
+			"until":  []string{until},
+			"format": []string{"json"},
+			"target": []string{fixIntervalFormat(target)},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range series {
+			for _, point := range s.DataPoints {
+				if !point[0].Valid {
+					continue
+				}
+				frame.AppendRow(time.Unix(0, int64(point[1].Float64)*int64(time.Millisecond)).UTC(), s.Target, "", strings.Join(tags, ","))
+			}
+		}
+	} else {
+		events, err := e.getEvents(ctx, httpClient, dsInfo, tags, from, until)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, event := range events {
+			frame.AppendRow(time.Unix(0, int64(event.When*float64(time.Second))).UTC(), event.What, event.Data, strings.Join(annotationTags(event.Tags), ","))
+		}
+	}
+
+	queryRes := tsdb.NewQueryResult()
+	queryRes.Dataframes = tsdb.NewDecodedDataFrames(data.Frames{frame})
+	return queryRes, nil
+}
+
+// getEvents returns the Graphite events with all of the tags, or all events
+// if there are no tags.
+func (e *GraphiteExecutor) getEvents(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, tags []string, from, until string) ([]EventDTO, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "events/get_data")
+	params := url.Values{
+		"from":  []string{from},
+		"until": []string{until},
+	}
+	if len(tags) > 0 {
+		params.Set("tags", strings.Join(tags, " "))
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	res, err := ctxhttp.Do(ctx, httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			glog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 != 2 {
+		glog.Info("Events request failed", "status", res.Status, "body", string(body))
+		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+
+	var events []EventDTO
+	if err := json.Unmarshal(body, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// annotationTags returns the tags of an annotation query or event, which are
+// either a list or a single string of space or comma separated tags.
+func annotationTags(value interface{}) []string {
+	tags := make([]string, 0)
+	switch v := value.(type) {
+	case string:
+		for _, tag := range tagsSeparator.Split(v, -1) {
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	case []interface{}:
+		for _, tag := range v {
+			if s, ok := tag.(string); ok && s != "" {
+				tags = append(tags, s)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,96 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotationQueries(t *testing.T) {
+	var eventsQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/events/get_data":
+			eventsQuery = req.URL.RawQuery
+			_, _ = rw.Write([]byte(`[
+				{"when": 1526406700, "what": "deploy", "data": "v1.2.3", "tags": ["deploy", "prod"]},
+				{"when": 1526406800.5, "what": "restart", "data": "", "tags": "prod restart"}
+			]`))
+		case "/render":
+			_, _ = rw.Write([]byte(`[
+				{"target": "deploys", "tags": {"name": "deploys"}, "datapoints": [[1, 1526406600], [null, 1526406660], [1, 1526406720]]}
+			]`))
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	ds := &models.DataSource{Url: server.URL, JsonData: simplejson.New()}
+	executor := &GraphiteExecutor{}
+
+	query := func(t *testing.T, model map[string]interface{}) *tsdb.QueryResult {
+		t.Helper()
+		resp, err := executor.Query(context.Background(), ds, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1526406600000", "1526410200000"),
+			Queries: []*tsdb.Query{
+				{RefId: "A", QueryType: annotationsQueryType, Model: simplejson.NewFromAny(model)},
+			},
+		})
+		require.NoError(t, err)
+		res := resp.Results["A"]
+		require.NotNil(t, res)
+		require.NoError(t, res.Error)
+		return res
+	}
+
+	t.Run("returns the events with the query tags", func(t *testing.T) {
+		res := query(t, map[string]interface{}{"tags": "deploy, prod"})
+		assert.Equal(t, "from=1526406600&tags=deploy+prod&until=1526410200", eventsQuery)
+
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.Unix(1526406700, 0).UTC(), frame.Fields[0].At(0))
+		assert.Equal(t, time.Unix(1526406800, 500000000).UTC(), frame.Fields[0].At(1))
+		assert.Equal(t, "deploy", frame.Fields[1].At(0))
+		assert.Equal(t, "v1.2.3", frame.Fields[2].At(0))
+		assert.Equal(t, "deploy,prod", frame.Fields[3].At(0))
+		assert.Equal(t, "prod,restart", frame.Fields[3].At(1))
+	})
+
+	t.Run("returns the data points of the target", func(t *testing.T) {
+		res := query(t, map[string]interface{}{"target": "deploys", "tags": []interface{}{"deploy"}})
+
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.Unix(1526406720, 0).UTC(), frame.Fields[0].At(1))
+		assert.Equal(t, "deploys", frame.Fields[1].At(1))
+		assert.Equal(t, "deploy", frame.Fields[3].At(1))
+	})
+
+	t.Run("returns the tags of tagged series", func(t *testing.T) {
+		resp, err := executor.Query(context.Background(), ds, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1526406600000", "1526410200000"),
+			Queries: []*tsdb.Query{
+				{RefId: "A", Model: simplejson.NewFromAny(map[string]interface{}{"target": "seriesByTag('name=deploys')"})},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results["A"].Series, 1)
+		assert.Equal(t, map[string]string{"name": "deploys"}, resp.Results["A"].Series[0].Tags)
+	})
+}
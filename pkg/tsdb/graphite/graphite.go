@@ -37,8 +37,6 @@ func init() {
 }
 
 func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
-	result := &tsdb.Response{}
-
 	// This logic is used when called from Dashboard Alerting.
 	from := "-" + formatTimeRange(tsdbQuery.TimeRange.From)
 	until := formatTimeRange(tsdbQuery.TimeRange.To)
@@ -52,18 +50,15 @@ func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 		}
 	}
 
-	var target string
-
-	formData := url.Values{
-		"from":          []string{from},
-		"until":         []string{until},
-		"format":        []string{"json"},
-		"maxDataPoints": []string{"500"},
-	}
-
+	targets := make(map[string]string, len(tsdbQuery.Queries))
 	emptyQueries := make([]string, 0)
+	annotationQueries := 0
 	for _, query := range tsdbQuery.Queries {
 		glog.Debug("graphite", "query", query.Model)
+		if query.QueryType == annotationsQueryType {
+			annotationQueries++
+			continue
+		}
 		currTarget := ""
 		if fullTarget, err := query.Model.Get("targetFull").String(); err == nil {
 			currTarget = fullTarget
@@ -75,34 +70,77 @@ func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 			emptyQueries = append(emptyQueries, fmt.Sprintf("Query: %v has no target", query.Model))
 			continue
 		}
-		target = fixIntervalFormat(currTarget)
+		targets[query.RefId] = fixIntervalFormat(currTarget)
 	}
 
-	if target == "" {
+	if len(targets) == 0 && annotationQueries == 0 {
 		glog.Error("No targets in query model", "models without targets", strings.Join(emptyQueries, "\n"))
 		return nil, errors.New("no query target found for the alert rule")
 	}
 
-	formData["target"] = []string{target}
-
-	if setting.Env == setting.Dev {
-		glog.Debug("Graphite request", "params", formData)
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
 	}
 
-	req, err := e.createRequest(dsInfo, formData)
+	return tsdb.ExecuteQueries(ctx, dsInfo, tsdbQuery, func(ctx context.Context, query *tsdb.Query) (*tsdb.QueryResult, error) {
+		if query.QueryType == annotationsQueryType {
+			return e.runAnnotationQuery(ctx, httpClient, dsInfo, query, from, until)
+		}
+
+		target, ok := targets[query.RefId]
+		if !ok {
+			return nil, nil
+		}
+
+		formData := url.Values{
+			"from":          []string{from},
+			"until":         []string{until},
+			"format":        []string{"json"},
+			"maxDataPoints": []string{"500"},
+			"target":        []string{target},
+		}
+		return e.runQuery(ctx, httpClient, dsInfo, formData)
+	}), nil
+}
+
+func (e *GraphiteExecutor) runQuery(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, formData url.Values) (*tsdb.QueryResult, error) {
+	data, err := e.render(ctx, httpClient, dsInfo, formData)
 	if err != nil {
 		return nil, err
 	}
 
-	httpClient, err := dsInfo.GetHttpClient()
+	queryRes := tsdb.NewQueryResult()
+	for _, series := range data {
+		queryRes.Series = append(queryRes.Series, &tsdb.TimeSeries{
+			Name:   series.Target,
+			Points: series.DataPoints,
+			Tags:   formatTags(series.Tags),
+		})
+
+		if setting.Env == setting.Dev {
+			glog.Debug("Graphite response", "target", series.Target, "datapoints", len(series.DataPoints))
+		}
+	}
+
+	return queryRes, nil
+}
+
+// render returns the series of the targets of a render request.
+func (e *GraphiteExecutor) render(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, formData url.Values) ([]TargetResponseDTO, error) {
+	if setting.Env == setting.Dev {
+		glog.Debug("Graphite request", "params", formData)
+	}
+
+	req, err := e.createRequest(dsInfo, formData)
 	if err != nil {
 		return nil, err
 	}
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, "graphite query")
-	span.SetTag("target", target)
-	span.SetTag("from", from)
-	span.SetTag("until", until)
+	span.SetTag("target", formData.Get("target"))
+	span.SetTag("from", formData.Get("from"))
+	span.SetTag("until", formData.Get("until"))
 	span.SetTag("datasource_id", dsInfo.Id)
 	span.SetTag("org_id", dsInfo.OrgId)
 
@@ -120,27 +158,7 @@ func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 		return nil, err
 	}
 
-	data, err := e.parseResponse(res)
-	if err != nil {
-		return nil, err
-	}
-
-	result.Results = make(map[string]*tsdb.QueryResult)
-	queryRes := tsdb.NewQueryResult()
-
-	for _, series := range data {
-		queryRes.Series = append(queryRes.Series, &tsdb.TimeSeries{
-			Name:   series.Target,
-			Points: series.DataPoints,
-		})
-
-		if setting.Env == setting.Dev {
-			glog.Debug("Graphite response", "target", series.Target, "datapoints", len(series.DataPoints))
-		}
-	}
-
-	result.Results["A"] = queryRes
-	return result, nil
+	return e.parseResponse(res)
 }
 
 func (e *GraphiteExecutor) parseResponse(res *http.Response) ([]TargetResponseDTO, error) {
@@ -196,6 +214,23 @@ func (e *GraphiteExecutor) createRequest(dsInfo *models.DataSource, data url.Val
 	return req, err
 }
 
+// formatTags returns the tags of a tagged series as labels.
+func formatTags(tags map[string]interface{}) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if s, ok := v.(string); ok {
+			labels[k] = s
+		} else {
+			labels[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	return labels
+}
+
 func formatTimeRange(input string) string {
 	if input == "now" {
 		return input
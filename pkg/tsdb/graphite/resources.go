@@ -0,0 +1,175 @@
+package graphite
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/coreplugin"
+	"github.com/grafana/grafana/pkg/registry"
+	gocache "github.com/patrickmn/go-cache"
+)
+
+func init() {
+	registry.RegisterService(&resourceService{})
+}
+
+// resourceTTLs lists the Graphite endpoints that are served through the data
+// source resources API, and how long their responses are cached. The list of
+// functions only changes when Graphite is upgraded.
+var resourceTTLs = map[string]time.Duration{
+	"/tags/autoComplete/tags":   time.Minute,
+	"/tags/autoComplete/values": time.Minute,
+	"/metrics/find":             time.Minute,
+	"/functions":                time.Hour,
+}
+
+// getDataSource returns the data source of a resource call. Stubbed by tests.
+var getDataSource = func(pCtx backend.PluginContext) (*models.DataSource, error) {
+	if pCtx.DataSourceInstanceSettings == nil {
+		return nil, fmt.Errorf("missing data source")
+	}
+
+	query := &models.GetDataSourceQuery{Id: pCtx.DataSourceInstanceSettings.ID, OrgId: pCtx.OrgID}
+	if err := bus.Dispatch(query); err != nil {
+		return nil, err
+	}
+	return query.Result, nil
+}
+
+// resourceService serves the Graphite endpoints used by the query editor so
+// that the browser does not need direct access to Graphite.
+type resourceService struct {
+	BackendPluginManager backendplugin.Manager `inject:""`
+
+	logger log.Logger
+	cache  *gocache.Cache
+}
+
+func (s *resourceService) Init() error {
+	s.logger = log.New("tsdb.graphite")
+	s.cache = gocache.New(time.Minute, 10*time.Minute)
+
+	mux := http.NewServeMux()
+	for p := range resourceTTLs {
+		mux.HandleFunc(p, s.handleResource)
+	}
+
+	factory := coreplugin.New(backend.ServeOpts{
+		CallResourceHandler: httpadapter.New(mux),
+	})
+	if err := s.BackendPluginManager.Register("graphite", factory); err != nil {
+		s.logger.Error("Failed to register plugin", "error", err)
+	}
+	return nil
+}
+
+type cachedResource struct {
+	status      int
+	contentType string
+	body        []byte
+}
+
+// handleResource forwards a request to Graphite. Form values of POST
+// requests are sent as query parameters.
+func (s *resourceService) handleResource(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := req.ParseForm(); err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ds, err := getDataSource(httpadapter.PluginConfigFromContext(req.Context()))
+	if err != nil {
+		s.logger.Error("Failed to get data source", "error", err)
+		http.Error(rw, "failed to get data source", http.StatusInternalServerError)
+		return
+	}
+
+	// url.Values.Encode sorts the parameters, which normalizes the key.
+	params := req.Form.Encode()
+	key := fmt.Sprintf("%d:%d:%d:%s?%s", ds.OrgId, ds.Id, ds.Version, req.URL.Path, params)
+	if cached, ok := s.cache.Get(key); ok {
+		writeResource(rw, cached.(*cachedResource), "HIT")
+		return
+	}
+
+	resource, err := s.fetch(req, ds, params)
+	if err != nil {
+		s.logger.Error("Graphite request failed", "path", req.URL.Path, "error", err)
+		http.Error(rw, "graphite request failed", http.StatusBadGateway)
+		return
+	}
+
+	if resource.status/100 == 2 {
+		s.cache.Set(key, resource, resourceTTLs[req.URL.Path])
+	}
+	writeResource(rw, resource, "MISS")
+}
+
+func (s *resourceService) fetch(req *http.Request, ds *models.DataSource, params string) (*cachedResource, error) {
+	u, err := url.Parse(ds.Url)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, req.URL.Path)
+	u.RawQuery = params
+
+	graphiteReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if ds.BasicAuth {
+		graphiteReq.SetBasicAuth(ds.BasicAuthUser, ds.DecryptedBasicAuthPassword())
+	}
+
+	httpClient, err := ds.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := httpClient.Do(graphiteReq)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			s.logger.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cachedResource{
+		status:      res.StatusCode,
+		contentType: res.Header.Get("Content-Type"),
+		body:        body,
+	}, nil
+}
+
+func writeResource(rw http.ResponseWriter, resource *cachedResource, cacheStatus string) {
+	if resource.contentType != "" {
+		rw.Header().Set("Content-Type", resource.contentType)
+	}
+	rw.Header().Set("X-Grafana-Cache", cacheStatus)
+	rw.Header().Set("Content-Length", strconv.Itoa(len(resource.body)))
+	rw.WriteHeader(resource.status)
+	_, _ = rw.Write(resource.body)
+}
@@ -0,0 +1,89 @@
+package graphite
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	gocache "github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceService(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		requests = append(requests, req.URL.String())
+		if req.URL.Path == "/tags/autoComplete/values" {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte(`["a", "b"]`))
+	}))
+	t.Cleanup(server.Close)
+
+	origGetDataSource := getDataSource
+	t.Cleanup(func() { getDataSource = origGetDataSource })
+	getDataSource = func(pCtx backend.PluginContext) (*models.DataSource, error) {
+		return &models.DataSource{Id: 1, OrgId: 1, Url: server.URL, JsonData: simplejson.New()}, nil
+	}
+
+	s := &resourceService{logger: log.New("test"), cache: gocache.New(time.Minute, time.Minute)}
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		rw := httptest.NewRecorder()
+		s.handleResource(rw, req)
+		return rw
+	}
+
+	t.Run("forwards and caches requests", func(t *testing.T) {
+		requests = nil
+
+		rw := do(http.MethodGet, "/tags/autoComplete/tags?tagPrefix=ho&expr=name%3Dcpu", "")
+		require.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, `["a", "b"]`, rw.Body.String())
+		assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+		assert.Equal(t, "MISS", rw.Header().Get("X-Grafana-Cache"))
+
+		// The same parameters in a different order.
+		rw = do(http.MethodGet, "/tags/autoComplete/tags?expr=name%3Dcpu&tagPrefix=ho", "")
+		require.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, "HIT", rw.Header().Get("X-Grafana-Cache"))
+
+		assert.Equal(t, []string{"/tags/autoComplete/tags?expr=name%3Dcpu&tagPrefix=ho"}, requests)
+	})
+
+	t.Run("sends form values as query parameters", func(t *testing.T) {
+		requests = nil
+
+		rw := do(http.MethodPost, "/metrics/find", "query=servers.*")
+		require.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, []string{"/metrics/find?query=servers.%2A"}, requests)
+	})
+
+	t.Run("does not cache failed requests", func(t *testing.T) {
+		requests = nil
+
+		for i := 0; i < 2; i++ {
+			rw := do(http.MethodGet, "/tags/autoComplete/values?tag=host", "")
+			assert.Equal(t, http.StatusInternalServerError, rw.Code)
+		}
+		assert.Len(t, requests, 2)
+	})
+
+	t.Run("rejects other methods", func(t *testing.T) {
+		rw := do(http.MethodDelete, "/functions", "")
+		assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	})
+}
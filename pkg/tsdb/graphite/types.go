@@ -5,4 +5,14 @@ import "github.com/grafana/grafana/pkg/tsdb"
 type TargetResponseDTO struct {
 	Target     string                `json:"target"`
 	DataPoints tsdb.TimeSeriesPoints `json:"datapoints"`
+	// Tags are set for tagged series, for example from seriesByTag.
+	Tags map[string]interface{} `json:"tags"`
+}
+
+// EventDTO is a Graphite event returned by the events API.
+type EventDTO struct {
+	When float64     `json:"when"`
+	What string      `json:"what"`
+	Data string      `json:"data"`
+	Tags interface{} `json:"tags"`
 }
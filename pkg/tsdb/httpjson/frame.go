@@ -0,0 +1,149 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// The types of the fields of a query. The type of fields without one is
+// detected from their values.
+const (
+	fieldTypeString  = "string"
+	fieldTypeNumber  = "number"
+	fieldTypeBoolean = "boolean"
+	fieldTypeTime    = "time"
+)
+
+// newField creates a field of the given type from the values extracted from
+// a response.
+func newField(name, fieldType string, values []interface{}) (*data.Field, error) {
+	if fieldType == "" {
+		fieldType = detectFieldType(values)
+	}
+
+	var field *data.Field
+	switch fieldType {
+	case fieldTypeString:
+		field = data.NewField(name, nil, make([]*string, len(values)))
+	case fieldTypeNumber:
+		field = data.NewField(name, nil, make([]*float64, len(values)))
+	case fieldTypeBoolean:
+		field = data.NewField(name, nil, make([]*bool, len(values)))
+	case fieldTypeTime:
+		field = data.NewField(name, nil, make([]*time.Time, len(values)))
+	default:
+		return nil, fmt.Errorf("field %q has unknown type %q", name, fieldType)
+	}
+
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		converted, err := convertValue(fieldType, v)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", name, err)
+		}
+		field.Set(i, converted)
+	}
+	return field, nil
+}
+
+// detectFieldType returns the type of values which are all numbers or all
+// booleans, and string otherwise.
+func detectFieldType(values []interface{}) string {
+	fieldType := ""
+	for _, v := range values {
+		var t string
+		switch v.(type) {
+		case nil:
+			continue
+		case float64:
+			t = fieldTypeNumber
+		case bool:
+			t = fieldTypeBoolean
+		default:
+			return fieldTypeString
+		}
+		if fieldType != "" && fieldType != t {
+			return fieldTypeString
+		}
+		fieldType = t
+	}
+	if fieldType == "" {
+		return fieldTypeString
+	}
+	return fieldType
+}
+
+func convertValue(fieldType string, v interface{}) (interface{}, error) {
+	switch fieldType {
+	case fieldTypeNumber:
+		switch v := v.(type) {
+		case float64:
+			return &v, nil
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q", v)
+			}
+			return &f, nil
+		}
+	case fieldTypeBoolean:
+		switch v := v.(type) {
+		case bool:
+			return &v, nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid boolean %q", v)
+			}
+			return &b, nil
+		}
+	case fieldTypeTime:
+		t, err := parseTime(v)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	default:
+		if s, ok := v.(string); ok {
+			return &s, nil
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		s := string(b)
+		return &s, nil
+	}
+	return nil, fmt.Errorf("cannot convert %v of type %T to %s", v, v, fieldType)
+}
+
+// parseTime parses RFC 3339 strings and numbers of seconds, milliseconds or
+// nanoseconds since the epoch.
+func parseTime(v interface{}) (time.Time, error) {
+	var epoch float64
+	switch v := v.(type) {
+	case float64:
+		epoch = v
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", v)
+		}
+		epoch = f
+	default:
+		return time.Time{}, fmt.Errorf("cannot convert %v of type %T to time", v, v)
+	}
+
+	ms := tsdb.EpochPrecisionToMs(epoch)
+	return time.Unix(0, int64(ms*float64(time.Millisecond))).UTC(), nil
+}
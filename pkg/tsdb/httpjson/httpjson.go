@@ -0,0 +1,487 @@
+// Package httpjson queries services that return JSON over HTTP and extracts
+// fields from the responses with JSONPath or JMESPath expressions.
+package httpjson
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/jmespath/go-jmespath"
+	gocache "github.com/patrickmn/go-cache"
+)
+
+const (
+	languageJSONPath = "jsonpath"
+	languageJMESPath = "jmespath"
+
+	// maxErrorBodyLength caps how much of an error response ends up in the
+	// error message.
+	maxErrorBodyLength = 512
+	// maxResponseBodyLength caps the size of the responses that are decoded
+	// and cached.
+	maxResponseBodyLength = 10 << 20
+)
+
+var (
+	plog log.Logger
+
+	// responseCache holds the bodies of successful responses of data sources
+	// with a cache TTL, by cacheKey.
+	responseCache = gocache.New(time.Minute, 10*time.Minute)
+)
+
+func init() {
+	plog = log.New("tsdb.httpjson")
+	tsdb.RegisterTsdbQueryEndpoint("httpjson", NewExecutor)
+}
+
+// Executor runs HTTP/JSON queries.
+type Executor struct {
+	client   *http.Client
+	cacheTTL time.Duration
+}
+
+// NewExecutor creates an executor that sends requests with the
+// authentication, headers and TLS settings of the data source.
+func NewExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	transport, err := dsInfo.GetHttpTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	cacheTTL := 0
+	if dsInfo.JsonData != nil {
+		cacheTTL = dsInfo.JsonData.Get("cacheTTL").MustInt(0)
+	}
+
+	return &Executor{
+		client: &http.Client{
+			Timeout:   time.Duration(setting.DataProxyTimeout) * time.Second,
+			Transport: transport,
+		},
+		cacheTTL: time.Duration(cacheTTL) * time.Second,
+	}, nil
+}
+
+// queryModel is the model of a query.
+type queryModel struct {
+	// Method is GET, the default, or POST.
+	Method string `json:"method"`
+	// Path is appended to the URL of the data source and may contain a
+	// query string.
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+	// Language of the paths, jsonpath by default.
+	Language string `json:"language"`
+	// Root is the optional path of the items of the response. Each item is
+	// a row of the frame and the field paths are relative to the items.
+	Root   string       `json:"root"`
+	Fields []fieldModel `json:"fields"`
+}
+
+// fieldModel extracts a field of the response frame.
+type fieldModel struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Type is the type of the field, detected from the values if empty.
+	Type string `json:"type"`
+}
+
+// name returns the name of the field, which defaults to its path.
+func (f fieldModel) name() string {
+	if f.Name == "" {
+		return f.Path
+	}
+	return f.Name
+}
+
+// Query runs the queries of a request.
+func (e *Executor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	return tsdb.ExecuteQueries(ctx, dsInfo, tsdbQuery, func(ctx context.Context, query *tsdb.Query) (*tsdb.QueryResult, error) {
+		model, err := parseQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		interval := time.Duration(query.IntervalMs) * time.Millisecond
+		req, err := e.createRequest(ctx, dsInfo, model, tsdbQuery.TimeRange, interval)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := e.fetch(dsInfo, req)
+		if err != nil {
+			return nil, err
+		}
+
+		var doc interface{}
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+
+		frame, err := buildFrame(query.RefId, model, doc)
+		if err != nil {
+			return nil, err
+		}
+		frame.SetMeta(&data.FrameMeta{ExecutedQueryString: req.Method + " " + req.URL.String()})
+
+		queryResult := tsdb.NewQueryResult()
+		queryResult.Dataframes = tsdb.NewDecodedDataFrames(data.Frames{frame})
+		return queryResult, nil
+	}), nil
+}
+
+func parseQuery(query *tsdb.Query) (*queryModel, error) {
+	model := &queryModel{}
+	if query.Model != nil {
+		b, err := query.Model.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(b, model); err != nil {
+			return nil, fmt.Errorf("failed to parse query: %w", err)
+		}
+	}
+
+	model.Method = strings.ToUpper(model.Method)
+	switch model.Method {
+	case "":
+		model.Method = http.MethodGet
+	case http.MethodGet, http.MethodPost:
+	default:
+		return nil, fmt.Errorf("unsupported method %q, expected GET or POST", model.Method)
+	}
+
+	switch model.Language {
+	case "":
+		model.Language = languageJSONPath
+	case languageJSONPath, languageJMESPath:
+	default:
+		return nil, fmt.Errorf("unsupported language %q, expected %s or %s", model.Language, languageJSONPath, languageJMESPath)
+	}
+	return model, nil
+}
+
+func (e *Executor) createRequest(ctx context.Context, dsInfo *models.DataSource, model *queryModel, timeRange *tsdb.TimeRange, interval time.Duration) (*http.Request, error) {
+	p, err := interpolate(model.Path, timeRange, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	// the path is appended to the URL so that requests can't reach another host
+	u, err := url.Parse(strings.TrimSuffix(dsInfo.Url, "/") + "/" + strings.TrimPrefix(p, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", p, err)
+	}
+
+	var body string
+	if model.Method == http.MethodPost {
+		body, err = interpolate(model.Body, timeRange, interval)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, model.Method, u.String(), strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Grafana")
+	req.Header.Set("Accept", "application/json")
+	if model.Method == http.MethodPost {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, value := range model.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+	return req, nil
+}
+
+// fetch sends a request and returns the body of the response, from the
+// response cache if the data source has a cache TTL.
+func (e *Executor) fetch(dsInfo *models.DataSource, req *http.Request) ([]byte, error) {
+	var key string
+	if e.cacheTTL > 0 {
+		var err error
+		key, err = cacheKey(dsInfo, req)
+		if err != nil {
+			return nil, err
+		}
+		if body, ok := responseCache.Get(key); ok {
+			plog.Debug("Serving response from cache", "method", req.Method, "url", req.URL.String())
+			return body.([]byte), nil
+		}
+	}
+
+	plog.Debug("Sending request", "method", req.Method, "url", req.URL.String())
+	res, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			plog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxResponseBodyLength+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(body))
+		if len(msg) > maxErrorBodyLength {
+			msg = msg[:maxErrorBodyLength] + "..."
+		}
+		if msg == "" {
+			return nil, fmt.Errorf("request failed with status %s", res.Status)
+		}
+		return nil, fmt.Errorf("request failed with status %s: %s", res.Status, msg)
+	}
+	if len(body) > maxResponseBodyLength {
+		return nil, fmt.Errorf("response is larger than %d bytes", maxResponseBodyLength)
+	}
+
+	if key != "" {
+		responseCache.Set(key, body, e.cacheTTL)
+	}
+	return body, nil
+}
+
+// cacheKey identifies a request of a version of a data source.
+func cacheKey(dsInfo *models.DataSource, req *http.Request) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%d\n%s\n%s\n", dsInfo.Id, dsInfo.Version, req.Method, req.URL.String())
+
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(h, "%s: %s\n", name, strings.Join(req.Header[name], ","))
+	}
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		b, err := ioutil.ReadAll(body)
+		if err != nil {
+			return "", err
+		}
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// column holds the values extracted for a field.
+type column struct {
+	name      string
+	fieldType string
+	values    []interface{}
+}
+
+// buildFrame extracts the fields of the query from the decoded response.
+// Without fields, the response, or the items of the root, must be an object
+// or an array of objects and there is a field for each of their keys.
+func buildFrame(refID string, model *queryModel, doc interface{}) (*data.Frame, error) {
+	var columns []column
+	var err error
+	if model.Root != "" {
+		columns, err = itemColumns(model, doc)
+	} else {
+		columns, err = documentColumns(model, doc)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	frame := data.NewFrame(refID)
+	for _, c := range columns {
+		if len(frame.Fields) > 0 && len(c.values) != frame.Fields[0].Len() {
+			return nil, fmt.Errorf("field %q has %d values but field %q has %d", c.name, len(c.values), frame.Fields[0].Name, frame.Fields[0].Len())
+		}
+
+		field, err := newField(c.name, c.fieldType, c.values)
+		if err != nil {
+			return nil, err
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+
+	// alerting only reads wide time series
+	if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+		wide, err := data.LongToWide(frame, nil)
+		if err != nil {
+			plog.Debug("Failed to convert long frame to wide", "err", err)
+			return frame, nil
+		}
+		return wide, nil
+	}
+	return frame, nil
+}
+
+// documentColumns extracts the values of each field from the whole response.
+func documentColumns(model *queryModel, doc interface{}) ([]column, error) {
+	if len(model.Fields) == 0 {
+		return objectColumns(doc), nil
+	}
+
+	columns := make([]column, 0, len(model.Fields))
+	for _, f := range model.Fields {
+		values, err := extract(model.Language, f.Path, doc)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.name(), err)
+		}
+		columns = append(columns, column{name: f.name(), fieldType: f.Type, values: values})
+	}
+	return columns, nil
+}
+
+// itemColumns extracts a value of each field from each item of the root, so
+// that the values of a row come from the same item. The value of a field is
+// null for the items without a match.
+func itemColumns(model *queryModel, doc interface{}) ([]column, error) {
+	items, err := extract(model.Language, model.Root, doc)
+	if err != nil {
+		return nil, fmt.Errorf("root: %w", err)
+	}
+	if len(model.Fields) == 0 {
+		return objectColumns(items), nil
+	}
+
+	columns := make([]column, 0, len(model.Fields))
+	for _, f := range model.Fields {
+		search, err := compilePath(model.Language, f.Path)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.name(), err)
+		}
+
+		values := make([]interface{}, len(items))
+		for i, item := range items {
+			matches, err := search(item)
+			if err != nil {
+				return nil, fmt.Errorf("field %q: %w", f.name(), err)
+			}
+			switch len(matches) {
+			case 0:
+			case 1:
+				values[i] = matches[0]
+			default:
+				return nil, fmt.Errorf("field %q has %d values in item %d", f.name(), len(matches), i)
+			}
+		}
+		columns = append(columns, column{name: f.name(), fieldType: f.Type, values: values})
+	}
+	return columns, nil
+}
+
+// objectColumns returns a column for each key of the objects of the
+// response, which is an object or an array of objects.
+func objectColumns(doc interface{}) []column {
+	var objects []map[string]interface{}
+	switch v := doc.(type) {
+	case []interface{}:
+		for _, o := range v {
+			if obj, ok := o.(map[string]interface{}); ok {
+				objects = append(objects, obj)
+			}
+		}
+	case map[string]interface{}:
+		objects = append(objects, v)
+	}
+
+	seen := map[string]bool{}
+	var keys []string
+	for _, obj := range objects {
+		for key := range obj {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	columns := make([]column, 0, len(keys))
+	for _, key := range keys {
+		values := make([]interface{}, len(objects))
+		for i, obj := range objects {
+			values[i] = obj[key]
+		}
+		columns = append(columns, column{name: key, values: values})
+	}
+	return columns
+}
+
+// extract evaluates a path on the decoded response. A single array result
+// holds the values of the field.
+func extract(language, path string, doc interface{}) ([]interface{}, error) {
+	search, err := compilePath(language, path)
+	if err != nil {
+		return nil, err
+	}
+	values, err := search(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 1 {
+		if arr, ok := values[0].([]interface{}); ok {
+			return arr, nil
+		}
+	}
+	return values, nil
+}
+
+// compilePath compiles a path into a function returning its matches in a
+// decoded JSON document.
+func compilePath(language, path string) (func(doc interface{}) ([]interface{}, error), error) {
+	if language == languageJMESPath {
+		jp, err := jmespath.Compile(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JMESPath %q: %w", path, err)
+		}
+		return func(doc interface{}) ([]interface{}, error) {
+			result, err := jp.Search(doc)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JMESPath %q: %w", path, err)
+			}
+			if result == nil {
+				return nil, nil
+			}
+			return []interface{}{result}, nil
+		}, nil
+	}
+
+	jp, err := compileJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return func(doc interface{}) ([]interface{}, error) {
+		return jp.evaluate(doc), nil
+	}, nil
+}
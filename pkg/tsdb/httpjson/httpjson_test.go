@@ -0,0 +1,282 @@
+package httpjson
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xorcare/pointer"
+)
+
+func TestHTTPJSONExecutor(t *testing.T) {
+	var lastRequest *http.Request
+	var lastBody string
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		lastRequest = r
+		b, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		lastBody = string(b)
+
+		switch r.URL.Path {
+		case "/api/metrics":
+			_, _ = w.Write(loadTestFile(t, "metrics.json"))
+		case "/api/hosts":
+			_, _ = w.Write([]byte(`[{"name": "a", "up": true}, {"name": "b", "cpus": 4}]`))
+		case "/api/large":
+			_, _ = w.Write([]byte(`"` + strings.Repeat("a", maxResponseBodyLength) + `"`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("no such endpoint"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	newDataSource := func(jsonData string) *models.DataSource {
+		json, err := simplejson.NewJson([]byte(jsonData))
+		require.NoError(t, err)
+		return &models.DataSource{
+			Id:       1,
+			Url:      server.URL + "/api",
+			JsonData: json,
+		}
+	}
+
+	runQuery := func(t *testing.T, dsInfo *models.DataSource, model string) *tsdb.QueryResult {
+		t.Helper()
+
+		executor, err := NewExecutor(dsInfo)
+		require.NoError(t, err)
+
+		jsonModel, err := simplejson.NewJson([]byte(model))
+		require.NoError(t, err)
+
+		resp, err := executor.Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1614592800000", "1614596400000"),
+			Queries:   []*tsdb.Query{{RefId: "A", Model: jsonModel, IntervalMs: 60000}},
+		})
+		require.NoError(t, err)
+		return resp.Results["A"]
+	}
+
+	decode := func(t *testing.T, res *tsdb.QueryResult) *data.Frame {
+		t.Helper()
+
+		require.NoError(t, res.Error)
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		return frames[0]
+	}
+
+	dsInfo := newDataSource(`{}`)
+
+	t.Run("GET with JSONPath fields", func(t *testing.T) {
+		frame := decode(t, runQuery(t, dsInfo, `{
+			"path": "/metrics?from=$__from&to=${__to:date:iso}&step=$__interval",
+			"fields": [
+				{"name": "Host", "path": "$.metrics[*].host"},
+				{"name": "Latency", "path": "$..latency", "type": "number"}
+			]
+		}`))
+
+		assert.Equal(t, http.MethodGet, lastRequest.Method)
+		assert.Equal(t, "from=1614592800000&to=2021-03-01T11:00:00.000Z&step=1m", lastRequest.URL.RawQuery)
+		assert.Equal(t, "GET "+server.URL+"/api/metrics?from=1614592800000&to=2021-03-01T11:00:00.000Z&step=1m", frame.Meta.ExecutedQueryString)
+
+		assert.Equal(t, "A", frame.Name)
+		require.Len(t, frame.Fields, 2)
+		assert.Equal(t, "Host", frame.Fields[0].Name)
+		assert.Equal(t, pointer.String("a"), frame.Fields[0].At(0))
+		assert.Equal(t, "Latency", frame.Fields[1].Name)
+		assert.Equal(t, []*float64{pointer.Float64(120.5), pointer.Float64(98), pointer.Float64(130), nil}, []*float64{
+			frame.Fields[1].At(0).(*float64),
+			frame.Fields[1].At(1).(*float64),
+			frame.Fields[1].At(2).(*float64),
+			frame.Fields[1].At(3).(*float64),
+		})
+	})
+
+	t.Run("POST with JMESPath fields", func(t *testing.T) {
+		frame := decode(t, runQuery(t, dsInfo, `{
+			"method": "POST",
+			"path": "metrics",
+			"headers": {"X-Service": "checkout"},
+			"body": "{\"from\": ${__from:date:seconds}, \"to\": ${__to:date:seconds}}",
+			"language": "jmespath",
+			"fields": [
+				{"name": "Time", "path": "metrics[?host=='a'].timestamp", "type": "time"},
+				{"name": "Healthy", "path": "metrics[?host=='a'].healthy"}
+			]
+		}`))
+
+		assert.Equal(t, http.MethodPost, lastRequest.Method)
+		assert.Equal(t, "checkout", lastRequest.Header.Get("X-Service"))
+		assert.Equal(t, "application/json", lastRequest.Header.Get("Content-Type"))
+		assert.Equal(t, `{"from": 1614592800, "to": 1614596400}`, lastBody)
+
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		assert.Equal(t, time.Date(2021, 3, 1, 10, 1, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+		assert.Equal(t, pointer.Bool(false), frame.Fields[1].At(1))
+	})
+
+	t.Run("fields of the objects of the response without fields", func(t *testing.T) {
+		frame := decode(t, runQuery(t, dsInfo, `{"path": "hosts"}`))
+
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, "cpus", frame.Fields[0].Name)
+		assert.Equal(t, []*float64{nil, pointer.Float64(4)}, []*float64{frame.Fields[0].At(0).(*float64), frame.Fields[0].At(1).(*float64)})
+		assert.Equal(t, "name", frame.Fields[1].Name)
+		assert.Equal(t, "up", frame.Fields[2].Name)
+		assert.Equal(t, pointer.Bool(true), frame.Fields[2].At(0))
+	})
+
+	t.Run("fields relative to the items of the root", func(t *testing.T) {
+		frame := decode(t, runQuery(t, dsInfo, `{
+			"path": "hosts",
+			"root": "$",
+			"fields": [
+				{"name": "Name", "path": "$.name"},
+				{"name": "CPUs", "path": "$.cpus"},
+				{"name": "Up", "path": "$.up"}
+			]
+		}`))
+
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, []*string{pointer.String("a"), pointer.String("b")}, []*string{frame.Fields[0].At(0).(*string), frame.Fields[0].At(1).(*string)})
+		assert.Equal(t, []*float64{nil, pointer.Float64(4)}, []*float64{frame.Fields[1].At(0).(*float64), frame.Fields[1].At(1).(*float64)})
+		assert.Equal(t, []*bool{pointer.Bool(true), nil}, []*bool{frame.Fields[2].At(0).(*bool), frame.Fields[2].At(1).(*bool)})
+	})
+
+	t.Run("JMESPath fields relative to the items of the root", func(t *testing.T) {
+		frame := decode(t, runQuery(t, dsInfo, `{
+			"path": "metrics",
+			"root": "metrics[?host=='b']",
+			"language": "jmespath",
+			"fields": [
+				{"name": "Time", "path": "timestamp", "type": "time"},
+				{"name": "Latency", "path": "latency"}
+			]
+		}`))
+
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.Date(2021, 3, 1, 10, 1, 0, 0, time.UTC), *frame.Fields[0].At(1).(*time.Time))
+		assert.Equal(t, []*float64{pointer.Float64(98), nil}, []*float64{frame.Fields[1].At(0).(*float64), frame.Fields[1].At(1).(*float64)})
+	})
+
+	t.Run("fields of the items of the root without fields", func(t *testing.T) {
+		frame := decode(t, runQuery(t, dsInfo, `{"path": "metrics", "root": "$.metrics"}`))
+
+		require.Len(t, frame.Fields, 4)
+		assert.Equal(t, "healthy", frame.Fields[0].Name)
+		assert.Equal(t, 4, frame.Rows())
+	})
+
+	t.Run("fields with several values in an item of the root", func(t *testing.T) {
+		res := runQuery(t, dsInfo, `{
+			"path": "metrics",
+			"root": "$",
+			"fields": [{"name": "Host", "path": "$.metrics[*].host"}]
+		}`)
+		require.EqualError(t, res.Error, `field "Host" has 4 values in item 0`)
+	})
+
+	t.Run("long time series are converted to wide for alerting", func(t *testing.T) {
+		frame := decode(t, runQuery(t, dsInfo, `{
+			"path": "metrics",
+			"fields": [
+				{"name": "Time", "path": "$.metrics[*].timestamp", "type": "time"},
+				{"name": "host", "path": "$.metrics[*].host"},
+				{"name": "Latency", "path": "$.metrics[*].latency", "type": "number"}
+			]
+		}`))
+
+		assert.Equal(t, data.TimeSeriesTypeWide, frame.TimeSeriesSchema().Type)
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, data.Labels{"host": "a"}, frame.Fields[1].Labels)
+		assert.Equal(t, data.Labels{"host": "b"}, frame.Fields[2].Labels)
+		assert.Equal(t, 2, frame.Rows())
+	})
+
+	t.Run("fields with different numbers of values", func(t *testing.T) {
+		res := runQuery(t, dsInfo, `{
+			"path": "metrics",
+			"fields": [
+				{"name": "Service", "path": "$.service"},
+				{"name": "Host", "path": "$.metrics[*].host"}
+			]
+		}`)
+		require.EqualError(t, res.Error, `field "Host" has 4 values but field "Service" has 1`)
+	})
+
+	t.Run("invalid field type", func(t *testing.T) {
+		res := runQuery(t, dsInfo, `{
+			"path": "metrics",
+			"fields": [{"name": "Host", "path": "$.metrics[*].host", "type": "number"}]
+		}`)
+		require.EqualError(t, res.Error, `field "Host": invalid number "a"`)
+	})
+
+	t.Run("failed request", func(t *testing.T) {
+		res := runQuery(t, dsInfo, `{"path": "missing"}`)
+		require.EqualError(t, res.Error, "request failed with status 404 Not Found: no such endpoint")
+	})
+
+	t.Run("response larger than the limit", func(t *testing.T) {
+		res := runQuery(t, dsInfo, `{"path": "large"}`)
+		require.EqualError(t, res.Error, "response is larger than 10485760 bytes")
+	})
+
+	t.Run("unsupported method", func(t *testing.T) {
+		res := runQuery(t, dsInfo, `{"method": "DELETE", "path": "metrics"}`)
+		require.EqualError(t, res.Error, `unsupported method "DELETE", expected GET or POST`)
+	})
+
+	t.Run("responses are cached with a cache TTL", func(t *testing.T) {
+		cachedDsInfo := newDataSource(`{"cacheTTL": 60}`)
+		cachedDsInfo.Id = 2
+		model := `{"path": "hosts?cached=true"}`
+
+		before := requests
+		decode(t, runQuery(t, cachedDsInfo, model))
+		decode(t, runQuery(t, cachedDsInfo, model))
+		assert.Equal(t, before+1, requests)
+
+		// the cache is invalidated when the data source is updated
+		cachedDsInfo.Version++
+		decode(t, runQuery(t, cachedDsInfo, model))
+		assert.Equal(t, before+2, requests)
+
+		// without a cache TTL every query sends a request
+		decode(t, runQuery(t, dsInfo, model))
+		decode(t, runQuery(t, dsInfo, model))
+		assert.Equal(t, before+4, requests)
+	})
+}
+
+func loadTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	// Ignore gosec warning G304 since it's a test
+	// nolint:gosec
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return b
+}
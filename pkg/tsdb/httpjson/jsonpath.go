@@ -0,0 +1,268 @@
+package httpjson
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a compiled JSONPath expression. The supported subset is the
+// root ($), child names (.name and ['name']), wildcards (.* and [*]), array
+// indexes ([0], [-1]), array slices ([1:3]) and recursive descent (..name).
+type jsonPath []jsonPathStep
+
+type jsonPathStep interface {
+	apply(node interface{}) []interface{}
+}
+
+// compileJSONPath parses a JSONPath expression.
+func compileJSONPath(expr string) (jsonPath, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", expr)
+	}
+
+	p := &jsonPathParser{expr: expr, pos: 1}
+	var steps jsonPath
+	for p.pos < len(p.expr) {
+		step, err := p.next()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSONPath %q: %w", expr, err)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// evaluate returns the values matched by the path in the decoded JSON document.
+func (jp jsonPath) evaluate(doc interface{}) []interface{} {
+	nodes := []interface{}{doc}
+	for _, step := range jp {
+		var next []interface{}
+		for _, node := range nodes {
+			next = append(next, step.apply(node)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+type jsonPathParser struct {
+	expr string
+	pos  int
+}
+
+func (p *jsonPathParser) next() (jsonPathStep, error) {
+	switch p.expr[p.pos] {
+	case '.':
+		p.pos++
+		if p.pos < len(p.expr) && p.expr[p.pos] == '.' {
+			p.pos++
+			if p.pos < len(p.expr) && p.expr[p.pos] == '[' {
+				step, err := p.bracket()
+				if err != nil {
+					return nil, err
+				}
+				return recursiveStep{step}, nil
+			}
+			step, err := p.dotted()
+			if err != nil {
+				return nil, err
+			}
+			return recursiveStep{step}, nil
+		}
+		return p.dotted()
+	case '[':
+		return p.bracket()
+	default:
+		return nil, fmt.Errorf("unexpected character %q at position %d", p.expr[p.pos], p.pos)
+	}
+}
+
+// dotted parses the name or wildcard following a dot.
+func (p *jsonPathParser) dotted() (jsonPathStep, error) {
+	start := p.pos
+	for p.pos < len(p.expr) && p.expr[p.pos] != '.' && p.expr[p.pos] != '[' {
+		p.pos++
+	}
+	name := p.expr[start:p.pos]
+	switch name {
+	case "":
+		return nil, fmt.Errorf("missing name at position %d", start)
+	case "*":
+		return wildcardStep{}, nil
+	default:
+		return childStep(name), nil
+	}
+}
+
+// bracket parses a bracketed name, wildcard, index or slice.
+func (p *jsonPathParser) bracket() (jsonPathStep, error) {
+	start := p.pos
+	p.pos++
+	var quote byte
+	for ; p.pos < len(p.expr); p.pos++ {
+		c := p.expr[p.pos]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+			continue
+		}
+		if c == ']' {
+			break
+		}
+	}
+	if p.pos >= len(p.expr) {
+		return nil, fmt.Errorf("missing ] for [ at position %d", start)
+	}
+	content := strings.TrimSpace(p.expr[start+1 : p.pos])
+	p.pos++
+
+	switch {
+	case content == "*":
+		return wildcardStep{}, nil
+	case len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0]:
+		return childStep(content[1 : len(content)-1]), nil
+	case strings.Contains(content, ":"):
+		return parseSlice(content)
+	default:
+		index, err := strconv.Atoi(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %q", content)
+		}
+		return indexStep(index), nil
+	}
+}
+
+func parseSlice(content string) (jsonPathStep, error) {
+	parts := strings.Split(content, ":")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid slice %q", content)
+	}
+
+	var bounds [2]*int
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slice %q", content)
+		}
+		bounds[i] = &n
+	}
+	return sliceStep{start: bounds[0], end: bounds[1]}, nil
+}
+
+type childStep string
+
+func (s childStep) apply(node interface{}) []interface{} {
+	if obj, ok := node.(map[string]interface{}); ok {
+		if v, ok := obj[string(s)]; ok {
+			return []interface{}{v}
+		}
+	}
+	return nil
+}
+
+type wildcardStep struct{}
+
+func (wildcardStep) apply(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			values = append(values, v[key])
+		}
+		return values
+	}
+	return nil
+}
+
+type indexStep int
+
+func (s indexStep) apply(node interface{}) []interface{} {
+	arr, ok := node.([]interface{})
+	if !ok {
+		return nil
+	}
+	i := int(s)
+	if i < 0 {
+		i += len(arr)
+	}
+	if i < 0 || i >= len(arr) {
+		return nil
+	}
+	return []interface{}{arr[i]}
+}
+
+type sliceStep struct {
+	start *int
+	end   *int
+}
+
+func (s sliceStep) apply(node interface{}) []interface{} {
+	arr, ok := node.([]interface{})
+	if !ok {
+		return nil
+	}
+	bound := func(b *int, def int) int {
+		if b == nil {
+			return def
+		}
+		i := *b
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 {
+			return 0
+		}
+		if i > len(arr) {
+			return len(arr)
+		}
+		return i
+	}
+	start, end := bound(s.start, 0), bound(s.end, len(arr))
+	if start >= end {
+		return nil
+	}
+	return arr[start:end]
+}
+
+// recursiveStep applies a step to a node and all of its descendants.
+type recursiveStep struct {
+	step jsonPathStep
+}
+
+func (s recursiveStep) apply(node interface{}) []interface{} {
+	values := s.step.apply(node)
+	switch v := node.(type) {
+	case []interface{}:
+		for _, child := range v {
+			values = append(values, s.apply(child)...)
+		}
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			values = append(values, s.apply(v[key])...)
+		}
+	}
+	return values
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,70 @@
+package httpjson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath(t *testing.T) {
+	var doc interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"store": {
+			"books": [
+				{"title": "Sayings", "price": 8.95, "tags": ["quotes"]},
+				{"title": "Sword", "price": 12.99},
+				{"title": "Moby Dick", "price": 8.99, "isbn": "0-553-21311-3"}
+			],
+			"bicycle": {"color": "red", "price": 19.95},
+			"the store": "downtown"
+		}
+	}`), &doc))
+
+	tests := []struct {
+		path     string
+		expected []interface{}
+	}{
+		{path: "$", expected: []interface{}{doc}},
+		{path: "$.store.bicycle.color", expected: []interface{}{"red"}},
+		{path: "$['store']['the store']", expected: []interface{}{"downtown"}},
+		{path: `$["store"].bicycle["price"]`, expected: []interface{}{19.95}},
+		{path: "$.store.books[*].title", expected: []interface{}{"Sayings", "Sword", "Moby Dick"}},
+		{path: "$.store.books.*.price", expected: []interface{}{8.95, 12.99, 8.99}},
+		{path: "$.store.books[1].title", expected: []interface{}{"Sword"}},
+		{path: "$.store.books[-1].title", expected: []interface{}{"Moby Dick"}},
+		{path: "$.store.books[5].title", expected: nil},
+		{path: "$.store.books[0:2].title", expected: []interface{}{"Sayings", "Sword"}},
+		{path: "$.store.books[1:].title", expected: []interface{}{"Sword", "Moby Dick"}},
+		{path: "$.store.books[:-2].title", expected: []interface{}{"Sayings"}},
+		{path: "$..isbn", expected: []interface{}{"0-553-21311-3"}},
+		{path: "$..price", expected: []interface{}{19.95, 8.95, 12.99, 8.99}},
+		{path: "$..tags[0]", expected: []interface{}{"quotes"}},
+		{path: "$.store.missing", expected: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			jp, err := compileJSONPath(tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, jp.evaluate(doc))
+		})
+	}
+}
+
+func TestInvalidJSONPath(t *testing.T) {
+	tests := map[string]string{
+		"store.books":   `invalid JSONPath "store.books": must start with $`,
+		"$.store.":      `invalid JSONPath "$.store.": missing name at position 8`,
+		"$.store[0":     `invalid JSONPath "$.store[0": missing ] for [ at position 7`,
+		"$.store[one]":  `invalid JSONPath "$.store[one]": invalid index "one"`,
+		"$.store[1:2:]": `invalid JSONPath "$.store[1:2:]": invalid slice "1:2:"`,
+		"$store":        `invalid JSONPath "$store": unexpected character 's' at position 1`,
+	}
+	for path, expected := range tests {
+		t.Run(path, func(t *testing.T) {
+			_, err := compileJSONPath(path)
+			require.EqualError(t, err, expected)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package httpjson
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// isoTimeLayout is the format of the ${__from:date} and ${__to:date} macros.
+const isoTimeLayout = "2006-01-02T15:04:05.000Z"
+
+var (
+	// timeMacroRe matches ${__from}, ${__from:date}, ${__from:date:iso},
+	// ${__from:date:seconds} and the same for __to.
+	timeMacroRe = regexp.MustCompile(`\$\{__(from|to)(:date(?::(iso|seconds))?)?\}`)
+	// shortTimeMacroRe matches $__from and $__to.
+	shortTimeMacroRe  = regexp.MustCompile(`\$__(from|to)\b`)
+	intervalMsMacroRe = regexp.MustCompile(`\$__interval_ms\b`)
+	intervalMacroRe   = regexp.MustCompile(`\$__interval\b`)
+)
+
+// interpolate replaces the time range macros of the URL or the body of a
+// request, like the frontend does with the global variables of the same
+// name, so that queries evaluate the same in alerting.
+func interpolate(s string, timeRange *tsdb.TimeRange, interval time.Duration) (string, error) {
+	from, err := timeRange.ParseFrom()
+	if err != nil {
+		return "", err
+	}
+	to, err := timeRange.ParseTo()
+	if err != nil {
+		return "", err
+	}
+
+	bound := func(name string) time.Time {
+		if name == "from" {
+			return from
+		}
+		return to
+	}
+
+	s = timeMacroRe.ReplaceAllStringFunc(s, func(match string) string {
+		groups := timeMacroRe.FindStringSubmatch(match)
+		t := bound(groups[1])
+		switch {
+		case groups[2] == "":
+			return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+		case groups[3] == "seconds":
+			return strconv.FormatInt(t.Unix(), 10)
+		default:
+			return t.UTC().Format(isoTimeLayout)
+		}
+	})
+	s = shortTimeMacroRe.ReplaceAllStringFunc(s, func(match string) string {
+		t := bound(shortTimeMacroRe.FindStringSubmatch(match)[1])
+		return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+	})
+	s = intervalMsMacroRe.ReplaceAllString(s, strconv.FormatInt(interval.Milliseconds(), 10))
+	s = intervalMacroRe.ReplaceAllString(s, tsdb.FormatDuration(interval))
+	return s, nil
+}
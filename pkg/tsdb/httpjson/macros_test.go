@@ -0,0 +1,30 @@
+package httpjson
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	timeRange := tsdb.NewTimeRange("1614592800000", "1614596400000")
+
+	tests := map[string]string{
+		"from=$__from&to=$__to":                         "from=1614592800000&to=1614596400000",
+		"from=${__from}&to=${__to}":                     "from=1614592800000&to=1614596400000",
+		"from=${__from:date}&to=${__to:date:iso}":       "from=2021-03-01T10:00:00.000Z&to=2021-03-01T11:00:00.000Z",
+		"from=${__from:date:seconds}":                   "from=1614592800",
+		"step=$__interval&step_ms=$__interval_ms":       "step=5m&step_ms=300000",
+		"$__fromDate and ${__from:unknown} and $__time": "$__fromDate and ${__from:unknown} and $__time",
+	}
+	for s, expected := range tests {
+		t.Run(s, func(t *testing.T) {
+			interpolated, err := interpolate(s, timeRange, 5*time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, expected, interpolated)
+		})
+	}
+}
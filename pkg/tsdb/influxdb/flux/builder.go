@@ -230,8 +230,8 @@ func (fb *frameBuilder) Append(record *query.FluxRecord) error {
 	table := getTableID(record, fb.groupKeyColumnNames)
 	if (fb.currentGroupKey == nil) || !isTableIDEqual(table, fb.currentGroupKey) {
 		fb.totalSeries++
-		if fb.totalSeries > fb.maxSeries {
-			return fmt.Errorf("results are truncated, max series reached (%d)", fb.maxSeries)
+		if fb.maxSeries > 0 && fb.totalSeries > fb.maxSeries {
+			return errMaxSeriesReached
 		}
 
 		if fb.isTimeSeries {
@@ -2,6 +2,7 @@ package flux
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -10,8 +11,8 @@ import (
 )
 
 // executeQuery runs a flux query using the queryModel to interpolate the query and the runner to execute it.
-// maxSeries somehow limits the response.
-func executeQuery(ctx context.Context, query queryModel, runner queryRunner, maxSeries int) (dr backend.DataResponse) {
+// The limits truncate the response while it is read.
+func executeQuery(ctx context.Context, query queryModel, runner queryRunner, limits queryLimits) (dr backend.DataResponse) {
 	dr = backend.DataResponse{}
 
 	flux, err := interpolate(query)
@@ -22,12 +23,22 @@ func executeQuery(ctx context.Context, query queryModel, runner queryRunner, max
 
 	glog.Debug("Executing Flux query", "flux", flux)
 
+	if limits.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.timeout)
+		defer cancel()
+	}
+
 	tables, err := runner.runQuery(ctx, flux)
 	if err != nil {
 		glog.Warn("Flux query failed", "err", err, "query", flux)
+		if limits.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			limitsReached.WithLabelValues("timeout").Inc()
+			err = fmt.Errorf("flux query timed out after %s", limits.timeout)
+		}
 		dr.Error = err
 	} else {
-		dr = readDataFrames(tables, int(float64(query.MaxDataPoints)*2), maxSeries)
+		dr = readDataFrames(ctx, tables, int(float64(query.MaxDataPoints)*2), limits)
 	}
 
 	// Make sure there is at least one frame
@@ -42,15 +53,23 @@ func executeQuery(ctx context.Context, query queryModel, runner queryRunner, max
 	return dr
 }
 
-func readDataFrames(result *api.QueryTableResult, maxPoints int, maxSeries int) (dr backend.DataResponse) {
-	glog.Debug("Reading data frames from query result", "maxPoints", maxPoints, "maxSeries", maxSeries)
+func readDataFrames(ctx context.Context, result *api.QueryTableResult, maxPoints int, limits queryLimits) (dr backend.DataResponse) {
+	glog.Debug("Reading data frames from query result", "maxPoints", maxPoints, "limits", limits)
 	dr = backend.DataResponse{}
 
 	builder := &frameBuilder{
 		maxPoints: maxPoints,
-		maxSeries: maxSeries,
+		maxSeries: limits.maxSeries,
 	}
 
+	rows := 0
+	defer func() {
+		rowsProcessed.Add(float64(rows))
+	}()
+
+	// limit and truncated describe the limit that truncated the results, if any
+	var limit, truncated string
+
 	for result.Next() {
 		// Observe when there is new grouping key producing new table
 		if result.TableChanged() {
@@ -71,11 +90,21 @@ func readDataFrames(result *api.QueryTableResult, maxPoints int, maxSeries int)
 			return dr
 		}
 
+		if limits.maxRows > 0 && rows >= limits.maxRows {
+			limit, truncated = "rows", fmt.Sprintf("max rows reached (%d)", limits.maxRows)
+			break
+		}
+
 		err := builder.Append(result.Record())
+		if errors.Is(err, errMaxSeriesReached) {
+			limit, truncated = "series", fmt.Sprintf("max series reached (%d)", limits.maxSeries)
+			break
+		}
 		if err != nil {
 			dr.Error = err
 			break
 		}
+		rows++
 	}
 
 	// Add the inprogress record
@@ -86,8 +115,32 @@ func readDataFrames(result *api.QueryTableResult, maxPoints int, maxSeries int)
 	}
 
 	// result.Err() is probably more important then the other errors
-	if result.Err() != nil {
-		dr.Error = result.Err()
+	if err := result.Err(); err != nil {
+		switch {
+		case errors.Is(err, errMaxBytesReached):
+			limit, truncated = "bytes", fmt.Sprintf("max bytes reached (%d)", limits.maxBytes)
+		case limits.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) && len(dr.Frames) > 0:
+			limit, truncated = "timeout", fmt.Sprintf("query timed out after %s", limits.timeout)
+		default:
+			dr.Error = err
+		}
+	}
+
+	if truncated != "" {
+		// Stop reading the rest of the response
+		if err := result.Close(); err != nil {
+			glog.Warn("Failed to close Flux query result", "err", err)
+		}
+
+		glog.Debug("Flux query results are truncated", "limit", limit, "rows", rows)
+		limitsReached.WithLabelValues(limit).Inc()
+		if len(dr.Frames) == 0 {
+			dr.Frames = append(dr.Frames, data.NewFrame(""))
+		}
+		dr.Frames[0].AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     "results are truncated, " + truncated,
+		})
 	}
 	return dr
 }
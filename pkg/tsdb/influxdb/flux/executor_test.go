@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 // MockRunner reads local file path for testdata.
 type MockRunner struct {
 	testDataPath string
+	maxBytes     int64
 }
 
 func (r *MockRunner) runQuery(ctx context.Context, q string) (*api.QueryTableResult, error) {
@@ -54,7 +56,13 @@ func (r *MockRunner) runQuery(ctx context.Context, q string) (*api.QueryTableRes
 	}))
 	defer server.Close()
 
-	client := influxdb2.NewClient(server.URL, "a")
+	opts := influxdb2.DefaultOptions()
+	if r.maxBytes > 0 {
+		opts.HTTPOptions().SetHTTPClient(&http.Client{
+			Transport: &limitedTransport{transport: http.DefaultTransport, maxBytes: r.maxBytes},
+		})
+	}
+	client := influxdb2.NewClientWithOptions(server.URL, "a", opts)
 	return client.QueryAPI("x").Query(ctx, q)
 }
 
@@ -63,7 +71,7 @@ func verifyGoldenResponse(t *testing.T, name string) *backend.DataResponse {
 		testDataPath: name + ".csv",
 	}
 
-	dr := executeQuery(context.Background(), queryModel{MaxDataPoints: 100}, runner, 50)
+	dr := executeQuery(context.Background(), queryModel{MaxDataPoints: 100}, runner, queryLimits{maxSeries: 50})
 	err := experimental.CheckGoldenDataResponse(filepath.Join("testdata", fmt.Sprintf("%s.golden.txt", name)),
 		&dr, true)
 	require.NoError(t, err)
@@ -218,14 +226,85 @@ func TestRealQuery(t *testing.T) {
 			}),
 		}
 
-		runner, err := runnerFromDataSource(dsInfo)
+		runner, err := runnerFromDataSource(dsInfo, getQueryLimits(dsInfo))
 		require.NoError(t, err)
 
 		dr := executeQuery(context.Background(), queryModel{
 			MaxDataPoints: 100,
 			RawQuery:      "buckets()",
-		}, runner, 50)
+		}, runner, queryLimits{maxSeries: 50})
 		err = experimental.CheckGoldenDataResponse(filepath.Join("testdata", "buckets-real.golden.txt"), &dr, true)
 		require.NoError(t, err)
 	})
 }
+
+func TestExecuteLimits(t *testing.T) {
+	execute := func(t *testing.T, runner *MockRunner, limits queryLimits) backend.DataResponse {
+		t.Helper()
+		dr := executeQuery(context.Background(), queryModel{MaxDataPoints: 100}, runner, limits)
+		require.NoError(t, dr.Error)
+		require.NotEmpty(t, dr.Frames)
+		return dr
+	}
+
+	t.Run("No limit reached", func(t *testing.T) {
+		dr := execute(t, &MockRunner{testDataPath: "multiple.csv", maxBytes: 1500}, queryLimits{maxSeries: 3, maxRows: 6})
+		require.Len(t, dr.Frames, 3)
+		require.Empty(t, dr.Frames[0].Meta.Notices)
+	})
+
+	t.Run("Max rows reached", func(t *testing.T) {
+		dr := execute(t, &MockRunner{testDataPath: "multiple.csv"}, queryLimits{maxSeries: 50, maxRows: 3})
+		require.Len(t, dr.Frames, 2)
+		require.Equal(t, 2, dr.Frames[0].Rows())
+		require.Equal(t, 1, dr.Frames[1].Rows())
+		require.Equal(t, []data.Notice{{
+			Severity: data.NoticeSeverityWarning,
+			Text:     "results are truncated, max rows reached (3)",
+		}}, dr.Frames[0].Meta.Notices)
+	})
+
+	t.Run("Max series reached", func(t *testing.T) {
+		dr := execute(t, &MockRunner{testDataPath: "multiple.csv"}, queryLimits{maxSeries: 2})
+		require.Len(t, dr.Frames, 2)
+		require.Equal(t, []data.Notice{{
+			Severity: data.NoticeSeverityWarning,
+			Text:     "results are truncated, max series reached (2)",
+		}}, dr.Frames[0].Meta.Notices)
+	})
+
+	t.Run("Max bytes reached", func(t *testing.T) {
+		dr := execute(t, &MockRunner{testDataPath: "multiple.csv", maxBytes: 1000}, queryLimits{maxSeries: 50, maxBytes: 1000})
+		require.Len(t, dr.Frames, 2)
+		require.Equal(t, []data.Notice{{
+			Severity: data.NoticeSeverityWarning,
+			Text:     "results are truncated, max bytes reached (1000)",
+		}}, dr.Frames[0].Meta.Notices)
+	})
+}
+
+func TestLimitedReadCloser(t *testing.T) {
+	read := func(body string, maxBytes int64) (string, error) {
+		r := &limitedReadCloser{ReadCloser: ioutil.NopCloser(strings.NewReader(body)), remaining: maxBytes}
+		b, err := ioutil.ReadAll(r)
+		return string(b), err
+	}
+
+	t.Run("Body shorter than the limit", func(t *testing.T) {
+		b, err := read("abc", 5)
+		require.NoError(t, err)
+		require.Equal(t, "abc", b)
+	})
+
+	t.Run("Body of exactly the limit", func(t *testing.T) {
+		b, err := read("abcde", 5)
+		require.NoError(t, err)
+		require.Equal(t, "abcde", b)
+	})
+
+	t.Run("Body longer than the limit", func(t *testing.T) {
+		b, err := read("abcdef", 5)
+		require.ErrorIs(t, err, errMaxBytesReached)
+		require.Equal(t, "abcde", b)
+	})
+}
@@ -26,7 +26,8 @@ func Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQ
 	tRes := &tsdb.Response{
 		Results: make(map[string]*tsdb.QueryResult),
 	}
-	r, err := runnerFromDataSource(dsInfo)
+	limits := getQueryLimits(dsInfo)
+	r, err := runnerFromDataSource(dsInfo, limits)
 	if err != nil {
 		return nil, err
 	}
@@ -39,9 +40,7 @@ func Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQ
 			continue
 		}
 
-		// If the default changes also update labels/placeholder in config page.
-		maxSeries := dsInfo.JsonData.Get("maxSeries").MustInt(1000)
-		res := executeQuery(ctx, *qm, r, maxSeries)
+		res := executeQuery(ctx, *qm, r, limits)
 
 		tRes.Results[query.RefId] = backendDataResponseToTSDBResponse(&res, query.RefId)
 	}
@@ -67,7 +66,8 @@ func (r *runner) runQuery(ctx context.Context, fluxQuery string) (*api.QueryTabl
 }
 
 // runnerFromDataSource creates a runner from the datasource model (the datasource instance's configuration).
-func runnerFromDataSource(dsInfo *models.DataSource) (*runner, error) {
+// The size of the responses read by the runner is limited by limits.maxBytes.
+func runnerFromDataSource(dsInfo *models.DataSource, limits queryLimits) (*runner, error) {
 	org := dsInfo.JsonData.Get("organization").MustString("")
 	if org == "" {
 		return nil, fmt.Errorf("missing organization in datasource configuration")
@@ -87,6 +87,7 @@ func runnerFromDataSource(dsInfo *models.DataSource) (*runner, error) {
 	if err != nil {
 		return nil, err
 	}
+	hc.Transport = &limitedTransport{transport: hc.Transport, maxBytes: limits.maxBytes}
 	opts.HTTPOptions().SetHTTPClient(hc)
 	return &runner{
 		client: influxdb2.NewClientWithOptions(url, token, opts),
@@ -0,0 +1,86 @@
+package flux
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// If the defaults change also update labels/placeholder in config page.
+const (
+	defaultMaxSeries = 1000
+	defaultMaxRows   = 1000000
+	defaultMaxBytes  = 100 * 1024 * 1024
+)
+
+var (
+	errMaxSeriesReached = errors.New("max series reached")
+	errMaxBytesReached  = errors.New("max bytes reached")
+)
+
+// queryLimits are the limits of a single Flux query. Results are truncated
+// when a limit is reached while reading the response.
+type queryLimits struct {
+	maxSeries int
+	maxRows   int
+	maxBytes  int64
+	// timeout is the maximum duration of the query, no limit when zero.
+	timeout time.Duration
+}
+
+// getQueryLimits reads the query limits from the datasource configuration.
+func getQueryLimits(dsInfo *models.DataSource) queryLimits {
+	return queryLimits{
+		maxSeries: dsInfo.JsonData.Get("maxSeries").MustInt(defaultMaxSeries),
+		maxRows:   dsInfo.JsonData.Get("maxRows").MustInt(defaultMaxRows),
+		maxBytes:  dsInfo.JsonData.Get("maxBytes").MustInt64(defaultMaxBytes),
+		timeout:   time.Duration(dsInfo.JsonData.Get("queryTimeout").MustInt(0)) * time.Second,
+	}
+}
+
+// limitedTransport limits the size of the bodies of the responses of a round tripper.
+type limitedTransport struct {
+	transport http.RoundTripper
+	maxBytes  int64
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.transport.RoundTrip(req)
+	if err != nil || t.maxBytes <= 0 {
+		return res, err
+	}
+	res.Body = &limitedReadCloser{ReadCloser: res.Body, remaining: t.maxBytes}
+	return res, nil
+}
+
+// limitedReadCloser fails with errMaxBytesReached when the body is longer
+// than the remaining bytes.
+type limitedReadCloser struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (r *limitedReadCloser) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// a body of exactly the maximum size is not truncated
+		var b [1]byte
+		for {
+			n, err := r.ReadCloser.Read(b[:])
+			if n > 0 {
+				return 0, errMaxBytesReached
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
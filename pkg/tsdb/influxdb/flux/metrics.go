@@ -0,0 +1,25 @@
+package flux
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	rowsProcessed = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "datasource_flux",
+		Name:      "rows_processed_total",
+		Help:      "Total number of rows read from Flux query responses",
+	})
+
+	limitsReached = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "datasource_flux",
+		Name:      "limits_reached_total",
+		Help:      "Total number of Flux queries truncated because a limit was reached, by limit",
+	}, []string{"limit"})
+)
+
+func init() {
+	prometheus.MustRegister(rowsProcessed, limitsReached)
+}
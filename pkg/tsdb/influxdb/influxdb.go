@@ -10,6 +10,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
@@ -54,7 +55,18 @@ func (e *InfluxDBExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 	// NOTE: the following path is currently only called from alerting queries
 	// In dashboards, the request runs through proxy and are managed in the frontend
 
-	query, err := e.getQuery(dsInfo, tsdbQuery.Queries, tsdbQuery)
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return tsdb.ExecuteQueries(ctx, dsInfo, tsdbQuery, func(ctx context.Context, q *tsdb.Query) (*tsdb.QueryResult, error) {
+		return e.runQuery(ctx, httpClient, dsInfo, q, tsdbQuery)
+	}), nil
+}
+
+func (e *InfluxDBExecutor) runQuery(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, q *tsdb.Query, tsdbQuery *tsdb.TsdbQuery) (*tsdb.QueryResult, error) {
+	query, err := e.QueryParser.Parse(q.Model, dsInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -73,11 +85,6 @@ func (e *InfluxDBExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 		return nil, err
 	}
 
-	httpClient, err := dsInfo.GetHttpClient()
-	if err != nil {
-		return nil, err
-	}
-
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -97,29 +104,23 @@ func (e *InfluxDBExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 	if err := dec.Decode(&response); err != nil {
 		return nil, err
 	}
-	if response.Err != nil {
-		return nil, response.Err
-	}
-
-	result := &tsdb.Response{}
-	result.Results = make(map[string]*tsdb.QueryResult)
-	result.Results["A"] = e.ResponseParser.Parse(&response, query)
-
-	return result, nil
-}
-
-func (e *InfluxDBExecutor) getQuery(dsInfo *models.DataSource, queries []*tsdb.Query, context *tsdb.TsdbQuery) (*Query, error) {
-	if len(queries) == 0 {
-		return nil, fmt.Errorf("query request contains no queries")
+	if err := response.error(); err != nil {
+		return nil, err
 	}
 
-	// The model supports multiple queries, but right now this is only used from
-	// alerting so we only needed to support batch executing 1 query at a time.
-	query, err := e.QueryParser.Parse(queries[0].Model, dsInfo)
+	queryRes := e.ResponseParser.Parse(&response, query)
+	frames, err := queryRes.Dataframes.Decoded()
 	if err != nil {
 		return nil, err
 	}
-	return query, nil
+	if len(frames) > 0 {
+		if frames[0].Meta == nil {
+			frames[0].Meta = &data.FrameMeta{}
+		}
+		frames[0].Meta.ExecutedQueryString = rawQuery
+	}
+
+	return queryRes, nil
 }
 
 func (e *InfluxDBExecutor) createRequest(ctx context.Context, dsInfo *models.DataSource, query string) (*http.Request, error) {
@@ -154,7 +155,7 @@ func (e *InfluxDBExecutor) createRequest(ctx context.Context, dsInfo *models.Dat
 
 	params := req.URL.Query()
 	params.Set("db", dsInfo.Database)
-	params.Set("epoch", "s")
+	params.Set("epoch", "ms")
 
 	if httpMode == "GET" {
 		params.Set("q", query)
@@ -1,6 +1,9 @@
 package influxdb
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type Query struct {
 	Measurement  string
@@ -27,13 +30,37 @@ type Select []QueryPart
 
 type Response struct {
 	Results []Result
-	Err     error
+	Err     error  `json:"-"`
+	Error   string `json:"error,omitempty"`
 }
 
+func (r *Response) error() error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if r.Error != "" {
+		return errors.New(r.Error)
+	}
+	return nil
+}
+
+// Result is the result of a single statement of a query.
 type Result struct {
-	Series   []Row
-	Messages []*Message
-	Err      error
+	StatementID int `json:"statement_id"`
+	Series      []Row
+	Messages    []*Message
+	Err         error  `json:"-"`
+	Error       string `json:"error,omitempty"`
+}
+
+func (r *Result) error() error {
+	if r.Err != nil {
+		return r.Err
+	}
+	if r.Error != "" {
+		return errors.New(r.Error)
+	}
+	return nil
 }
 
 type Message struct {
@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
-	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/tsdb"
 )
 
@@ -21,43 +23,213 @@ func init() {
 	legendFormat = regexp.MustCompile(`\[\[(\w+)(\.\w+)*\]\]*|\$\s*(\w+?)*`)
 }
 
+// Parse converts the results of the statements of a query to data frames.
+// Rows with a time column become one time series frame per value column,
+// unless the query uses the table format. Rows without a time column, like
+// the results of SHOW statements, always become table frames.
 func (rp *ResponseParser) Parse(response *Response, query *Query) *tsdb.QueryResult {
 	queryRes := tsdb.NewQueryResult()
+	frames := data.Frames{}
 
 	for _, result := range response.Results {
-		queryRes.Series = append(queryRes.Series, rp.transformRows(result.Series, queryRes, query)...)
-		if result.Err != nil {
-			queryRes.Error = result.Err
+		frames = append(frames, rp.transformRows(result.Series, query)...)
+		if err := result.error(); err != nil {
+			queryRes.Error = err
 		}
 	}
 
+	queryRes.Dataframes = tsdb.NewDecodedDataFrames(frames)
 	return queryRes
 }
 
-func (rp *ResponseParser) transformRows(rows []Row, queryResult *tsdb.QueryResult, query *Query) tsdb.TimeSeriesSlice {
-	var result tsdb.TimeSeriesSlice
+func (rp *ResponseParser) transformRows(rows []Row, query *Query) data.Frames {
+	frames := data.Frames{}
+	var table []Row
+
 	for _, row := range rows {
-		for columnIndex, column := range row.Columns {
-			if column == "time" {
+		if query.ResultFormat != "table" && len(row.Columns) > 0 && row.Columns[0] == "time" {
+			frames = append(frames, rp.timeSeriesFrames(row, query)...)
+			continue
+		}
+
+		// Rows of the same statement, for example one per GROUP BY tag
+		// value, share a table as long as they have the same columns.
+		if len(table) > 0 && !sameColumns(table[0].Columns, row.Columns) {
+			frames = append(frames, rp.tableFrame(table))
+			table = nil
+		}
+		table = append(table, row)
+	}
+
+	if len(table) > 0 {
+		frames = append(frames, rp.tableFrame(table))
+	}
+
+	return frames
+}
+
+// timeSeriesFrames returns a frame for each value column of a row, labeled
+// with the tags of the row.
+func (rp *ResponseParser) timeSeriesFrames(row Row, query *Query) data.Frames {
+	frames := make(data.Frames, 0, len(row.Columns)-1)
+
+	times := make([]time.Time, 0, len(row.Values))
+	for _, values := range row.Values {
+		t, err := parseTimestamp(values[0])
+		if err != nil {
+			glog.Debug("Skipping value with invalid timestamp", "err", err)
+			continue
+		}
+		times = append(times, t)
+	}
+
+	for columnIndex, column := range row.Columns {
+		if column == "time" {
+			continue
+		}
+
+		columnValues := make([]interface{}, 0, len(row.Values))
+		for _, values := range row.Values {
+			if _, err := parseTimestamp(values[0]); err != nil {
 				continue
 			}
+			columnValues = append(columnValues, values[columnIndex])
+		}
+
+		name := rp.formatSeriesName(row, column, query)
+		valueField := newValueField(column, columnValues)
+		valueField.Name = "value"
+		valueField.Labels = data.Labels(row.Tags)
+		valueField.Config = &data.FieldConfig{DisplayNameFromDS: name}
+
+		frames = append(frames, data.NewFrame(name, data.NewField("time", nil, times), valueField))
+	}
+
+	return frames
+}
+
+// tableFrame returns a table with a column for each tag and each column of
+// the rows.
+func (rp *ResponseParser) tableFrame(rows []Row) *data.Frame {
+	tagKeys := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for k := range row.Tags {
+			if !seen[k] {
+				seen[k] = true
+				tagKeys = append(tagKeys, k)
+			}
+		}
+	}
+	sort.Strings(tagKeys)
+
+	columns := rows[0].Columns
+	fields := make([]*data.Field, 0, len(tagKeys)+len(columns))
+
+	for columnIndex, column := range columns {
+		columnValues := make([]interface{}, 0)
+		for _, row := range rows {
+			for _, values := range row.Values {
+				columnValues = append(columnValues, values[columnIndex])
+			}
+		}
 
-			var points tsdb.TimeSeriesPoints
-			for _, valuePair := range row.Values {
-				point, err := rp.parseTimepoint(valuePair, columnIndex)
-				if err == nil {
-					points = append(points, point)
+		if column == "time" {
+			times := make([]*time.Time, len(columnValues))
+			for i, v := range columnValues {
+				if t, err := parseTimestamp(v); err == nil {
+					times[i] = &t
 				}
 			}
-			result = append(result, &tsdb.TimeSeries{
-				Name:   rp.formatSeriesName(row, column, query),
-				Points: points,
-				Tags:   row.Tags,
-			})
+			fields = append(fields, data.NewField(column, nil, times))
+			// Tags follow the time column like in the InfluxDB CLI.
+			fields = append(fields, tagFields(rows, tagKeys)...)
+			continue
+		}
+
+		if columnIndex == 0 {
+			fields = append(fields, tagFields(rows, tagKeys)...)
 		}
+		fields = append(fields, newValueField(column, columnValues))
 	}
 
-	return result
+	return data.NewFrame(rows[0].Name, fields...)
+}
+
+// tagFields returns a field for each tag key holding the tag value of the
+// row of each value.
+func tagFields(rows []Row, tagKeys []string) []*data.Field {
+	fields := make([]*data.Field, 0, len(tagKeys))
+	for _, k := range tagKeys {
+		values := make([]string, 0)
+		for _, row := range rows {
+			for range row.Values {
+				values = append(values, row.Tags[k])
+			}
+		}
+		fields = append(fields, data.NewField(k, nil, values))
+	}
+	return fields
+}
+
+// newValueField returns a field for the values of a column. Columns holding
+// only numbers or only booleans get a field of that type, any other column
+// becomes a string field.
+func newValueField(name string, values []interface{}) *data.Field {
+	isNumber, isBool := true, true
+	for _, v := range values {
+		switch v.(type) {
+		case nil:
+		case json.Number:
+			isBool = false
+		case bool:
+			isNumber = false
+		default:
+			isNumber, isBool = false, false
+		}
+	}
+
+	switch {
+	case isNumber:
+		floats := make([]*float64, len(values))
+		for i, v := range values {
+			floats[i] = parseValue(v)
+		}
+		return data.NewField(name, nil, floats)
+	case isBool:
+		bools := make([]*bool, len(values))
+		for i, v := range values {
+			if b, ok := v.(bool); ok {
+				bools[i] = &b
+			}
+		}
+		return data.NewField(name, nil, bools)
+	}
+
+	strs := make([]*string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+		case string:
+			strs[i] = &v
+		default:
+			s := fmt.Sprintf("%v", v)
+			strs[i] = &s
+		}
+	}
+	return data.NewField(name, nil, strs)
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
 }
 
 func (rp *ResponseParser) formatSeriesName(row Row, column string, query *Query) string {
@@ -102,9 +274,15 @@ func (rp *ResponseParser) formatSeriesName(row Row, column string, query *Query)
 }
 
 func (rp *ResponseParser) buildSeriesNameFromQuery(row Row, column string) string {
+	keys := make([]string, 0, len(row.Tags))
+	for k := range row.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
 	var tags []string
-	for k, v := range row.Tags {
-		tags = append(tags, fmt.Sprintf("%s: %s", k, v))
+	for _, k := range keys {
+		tags = append(tags, fmt.Sprintf("%s: %s", k, row.Tags[k]))
 	}
 
 	tagText := ""
@@ -115,36 +293,36 @@ func (rp *ResponseParser) buildSeriesNameFromQuery(row Row, column string) strin
 	return fmt.Sprintf("%s.%s%s", row.Name, column, tagText)
 }
 
-func (rp *ResponseParser) parseTimepoint(valuePair []interface{}, valuePosition int) (tsdb.TimePoint, error) {
-	value := rp.parseValue(valuePair[valuePosition])
-
-	timestampNumber, ok := valuePair[0].(json.Number)
+// parseTimestamp parses a timestamp in milliseconds since epoch.
+func parseTimestamp(value interface{}) (time.Time, error) {
+	timestampNumber, ok := value.(json.Number)
 	if !ok {
-		return tsdb.TimePoint{}, fmt.Errorf("valuePair[0] has invalid type: %#v", valuePair[0])
+		return time.Time{}, fmt.Errorf("timestamp has invalid type: %#v", value)
 	}
-	timestamp, err := timestampNumber.Float64()
+	timestamp, err := timestampNumber.Int64()
 	if err != nil {
-		return tsdb.TimePoint{}, err
+		return time.Time{}, err
 	}
 
-	return tsdb.NewTimePoint(value, timestamp), nil
+	return time.Unix(0, timestamp*int64(time.Millisecond)).UTC(), nil
 }
 
-func (rp *ResponseParser) parseValue(value interface{}) null.Float {
+func parseValue(value interface{}) *float64 {
 	number, ok := value.(json.Number)
 	if !ok {
-		return null.FloatFromPtr(nil)
+		return nil
 	}
 
 	fvalue, err := number.Float64()
 	if err == nil {
-		return null.FloatFrom(fvalue)
+		return &fvalue
 	}
 
 	ivalue, err := number.Int64()
 	if err == nil {
-		return null.FloatFrom(float64(ivalue))
+		fvalue = float64(ivalue)
+		return &fvalue
 	}
 
-	return null.FloatFromPtr(nil)
+	return nil
 }
@@ -4,8 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -42,28 +45,29 @@ func TestInfluxdbResponseParser(t *testing.T) {
 			query := &Query{}
 
 			result := parser.Parse(response, query)
+			frames := decodeFrames(result)
 
 			Convey("can parse all series", func() {
-				So(len(result.Series), ShouldEqual, 2)
+				So(frames, ShouldHaveLength, 2)
 			})
 
 			Convey("can parse all points", func() {
-				So(len(result.Series[0].Points), ShouldEqual, 3)
-				So(len(result.Series[1].Points), ShouldEqual, 3)
+				So(frames[0].Rows(), ShouldEqual, 3)
+				So(frames[1].Rows(), ShouldEqual, 3)
 			})
 
 			Convey("can parse multi row result", func() {
-				So(result.Series[0].Points[1][0].Float64, ShouldEqual, float64(222))
-				So(result.Series[1].Points[1][0].Float64, ShouldEqual, float64(333))
+				So(*frames[0].Fields[1].At(1).(*float64), ShouldEqual, float64(222))
+				So(*frames[1].Fields[1].At(1).(*float64), ShouldEqual, float64(333))
 			})
 
 			Convey("can parse null points", func() {
-				So(result.Series[0].Points[2][0].Valid, ShouldBeFalse)
+				So(frames[0].Fields[1].At(2).(*float64), ShouldBeNil)
 			})
 
 			Convey("can format series names", func() {
-				So(result.Series[0].Name, ShouldEqual, "cpu.mean { datacenter: America }")
-				So(result.Series[1].Name, ShouldEqual, "cpu.sum { datacenter: America }")
+				So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "cpu.mean { datacenter: America }")
+				So(frames[1].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "cpu.sum { datacenter: America }")
 			})
 		})
 
@@ -94,44 +98,50 @@ func TestInfluxdbResponseParser(t *testing.T) {
 				Convey("simple alias", func() {
 					query := &Query{Alias: "series alias"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "series alias")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "series alias")
 				})
 
 				Convey("measurement alias", func() {
 					query := &Query{Alias: "alias $m $measurement", Measurement: "10m"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "alias 10m 10m")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias 10m 10m")
 				})
 
 				Convey("column alias", func() {
 					query := &Query{Alias: "alias $col", Measurement: "10m"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "alias mean")
-					So(result.Series[1].Name, ShouldEqual, "alias sum")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias mean")
+					So(frames[1].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias sum")
 				})
 
 				Convey("tag alias", func() {
 					query := &Query{Alias: "alias $tag_datacenter"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "alias America")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias America")
 				})
 
 				Convey("segment alias", func() {
 					query := &Query{Alias: "alias $1"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "alias upc")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias upc")
 				})
 
 				Convey("segment position out of bound", func() {
 					query := &Query{Alias: "alias $5"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "alias $5")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias $5")
 				})
 			})
 
@@ -139,37 +149,42 @@ func TestInfluxdbResponseParser(t *testing.T) {
 				Convey("simple alias", func() {
 					query := &Query{Alias: "series alias"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "series alias")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "series alias")
 				})
 
 				Convey("measurement alias", func() {
 					query := &Query{Alias: "alias [[m]] [[measurement]]", Measurement: "10m"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "alias 10m 10m")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias 10m 10m")
 				})
 
 				Convey("column alias", func() {
 					query := &Query{Alias: "alias [[col]]", Measurement: "10m"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "alias mean")
-					So(result.Series[1].Name, ShouldEqual, "alias sum")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias mean")
+					So(frames[1].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias sum")
 				})
 
 				Convey("tag alias", func() {
 					query := &Query{Alias: "alias [[tag_datacenter]]"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "alias America")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias America")
 				})
 
 				Convey("tag alias with periods", func() {
 					query := &Query{Alias: "alias [[tag_dc.region.name]]"}
 					result := parser.Parse(response, query)
+					frames := decodeFrames(result)
 
-					So(result.Series[0].Name, ShouldEqual, "alias Northeast")
+					So(frames[0].Fields[1].Config.DisplayNameFromDS, ShouldEqual, "alias Northeast")
 				})
 			})
 		})
@@ -208,14 +223,15 @@ func TestInfluxdbResponseParser(t *testing.T) {
 			query := &Query{}
 
 			result := parser.Parse(response, query)
+			frames := decodeFrames(result)
 
 			Convey("can parse all series", func() {
-				So(len(result.Series), ShouldEqual, 2)
+				So(frames, ShouldHaveLength, 2)
 			})
 
 			Convey("can parse all points", func() {
-				So(len(result.Series[0].Points), ShouldEqual, 3)
-				So(len(result.Series[1].Points), ShouldEqual, 3)
+				So(frames[0].Rows(), ShouldEqual, 3)
+				So(frames[1].Rows(), ShouldEqual, 3)
 			})
 
 			Convey("can parse errors ", func() {
@@ -223,5 +239,130 @@ func TestInfluxdbResponseParser(t *testing.T) {
 				So(result.Error.Error(), ShouldEqual, "query-timeout limit exceeded")
 			})
 		})
+
+		Convey("Response parser with string fields", func() {
+			parser := &ResponseParser{}
+
+			response := &Response{
+				Results: []Result{
+					{
+						Series: []Row{
+							{
+								Name:    "logs",
+								Columns: []string{"time", "message", "ok"},
+								Tags:    map[string]string{"host": "server-1"},
+								Values: [][]interface{}{
+									{json.Number("1000"), "started", true},
+									{json.Number("2000"), nil, false},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			result := parser.Parse(response, &Query{})
+			frames := decodeFrames(result)
+
+			So(frames, ShouldHaveLength, 2)
+			So(frames[0].Fields[0].At(1), ShouldEqual, time.Unix(2, 0).UTC())
+			So(*frames[0].Fields[1].At(0).(*string), ShouldEqual, "started")
+			So(frames[0].Fields[1].At(1).(*string), ShouldBeNil)
+			So(frames[0].Fields[1].Labels, ShouldResemble, data.Labels{"host": "server-1"})
+			So(*frames[1].Fields[1].At(1).(*bool), ShouldBeFalse)
+		})
+
+		Convey("Response parser with table format", func() {
+			parser := &ResponseParser{}
+
+			response := &Response{
+				Results: []Result{
+					{
+						Series: []Row{
+							{
+								Name:    "cpu",
+								Columns: []string{"time", "mean"},
+								Tags:    map[string]string{"datacenter": "America"},
+								Values: [][]interface{}{
+									{json.Number("1000"), json.Number("1.5")},
+								},
+							},
+							{
+								Name:    "cpu",
+								Columns: []string{"time", "mean"},
+								Tags:    map[string]string{"datacenter": "Europe"},
+								Values: [][]interface{}{
+									{json.Number("1000"), json.Number("2.5")},
+									{json.Number("2000"), json.Number("3.5")},
+								},
+							},
+						},
+					},
+				},
+			}
+
+			result := parser.Parse(response, &Query{ResultFormat: "table"})
+			frames := decodeFrames(result)
+
+			So(frames, ShouldHaveLength, 1)
+			frame := frames[0]
+			So(frame.Rows(), ShouldEqual, 3)
+			So(frame.Fields, ShouldHaveLength, 3)
+			So(frame.Fields[0].Name, ShouldEqual, "time")
+			So(frame.Fields[1].Name, ShouldEqual, "datacenter")
+			So(frame.Fields[2].Name, ShouldEqual, "mean")
+			So(frame.Fields[1].At(0), ShouldEqual, "America")
+			So(frame.Fields[1].At(2), ShouldEqual, "Europe")
+			So(*frame.Fields[2].At(2).(*float64), ShouldEqual, 3.5)
+		})
+
+		Convey("Response parser with multiple statements", func() {
+			parser := &ResponseParser{}
+
+			response := &Response{
+				Results: []Result{
+					{
+						StatementID: 0,
+						Series: []Row{
+							{
+								Name:    "measurements",
+								Columns: []string{"name"},
+								Values:  [][]interface{}{{"cpu"}, {"mem"}},
+							},
+						},
+					},
+					{
+						StatementID: 1,
+						Series: []Row{
+							{
+								Name:    "cpu",
+								Columns: []string{"fieldKey", "fieldType"},
+								Values:  [][]interface{}{{"usage", "float"}},
+							},
+						},
+					},
+					{
+						StatementID: 2,
+						Error:       "measurement not found",
+					},
+				},
+			}
+
+			result := parser.Parse(response, &Query{ResultFormat: "time_series"})
+			frames := decodeFrames(result)
+
+			So(frames, ShouldHaveLength, 2)
+			So(frames[0].Name, ShouldEqual, "measurements")
+			So(*frames[0].Fields[0].At(1).(*string), ShouldEqual, "mem")
+			So(frames[1].Fields[1].Name, ShouldEqual, "fieldType")
+			So(result.Error, ShouldNotBeNil)
+			So(result.Error.Error(), ShouldEqual, "measurement not found")
+		})
 	})
 }
+
+func decodeFrames(result *tsdb.QueryResult) data.Frames {
+	frames, err := result.Dataframes.Decoded()
+	So(err, ShouldBeNil)
+	return frames
+}
@@ -0,0 +1,68 @@
+package jaeger
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/tracing"
+)
+
+func NewExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	return &tracing.Executor{Backend: backend{}, Logger: plog}, nil
+}
+
+var plog log.Logger
+
+func init() {
+	plog = log.New("tsdb.jaeger")
+	tsdb.RegisterTsdbQueryEndpoint("jaeger", NewExecutor)
+}
+
+// backend reads traces from the Jaeger query API.
+type backend struct{}
+
+func (backend) GetTrace(ctx context.Context, client *http.Client, dsInfo *models.DataSource, traceID string) ([]*tracing.Span, error) {
+	return GetTrace(ctx, client, dsInfo, traceID)
+}
+
+func (backend) SearchTraces(ctx context.Context, client *http.Client, dsInfo *models.DataSource, query *tracing.Query) ([]tracing.TraceSummary, error) {
+	params := url.Values{}
+	params.Set("service", query.Service)
+	if query.Operation != "" {
+		params.Set("operation", query.Operation)
+	}
+	params.Set("start", strconv.FormatInt(query.Start.UnixNano()/1000, 10))
+	params.Set("end", strconv.FormatInt(query.End.UnixNano()/1000, 10))
+	params.Set("limit", strconv.Itoa(query.Limit))
+
+	var response Response
+	if err := tracing.Get(ctx, client, dsInfo, "api/traces", params, &response); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]tracing.TraceSummary, 0, len(response.Data))
+	for _, trace := range response.Data {
+		summaries = append(summaries, tracing.Summarize(trace.TraceID, trace.ToSpans()))
+	}
+	return summaries, nil
+}
+
+// GetTrace fetches a trace from the Jaeger query API, which Tempo also
+// implements.
+func GetTrace(ctx context.Context, client *http.Client, dsInfo *models.DataSource, traceID string) ([]*tracing.Span, error) {
+	var response Response
+	if err := tracing.Get(ctx, client, dsInfo, "api/traces/"+url.PathEscape(traceID), url.Values{}, &response); err != nil {
+		return nil, err
+	}
+
+	spans := []*tracing.Span{}
+	for _, trace := range response.Data {
+		spans = append(spans, trace.ToSpans()...)
+	}
+	return spans, nil
+}
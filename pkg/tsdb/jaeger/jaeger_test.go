@@ -0,0 +1,135 @@
+package jaeger
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTraceToSpans(t *testing.T) {
+	var response Response
+	require.NoError(t, json.Unmarshal(loadTestFile(t, "trace.json"), &response))
+	require.Len(t, response.Data, 1)
+
+	spans := response.Data[0].ToSpans()
+	require.Len(t, spans, 2)
+
+	root := spans[0]
+	assert.Equal(t, "3fa414edcef6ad90", root.SpanID)
+	assert.Equal(t, "", root.ParentSpanID)
+	assert.Equal(t, "tempo-querier", root.ServiceName)
+	assert.Len(t, root.ServiceTags, 2)
+	assert.Equal(t, int64(1605873894680409), root.StartTime)
+	assert.Equal(t, int64(1049141), root.Duration)
+	assert.Len(t, root.Tags, 3)
+
+	child := spans[1]
+	assert.Equal(t, "3fa414edcef6ad90", child.ParentSpanID)
+	assert.Equal(t, "tempo-ingester", child.ServiceName)
+	require.Len(t, child.Logs, 1)
+	assert.Equal(t, "retrieved from store", child.Logs[0].Fields[0].Value)
+}
+
+func TestJaegerExecutor(t *testing.T) {
+	var lastRequest *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		switch r.URL.Path {
+		case "/api/traces/3fa414edcef6ad90":
+			_, _ = w.Write(loadTestFile(t, "trace.json"))
+		case "/api/traces":
+			_, _ = w.Write(loadTestFile(t, "search.json"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"data": null, "errors": [{"code": 404, "msg": "trace not found"}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dsInfo := &models.DataSource{
+		Url:      server.URL,
+		JsonData: simplejson.New(),
+	}
+	executor, err := NewExecutor(dsInfo)
+	require.NoError(t, err)
+
+	runQuery := func(t *testing.T, model string) *tsdb.QueryResult {
+		t.Helper()
+
+		jsonModel, err := simplejson.NewJson([]byte(model))
+		require.NoError(t, err)
+
+		resp, err := executor.Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1h", "now"),
+			Queries:   []*tsdb.Query{{RefId: "A", Model: jsonModel}},
+		})
+		require.NoError(t, err)
+		return resp.Results["A"]
+	}
+
+	decode := func(t *testing.T, res *tsdb.QueryResult) *data.Frame {
+		t.Helper()
+
+		require.NoError(t, res.Error)
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		return frames[0]
+	}
+
+	t.Run("trace by ID", func(t *testing.T) {
+		frame := decode(t, runQuery(t, `{"query": "3fa414edcef6ad90"}`))
+		assert.Equal(t, data.VisType("trace"), frame.Meta.PreferredVisualization)
+		assert.Equal(t, 2, frame.Rows())
+	})
+
+	t.Run("search by service and operation", func(t *testing.T) {
+		frame := decode(t, runQuery(t, `{"service": "frontend", "operation": "HTTP GET /dispatch", "limit": 2}`))
+
+		params := lastRequest.URL.Query()
+		assert.Equal(t, "frontend", params.Get("service"))
+		assert.Equal(t, "HTTP GET /dispatch", params.Get("operation"))
+		assert.Equal(t, "2", params.Get("limit"))
+		assert.NotEmpty(t, params.Get("start"))
+		assert.NotEmpty(t, params.Get("end"))
+
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, "1a2b3c4d5e6f7081", frame.Fields[0].At(0))
+		assert.Equal(t, "frontend: HTTP GET /dispatch", frame.Fields[1].At(0))
+		assert.Equal(t, 700.0, frame.Fields[3].At(0))
+		assert.Equal(t, int64(2), frame.Fields[4].At(0))
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		frame := decode(t, runQuery(t, `{"query": ""}`))
+		assert.Equal(t, 0, frame.Rows())
+	})
+
+	t.Run("missing trace", func(t *testing.T) {
+		res := runQuery(t, `{"query": "unknown"}`)
+		require.Error(t, res.Error)
+		assert.Contains(t, res.Error.Error(), "trace not found")
+	})
+}
+
+func loadTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	// Ignore gosec warning G304 since it's a test
+	// nolint:gosec
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return b
+}
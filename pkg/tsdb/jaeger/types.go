@@ -0,0 +1,73 @@
+package jaeger
+
+import "github.com/grafana/grafana/pkg/tsdb/tracing"
+
+// Response is the envelope of the Jaeger query API, as served by
+// /api/traces and /api/traces/{traceID}.
+type Response struct {
+	Data []Trace `json:"data"`
+}
+
+type Trace struct {
+	TraceID   string             `json:"traceID"`
+	Spans     []Span             `json:"spans"`
+	Processes map[string]Process `json:"processes"`
+}
+
+type Span struct {
+	TraceID       string             `json:"traceID"`
+	SpanID        string             `json:"spanID"`
+	OperationName string             `json:"operationName"`
+	References    []Reference        `json:"references"`
+	StartTime     int64              `json:"startTime"`
+	Duration      int64              `json:"duration"`
+	Tags          []tracing.KeyValue `json:"tags"`
+	Logs          []tracing.Log      `json:"logs"`
+	ProcessID     string             `json:"processID"`
+}
+
+type Reference struct {
+	RefType string `json:"refType"`
+	TraceID string `json:"traceID"`
+	SpanID  string `json:"spanID"`
+}
+
+type Process struct {
+	ServiceName string             `json:"serviceName"`
+	Tags        []tracing.KeyValue `json:"tags"`
+}
+
+// ToSpans returns the spans of the trace with their process resolved.
+func (t Trace) ToSpans() []*tracing.Span {
+	spans := make([]*tracing.Span, 0, len(t.Spans))
+	for _, s := range t.Spans {
+		process := t.Processes[s.ProcessID]
+
+		span := &tracing.Span{
+			TraceID:       s.TraceID,
+			SpanID:        s.SpanID,
+			OperationName: s.OperationName,
+			ServiceName:   process.ServiceName,
+			ServiceTags:   process.Tags,
+			StartTime:     s.StartTime,
+			Duration:      s.Duration,
+			Logs:          s.Logs,
+			Tags:          s.Tags,
+		}
+
+		// The parent is the first CHILD_OF reference, falling back to the
+		// first reference of any type.
+		for _, ref := range s.References {
+			if ref.RefType == "CHILD_OF" {
+				span.ParentSpanID = ref.SpanID
+				break
+			}
+		}
+		if span.ParentSpanID == "" && len(s.References) > 0 {
+			span.ParentSpanID = s.References[0].SpanID
+		}
+
+		spans = append(spans, span)
+	}
+	return spans
+}
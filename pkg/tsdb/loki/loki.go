@@ -0,0 +1,351 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/loki/pkg/loghttp"
+	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// defaultMaxLines is the log line limit used when neither the query nor
+	// the datasource configures one. It matches the frontend default.
+	defaultMaxLines = 1000
+	// maxDataPoints is the number of points per series above which Loki
+	// rejects range queries.
+	maxDataPoints = 11000
+)
+
+type LokiExecutor struct{}
+
+func NewExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	return &LokiExecutor{}, nil
+}
+
+var (
+	plog               log.Logger
+	legendFormat       *regexp.Regexp
+	intervalCalculator tsdb.IntervalCalculator
+)
+
+func init() {
+	plog = log.New("tsdb.loki")
+	tsdb.RegisterTsdbQueryEndpoint("loki", NewExecutor)
+	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
+	intervalCalculator = tsdb.NewIntervalCalculator(&tsdb.IntervalOptions{MinInterval: time.Second * 1})
+}
+
+func (e *LokiExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return tsdb.ExecuteQueries(ctx, dsInfo, tsdbQuery, func(ctx context.Context, q *tsdb.Query) (*tsdb.QueryResult, error) {
+		queries, err := parseQuery(dsInfo, []*tsdb.Query{q}, tsdbQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		frames, err := runQuery(ctx, httpClient, dsInfo, queries[0])
+		if err != nil {
+			return nil, err
+		}
+
+		queryResult := tsdb.NewQueryResult()
+		queryResult.Dataframes = tsdb.NewDecodedDataFrames(frames)
+		return queryResult, nil
+	}), nil
+}
+
+func runQuery(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, query *lokiQuery) (data.Frames, error) {
+	plog.Debug("Sending query", "start", query.Start, "end", query.End, "step", query.Step, "instant", query.Instant, "query", query.Expr)
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.loki")
+	span.SetTag("expr", query.Expr)
+	span.SetTag("start_unixnano", query.Start.UnixNano())
+	span.SetTag("stop_unixnano", query.End.UnixNano())
+	defer span.Finish()
+
+	req, err := createRequest(ctx, dsInfo, query)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := execute(httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseResponse(value, query)
+}
+
+func createRequest(ctx context.Context, dsInfo *models.DataSource, query *lokiQuery) (*http.Request, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("query", query.Expr)
+	params.Set("limit", strconv.Itoa(query.MaxLines))
+	params.Set("direction", "backward")
+
+	if query.Instant {
+		u.Path = path.Join(u.Path, "loki/api/v1/query")
+		params.Set("time", strconv.FormatInt(query.End.UnixNano(), 10))
+	} else {
+		u.Path = path.Join(u.Path, "loki/api/v1/query_range")
+		params.Set("start", strconv.FormatInt(query.Start.UnixNano(), 10))
+		params.Set("end", strconv.FormatInt(query.End.UnixNano(), 10))
+		params.Set("step", strconv.FormatFloat(query.Step.Seconds(), 'f', -1, 64))
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Grafana")
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	return req, nil
+}
+
+func execute(client *http.Client, req *http.Request) (loghttp.ResultValue, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			plog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Loki answers failed queries with a plain text error message.
+	if resp.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(body))
+		if msg == "" {
+			msg = resp.Status
+		}
+		return nil, fmt.Errorf("loki returned error status %d: %s", resp.StatusCode, msg)
+	}
+
+	var response loghttp.QueryResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse loki response: %w", err)
+	}
+	if response.Status != loghttp.QueryStatusSuccess {
+		return nil, fmt.Errorf("loki returned status %q", response.Status)
+	}
+
+	return response.Data.Result, nil
+}
+
+func formatLegend(metric model.Metric, query *lokiQuery) string {
+	if query.LegendFormat == "" {
+		return metric.String()
+	}
+
+	result := legendFormat.ReplaceAllFunc([]byte(query.LegendFormat), func(in []byte) []byte {
+		labelName := strings.Replace(string(in), "{{", "", 1)
+		labelName = strings.Replace(labelName, "}}", "", 1)
+		labelName = strings.TrimSpace(labelName)
+		if val, exists := metric[model.LabelName(labelName)]; exists {
+			return []byte(val)
+		}
+		return []byte{}
+	})
+
+	return string(result)
+}
+
+func parseQuery(dsInfo *models.DataSource, queries []*tsdb.Query, queryContext *tsdb.TsdbQuery) ([]*lokiQuery, error) {
+	qs := []*lokiQuery{}
+	for _, queryModel := range queries {
+		expr, err := queryModel.Model.Get("expr").String()
+		if err != nil {
+			return nil, err
+		}
+
+		start, err := queryContext.TimeRange.ParseFrom()
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := queryContext.TimeRange.ParseTo()
+		if err != nil {
+			return nil, err
+		}
+
+		dsInterval, err := tsdb.GetIntervalFrom(dsInfo, queryModel.Model, time.Second)
+		if err != nil {
+			return nil, err
+		}
+
+		intervalFactor := queryModel.Model.Get("intervalFactor").MustInt64(1)
+		interval := intervalCalculator.Calculate(queryContext.TimeRange, dsInterval)
+		step := time.Duration(int64(interval.Value) * intervalFactor)
+
+		// Loki rejects range queries returning too many points, so widen the
+		// step for long time ranges instead of failing the query.
+		if minStep := end.Sub(start) / maxDataPoints; step < minStep {
+			step = minStep.Truncate(time.Second) + time.Second
+		}
+
+		maxLines := queryModel.Model.Get("maxLines").MustInt(0)
+		if maxLines <= 0 {
+			maxLines, err = strconv.Atoi(dsInfo.JsonData.Get("maxLines").MustString(""))
+			if err != nil || maxLines <= 0 {
+				maxLines = defaultMaxLines
+			}
+		}
+
+		qs = append(qs, &lokiQuery{
+			Expr:         expr,
+			Step:         step,
+			LegendFormat: queryModel.Model.Get("legendFormat").MustString(""),
+			Instant:      queryModel.Model.Get("instant").MustBool(false),
+			MaxLines:     maxLines,
+			Start:        start,
+			End:          end,
+			RefId:        queryModel.RefId,
+		})
+	}
+
+	return qs, nil
+}
+
+func parseResponse(value loghttp.ResultValue, query *lokiQuery) (data.Frames, error) {
+	switch v := value.(type) {
+	case loghttp.Matrix:
+		return matrixToFrames(v, query), nil
+	case loghttp.Vector:
+		return vectorToFrames(v, query), nil
+	case loghttp.Scalar:
+		return scalarToFrames(v, query), nil
+	case loghttp.Streams:
+		return streamsToFrames(v, query), nil
+	case nil:
+		return data.Frames{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported result format: %q", value.Type())
+	}
+}
+
+func matrixToFrames(matrix loghttp.Matrix, query *lokiQuery) data.Frames {
+	frames := make(data.Frames, 0, len(matrix))
+
+	for _, v := range matrix {
+		times := make([]time.Time, 0, len(v.Values))
+		values := make([]float64, 0, len(v.Values))
+		for _, k := range v.Values {
+			times = append(times, k.Timestamp.Time().UTC())
+			values = append(values, float64(k.Value))
+		}
+
+		frames = append(frames, newSeriesFrame(v.Metric, times, values, query))
+	}
+
+	return frames
+}
+
+func vectorToFrames(vector loghttp.Vector, query *lokiQuery) data.Frames {
+	frames := make(data.Frames, 0, len(vector))
+
+	for _, v := range vector {
+		frames = append(frames, newSeriesFrame(v.Metric,
+			[]time.Time{v.Timestamp.Time().UTC()},
+			[]float64{float64(v.Value)},
+			query))
+	}
+
+	return frames
+}
+
+func scalarToFrames(scalar loghttp.Scalar, query *lokiQuery) data.Frames {
+	return data.Frames{
+		newSeriesFrame(model.Metric{},
+			[]time.Time{scalar.Timestamp.Time().UTC()},
+			[]float64{float64(scalar.Value)},
+			query),
+	}
+}
+
+func newSeriesFrame(metric model.Metric, times []time.Time, values []float64, query *lokiQuery) *data.Frame {
+	name := formatLegend(metric, query)
+
+	labels := make(data.Labels, len(metric))
+	for k, v := range metric {
+		labels[string(k)] = string(v)
+	}
+
+	valueField := data.NewField("value", labels, values)
+	valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: name})
+
+	frame := data.NewFrame(name,
+		data.NewField("time", nil, times),
+		valueField,
+	)
+	frame.RefID = query.RefId
+	frame.Meta = &data.FrameMeta{ExecutedQueryString: query.Expr}
+
+	return frame
+}
+
+func streamsToFrames(streams loghttp.Streams, query *lokiQuery) data.Frames {
+	frames := make(data.Frames, 0, len(streams))
+
+	for _, stream := range streams {
+		times := make([]time.Time, 0, len(stream.Entries))
+		lines := make([]string, 0, len(stream.Entries))
+		for _, entry := range stream.Entries {
+			times = append(times, entry.Timestamp.UTC())
+			lines = append(lines, entry.Line)
+		}
+
+		labels := make(data.Labels, len(stream.Labels))
+		for k, v := range stream.Labels {
+			labels[k] = v
+		}
+
+		frame := data.NewFrame(stream.Labels.String(),
+			data.NewField("ts", nil, times),
+			data.NewField("line", labels, lines),
+		)
+		frame.RefID = query.RefId
+		frame.Meta = &data.FrameMeta{
+			ExecutedQueryString:    query.Expr,
+			PreferredVisualization: data.VisTypeLogs,
+		}
+
+		frames = append(frames, frame)
+	}
+
+	return frames
+}
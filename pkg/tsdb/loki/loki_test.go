@@ -0,0 +1,239 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/loki/pkg/loghttp"
+	p "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoki(t *testing.T) {
+	dsInfo := &models.DataSource{
+		JsonData: simplejson.New(),
+	}
+
+	t.Run("converting metric name", func(t *testing.T) {
+		metric := map[p.LabelName]p.LabelValue{
+			p.LabelName("app"):    p.LabelValue("backend"),
+			p.LabelName("device"): p.LabelValue("mobile"),
+		}
+
+		query := &lokiQuery{
+			LegendFormat: "legend {{app}} {{ device }} {{broken}}",
+		}
+
+		require.Equal(t, "legend backend mobile ", formatLegend(metric, query))
+	})
+
+	t.Run("build full series name", func(t *testing.T) {
+		metric := map[p.LabelName]p.LabelValue{
+			p.LabelName("app"):    p.LabelValue("backend"),
+			p.LabelName("device"): p.LabelValue("mobile"),
+		}
+
+		query := &lokiQuery{
+			LegendFormat: "",
+		}
+
+		require.Equal(t, `{app="backend", device="mobile"}`, formatLegend(metric, query))
+	})
+
+	t.Run("parsing query model with step", func(t *testing.T) {
+		json := `{
+				"expr": "rate({app=\"backend\"}[5m])",
+				"refId": "A"
+			}`
+		jsonModel, _ := simplejson.NewJson([]byte(json))
+		queryContext := &tsdb.TsdbQuery{}
+		queryModels := []*tsdb.Query{
+			{Model: jsonModel, RefId: "A"},
+		}
+
+		queryContext.TimeRange = tsdb.NewTimeRange("12h", "now")
+
+		models, err := parseQuery(dsInfo, queryModels, queryContext)
+		require.NoError(t, err)
+		require.Len(t, models, 1)
+		require.Equal(t, time.Second*30, models[0].Step)
+		require.Equal(t, defaultMaxLines, models[0].MaxLines)
+		require.False(t, models[0].Instant)
+		require.Equal(t, "A", models[0].RefId)
+	})
+
+	t.Run("parsing query model widens step for long time ranges", func(t *testing.T) {
+		jsonModel, _ := simplejson.NewJson([]byte(`{"expr": "count_over_time({app=\"x\"}[1m])", "interval": "1s"}`))
+		queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("8760h", "now")}
+
+		models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel}}, queryContext)
+		require.NoError(t, err)
+
+		from, _ := queryContext.TimeRange.ParseFrom()
+		to, _ := queryContext.TimeRange.ParseTo()
+		require.Less(t, int64(to.Sub(from)/models[0].Step), int64(maxDataPoints))
+	})
+
+	t.Run("parsing query model with instant and max lines", func(t *testing.T) {
+		jsonModel, _ := simplejson.NewJson([]byte(`{"expr": "{app=\"x\"}", "instant": true, "maxLines": 20}`))
+		queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("1h", "now")}
+
+		models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel}}, queryContext)
+		require.NoError(t, err)
+		require.True(t, models[0].Instant)
+		require.Equal(t, 20, models[0].MaxLines)
+	})
+
+	t.Run("parsing query model uses datasource max lines", func(t *testing.T) {
+		ds := &models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{"maxLines": "50"})}
+		jsonModel, _ := simplejson.NewJson([]byte(`{"expr": "{app=\"x\"}"}`))
+		queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("1h", "now")}
+
+		models, err := parseQuery(ds, []*tsdb.Query{{Model: jsonModel}}, queryContext)
+		require.NoError(t, err)
+		require.Equal(t, 50, models[0].MaxLines)
+	})
+}
+
+func TestParseResponse(t *testing.T) {
+	query := &lokiQuery{RefId: "A", Expr: "expr", LegendFormat: "{{app}}"}
+
+	parse := func(t *testing.T, body string) data.Frames {
+		t.Helper()
+
+		var response loghttp.QueryResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &response))
+
+		frames, err := parseResponse(response.Data.Result, query)
+		require.NoError(t, err)
+		return frames
+	}
+
+	t.Run("matrix", func(t *testing.T) {
+		frames := parse(t, `{"status": "success", "data": {"resultType": "matrix", "result": [
+			{"metric": {"app": "backend"}, "values": [[1600000000, "1"], [1600000010, "2.5"]]}
+		]}}`)
+
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		assert.Equal(t, "backend", frame.Name)
+		assert.Equal(t, "A", frame.RefID)
+		require.Len(t, frame.Fields, 2)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.Unix(1600000010, 0).UTC(), frame.Fields[0].At(1))
+		assert.Equal(t, 2.5, frame.Fields[1].At(1))
+		assert.Equal(t, data.Labels{"app": "backend"}, frame.Fields[1].Labels)
+		assert.Equal(t, "backend", frame.Fields[1].Config.DisplayNameFromDS)
+	})
+
+	t.Run("vector", func(t *testing.T) {
+		frames := parse(t, `{"status": "success", "data": {"resultType": "vector", "result": [
+			{"metric": {"app": "backend"}, "value": [1600000000, "3"]},
+			{"metric": {"app": "frontend"}, "value": [1600000000, "4"]}
+		]}}`)
+
+		require.Len(t, frames, 2)
+		assert.Equal(t, "frontend", frames[1].Name)
+		require.Equal(t, 1, frames[1].Rows())
+		assert.Equal(t, 4.0, frames[1].Fields[1].At(0))
+	})
+
+	t.Run("streams", func(t *testing.T) {
+		frames := parse(t, `{"status": "success", "data": {"resultType": "streams", "result": [
+			{"stream": {"level": "error", "app": "backend"}, "values": [["1600000000000000000", "first"], ["1600000001000000000", "second"]]}
+		]}}`)
+
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		assert.Equal(t, `{app="backend", level="error"}`, frame.Name)
+		assert.Equal(t, data.VisType(data.VisTypeLogs), frame.Meta.PreferredVisualization)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.Unix(1600000001, 0).UTC(), frame.Fields[0].At(1))
+		assert.Equal(t, "second", frame.Fields[1].At(1))
+		assert.Equal(t, data.Labels{"app": "backend", "level": "error"}, frame.Fields[1].Labels)
+	})
+}
+
+func TestLokiExecutor(t *testing.T) {
+	var lastRequest *http.Request
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	dsInfo := &models.DataSource{
+		Id:            1,
+		Url:           server.URL,
+		JsonData:      simplejson.New(),
+		BasicAuth:     true,
+		BasicAuthUser: "user",
+	}
+	executor, err := NewExecutor(dsInfo)
+	require.NoError(t, err)
+
+	newQuery := func(model string) *tsdb.TsdbQuery {
+		jsonModel, err := simplejson.NewJson([]byte(model))
+		require.NoError(t, err)
+		return &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1h", "now"),
+			Queries:   []*tsdb.Query{{RefId: "A", Model: jsonModel}},
+		}
+	}
+
+	t.Run("range query", func(t *testing.T) {
+		status = http.StatusOK
+		body = `{"status": "success", "data": {"resultType": "matrix", "result": [
+			{"metric": {"app": "backend"}, "values": [[1600000000, "1"]]}
+		]}}`
+
+		res, err := executor.Query(context.Background(), dsInfo, newQuery(`{"expr": "rate({app=\"backend\"}[5m])"}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, "/loki/api/v1/query_range", lastRequest.URL.Path)
+		assert.Equal(t, `rate({app="backend"}[5m])`, lastRequest.URL.Query().Get("query"))
+		assert.NotEmpty(t, lastRequest.URL.Query().Get("start"))
+		assert.NotEmpty(t, lastRequest.URL.Query().Get("step"))
+		user, _, ok := lastRequest.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", user)
+
+		frames, err := res.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, 1, frames[0].Rows())
+	})
+
+	t.Run("instant query", func(t *testing.T) {
+		status = http.StatusOK
+		body = `{"status": "success", "data": {"resultType": "vector", "result": []}}`
+
+		_, err := executor.Query(context.Background(), dsInfo, newQuery(`{"expr": "count_over_time({app=\"backend\"}[5m])", "instant": true}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, "/loki/api/v1/query", lastRequest.URL.Path)
+		assert.NotEmpty(t, lastRequest.URL.Query().Get("time"))
+		assert.Empty(t, lastRequest.URL.Query().Get("start"))
+	})
+
+	t.Run("error response", func(t *testing.T) {
+		status = http.StatusBadRequest
+		body = "parse error : syntax error: unexpected IDENTIFIER\n"
+
+		resp, err := executor.Query(context.Background(), dsInfo, newQuery(`{"expr": "rate(app[5m])"}`))
+		require.NoError(t, err)
+		require.EqualError(t, resp.Results["A"].Error, "loki returned error status 400: parse error : syntax error: unexpected IDENTIFIER")
+	})
+}
@@ -0,0 +1,14 @@
+package loki
+
+import "time"
+
+type lokiQuery struct {
+	Expr         string
+	Step         time.Duration
+	LegendFormat string
+	Instant      bool
+	MaxLines     int
+	Start        time.Time
+	End          time.Time
+	RefId        string
+}
@@ -19,6 +19,9 @@ type TsdbQuery struct {
 	Headers   map[string]string
 	Debug     bool
 	User      *models.SignedInUser
+	// ConcurrentQueryLimit is the server-wide limit of queries of the request
+	// that run at the same time, the default limit is used when it is 0.
+	ConcurrentQueryLimit int
 }
 
 type Query struct {
@@ -3,6 +3,7 @@ package mssql
 import (
 	"fmt"
 	"strconv"
+	"sync"
 	"testing"
 
 	"time"
@@ -218,3 +219,33 @@ func TestMacroEngine(t *testing.T) {
 		})
 	})
 }
+
+func TestMacroEngineConcurrency(t *testing.T) {
+	Convey("MacroEngine interpolates the queries of a request concurrently", t, func() {
+		engine := newMssqlMacroEngine()
+		timeRange := tsdb.NewTimeRange("5m", "now")
+
+		queries := make([]*tsdb.Query, 20)
+		sqls := make([]string, len(queries))
+		errs := make([]error, len(queries))
+		var wg sync.WaitGroup
+		for i := range queries {
+			queries[i] = &tsdb.Query{RefId: strconv.Itoa(i), Model: simplejson.New()}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				sql := fmt.Sprintf("SELECT $__timeGroupAlias(time_column,'%dm',%d) WHERE $__timeFilter(time_column)", i+1, i)
+				sqls[i], errs[i] = engine.Interpolate(queries[i], timeRange, sql)
+			}(i)
+		}
+		wg.Wait()
+
+		for i, query := range queries {
+			So(errs[i], ShouldBeNil)
+			So(sqls[i], ShouldContainSubstring, fmt.Sprintf("/%d)*%d", (i+1)*60, (i+1)*60))
+			So(query.Model.Get("fillMode").MustString(), ShouldEqual, "value")
+			So(query.Model.Get("fillValue").MustFloat64(), ShouldEqual, i)
+			So(query.Model.Get("fillInterval").MustFloat64(), ShouldEqual, (i+1)*60)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -138,38 +139,42 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(err, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
+				column := func(i int) interface{} {
+					return frame.Fields[i].At(0)
+				}
 
-				So(column[0].(bool), ShouldEqual, true)
+				So(*column(0).(*bool), ShouldEqual, true)
 
-				So(column[1].(int64), ShouldEqual, 5)
-				So(column[2].(int64), ShouldEqual, 20020)
-				So(column[3].(int64), ShouldEqual, 980300)
-				So(column[4].(int64), ShouldEqual, 1420070400)
+				So(*column(1).(*int64), ShouldEqual, 5)
+				So(*column(2).(*int64), ShouldEqual, 20020)
+				So(*column(3).(*int64), ShouldEqual, 980300)
+				So(*column(4).(*int64), ShouldEqual, 1420070400)
 
-				So(column[5].(float64), ShouldEqual, 20000.15)
-				So(column[6].(float64), ShouldEqual, 2.15)
-				So(column[7].(float64), ShouldEqual, 12345.12)
-				So(column[8].(float64), ShouldEqual, 1.1100000143051147)
-				So(column[9].(float64), ShouldEqual, 2.22)
-				So(column[10].(float64), ShouldEqual, 3.33)
+				So(*column(5).(*float64), ShouldEqual, 20000.15)
+				So(*column(6).(*float64), ShouldEqual, 2.15)
+				So(*column(7).(*float64), ShouldEqual, 12345.12)
+				So(*column(8).(*float64), ShouldEqual, 1.1100000143051147)
+				So(*column(9).(*float64), ShouldEqual, 2.22)
+				So(*column(10).(*float64), ShouldEqual, 3.33)
 
-				So(column[11].(string), ShouldEqual, "char10    ")
-				So(column[12].(string), ShouldEqual, "varchar10")
-				So(column[13].(string), ShouldEqual, "text")
+				So(*column(11).(*string), ShouldEqual, "char10    ")
+				So(*column(12).(*string), ShouldEqual, "varchar10")
+				So(*column(13).(*string), ShouldEqual, "text")
 
-				So(column[14].(string), ShouldEqual, "☺nchar12☺   ")
-				So(column[15].(string), ShouldEqual, "☺nvarchar12☺")
-				So(column[16].(string), ShouldEqual, "☺text☺")
+				So(*column(14).(*string), ShouldEqual, "☺nchar12☺   ")
+				So(*column(15).(*string), ShouldEqual, "☺nvarchar12☺")
+				So(*column(16).(*string), ShouldEqual, "☺text☺")
 
-				So(column[17].(time.Time), ShouldEqual, dt)
-				So(column[18].(time.Time), ShouldEqual, dt2)
-				So(column[19].(time.Time), ShouldEqual, dt.Truncate(time.Minute))
-				So(column[20].(time.Time), ShouldEqual, dt.Truncate(24*time.Hour))
-				So(column[21].(time.Time), ShouldEqual, time.Date(1, 1, 1, dt.Hour(), dt.Minute(), dt.Second(), dt.Nanosecond(), time.UTC))
-				So(column[22].(time.Time), ShouldEqual, dt2.In(time.FixedZone("UTC-7", int(-7*60*60))))
+				So(*column(17).(*time.Time), ShouldEqual, dt)
+				So(*column(18).(*time.Time), ShouldEqual, dt2)
+				So(*column(19).(*time.Time), ShouldEqual, dt.Truncate(time.Minute))
+				So(*column(20).(*time.Time), ShouldEqual, dt.Truncate(24*time.Hour))
+				So(*column(21).(*time.Time), ShouldEqual, time.Date(1, 1, 1, dt.Hour(), dt.Minute(), dt.Second(), dt.Nanosecond(), time.UTC))
+				So(*column(22).(*time.Time), ShouldEqual, dt2.In(time.FixedZone("UTC-7", int(-7*60*60))))
 
-				So(column[23].(string), ShouldEqual, uuid)
+				So(*column(23).(*string), ShouldEqual, uuid)
 			})
 		})
 
@@ -231,15 +236,15 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				frame := decodedFrame(queryResult)
 				// without fill this should result in 4 buckets
-				So(len(points), ShouldEqual, 4)
+				So(frame.Rows(), ShouldEqual, 4)
 
 				dt := fromStart
 
 				for i := 0; i < 2; i++ {
-					aValue := points[i][0].Float64
-					aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+					aValue := *frame.Fields[1].At(i).(*float64)
+					aTime := frame.Fields[0].At(i).(time.Time)
 					So(aValue, ShouldEqual, 15)
 					So(aTime, ShouldEqual, dt)
 					dt = dt.Add(5 * time.Minute)
@@ -248,8 +253,8 @@ func TestMSSQL(t *testing.T) {
 				// adjust for 10 minute gap between first and second set of points
 				dt = dt.Add(10 * time.Minute)
 				for i := 2; i < 4; i++ {
-					aValue := points[i][0].Float64
-					aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+					aValue := *frame.Fields[1].At(i).(*float64)
+					aTime := frame.Fields[0].At(i).(time.Time)
 					So(aValue, ShouldEqual, 20)
 					So(aTime, ShouldEqual, dt)
 					dt = dt.Add(5 * time.Minute)
@@ -278,34 +283,34 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
-				So(len(points), ShouldEqual, 7)
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 7)
 
 				dt := fromStart
 
 				for i := 0; i < 2; i++ {
-					aValue := points[i][0].Float64
-					aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+					aValue := *frame.Fields[1].At(i).(*float64)
+					aTime := frame.Fields[0].At(i).(time.Time)
 					So(aValue, ShouldEqual, 15)
 					So(aTime, ShouldEqual, dt)
 					dt = dt.Add(5 * time.Minute)
 				}
 
 				// check for NULL values inserted by fill
-				So(points[2][0].Valid, ShouldBeFalse)
-				So(points[3][0].Valid, ShouldBeFalse)
+				So(frame.Fields[1].At(2), ShouldBeNil)
+				So(frame.Fields[1].At(3), ShouldBeNil)
 
 				// adjust for 10 minute gap between first and second set of points
 				dt = dt.Add(10 * time.Minute)
 				for i := 4; i < 6; i++ {
-					aValue := points[i][0].Float64
-					aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+					aValue := *frame.Fields[1].At(i).(*float64)
+					aTime := frame.Fields[0].At(i).(time.Time)
 					So(aValue, ShouldEqual, 20)
 					So(aTime, ShouldEqual, dt)
 					dt = dt.Add(5 * time.Minute)
 				}
 
-				So(points[6][0].Valid, ShouldBeFalse)
+				So(frame.Fields[1].At(6), ShouldBeNil)
 			})
 
 			Convey("When doing a metric query using timeGroup and $__interval", func() {
@@ -364,8 +369,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
-				So(points[3][0].Float64, ShouldEqual, 1.5)
+				frame := decodedFrame(queryResult)
+				So(*frame.Fields[1].At(3).(*float64), ShouldEqual, 1.5)
 			})
 		})
 
@@ -454,8 +459,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int64 nullable) as time column and value column (int64 nullable) should return metric with time in milliseconds", func() {
@@ -476,8 +482,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64) as time column and value column (float64) should return metric with time in milliseconds", func() {
@@ -498,8 +505,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64 nullable) as time column and value column (float64 nullable) should return metric with time in milliseconds", func() {
@@ -520,8 +528,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32) as time column and value column (int32) should return metric with time in milliseconds", func() {
@@ -542,8 +551,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32 nullable) as time column and value column (int32 nullable) should return metric with time in milliseconds", func() {
@@ -564,8 +574,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float32) as time column and value column (float32) should return metric with time in milliseconds", func() {
@@ -586,8 +597,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query using epoch (float32 nullable) as time column and value column (float32 nullable) should return metric with time in milliseconds", func() {
@@ -608,8 +620,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query grouping by time and select metric column should return correct series", func() {
@@ -630,9 +643,10 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A - value one")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric B - value one")
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 3)
+				So(frame.Fields[1].Name, ShouldEqual, "Metric A - value one")
+				So(frame.Fields[2].Name, ShouldEqual, "Metric B - value one")
 			})
 
 			Convey("When doing a metric query grouping by time should return correct series", func() {
@@ -653,9 +667,10 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "valueTwo")
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 3)
+				So(frame.Fields[1].Name, ShouldEqual, "valueOne")
+				So(frame.Fields[2].Name, ShouldEqual, "valueTwo")
 			})
 
 			Convey("When doing a metric query with metric column and multiple value columns", func() {
@@ -676,11 +691,12 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 4)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-				So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-				So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 5)
+				So(frame.Fields[1].Name, ShouldEqual, "Metric A valueOne")
+				So(frame.Fields[2].Name, ShouldEqual, "Metric A valueTwo")
+				So(frame.Fields[3].Name, ShouldEqual, "Metric B valueOne")
+				So(frame.Fields[4].Name, ShouldEqual, "Metric B valueTwo")
 			})
 
 			Convey("When doing a query with timeFrom,timeTo,unixEpochFrom,unixEpochTo macros", func() {
@@ -777,11 +793,12 @@ func TestMSSQL(t *testing.T) {
 					So(err, ShouldBeNil)
 					So(queryResult.Error, ShouldBeNil)
 
-					So(len(queryResult.Series), ShouldEqual, 4)
-					So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-					So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-					So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-					So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+					frame := decodedFrame(queryResult)
+					So(frame.Fields, ShouldHaveLength, 5)
+					So(frame.Fields[1].Name, ShouldEqual, "Metric A valueOne")
+					So(frame.Fields[2].Name, ShouldEqual, "Metric A valueTwo")
+					So(frame.Fields[3].Name, ShouldEqual, "Metric B valueOne")
+					So(frame.Fields[4].Name, ShouldEqual, "Metric B valueTwo")
 				})
 			})
 
@@ -856,11 +873,12 @@ func TestMSSQL(t *testing.T) {
 					So(err, ShouldBeNil)
 					So(queryResult.Error, ShouldBeNil)
 
-					So(len(queryResult.Series), ShouldEqual, 4)
-					So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-					So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-					So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-					So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+					frame := decodedFrame(queryResult)
+					So(frame.Fields, ShouldHaveLength, 5)
+					So(frame.Fields[1].Name, ShouldEqual, "Metric A valueOne")
+					So(frame.Fields[2].Name, ShouldEqual, "Metric A valueTwo")
+					So(frame.Fields[3].Name, ShouldEqual, "Metric B valueOne")
+					So(frame.Fields[4].Name, ShouldEqual, "Metric B valueTwo")
 				})
 			})
 		})
@@ -930,7 +948,8 @@ func TestMSSQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -953,7 +972,8 @@ func TestMSSQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -980,11 +1000,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				// Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.UnixNano()/1e6))
+				So(float64(frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6), ShouldEqual, float64(dt.UnixNano()/1e6))
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format should return ms", func() {
@@ -1010,11 +1030,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				// Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format (int) should return ms", func() {
@@ -1040,11 +1060,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				// Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch millisecond format should return ms", func() {
@@ -1070,11 +1090,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				// Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.Unix()*1000))
+				So(float64(frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6), ShouldEqual, float64(dt.Unix()*1000))
 			})
 
 			Convey("When doing an annotation query with a time column holding a bigint null value should return nil", func() {
@@ -1098,11 +1118,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				// Should be in milliseconds
-				So(columns[0], ShouldBeNil)
+				So(frame.Fields[0].At(0), ShouldBeNil)
 			})
 
 			Convey("When doing an annotation query with a time column holding a datetime null value should return nil", func() {
@@ -1126,11 +1146,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				// Should be in milliseconds
-				So(columns[0], ShouldBeNil)
+				So(frame.Fields[0].At(0), ShouldBeNil)
 			})
 		})
 	})
@@ -1152,6 +1172,14 @@ func InitMSSQLTestDB(t *testing.T) *xorm.Engine {
 	return x
 }
 
+// decodedFrame returns the data frame of a query result.
+func decodedFrame(queryResult *tsdb.QueryResult) *data.Frame {
+	frames, err := queryResult.Dataframes.Decoded()
+	So(err, ShouldBeNil)
+	So(frames, ShouldHaveLength, 1)
+	return frames[0]
+}
+
 func genTimeRangeByInterval(from time.Time, duration time.Duration, interval time.Duration) []time.Time {
 	durationSec := int64(duration.Seconds())
 	intervalSec := int64(interval.Seconds())
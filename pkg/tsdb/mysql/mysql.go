@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/VividCortex/mysqlerr"
 	"github.com/grafana/grafana/pkg/setting"
@@ -67,6 +68,11 @@ func newMysqlQueryEndpoint(datasource *models.DataSource) (tsdb.TsdbQueryEndpoin
 		Datasource:        datasource,
 		TimeColumnNames:   []string{"time", "time_sec"},
 		MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
+		// The driver does not cancel queries on the server, and
+		// max_execution_time only applies to SELECT statements of MySQL 5.7.8+.
+		StatementTimeoutSQL: func(timeout time.Duration) string {
+			return fmt.Sprintf("SET SESSION max_execution_time = %d", timeout.Milliseconds())
+		},
 	}
 
 	rowTransformer := mysqlQueryResultTransformer{
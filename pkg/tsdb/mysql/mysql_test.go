@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -140,39 +141,43 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
-
-				So(*column[0].(*int8), ShouldEqual, 1)
-				So(column[1].(string), ShouldEqual, "abc")
-				So(column[2].(string), ShouldEqual, "def")
-				So(*column[3].(*int32), ShouldEqual, 1)
-				So(*column[4].(*int16), ShouldEqual, 10)
-				So(*column[5].(*int64), ShouldEqual, 100)
-				So(*column[6].(*int32), ShouldEqual, 1420070400)
-				So(column[7].(float64), ShouldEqual, 1.11)
-				So(column[8].(float64), ShouldEqual, 2.22)
-				So(*column[9].(*float32), ShouldEqual, 3.33)
-				So(column[10].(time.Time), ShouldHappenWithin, 10*time.Second, time.Now())
-				So(column[11].(time.Time), ShouldHappenWithin, 10*time.Second, time.Now())
-				So(column[12].(string), ShouldEqual, "11:11:11")
-				So(column[13].(int64), ShouldEqual, 2018)
-				So(*column[14].(*[]byte), ShouldHaveSameTypeAs, []byte{1})
-				So(column[15].(string), ShouldEqual, "tinytext")
-				So(column[16].(string), ShouldEqual, "tinyblob")
-				So(column[17].(string), ShouldEqual, "text")
-				So(column[18].(string), ShouldEqual, "blob")
-				So(column[19].(string), ShouldEqual, "mediumtext")
-				So(column[20].(string), ShouldEqual, "mediumblob")
-				So(column[21].(string), ShouldEqual, "longtext")
-				So(column[22].(string), ShouldEqual, "longblob")
-				So(column[23].(string), ShouldEqual, "val2")
-				So(column[24].(string), ShouldEqual, "a,b")
-				So(column[25].(time.Time).Format("2006-01-02T00:00:00Z"), ShouldEqual, time.Now().UTC().Format("2006-01-02T00:00:00Z"))
-				So(column[26].(float64), ShouldEqual, float64(1.514764861123456*1e12))
-				So(column[27], ShouldEqual, nil)
-				So(column[28], ShouldEqual, nil)
-				So(column[29], ShouldEqual, "")
-				So(column[30], ShouldEqual, nil)
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
+				column := func(i int) interface{} {
+					return frame.Fields[i].At(0)
+				}
+
+				So(*column(0).(*int64), ShouldEqual, 1)
+				So(*column(1).(*string), ShouldEqual, "abc")
+				So(*column(2).(*string), ShouldEqual, "def")
+				So(*column(3).(*int64), ShouldEqual, 1)
+				So(*column(4).(*int64), ShouldEqual, 10)
+				So(*column(5).(*int64), ShouldEqual, 100)
+				So(*column(6).(*int64), ShouldEqual, 1420070400)
+				So(*column(7).(*float64), ShouldEqual, 1.11)
+				So(*column(8).(*float64), ShouldEqual, 2.22)
+				So(*column(9).(*float64), ShouldEqual, float64(float32(3.33)))
+				So(*column(10).(*time.Time), ShouldHappenWithin, 10*time.Second, time.Now())
+				So(*column(11).(*time.Time), ShouldHappenWithin, 10*time.Second, time.Now())
+				So(*column(12).(*string), ShouldEqual, "11:11:11")
+				So(*column(13).(*int64), ShouldEqual, 2018)
+				So(*column(14).(*string), ShouldEqual, "\x01")
+				So(*column(15).(*string), ShouldEqual, "tinytext")
+				So(*column(16).(*string), ShouldEqual, "tinyblob")
+				So(*column(17).(*string), ShouldEqual, "text")
+				So(*column(18).(*string), ShouldEqual, "blob")
+				So(*column(19).(*string), ShouldEqual, "mediumtext")
+				So(*column(20).(*string), ShouldEqual, "mediumblob")
+				So(*column(21).(*string), ShouldEqual, "longtext")
+				So(*column(22).(*string), ShouldEqual, "longblob")
+				So(*column(23).(*string), ShouldEqual, "val2")
+				So(*column(24).(*string), ShouldEqual, "a,b")
+				So(column(25).(*time.Time).Format("2006-01-02T00:00:00Z"), ShouldEqual, time.Now().UTC().Format("2006-01-02T00:00:00Z"))
+				So(*column(26).(*time.Time), ShouldEqual, time.Date(2018, 1, 1, 0, 1, 1, 123456000, time.UTC))
+				So(column(27), ShouldBeNil)
+				So(column(28), ShouldBeNil)
+				So(*column(29).(*string), ShouldEqual, "")
+				So(column(30), ShouldBeNil)
 			})
 		})
 
@@ -229,15 +234,15 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				frame := decodedFrame(queryResult)
 				// without fill this should result in 4 buckets
-				So(len(points), ShouldEqual, 4)
+				So(frame.Rows(), ShouldEqual, 4)
 
 				dt := fromStart
 
 				for i := 0; i < 2; i++ {
-					aValue := points[i][0].Float64
-					aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+					aValue := *frame.Fields[1].At(i).(*float64)
+					aTime := frame.Fields[0].At(i).(time.Time)
 					So(aValue, ShouldEqual, 15)
 					So(aTime, ShouldEqual, dt)
 					dt = dt.Add(5 * time.Minute)
@@ -246,8 +251,8 @@ func TestMySQL(t *testing.T) {
 				// adjust for 10 minute gap between first and second set of points
 				dt = dt.Add(10 * time.Minute)
 				for i := 2; i < 4; i++ {
-					aValue := points[i][0].Float64
-					aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+					aValue := *frame.Fields[1].At(i).(*float64)
+					aTime := frame.Fields[0].At(i).(time.Time)
 					So(aValue, ShouldEqual, 20)
 					So(aTime, ShouldEqual, dt)
 					dt = dt.Add(5 * time.Minute)
@@ -276,35 +281,35 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
-				So(len(points), ShouldEqual, 7)
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 7)
 
 				dt := fromStart
 
 				for i := 0; i < 2; i++ {
-					aValue := points[i][0].Float64
-					aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+					aValue := *frame.Fields[1].At(i).(*float64)
+					aTime := frame.Fields[0].At(i).(time.Time)
 					So(aValue, ShouldEqual, 15)
 					So(aTime, ShouldEqual, dt)
 					dt = dt.Add(5 * time.Minute)
 				}
 
 				// check for NULL values inserted by fill
-				So(points[2][0].Valid, ShouldBeFalse)
-				So(points[3][0].Valid, ShouldBeFalse)
+				So(frame.Fields[1].At(2), ShouldBeNil)
+				So(frame.Fields[1].At(3), ShouldBeNil)
 
 				// adjust for 10 minute gap between first and second set of points
 				dt = dt.Add(10 * time.Minute)
 				for i := 4; i < 6; i++ {
-					aValue := points[i][0].Float64
-					aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+					aValue := *frame.Fields[1].At(i).(*float64)
+					aTime := frame.Fields[0].At(i).(time.Time)
 					So(aValue, ShouldEqual, 20)
 					So(aTime, ShouldEqual, dt)
 					dt = dt.Add(5 * time.Minute)
 				}
 
 				// check for NULL values inserted by fill
-				So(points[6][0].Valid, ShouldBeFalse)
+				So(frame.Fields[1].At(6), ShouldBeNil)
 			})
 
 			Convey("When doing a metric query using timeGroup and $__interval", func() {
@@ -363,8 +368,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
-				So(points[3][0].Float64, ShouldEqual, 1.5)
+				frame := decodedFrame(queryResult)
+				So(*frame.Fields[1].At(3).(*float64), ShouldEqual, 1.5)
 			})
 
 			Convey("When doing a metric query using timeGroup with previous fill enabled", func() {
@@ -389,10 +394,10 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
-				So(points[2][0].Float64, ShouldEqual, 15.0)
-				So(points[3][0].Float64, ShouldEqual, 15.0)
-				So(points[6][0].Float64, ShouldEqual, 20.0)
+				frame := decodedFrame(queryResult)
+				So(*frame.Fields[1].At(2).(*float64), ShouldEqual, 15.0)
+				So(*frame.Fields[1].At(3).(*float64), ShouldEqual, 15.0)
+				So(*frame.Fields[1].At(6).(*float64), ShouldEqual, 20.0)
 			})
 		})
 
@@ -484,8 +489,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using time (nullable) as time column should return metric with time in milliseconds", func() {
@@ -506,8 +512,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int64) as time column and value column (int64) should return metric with time in milliseconds", func() {
@@ -528,8 +535,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int64 nullable) as time column and value column (int64 nullable) should return metric with time in milliseconds", func() {
@@ -550,8 +558,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64) as time column and value column (float64) should return metric with time in milliseconds", func() {
@@ -572,8 +581,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64 nullable) as time column and value column (float64 nullable) should return metric with time in milliseconds", func() {
@@ -594,8 +604,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32) as time column and value column (int32) should return metric with time in milliseconds", func() {
@@ -616,8 +627,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32 nullable) as time column and value column (int32 nullable) should return metric with time in milliseconds", func() {
@@ -638,8 +650,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float32) as time column and value column (float32) should return metric with time in milliseconds", func() {
@@ -660,8 +673,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query using epoch (float32 nullable) as time column and value column (float32 nullable) should return metric with time in milliseconds", func() {
@@ -682,8 +696,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 2)
+				So(float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6), ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query grouping by time and select metric column should return correct series", func() {
@@ -704,9 +719,10 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A - value one")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric B - value one")
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 3)
+				So(frame.Fields[1].Name, ShouldEqual, "Metric A - value one")
+				So(frame.Fields[2].Name, ShouldEqual, "Metric B - value one")
 			})
 
 			Convey("When doing a metric query with metric column and multiple value columns", func() {
@@ -727,11 +743,12 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 4)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-				So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-				So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 5)
+				So(frame.Fields[1].Name, ShouldEqual, "Metric A valueOne")
+				So(frame.Fields[2].Name, ShouldEqual, "Metric A valueTwo")
+				So(frame.Fields[3].Name, ShouldEqual, "Metric B valueOne")
+				So(frame.Fields[4].Name, ShouldEqual, "Metric B valueTwo")
 			})
 
 			Convey("When doing a metric query grouping by time should return correct series", func() {
@@ -752,9 +769,10 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "valueTwo")
+				frame := decodedFrame(queryResult)
+				So(frame.Fields, ShouldHaveLength, 3)
+				So(frame.Fields[1].Name, ShouldEqual, "valueOne")
+				So(frame.Fields[2].Name, ShouldEqual, "valueTwo")
 			})
 		})
 
@@ -835,7 +853,8 @@ func TestMySQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -858,7 +877,8 @@ func TestMySQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -885,11 +905,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				//Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.Unix()*1000))
+				So(float64(frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6), ShouldEqual, float64(dt.Unix()*1000))
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format should return ms", func() {
@@ -915,11 +935,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format (signed integer) should return ms", func() {
@@ -945,11 +965,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch millisecond format should return ms", func() {
@@ -975,11 +995,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6, ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column holding a unsigned integer null value should return nil", func() {
@@ -1003,11 +1023,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				//Should be in milliseconds
-				So(columns[0], ShouldBeNil)
+				So(frame.Fields[0].At(0), ShouldBeNil)
 			})
 
 			Convey("When doing an annotation query with a time column holding a DATETIME null value should return nil", func() {
@@ -1031,11 +1051,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				frame := decodedFrame(queryResult)
+				So(frame.Rows(), ShouldEqual, 1)
 
 				//Should be in milliseconds
-				So(columns[0], ShouldBeNil)
+				So(frame.Fields[0].At(0), ShouldBeNil)
 			})
 		})
 	})
@@ -1057,6 +1077,14 @@ func InitMySQLTestDB(t *testing.T) *xorm.Engine {
 	return x
 }
 
+// decodedFrame returns the data frame of a query result.
+func decodedFrame(queryResult *tsdb.QueryResult) *data.Frame {
+	frames, err := queryResult.Dataframes.Decoded()
+	So(err, ShouldBeNil)
+	So(frames, ShouldHaveLength, 1)
+	return frames[0]
+}
+
 func genTimeRangeByInterval(from time.Time, duration time.Duration, interval time.Duration) []time.Time {
 	durationSec := int64(duration.Seconds())
 	intervalSec := int64(interval.Seconds())
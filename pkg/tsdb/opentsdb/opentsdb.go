@@ -4,8 +4,11 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context/ctxhttp"
 
@@ -14,18 +17,30 @@ import (
 	"net/http"
 	"net/url"
 
-	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 )
 
+// annotationsQueryType is the query type of annotation queries, which return
+// the annotations of the series of a metric, or the global annotations.
+const annotationsQueryType = "annotations"
+
 type OpenTsdbExecutor struct {
+	// tsdbVersion is the configured OpenTSDB version: 1 for <=2.1, 2 for 2.2,
+	// 3 for 2.3 and 4 for 2.4.
+	tsdbVersion int
 }
 
 func NewOpenTsdbExecutor(datasource *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
-	return &OpenTsdbExecutor{}, nil
+	tsdbVersion := 1
+	if datasource.JsonData != nil {
+		tsdbVersion = datasource.JsonData.Get("tsdbVersion").MustInt(1)
+	}
+	return &OpenTsdbExecutor{tsdbVersion: tsdbVersion}, nil
 }
 
 var (
@@ -38,44 +53,60 @@ func init() {
 }
 
 func (e *OpenTsdbExecutor) Query(ctx context.Context, dsInfo *models.DataSource, queryContext *tsdb.TsdbQuery) (*tsdb.Response, error) {
-	result := &tsdb.Response{}
-
-	var tsdbQuery OpenTsdbQuery
-
-	tsdbQuery.Start = queryContext.TimeRange.GetFromAsMsEpoch()
-	tsdbQuery.End = queryContext.TimeRange.GetToAsMsEpoch()
-
-	for _, query := range queryContext.Queries {
-		metric := e.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
-	}
-
-	if setting.Env == setting.Dev {
-		plog.Debug("OpenTsdb request", "params", tsdbQuery)
-	}
-
-	req, err := e.createRequest(dsInfo, tsdbQuery)
-	if err != nil {
-		return nil, err
-	}
-
 	httpClient, err := dsInfo.GetHttpClient()
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := ctxhttp.Do(ctx, httpClient, req)
-	if err != nil {
-		return nil, err
-	}
+	return tsdb.ExecuteQueries(ctx, dsInfo, queryContext, func(ctx context.Context, query *tsdb.Query) (*tsdb.QueryResult, error) {
+		tsdbQuery := OpenTsdbQuery{
+			Start:        queryContext.TimeRange.GetFromAsMsEpoch(),
+			End:          queryContext.TimeRange.GetToAsMsEpoch(),
+			MsResolution: true,
+			ShowTSUIDs:   true,
+		}
 
-	queryResult, err := e.parseResponse(tsdbQuery, res)
-	if err != nil {
-		return nil, err
-	}
+		isAnnotationQuery := query.QueryType == annotationsQueryType
+		if isAnnotationQuery {
+			metric := query.Model.Get("target").MustString()
+			if metric == "" {
+				return nil, fmt.Errorf("annotation query has no metric")
+			}
+			tsdbQuery.Queries = []map[string]interface{}{{"metric": metric, "aggregator": "sum"}}
+			tsdbQuery.GlobalAnnotations = query.Model.Get("isGlobal").MustBool()
+		} else {
+			// Like the query editor, skip queries without a metric. Hidden
+			// queries are still executed since alert rules may use them.
+			if query.Model.Get("metric").MustString() == "" {
+				return nil, nil
+			}
+			tsdbQuery.Queries = []map[string]interface{}{e.buildMetric(query)}
+		}
+
+		if setting.Env == setting.Dev {
+			plog.Debug("OpenTsdb request", "params", tsdbQuery)
+		}
+
+		req, err := e.createRequest(dsInfo, tsdbQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := ctxhttp.Do(ctx, httpClient, req)
+		if err != nil {
+			return nil, err
+		}
+
+		responses, err := e.readResponse(res)
+		if err != nil {
+			return nil, err
+		}
 
-	result.Results = queryResult
-	return result, nil
+		if isAnnotationQuery {
+			return parseAnnotations(responses, tsdbQuery.GlobalAnnotations), nil
+		}
+		return e.parseResponse(query, responses)
+	}), nil
 }
 
 func (e *OpenTsdbExecutor) createRequest(dsInfo *models.DataSource, data OpenTsdbQuery) (*http.Request, error) {
@@ -105,10 +136,7 @@ func (e *OpenTsdbExecutor) createRequest(dsInfo *models.DataSource, data OpenTsd
 	return req, err
 }
 
-func (e *OpenTsdbExecutor) parseResponse(query OpenTsdbQuery, res *http.Response) (map[string]*tsdb.QueryResult, error) {
-	queryResults := make(map[string]*tsdb.QueryResult)
-	queryRes := tsdb.NewQueryResult()
-
+func (e *OpenTsdbExecutor) readResponse(res *http.Response) ([]OpenTsdbResponse, error) {
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
@@ -124,32 +152,139 @@ func (e *OpenTsdbExecutor) parseResponse(query OpenTsdbQuery, res *http.Response
 		return nil, fmt.Errorf("request failed, status: %s", res.Status)
 	}
 
-	var data []OpenTsdbResponse
-	err = json.Unmarshal(body, &data)
+	var responses []OpenTsdbResponse
+	err = json.Unmarshal(body, &responses)
 	if err != nil {
 		plog.Info("Failed to unmarshal opentsdb response", "error", err, "status", res.Status, "body", string(body))
 		return nil, err
 	}
 
-	for _, val := range data {
-		series := tsdb.TimeSeries{
-			Name: val.Metric,
-		}
+	return responses, nil
+}
+
+// parseResponse returns a data frame for each series of the response, with
+// the tags of the series as labels.
+func (e *OpenTsdbExecutor) parseResponse(query *tsdb.Query, responses []OpenTsdbResponse) (*tsdb.QueryResult, error) {
+	queryRes := tsdb.NewQueryResult()
+	frames := make(data.Frames, 0, len(responses))
+	groupByTags := groupByTags(query.Model)
 
-		for timeString, value := range val.DataPoints {
-			timestamp, err := strconv.ParseFloat(timeString, 64)
+	for _, val := range responses {
+		timestamps := make([]int64, 0, len(val.DataPoints))
+		for timeString := range val.DataPoints {
+			timestamp, err := strconv.ParseInt(timeString, 10, 64)
 			if err != nil {
 				plog.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
 				return nil, err
 			}
-			series.Points = append(series.Points, tsdb.NewTimePoint(null.FloatFrom(value), timestamp))
+			timestamps = append(timestamps, timestamp)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		times := make([]time.Time, len(timestamps))
+		values := make([]float64, len(timestamps))
+		for i, timestamp := range timestamps {
+			times[i] = time.Unix(0, timestamp*int64(time.Millisecond)).UTC()
+			values[i] = val.DataPoints[strconv.FormatInt(timestamp, 10)]
+		}
+
+		name := seriesName(val, query.Model.Get("alias").MustString(), groupByTags)
+		valueField := data.NewField("value", data.Labels(val.Tags), values)
+		valueField.Config = &data.FieldConfig{DisplayNameFromDS: name}
+
+		frame := data.NewFrame(name, data.NewField("time", nil, times), valueField)
+		if len(val.TSUIDs) > 0 {
+			frame.Meta = &data.FrameMeta{Custom: map[string]interface{}{"tsuids": val.TSUIDs}}
+		}
+		frames = append(frames, frame)
+	}
+
+	queryRes.Dataframes = tsdb.NewDecodedDataFrames(frames)
+	return queryRes, nil
+}
+
+// parseAnnotations returns the annotations of the first series of the
+// response, or the global annotations.
+func parseAnnotations(responses []OpenTsdbResponse, global bool) *tsdb.QueryResult {
+	frame := data.NewFrame("annotations",
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("timeEnd", nil, []*time.Time{}),
+		data.NewField("text", nil, []string{}),
+	)
+
+	if len(responses) > 0 {
+		annotations := responses[0].Annotations
+		if global {
+			annotations = responses[0].GlobalAnnotations
+		}
+
+		for _, annotation := range annotations {
+			var timeEnd *time.Time
+			if annotation.EndTime > 0 {
+				t := time.Unix(annotation.EndTime, 0).UTC()
+				timeEnd = &t
+			}
+			frame.AppendRow(time.Unix(annotation.StartTime, 0).UTC(), timeEnd, annotation.Description)
 		}
+	}
+
+	queryRes := tsdb.NewQueryResult()
+	queryRes.Dataframes = tsdb.NewDecodedDataFrames(data.Frames{frame})
+	return queryRes
+}
+
+var aliasTagPattern = regexp.MustCompile(`\$tag_(\w+)|\[\[tag_(\w+)\]\]`)
+
+// seriesName names a series like the query editor: the alias with the
+// $tag_<key> patterns replaced, or the metric followed by the grouped by
+// tags.
+func seriesName(val OpenTsdbResponse, alias string, groupByTags map[string]bool) string {
+	if alias != "" {
+		return aliasTagPattern.ReplaceAllStringFunc(alias, func(match string) string {
+			submatches := aliasTagPattern.FindStringSubmatch(match)
+			key := submatches[1]
+			if key == "" {
+				key = submatches[2]
+			}
+			if value, ok := val.Tags[key]; ok {
+				return value
+			}
+			return match
+		})
+	}
 
-		queryRes.Series = append(queryRes.Series, &series)
+	keys := make([]string, 0, len(val.Tags))
+	for k := range val.Tags {
+		if groupByTags[k] {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return val.Metric
 	}
+	sort.Strings(keys)
 
-	queryResults["A"] = queryRes
-	return queryResults, nil
+	tags := make([]string, 0, len(keys))
+	for _, k := range keys {
+		tags = append(tags, k+"="+val.Tags[k])
+	}
+	return val.Metric + "{" + strings.Join(tags, ", ") + "}"
+}
+
+// groupByTags returns the tags a query groups by: the keys of its tags, or the
+// keys of its filters that group by.
+func groupByTags(model *simplejson.Json) map[string]bool {
+	result := make(map[string]bool)
+	for k := range model.Get("tags").MustMap() {
+		result[k] = true
+	}
+	for _, f := range model.Get("filters").MustArray() {
+		filter := simplejson.NewFromAny(f)
+		if filter.Get("groupBy").MustBool() {
+			result[filter.Get("tagk").MustString()] = true
+		}
+	}
+	return result
 }
 
 func (e *OpenTsdbExecutor) buildMetric(query *tsdb.Query) map[string]interface{} {
@@ -157,7 +292,7 @@ func (e *OpenTsdbExecutor) buildMetric(query *tsdb.Query) map[string]interface{}
 
 	// Setting metric and aggregator
 	metric["metric"] = query.Model.Get("metric").MustString()
-	metric["aggregator"] = query.Model.Get("aggregator").MustString()
+	metric["aggregator"] = query.Model.Get("aggregator").MustString("avg")
 
 	// Setting downsampling options
 	disableDownsampling := query.Model.Get("disableDownsampling").MustBool()
@@ -165,10 +300,22 @@ func (e *OpenTsdbExecutor) buildMetric(query *tsdb.Query) map[string]interface{}
 		downsampleInterval := query.Model.Get("downsampleInterval").MustString()
 		if downsampleInterval == "" {
 			downsampleInterval = "1m" // default value for blank
+			if query.IntervalMs%1000 != 0 {
+				downsampleInterval = strconv.FormatInt(query.IntervalMs, 10) + "ms"
+			} else if query.IntervalMs > 0 {
+				downsampleInterval = tsdb.FormatDuration(time.Duration(query.IntervalMs) * time.Millisecond)
+			}
+		}
+		// OpenTSDB does not support fractional intervals.
+		if fractionalSeconds.MatchString(downsampleInterval) {
+			if seconds, err := strconv.ParseFloat(strings.TrimSuffix(downsampleInterval, "s"), 64); err == nil {
+				downsampleInterval = strconv.FormatFloat(seconds*1000, 'f', -1, 64) + "ms"
+			}
 		}
 		downsample := downsampleInterval + "-" + query.Model.Get("downsampleAggregator").MustString()
-		if query.Model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + query.Model.Get("downsampleFillPolicy").MustString()
+		fillPolicy := query.Model.Get("downsampleFillPolicy").MustString()
+		if fillPolicy != "" && fillPolicy != "none" {
+			metric["downsample"] = downsample + "-" + fillPolicy
 		} else {
 			metric["downsample"] = downsample
 		}
@@ -180,34 +327,54 @@ func (e *OpenTsdbExecutor) buildMetric(query *tsdb.Query) map[string]interface{}
 		rateOptions := make(map[string]interface{})
 		rateOptions["counter"] = query.Model.Get("isCounter").MustBool()
 
-		counterMax, counterMaxCheck := query.Model.CheckGet("counterMax")
+		counterMax, counterMaxCheck := parseNumber(query.Model.Get("counterMax"))
 		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
+			rateOptions["counterMax"] = counterMax
 		}
 
-		resetValue, resetValueCheck := query.Model.CheckGet("counterResetValue")
+		resetValue, resetValueCheck := parseNumber(query.Model.Get("counterResetValue"))
 		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
+			rateOptions["resetValue"] = resetValue
 		}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
+		// Dropping resets requires OpenTSDB 2.2.
+		if e.tsdbVersion >= 2 && !counterMaxCheck && (!resetValueCheck || resetValue == 0) {
 			rateOptions["dropResets"] = true
 		}
 
 		metric["rateOptions"] = rateOptions
 	}
 
-	// Setting tags
-	tags, tagsCheck := query.Model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
-		metric["tags"] = tags.MustMap()
-	}
-
-	// Setting filters
+	// Setting filters, which replace the tags since OpenTSDB 2.2
 	filters, filtersCheck := query.Model.CheckGet("filters")
 	if filtersCheck && len(filters.MustArray()) > 0 {
 		metric["filters"] = filters.MustArray()
+	} else {
+		tags, tagsCheck := query.Model.CheckGet("tags")
+		if tagsCheck && len(tags.MustMap()) > 0 {
+			metric["tags"] = tags.MustMap()
+		}
+	}
+
+	if query.Model.Get("explicitTags").MustBool() {
+		metric["explicitTags"] = true
 	}
 
 	return metric
 }
+
+var fractionalSeconds = regexp.MustCompile(`\.[0-9]+s$`)
+
+// parseNumber returns the value of a numeric query option, which the query
+// editor stores as a string. Empty options are not set.
+func parseNumber(value *simplejson.Json) (float64, bool) {
+	if f, err := value.Float64(); err == nil {
+		return f, true
+	}
+	if s, err := value.String(); err == nil && s != "" {
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f, true
+		}
+	}
+	return 0, false
+}
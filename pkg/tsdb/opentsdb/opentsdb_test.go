@@ -170,4 +170,81 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(45), metricRateOptions["counterMax"])
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
+
+	t.Run("Build metric with defaults from the query", func(t *testing.T) {
+		query := &tsdb.Query{
+			Model:      simplejson.New(),
+			IntervalMs: 1500,
+		}
+
+		query.Model.Set("metric", "cpu.average.percent")
+		query.Model.Set("downsampleAggregator", "avg")
+		query.Model.Set("downsampleFillPolicy", "nan")
+
+		metric := exec.buildMetric(query)
+
+		require.Len(t, metric, 3)
+		require.Equal(t, "avg", metric["aggregator"])
+		require.Equal(t, "1500ms-avg-nan", metric["downsample"])
+	})
+
+	t.Run("Build metric with filters and explicit tags", func(t *testing.T) {
+		query := &tsdb.Query{
+			Model: simplejson.New(),
+		}
+
+		query.Model.Set("metric", "cpu.average.percent")
+		query.Model.Set("aggregator", "sum")
+		query.Model.Set("disableDownsampling", true)
+		query.Model.Set("explicitTags", true)
+		query.Model.Set("tags", map[string]interface{}{"env": "prod"})
+		query.Model.Set("filters", []interface{}{
+			map[string]interface{}{"type": "wildcard", "tagk": "host", "filter": "server-*", "groupBy": true},
+		})
+
+		metric := exec.buildMetric(query)
+
+		require.Len(t, metric, 4)
+		require.Nil(t, metric["tags"])
+		require.True(t, metric["explicitTags"].(bool))
+		filters := metric["filters"].([]interface{})
+		require.Len(t, filters, 1)
+		require.Equal(t, "server-*", filters[0].(map[string]interface{})["filter"])
+	})
+
+	t.Run("Build metric with rate options from strings", func(t *testing.T) {
+		query := &tsdb.Query{
+			Model: simplejson.New(),
+		}
+
+		query.Model.Set("metric", "cpu.average.percent")
+		query.Model.Set("disableDownsampling", true)
+		query.Model.Set("shouldComputeRate", true)
+		query.Model.Set("isCounter", true)
+		query.Model.Set("counterMax", "1000")
+		query.Model.Set("counterResetValue", "")
+
+		metric := (&OpenTsdbExecutor{tsdbVersion: 2}).buildMetric(query)
+
+		metricRateOptions := metric["rateOptions"].(map[string]interface{})
+		require.Len(t, metricRateOptions, 2)
+		require.Equal(t, float64(1000), metricRateOptions["counterMax"])
+	})
+
+	t.Run("Build metric dropping resets", func(t *testing.T) {
+		query := &tsdb.Query{
+			Model: simplejson.New(),
+		}
+
+		query.Model.Set("metric", "cpu.average.percent")
+		query.Model.Set("disableDownsampling", true)
+		query.Model.Set("shouldComputeRate", true)
+		query.Model.Set("isCounter", true)
+
+		metric := (&OpenTsdbExecutor{tsdbVersion: 2}).buildMetric(query)
+		require.True(t, metric["rateOptions"].(map[string]interface{})["dropResets"].(bool))
+
+		metric = exec.buildMetric(query)
+		require.Nil(t, metric["rateOptions"].(map[string]interface{})["dropResets"])
+	})
 }
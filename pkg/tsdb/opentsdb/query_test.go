@@ -0,0 +1,154 @@
+package opentsdb
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	var request OpenTsdbQuery
+	var fixture string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		request = OpenTsdbQuery{}
+		require.NoError(t, json.Unmarshal(body, &request))
+
+		response, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+		require.NoError(t, err)
+		_, _ = rw.Write(response)
+	}))
+	t.Cleanup(server.Close)
+
+	ds := &models.DataSource{Url: server.URL, JsonData: simplejson.NewFromAny(map[string]interface{}{"tsdbVersion": 3})}
+	executor, err := NewOpenTsdbExecutor(ds)
+	require.NoError(t, err)
+
+	query := func(t *testing.T, queryType string, model map[string]interface{}) *tsdb.QueryResult {
+		t.Helper()
+		resp, err := executor.Query(context.Background(), ds, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1526406600000", "1526410200000"),
+			Queries: []*tsdb.Query{
+				{RefId: "A", QueryType: queryType, Model: simplejson.NewFromAny(model)},
+			},
+		})
+		require.NoError(t, err)
+		res := resp.Results["A"]
+		require.NotNil(t, res)
+		require.NoError(t, res.Error)
+		return res
+	}
+
+	t.Run("returns a frame per series with the tags as labels", func(t *testing.T) {
+		fixture = "series.json"
+		res := query(t, "", map[string]interface{}{
+			"metric":              "cpu.average.percent",
+			"aggregator":          "avg",
+			"disableDownsampling": true,
+			"filters": []interface{}{
+				map[string]interface{}{"type": "wildcard", "tagk": "host", "filter": "*", "groupBy": true},
+				map[string]interface{}{"type": "literal_or", "tagk": "env", "filter": "prod", "groupBy": false},
+			},
+		})
+
+		assert.Equal(t, int64(1526406600000), request.Start)
+		assert.True(t, request.MsResolution)
+		assert.True(t, request.ShowTSUIDs)
+		require.Len(t, request.Queries, 1)
+		assert.Len(t, request.Queries[0]["filters"], 2)
+
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+
+		frame := frames[0]
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.Unix(1526406600, 0).UTC(), frame.Fields[0].At(0))
+		assert.Equal(t, time.Unix(1526406660, 0).UTC(), frame.Fields[0].At(1))
+		assert.Equal(t, 50.0, frame.Fields[1].At(0))
+		assert.Equal(t, 52.5, frame.Fields[1].At(1))
+		assert.Equal(t, "server-1", frame.Fields[1].Labels["host"])
+		assert.Equal(t, "cpu.average.percent{host=server-1}", frame.Fields[1].Config.DisplayNameFromDS)
+		assert.Equal(t, []string{"000001000001000001000002000002"}, frame.Meta.Custom.(map[string]interface{})["tsuids"])
+	})
+
+	t.Run("names the series with the alias", func(t *testing.T) {
+		fixture = "series.json"
+		res := query(t, "", map[string]interface{}{
+			"metric": "cpu.average.percent",
+			"alias":  "$tag_host in [[tag_env]] $tag_missing",
+		})
+
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		assert.Equal(t, "server-2 in prod $tag_missing", frames[1].Fields[1].Config.DisplayNameFromDS)
+	})
+
+	t.Run("executes hidden queries", func(t *testing.T) {
+		fixture = "series.json"
+		res := query(t, "", map[string]interface{}{"metric": "cpu.average.percent", "hide": true})
+
+		require.Len(t, request.Queries, 1)
+		assert.Equal(t, "cpu.average.percent", request.Queries[0]["metric"])
+
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		assert.Len(t, frames, 2)
+	})
+
+	t.Run("skips queries without a metric", func(t *testing.T) {
+		resp, err := executor.Query(context.Background(), ds, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1526406600000", "1526410200000"),
+			Queries: []*tsdb.Query{
+				{RefId: "A", Model: simplejson.NewFromAny(map[string]interface{}{"hide": true})},
+			},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, resp.Results["A"])
+	})
+
+	t.Run("returns the annotations of the metric", func(t *testing.T) {
+		fixture = "annotations.json"
+		res := query(t, annotationsQueryType, map[string]interface{}{"target": "deploys"})
+
+		assert.False(t, request.GlobalAnnotations)
+		assert.Equal(t, []map[string]interface{}{{"metric": "deploys", "aggregator": "sum"}}, request.Queries)
+
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, 1, frame.Rows())
+		assert.Equal(t, time.Unix(1526406700, 0).UTC(), frame.Fields[0].At(0))
+		assert.Nil(t, frame.Fields[1].At(0))
+		assert.Equal(t, "Deployed v1.2.3", frame.Fields[2].At(0))
+	})
+
+	t.Run("returns the global annotations", func(t *testing.T) {
+		fixture = "annotations.json"
+		res := query(t, annotationsQueryType, map[string]interface{}{"target": "deploys", "isGlobal": true})
+
+		assert.True(t, request.GlobalAnnotations)
+
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		frame := frames[0]
+		require.Equal(t, 1, frame.Rows())
+		end := time.Unix(1526407400, 0).UTC()
+		assert.Equal(t, &end, frame.Fields[1].At(0))
+		assert.Equal(t, "Maintenance window", frame.Fields[2].At(0))
+	})
+}
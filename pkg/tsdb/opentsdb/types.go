@@ -1,12 +1,29 @@
 package opentsdb
 
 type OpenTsdbQuery struct {
-	Start   int64                    `json:"start"`
-	End     int64                    `json:"end"`
-	Queries []map[string]interface{} `json:"queries"`
+	Start             int64                    `json:"start"`
+	End               int64                    `json:"end"`
+	Queries           []map[string]interface{} `json:"queries"`
+	MsResolution      bool                     `json:"msResolution"`
+	GlobalAnnotations bool                     `json:"globalAnnotations"`
+	ShowTSUIDs        bool                     `json:"showTSUIDs"`
 }
 
 type OpenTsdbResponse struct {
-	Metric     string             `json:"metric"`
-	DataPoints map[string]float64 `json:"dps"`
+	Metric            string               `json:"metric"`
+	Tags              map[string]string    `json:"tags"`
+	AggregateTags     []string             `json:"aggregateTags"`
+	DataPoints        map[string]float64   `json:"dps"`
+	Annotations       []OpenTsdbAnnotation `json:"annotations"`
+	GlobalAnnotations []OpenTsdbAnnotation `json:"globalAnnotations"`
+	TSUIDs            []string             `json:"tsuids"`
+}
+
+type OpenTsdbAnnotation struct {
+	TSUID       string                 `json:"tsuid"`
+	Description string                 `json:"description"`
+	Notes       string                 `json:"notes"`
+	Custom      map[string]interface{} `json:"custom"`
+	StartTime   int64                  `json:"startTime"`
+	EndTime     int64                  `json:"endTime"`
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/setting"
@@ -58,6 +59,9 @@ func (s *postgresService) newPostgresQueryEndpoint(datasource *models.DataSource
 		ConnectionString:  cnnstr,
 		Datasource:        datasource,
 		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
+		StatementTimeoutSQL: func(timeout time.Duration) string {
+			return fmt.Sprintf("SET statement_timeout = %d", timeout.Milliseconds())
+		},
 	}
 
 	queryResultTransformer := postgresQueryResultTransformer{
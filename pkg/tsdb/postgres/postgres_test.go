@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -250,32 +251,31 @@ func TestPostgres(t *testing.T) {
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
 
-			column := queryResult.Tables[0].Rows[0]
-			require.Equal(t, int64(1), column[0].(int64))
-			require.Equal(t, int64(2), column[1].(int64))
-			require.Equal(t, int64(3), column[2].(int64))
-
-			require.Equal(t, float64(4.5), column[3].(float64))
-			require.Equal(t, float64(6.7), column[4].(float64))
-			require.Equal(t, float64(1.1), column[5].(float64))
-			require.Equal(t, float64(1.2), column[6].(float64))
-
-			require.Equal(t, "char10    ", column[7].(string))
-			require.Equal(t, "varchar10", column[8].(string))
-			require.Equal(t, "text", column[9].(string))
-
-			_, ok := column[10].(time.Time)
-			require.True(t, ok)
-			_, ok = column[11].(time.Time)
-			require.True(t, ok)
-			_, ok = column[12].(time.Time)
-			require.True(t, ok)
-			_, ok = column[13].(time.Time)
-			require.True(t, ok)
-			_, ok = column[14].(time.Time)
-			require.True(t, ok)
-
-			require.Equal(t, "00:15:00", column[15].(string))
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 1, frame.Rows())
+			column := func(i int) interface{} {
+				return frame.Fields[i].At(0)
+			}
+
+			require.Equal(t, int64(1), *column(0).(*int64))
+			require.Equal(t, int64(2), *column(1).(*int64))
+			require.Equal(t, int64(3), *column(2).(*int64))
+
+			require.Equal(t, float64(4.5), *column(3).(*float64))
+			require.Equal(t, float64(6.7), *column(4).(*float64))
+			require.Equal(t, float64(1.1), *column(5).(*float64))
+			require.Equal(t, float64(1.2), *column(6).(*float64))
+
+			require.Equal(t, "char10    ", *column(7).(*string))
+			require.Equal(t, "varchar10", *column(8).(*string))
+			require.Equal(t, "text", *column(9).(*string))
+
+			for i := 10; i <= 14; i++ {
+				require.Equal(t, data.FieldTypeNullableTime, frame.Fields[i].Type())
+				require.NotNil(t, column(i))
+			}
+
+			require.Equal(t, "00:15:00", *column(15).(*string))
 		})
 	})
 
@@ -335,15 +335,15 @@ func TestPostgres(t *testing.T) {
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
 
-			points := queryResult.Series[0].Points
+			frame := decodedFrame(t, queryResult)
 			// without fill this should result in 4 buckets
-			require.Len(t, points, 4)
+			require.Equal(t, 4, frame.Rows())
 
 			dt := fromStart
 
 			for i := 0; i < 2; i++ {
-				aValue := points[i][0].Float64
-				aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+				aValue := *frame.Fields[1].At(i).(*float64)
+				aTime := frame.Fields[0].At(i).(time.Time)
 				require.Equal(t, float64(15), aValue)
 				require.Equal(t, dt, aTime)
 				require.Equal(t, int64(0), aTime.Unix()%300)
@@ -353,8 +353,8 @@ func TestPostgres(t *testing.T) {
 			// adjust for 10 minute gap between first and second set of points
 			dt = dt.Add(10 * time.Minute)
 			for i := 2; i < 4; i++ {
-				aValue := points[i][0].Float64
-				aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+				aValue := *frame.Fields[1].At(i).(*float64)
+				aTime := frame.Fields[0].At(i).(time.Time)
 				require.Equal(t, float64(20), aValue)
 				require.Equal(t, dt, aTime)
 				dt = dt.Add(5 * time.Minute)
@@ -416,35 +416,35 @@ func TestPostgres(t *testing.T) {
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
 
-			points := queryResult.Series[0].Points
-			require.Len(t, points, 7)
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 7, frame.Rows())
 
 			dt := fromStart
 
 			for i := 0; i < 2; i++ {
-				aValue := points[i][0].Float64
-				aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+				aValue := *frame.Fields[1].At(i).(*float64)
+				aTime := frame.Fields[0].At(i).(time.Time)
 				require.Equal(t, float64(15), aValue)
 				require.Equal(t, dt, aTime)
 				dt = dt.Add(5 * time.Minute)
 			}
 
 			// check for NULL values inserted by fill
-			require.False(t, points[2][0].Valid)
-			require.False(t, points[3][0].Valid)
+			require.Nil(t, frame.Fields[1].At(2))
+			require.Nil(t, frame.Fields[1].At(3))
 
 			// adjust for 10 minute gap between first and second set of points
 			dt = dt.Add(10 * time.Minute)
 			for i := 4; i < 6; i++ {
-				aValue := points[i][0].Float64
-				aTime := time.Unix(int64(points[i][1].Float64)/1000, 0)
+				aValue := *frame.Fields[1].At(i).(*float64)
+				aTime := frame.Fields[0].At(i).(time.Time)
 				require.Equal(t, float64(20), aValue)
 				require.Equal(t, dt, aTime)
 				dt = dt.Add(5 * time.Minute)
 			}
 
 			// check for NULL values inserted by fill
-			require.False(t, points[6][0].Valid)
+			require.Nil(t, frame.Fields[1].At(6))
 		})
 
 		t.Run("When doing a metric query using timeGroup with value fill enabled", func(t *testing.T) {
@@ -469,8 +469,8 @@ func TestPostgres(t *testing.T) {
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
 
-			points := queryResult.Series[0].Points
-			require.Equal(t, float64(1.5), points[3][0].Float64)
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, float64(1.5), *frame.Fields[1].At(3).(*float64))
 		})
 	})
 
@@ -496,10 +496,10 @@ func TestPostgres(t *testing.T) {
 		queryResult := resp.Results["A"]
 		require.NoError(t, queryResult.Error)
 
-		points := queryResult.Series[0].Points
-		require.Equal(t, float64(15.0), points[2][0].Float64)
-		require.Equal(t, float64(15.0), points[3][0].Float64)
-		require.Equal(t, float64(20.0), points[6][0].Float64)
+		frame := decodedFrame(t, queryResult)
+		require.Equal(t, float64(15.0), *frame.Fields[1].At(2).(*float64))
+		require.Equal(t, float64(15.0), *frame.Fields[1].At(3).(*float64))
+		require.Equal(t, float64(20.0), *frame.Fields[1].At(6).(*float64))
 	})
 
 	t.Run("Given a table with metrics having multiple values and measurements", func(t *testing.T) {
@@ -589,8 +589,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				require.NoError(t, queryResult.Error)
 
-				require.Equal(t, 1, len(queryResult.Series))
-				require.Equal(t, float64(tInitial.UnixNano()/1e6), queryResult.Series[0].Points[0][1].Float64)
+				frame := decodedFrame(t, queryResult)
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, float64(tInitial.UnixNano()/1e6), float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6))
 			})
 
 		t.Run("When doing a metric query using epoch (int64 nullable) as time column and value column (int64 nullable,) should return metric with time in milliseconds",
@@ -612,8 +613,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				require.NoError(t, queryResult.Error)
 
-				require.Len(t, queryResult.Series, 1)
-				require.Equal(t, float64(tInitial.UnixNano()/1e6), queryResult.Series[0].Points[0][1].Float64)
+				frame := decodedFrame(t, queryResult)
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, float64(tInitial.UnixNano()/1e6), float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6))
 			})
 
 		t.Run("When doing a metric query using epoch (float64) as time column and value column (float64), should return metric with time in milliseconds",
@@ -635,8 +637,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				require.NoError(t, queryResult.Error)
 
-				require.Len(t, queryResult.Series, 1)
-				require.Equal(t, float64(tInitial.UnixNano()/1e6), queryResult.Series[0].Points[0][1].Float64)
+				frame := decodedFrame(t, queryResult)
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, float64(tInitial.UnixNano()/1e6), float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6))
 			})
 
 		t.Run("When doing a metric query using epoch (float64 nullable) as time column and value column (float64 nullable), should return metric with time in milliseconds",
@@ -658,8 +661,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				require.NoError(t, queryResult.Error)
 
-				require.Len(t, queryResult.Series, 1)
-				require.Equal(t, float64(tInitial.UnixNano()/1e6), queryResult.Series[0].Points[0][1].Float64)
+				frame := decodedFrame(t, queryResult)
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, float64(tInitial.UnixNano()/1e6), float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6))
 			})
 
 		t.Run("When doing a metric query using epoch (int32) as time column and value column (int32), should return metric with time in milliseconds",
@@ -681,8 +685,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				require.NoError(t, queryResult.Error)
 
-				require.Len(t, queryResult.Series, 1)
-				require.Equal(t, float64(tInitial.UnixNano()/1e6), queryResult.Series[0].Points[0][1].Float64)
+				frame := decodedFrame(t, queryResult)
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, float64(tInitial.UnixNano()/1e6), float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6))
 			})
 
 		t.Run("When doing a metric query using epoch (int32 nullable) as time column and value column (int32 nullable), should return metric with time in milliseconds",
@@ -704,8 +709,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				require.NoError(t, queryResult.Error)
 
-				require.Len(t, queryResult.Series, 1)
-				require.Equal(t, float64(tInitial.UnixNano()/1e6), queryResult.Series[0].Points[0][1].Float64)
+				frame := decodedFrame(t, queryResult)
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, float64(tInitial.UnixNano()/1e6), float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6))
 			})
 
 		t.Run("When doing a metric query using epoch (float32) as time column and value column (float32), should return metric with time in milliseconds",
@@ -727,8 +733,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				require.NoError(t, queryResult.Error)
 
-				require.Len(t, queryResult.Series, 1)
-				require.Equal(t, float64(float32(tInitial.Unix()))*1e3, queryResult.Series[0].Points[0][1].Float64)
+				frame := decodedFrame(t, queryResult)
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, float64(float32(tInitial.Unix()))*1e3, float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6))
 			})
 
 		t.Run("When doing a metric query using epoch (float32 nullable) as time column and value column (float32 nullable), should return metric with time in milliseconds",
@@ -750,8 +757,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				require.NoError(t, queryResult.Error)
 
-				require.Len(t, queryResult.Series, 1)
-				require.Equal(t, float64(float32(tInitial.Unix()))*1e3, queryResult.Series[0].Points[0][1].Float64)
+				frame := decodedFrame(t, queryResult)
+				require.Len(t, frame.Fields, 2)
+				require.Equal(t, float64(float32(tInitial.Unix()))*1e3, float64(frame.Fields[0].At(0).(time.Time).UnixNano()/1e6))
 			})
 
 		t.Run("When doing a metric query grouping by time and select metric column should return correct series", func(t *testing.T) {
@@ -772,9 +780,10 @@ func TestPostgres(t *testing.T) {
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
 
-			require.Len(t, queryResult.Series, 2)
-			require.Equal(t, "Metric A - value one", queryResult.Series[0].Name)
-			require.Equal(t, "Metric B - value one", queryResult.Series[1].Name)
+			frame := decodedFrame(t, queryResult)
+			require.Len(t, frame.Fields, 3)
+			require.Equal(t, "Metric A - value one", frame.Fields[1].Name)
+			require.Equal(t, "Metric B - value one", frame.Fields[2].Name)
 		})
 
 		t.Run("When doing a metric query with metric column and multiple value columns", func(t *testing.T) {
@@ -795,11 +804,12 @@ func TestPostgres(t *testing.T) {
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
 
-			require.Len(t, queryResult.Series, 4)
-			require.Equal(t, "Metric A valueOne", queryResult.Series[0].Name)
-			require.Equal(t, "Metric A valueTwo", queryResult.Series[1].Name)
-			require.Equal(t, "Metric B valueOne", queryResult.Series[2].Name)
-			require.Equal(t, "Metric B valueTwo", queryResult.Series[3].Name)
+			frame := decodedFrame(t, queryResult)
+			require.Len(t, frame.Fields, 5)
+			require.Equal(t, "Metric A valueOne", frame.Fields[1].Name)
+			require.Equal(t, "Metric A valueTwo", frame.Fields[2].Name)
+			require.Equal(t, "Metric B valueOne", frame.Fields[3].Name)
+			require.Equal(t, "Metric B valueTwo", frame.Fields[4].Name)
 		})
 
 		t.Run("When doing a metric query grouping by time should return correct series", func(t *testing.T) {
@@ -820,9 +830,10 @@ func TestPostgres(t *testing.T) {
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
 
-			require.Len(t, queryResult.Series, 2)
-			require.Equal(t, "valueOne", queryResult.Series[0].Name)
-			require.Equal(t, "valueTwo", queryResult.Series[1].Name)
+			frame := decodedFrame(t, queryResult)
+			require.Len(t, frame.Fields, 3)
+			require.Equal(t, "valueOne", frame.Fields[1].Name)
+			require.Equal(t, "valueTwo", frame.Fields[2].Name)
 		})
 
 		t.Run("When doing a query with timeFrom,timeTo,unixEpochFrom,unixEpochTo macros", func(t *testing.T) {
@@ -910,7 +921,8 @@ func TestPostgres(t *testing.T) {
 			resp, err := endpoint.Query(context.Background(), nil, query)
 			queryResult := resp.Results["Deploys"]
 			require.NoError(t, err)
-			require.Len(t, queryResult.Tables[0].Rows, 3)
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 3, frame.Rows())
 		})
 
 		t.Run("When doing an annotation query of ticket events should return expected result", func(t *testing.T) {
@@ -933,7 +945,8 @@ func TestPostgres(t *testing.T) {
 			resp, err := endpoint.Query(context.Background(), nil, query)
 			queryResult := resp.Results["Tickets"]
 			require.NoError(t, err)
-			require.Len(t, queryResult.Tables[0].Rows, 3)
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 3, frame.Rows())
 		})
 
 		t.Run("When doing an annotation query with a time column in datetime format", func(t *testing.T) {
@@ -960,11 +973,11 @@ func TestPostgres(t *testing.T) {
 			require.NoError(t, err)
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
-			require.Len(t, queryResult.Tables[0].Rows, 1)
-			columns := queryResult.Tables[0].Rows[0]
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 1, frame.Rows())
 
 			//Should be in milliseconds
-			require.Equal(t, float64(dt.UnixNano()/1e6), columns[0].(float64))
+			require.Equal(t, float64(dt.UnixNano()/1e6), float64(frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6))
 		})
 
 		t.Run("When doing an annotation query with a time column in epoch second format should return ms", func(t *testing.T) {
@@ -990,11 +1003,11 @@ func TestPostgres(t *testing.T) {
 			require.NoError(t, err)
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
-			require.Len(t, queryResult.Tables[0].Rows, 1)
-			columns := queryResult.Tables[0].Rows[0]
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 1, frame.Rows())
 
 			//Should be in milliseconds
-			require.Equal(t, dt.Unix()*1000, columns[0].(int64))
+			require.Equal(t, dt.Unix()*1000, frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6)
 		})
 
 		t.Run("When doing an annotation query with a time column in epoch second format (t *testing.Tint) should return ms", func(t *testing.T) {
@@ -1020,11 +1033,11 @@ func TestPostgres(t *testing.T) {
 			require.NoError(t, err)
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
-			require.Len(t, queryResult.Tables[0].Rows, 1)
-			columns := queryResult.Tables[0].Rows[0]
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 1, frame.Rows())
 
 			//Should be in milliseconds
-			require.Equal(t, dt.Unix()*1000, columns[0].(int64))
+			require.Equal(t, dt.Unix()*1000, frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6)
 		})
 
 		t.Run("When doing an annotation query with a time column in epoch millisecond format should return ms", func(t *testing.T) {
@@ -1050,11 +1063,11 @@ func TestPostgres(t *testing.T) {
 			require.NoError(t, err)
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
-			require.Len(t, queryResult.Tables[0].Rows, 1)
-			columns := queryResult.Tables[0].Rows[0]
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 1, frame.Rows())
 
 			//Should be in milliseconds
-			require.Equal(t, dt.Unix()*1000, columns[0].(int64))
+			require.Equal(t, dt.Unix()*1000, frame.Fields[0].At(0).(*time.Time).UnixNano()/1e6)
 		})
 
 		t.Run("When doing an annotation query with a time column holding a bigint null value should return nil", func(t *testing.T) {
@@ -1078,11 +1091,11 @@ func TestPostgres(t *testing.T) {
 			require.NoError(t, err)
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
-			require.Len(t, queryResult.Tables[0].Rows, 1)
-			columns := queryResult.Tables[0].Rows[0]
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 1, frame.Rows())
 
 			//Should be in milliseconds
-			require.Nil(t, columns[0])
+			require.Nil(t, frame.Fields[0].At(0))
 		})
 
 		t.Run("When doing an annotation query with a time column holding a timestamp null value should return nil", func(t *testing.T) {
@@ -1106,11 +1119,11 @@ func TestPostgres(t *testing.T) {
 			require.NoError(t, err)
 			queryResult := resp.Results["A"]
 			require.NoError(t, queryResult.Error)
-			require.Len(t, queryResult.Tables[0].Rows, 1)
-			columns := queryResult.Tables[0].Rows[0]
+			frame := decodedFrame(t, queryResult)
+			require.Equal(t, 1, frame.Rows())
 
 			//Should be in milliseconds
-			assert.Nil(t, columns[0])
+			assert.Nil(t, frame.Fields[0].At(0))
 		})
 	})
 }
@@ -1129,6 +1142,15 @@ func InitPostgresTestDB(t *testing.T) *xorm.Engine {
 	return x
 }
 
+// decodedFrame returns the data frame of a query result.
+func decodedFrame(t *testing.T, queryResult *tsdb.QueryResult) *data.Frame {
+	t.Helper()
+	frames, err := queryResult.Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	return frames[0]
+}
+
 func genTimeRangeByInterval(from time.Time, duration time.Duration, interval time.Duration) []time.Time {
 	durationSec := int64(duration.Seconds())
 	intervalSec := int64(interval.Seconds())
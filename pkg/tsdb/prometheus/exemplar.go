@@ -0,0 +1,158 @@
+package prometheus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/api"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+)
+
+const exemplarsPath = "/api/v1/query_exemplars"
+
+// exemplarResult holds the exemplars of a single series as returned by
+// /api/v1/query_exemplars.
+type exemplarResult struct {
+	SeriesLabels map[string]string `json:"seriesLabels"`
+	Exemplars    []exemplar        `json:"exemplars"`
+}
+
+type exemplar struct {
+	Labels    map[string]string `json:"labels"`
+	Value     string            `json:"value"`
+	Timestamp float64           `json:"timestamp"`
+}
+
+type exemplarResponse struct {
+	Status    string           `json:"status"`
+	Data      []exemplarResult `json:"data"`
+	ErrorType string           `json:"errorType"`
+	Error     string           `json:"error"`
+}
+
+// queryExemplars fetches the exemplars of the series selected by the query.
+// The client_golang version in use has no API for this endpoint yet.
+func queryExemplars(ctx context.Context, client api.Client, query *PrometheusQuery) ([]exemplarResult, error) {
+	u := client.URL(exemplarsPath, nil)
+	params := u.Query()
+	params.Set("query", query.Expr)
+	params.Set("start", formatTime(query.Start))
+	params.Set("end", formatTime(query.End))
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, body, err := client.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var response exemplarResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		if resp.StatusCode/100 != 2 {
+			return nil, &apiv1.Error{
+				Type:   apiv1.ErrServer,
+				Msg:    fmt.Sprintf("server error: %d", resp.StatusCode),
+				Detail: string(body),
+			}
+		}
+		return nil, &apiv1.Error{Type: apiv1.ErrBadResponse, Msg: err.Error()}
+	}
+
+	if response.Status != "success" {
+		return nil, &apiv1.Error{
+			Type: apiv1.ErrorType(response.ErrorType),
+			Msg:  response.Error,
+		}
+	}
+
+	return response.Data, nil
+}
+
+// exemplarsToFrame converts exemplars into a single frame with a time and a
+// value field followed by one string field per label, sorted by name. Series
+// labels are included so that exemplars can be matched to their series.
+func exemplarsToFrame(results []exemplarResult, query *PrometheusQuery) *data.Frame {
+	labelNames := map[string]struct{}{}
+	count := 0
+	for _, result := range results {
+		for name := range result.SeriesLabels {
+			labelNames[name] = struct{}{}
+		}
+		for _, e := range result.Exemplars {
+			for name := range e.Labels {
+				labelNames[name] = struct{}{}
+			}
+		}
+		count += len(result.Exemplars)
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(labelNames))
+	for name := range labelNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	times := make([]time.Time, 0, count)
+	values := make([]float64, 0, count)
+	labelValues := make([][]string, len(names))
+	for _, result := range results {
+		for _, e := range result.Exemplars {
+			ms := int64(math.Round(e.Timestamp * 1000))
+			times = append(times, time.Unix(0, ms*int64(time.Millisecond)).UTC())
+
+			value, err := strconv.ParseFloat(e.Value, 64)
+			if err != nil {
+				value = math.NaN()
+			}
+			values = append(values, value)
+
+			for i, name := range names {
+				// Exemplar labels win over series labels of the same name.
+				v, ok := e.Labels[name]
+				if !ok {
+					v = result.SeriesLabels[name]
+				}
+				labelValues[i] = append(labelValues[i], v)
+			}
+		}
+	}
+
+	fields := []*data.Field{
+		data.NewField("Time", nil, times),
+		data.NewField("Value", nil, values),
+	}
+	for i, name := range names {
+		fields = append(fields, data.NewField(name, nil, labelValues[i]))
+	}
+
+	frame := data.NewFrame("exemplar", fields...)
+	frame.RefID = query.RefId
+	frame.Meta = &data.FrameMeta{
+		ExecutedQueryString: query.Expr,
+		Custom: &frameMeta{
+			ResultType: "exemplar",
+			Interval:   query.Step.Milliseconds(),
+		},
+	}
+
+	return frame
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.Unix())+float64(t.Nanosecond())/1e9, 'f', -1, 64)
+}
@@ -12,7 +12,7 @@ import (
 
 	"net/http"
 
-	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -61,7 +61,7 @@ func init() {
 	intervalCalculator = tsdb.NewIntervalCalculator(&tsdb.IntervalOptions{MinInterval: time.Second * 1})
 }
 
-func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (apiv1.API, error) {
+func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (api.Client, error) {
 	cfg := api.Config{
 		Address:      dsInfo.Url,
 		RoundTripper: e.Transport,
@@ -75,58 +75,107 @@ func (e *PrometheusExecutor) getClient(dsInfo *models.DataSource) (apiv1.API, er
 		}
 	}
 
-	client, err := api.NewClient(cfg)
-	if err != nil {
-		return nil, err
-	}
-
-	return apiv1.NewAPI(client), nil
+	return api.NewClient(cfg)
 }
 
 func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
-	result := &tsdb.Response{
-		Results: map[string]*tsdb.QueryResult{},
-	}
-
 	client, err := e.getClient(dsInfo)
 	if err != nil {
 		return nil, err
 	}
 
-	queries, err := parseQuery(dsInfo, tsdbQuery.Queries, tsdbQuery)
-	if err != nil {
-		return nil, err
-	}
+	// Exemplars are only useful for visualisation, alert rules never need them.
+	fromAlert := tsdbQuery.Headers["FromAlert"] == "true"
 
-	for _, query := range queries {
+	return tsdb.ExecuteQueries(ctx, dsInfo, tsdbQuery, func(ctx context.Context, q *tsdb.Query) (*tsdb.QueryResult, error) {
+		queries, err := parseQuery(dsInfo, []*tsdb.Query{q}, tsdbQuery)
+		if err != nil {
+			return nil, err
+		}
+
+		query := queries[0]
+		if fromAlert {
+			query.ExemplarQuery = false
+		}
+		return e.runQuery(ctx, client, query), nil
+	}), nil
+}
+
+// runQuery executes the range, instant and exemplar requests of a single
+// query. Failures are reported on the returned result so that the other
+// queries of the request are unaffected.
+func (e *PrometheusExecutor) runQuery(ctx context.Context, client api.Client, query *PrometheusQuery) *tsdb.QueryResult {
+	queryResult := tsdb.NewQueryResult()
+	queryResult.RefId = query.RefId
+
+	span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.prometheus")
+	span.SetTag("expr", query.Expr)
+	span.SetTag("start_unixnano", query.Start.UnixNano())
+	span.SetTag("stop_unixnano", query.End.UnixNano())
+	defer span.Finish()
+
+	promAPI := apiv1.NewAPI(client)
+	frames := data.Frames{}
+
+	if query.RangeQuery {
 		timeRange := apiv1.Range{
 			Start: query.Start,
 			End:   query.End,
 			Step:  query.Step,
 		}
 
-		plog.Debug("Sending query", "start", timeRange.Start, "end", timeRange.End, "step", timeRange.Step, "query", query.Expr)
+		plog.Debug("Sending range query", "start", timeRange.Start, "end", timeRange.End, "step", timeRange.Step, "query", query.Expr)
 
-		span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.prometheus")
-		span.SetTag("expr", query.Expr)
-		span.SetTag("start_unixnano", query.Start.UnixNano())
-		span.SetTag("stop_unixnano", query.End.UnixNano())
-		defer span.Finish()
+		value, _, err := promAPI.QueryRange(ctx, query.Expr, timeRange)
+		if err != nil {
+			queryResult.Error = err
+			return queryResult
+		}
 
-		value, _, err := client.QueryRange(ctx, query.Expr, timeRange)
+		rangeFrames, err := parseResponse(value, query)
+		if err != nil {
+			queryResult.Error = err
+			return queryResult
+		}
+		frames = append(frames, rangeFrames...)
+	}
+
+	if query.InstantQuery {
+		plog.Debug("Sending instant query", "time", query.End, "query", query.Expr)
 
+		value, _, err := promAPI.Query(ctx, query.Expr, query.End)
 		if err != nil {
-			return nil, err
+			queryResult.Error = err
+			return queryResult
 		}
 
-		queryResult, err := parseResponse(value, query)
+		instantFrames, err := parseResponse(value, query)
 		if err != nil {
-			return nil, err
+			queryResult.Error = err
+			return queryResult
 		}
-		result.Results[query.RefId] = queryResult
+		frames = append(frames, instantFrames...)
 	}
 
-	return result, nil
+	if query.ExemplarQuery {
+		plog.Debug("Sending exemplar query", "start", query.Start, "end", query.End, "query", query.Expr)
+
+		exemplars, err := queryExemplars(ctx, client, query)
+		if err != nil {
+			// Not every Prometheus version supports exemplars, so a failure
+			// here must not hide the metric data.
+			plog.Warn("Failed to query exemplars", "query", query.Expr, "err", err)
+			frames = appendNotice(frames, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     "Failed to query exemplars: " + ConvertAPIError(err).Error(),
+			})
+		} else if frame := exemplarsToFrame(exemplars, query); frame != nil {
+			frames = append(frames, frame)
+		}
+	}
+
+	queryResult.Dataframes = tsdb.NewDecodedDataFrames(frames)
+	return queryResult
 }
 
 func formatLegend(metric model.Metric, query *PrometheusQuery) string {
@@ -176,46 +225,98 @@ func parseQuery(dsInfo *models.DataSource, queries []*tsdb.Query, queryContext *
 		interval := intervalCalculator.Calculate(queryContext.TimeRange, dsInterval)
 		step := time.Duration(int64(interval.Value) * intervalFactor)
 
+		// A query runs as a range query unless it asks for an instant query
+		// only. Explore sets both flags to get a graph and a table at once.
+		instant := queryModel.Model.Get("instant").MustBool(false)
+		rangeQuery := queryModel.Model.Get("range").MustBool(!instant)
+
 		qs = append(qs, &PrometheusQuery{
-			Expr:         expr,
-			Step:         step,
-			LegendFormat: format,
-			Start:        start,
-			End:          end,
-			RefId:        queryModel.RefId,
+			Expr:          expr,
+			Step:          step,
+			LegendFormat:  format,
+			Start:         start,
+			End:           end,
+			RefId:         queryModel.RefId,
+			RangeQuery:    rangeQuery,
+			InstantQuery:  instant,
+			ExemplarQuery: queryModel.Model.Get("exemplar").MustBool(false),
 		})
 	}
 
 	return qs, nil
 }
 
-func parseResponse(value model.Value, query *PrometheusQuery) (*tsdb.QueryResult, error) {
-	queryRes := tsdb.NewQueryResult()
-
-	data, ok := value.(model.Matrix)
-	if !ok {
-		return queryRes, fmt.Errorf("unsupported result format: %q", value.Type().String())
+func parseResponse(value model.Value, query *PrometheusQuery) (data.Frames, error) {
+	frames := data.Frames{}
+
+	switch v := value.(type) {
+	case model.Matrix:
+		for _, stream := range v {
+			times := make([]time.Time, 0, len(stream.Values))
+			values := make([]float64, 0, len(stream.Values))
+			for _, pair := range stream.Values {
+				times = append(times, pair.Timestamp.Time().UTC())
+				values = append(values, float64(pair.Value))
+			}
+			frames = append(frames, newSeriesFrame(stream.Metric, times, values, v.Type(), query))
+		}
+	case model.Vector:
+		for _, sample := range v {
+			frames = append(frames, newSeriesFrame(sample.Metric,
+				[]time.Time{sample.Timestamp.Time().UTC()},
+				[]float64{float64(sample.Value)},
+				v.Type(), query))
+		}
+	case *model.Scalar:
+		frames = append(frames, newSeriesFrame(model.Metric{},
+			[]time.Time{v.Timestamp.Time().UTC()},
+			[]float64{float64(v.Value)},
+			v.Type(), query))
+	default:
+		return nil, fmt.Errorf("unsupported result format: %q", value.Type().String())
 	}
 
-	for _, v := range data {
-		series := tsdb.TimeSeries{
-			Name:   formatLegend(v.Metric, query),
-			Tags:   make(map[string]string, len(v.Metric)),
-			Points: make([]tsdb.TimePoint, 0, len(v.Values)),
-		}
+	return frames, nil
+}
 
-		for k, v := range v.Metric {
-			series.Tags[string(k)] = string(v)
-		}
+func newSeriesFrame(metric model.Metric, times []time.Time, values []float64, resultType model.ValueType, query *PrometheusQuery) *data.Frame {
+	name := formatLegend(metric, query)
 
-		for _, k := range v.Values {
-			series.Points = append(series.Points, tsdb.NewTimePoint(null.FloatFrom(float64(k.Value)), float64(k.Timestamp.Unix()*1000)))
-		}
+	labels := make(data.Labels, len(metric))
+	for k, v := range metric {
+		labels[string(k)] = string(v)
+	}
 
-		queryRes.Series = append(queryRes.Series, &series)
+	valueField := data.NewField("Value", labels, values)
+	valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: name})
+
+	frame := data.NewFrame(name,
+		data.NewField("Time", nil, times),
+		valueField,
+	)
+	frame.RefID = query.RefId
+	frame.Meta = &data.FrameMeta{
+		ExecutedQueryString: query.Expr,
+		Custom: &frameMeta{
+			ResultType: resultType.String(),
+			Interval:   query.Step.Milliseconds(),
+		},
 	}
 
-	return queryRes, nil
+	return frame
+}
+
+// appendNotice attaches a notice to the first frame, creating an empty
+// frame when there is none, so that it is shown alongside the query result.
+func appendNotice(frames data.Frames, notice data.Notice) data.Frames {
+	if len(frames) == 0 {
+		frames = append(frames, data.NewFrame(""))
+	}
+	if frames[0].Meta == nil {
+		frames[0].Meta = &data.FrameMeta{}
+	}
+	frames[0].Meta.Notices = append(frames[0].Meta.Notices, notice)
+	return frames
 }
 
 // IsAPIError returns whether err is or wraps a Prometheus error.
@@ -1,13 +1,20 @@
 package prometheus
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
 	p "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -125,4 +132,204 @@ func TestPrometheus(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, time.Minute*2, models[0].Step)
 	})
+
+	t.Run("parsing query model query types", func(t *testing.T) {
+		queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("1h", "now")}
+
+		for _, tc := range []struct {
+			model                         string
+			rangeQuery, instant, exemplar bool
+		}{
+			{model: `{"expr": "up"}`, rangeQuery: true},
+			{model: `{"expr": "up", "instant": true}`, instant: true},
+			{model: `{"expr": "up", "instant": true, "range": true}`, rangeQuery: true, instant: true},
+			{model: `{"expr": "up", "exemplar": true}`, rangeQuery: true, exemplar: true},
+		} {
+			jsonModel, err := simplejson.NewJson([]byte(tc.model))
+			require.NoError(t, err)
+
+			models, err := parseQuery(dsInfo, []*tsdb.Query{{Model: jsonModel}}, queryContext)
+			require.NoError(t, err)
+			require.Equal(t, tc.rangeQuery, models[0].RangeQuery, tc.model)
+			require.Equal(t, tc.instant, models[0].InstantQuery, tc.model)
+			require.Equal(t, tc.exemplar, models[0].ExemplarQuery, tc.model)
+		}
+	})
+}
+
+func TestParseResponse(t *testing.T) {
+	query := &PrometheusQuery{RefId: "A", Expr: "up", LegendFormat: "{{app}}", Step: 15 * time.Second}
+
+	t.Run("matrix", func(t *testing.T) {
+		value := p.Matrix{
+			&p.SampleStream{
+				Metric: p.Metric{"app": "backend"},
+				Values: []p.SamplePair{{Timestamp: 1000, Value: 1}, {Timestamp: 16000, Value: 2}},
+			},
+		}
+
+		frames, err := parseResponse(value, query)
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+
+		frame := frames[0]
+		assert.Equal(t, "backend", frame.Name)
+		assert.Equal(t, "A", frame.RefID)
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, time.Unix(16, 0).UTC(), frame.Fields[0].At(1))
+		assert.Equal(t, 2.0, frame.Fields[1].At(1))
+		assert.Equal(t, data.Labels{"app": "backend"}, frame.Fields[1].Labels)
+		assert.Equal(t, "backend", frame.Fields[1].Config.DisplayNameFromDS)
+		assert.Equal(t, &frameMeta{ResultType: "matrix", Interval: 15000}, frame.Meta.Custom)
+	})
+
+	t.Run("vector", func(t *testing.T) {
+		value := p.Vector{
+			&p.Sample{Metric: p.Metric{"app": "backend"}, Timestamp: 1000, Value: 3},
+			&p.Sample{Metric: p.Metric{"app": "frontend"}, Timestamp: 1000, Value: 4},
+		}
+
+		frames, err := parseResponse(value, query)
+		require.NoError(t, err)
+		require.Len(t, frames, 2)
+		assert.Equal(t, "frontend", frames[1].Name)
+		require.Equal(t, 1, frames[1].Rows())
+		assert.Equal(t, 4.0, frames[1].Fields[1].At(0))
+		assert.Equal(t, "vector", frames[1].Meta.Custom.(*frameMeta).ResultType)
+	})
+
+	t.Run("scalar", func(t *testing.T) {
+		frames, err := parseResponse(&p.Scalar{Timestamp: 1000, Value: 5}, query)
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, 5.0, frames[0].Fields[1].At(0))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := parseResponse(&p.String{}, query)
+		require.Error(t, err)
+	})
+}
+
+func TestExemplarsToFrame(t *testing.T) {
+	query := &PrometheusQuery{RefId: "A", Expr: "up", Step: time.Minute}
+
+	t.Run("no exemplars", func(t *testing.T) {
+		require.Nil(t, exemplarsToFrame([]exemplarResult{{SeriesLabels: map[string]string{"job": "api"}}}, query))
+	})
+
+	t.Run("exemplars with series labels", func(t *testing.T) {
+		frame := exemplarsToFrame([]exemplarResult{
+			{
+				SeriesLabels: map[string]string{"job": "api"},
+				Exemplars: []exemplar{
+					{Labels: map[string]string{"traceID": "abc"}, Value: "6", Timestamp: 1600000000.123},
+					{Labels: map[string]string{"traceID": "def", "job": "override"}, Value: "7", Timestamp: 1600000001},
+				},
+			},
+		}, query)
+
+		require.NotNil(t, frame)
+		assert.Equal(t, "exemplar", frame.Name)
+		require.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Fields, 4)
+		assert.Equal(t, "job", frame.Fields[2].Name)
+		assert.Equal(t, "traceID", frame.Fields[3].Name)
+		assert.Equal(t, time.Unix(1600000000, 123*int64(time.Millisecond)).UTC(), frame.Fields[0].At(0))
+		assert.Equal(t, 6.0, frame.Fields[1].At(0))
+		assert.Equal(t, "api", frame.Fields[2].At(0))
+		assert.Equal(t, "override", frame.Fields[2].At(1))
+		assert.Equal(t, "def", frame.Fields[3].At(1))
+	})
+}
+
+func TestPrometheusExecutor(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/query_exemplars":
+			_, _ = w.Write([]byte(`{"status": "success", "data": [{"seriesLabels": {"job": "api"}, "exemplars": [{"labels": {"traceID": "abc"}, "value": "1", "timestamp": 1600000000}]}]}`))
+		case strings.Contains(r.Form.Get("query"), "invalid"):
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": "error", "errorType": "bad_data", "error": "parse error"}`))
+		case r.URL.Path == "/api/v1/query":
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "vector", "result": [{"metric": {"job": "api"}, "value": [1600000000, "1"]}]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"resultType": "matrix", "result": [{"metric": {"job": "api"}, "values": [[1600000000, "1"], [1600000015, "2"]]}]}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dsInfo := &models.DataSource{
+		Url:      server.URL,
+		JsonData: simplejson.New(),
+	}
+	executor := &PrometheusExecutor{Transport: http.DefaultTransport}
+
+	newQuery := func(refID, model string) *tsdb.Query {
+		jsonModel, err := simplejson.NewJson([]byte(model))
+		require.NoError(t, err)
+		return &tsdb.Query{RefId: refID, Model: jsonModel}
+	}
+
+	decode := func(t *testing.T, res *tsdb.QueryResult) data.Frames {
+		t.Helper()
+		require.NoError(t, res.Error)
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		return frames
+	}
+
+	t.Run("runs every query and reports errors per query", func(t *testing.T) {
+		resp, err := executor.Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1h", "now"),
+			Queries: []*tsdb.Query{
+				newQuery("A", `{"expr": "up"}`),
+				newQuery("B", `{"expr": "up", "instant": true}`),
+				newQuery("C", `{"expr": "invalid("}`),
+				newQuery("D", `{"expr": "up", "exemplar": true}`),
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 4)
+
+		frames := decode(t, resp.Results["A"])
+		require.Len(t, frames, 1)
+		assert.Equal(t, 2, frames[0].Rows())
+
+		frames = decode(t, resp.Results["B"])
+		require.Len(t, frames, 1)
+		assert.Equal(t, 1, frames[0].Rows())
+
+		require.Error(t, resp.Results["C"].Error)
+		assert.True(t, IsAPIError(resp.Results["C"].Error))
+
+		frames = decode(t, resp.Results["D"])
+		require.Len(t, frames, 2)
+		assert.Equal(t, "exemplar", frames[1].Name)
+	})
+
+	t.Run("skips exemplars for alert queries", func(t *testing.T) {
+		mu.Lock()
+		paths = nil
+		mu.Unlock()
+
+		resp, err := executor.Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1h", "now"),
+			Queries:   []*tsdb.Query{newQuery("A", `{"expr": "up", "exemplar": true}`)},
+			Headers:   map[string]string{"FromAlert": "true"},
+		})
+		require.NoError(t, err)
+		require.Len(t, decode(t, resp.Results["A"]), 1)
+		assert.Equal(t, []string{"/api/v1/query_range"}, paths)
+	})
 }
@@ -3,10 +3,20 @@ package prometheus
 import "time"
 
 type PrometheusQuery struct {
-	Expr         string
-	Step         time.Duration
-	LegendFormat string
-	Start        time.Time
-	End          time.Time
-	RefId        string
+	Expr          string
+	Step          time.Duration
+	LegendFormat  string
+	Start         time.Time
+	End           time.Time
+	RefId         string
+	RangeQuery    bool
+	InstantQuery  bool
+	ExemplarQuery bool
+}
+
+// frameMeta is stored in the custom metadata of every returned frame.
+type frameMeta struct {
+	ResultType string `json:"resultType"`
+	// Interval is the query step in milliseconds.
+	Interval int64 `json:"interval"`
 }
@@ -0,0 +1,524 @@
+package sqleng
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"xorm.io/core"
+)
+
+// frameFromRows scans rows into a data frame with a field per column. The
+// type of a field is the scan type of its column, or the type of the values
+// returned by the query result transformer when the scan type is not known.
+// At most rowLimit rows are read, with a notice when the result is truncated.
+func (e *sqlQueryEndpoint) frameFromRows(rows *core.Rows) (*data.Frame, error) {
+	columnNames, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]*frameColumn, len(columnNames))
+	for i, name := range columnNames {
+		columns[i] = newFrameColumn(name, columnTypes[i])
+	}
+
+	truncated := false
+	var rowCount int64
+	for rows.Next() {
+		if rowCount == e.rowLimit {
+			truncated = true
+			break
+		}
+
+		values, err := e.queryResultTransformer.TransformQueryResult(columnTypes, rows)
+		if err != nil {
+			return nil, err
+		}
+		for i, column := range columns {
+			column.append(values[i])
+		}
+		rowCount++
+	}
+
+	frame := data.NewFrame("")
+	for _, column := range columns {
+		frame.Fields = append(frame.Fields, column.build())
+	}
+
+	if truncated {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Results have been limited to %d rows because the row limit of the data source was reached", e.rowLimit),
+		})
+	}
+
+	return frame, nil
+}
+
+// frameColumn builds the field of a column. Fields are nullable, and of type
+// int64, float64, bool, string or time. When the values of a column don't
+// match the type of its field, the field is widened to float64 or string.
+type frameColumn struct {
+	name  string
+	field *data.Field
+	// nulls is the number of values appended before the type of the field is
+	// known.
+	nulls int
+}
+
+func newFrameColumn(name string, columnType *sql.ColumnType) *frameColumn {
+	column := &frameColumn{name: name}
+	if fieldType, ok := scanFieldType(columnType); ok {
+		column.field = data.NewFieldFromFieldType(fieldType, 0)
+		column.field.Name = name
+	}
+	return column
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// scanFieldType returns the type of the field of a column from the scan type
+// reported by the driver, if it is one of the basic types.
+func scanFieldType(columnType *sql.ColumnType) (fieldType data.FieldType, ok bool) {
+	defer func() {
+		// Some drivers don't know the scan type before the first row.
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	scanType := columnType.ScanType()
+	if scanType == nil {
+		return fieldType, false
+	}
+	if scanType.Kind() == reflect.Ptr {
+		scanType = scanType.Elem()
+	}
+
+	if scanType == timeType {
+		return data.FieldTypeNullableTime, true
+	}
+	switch scanType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return data.FieldTypeNullableInt64, true
+	case reflect.Float32, reflect.Float64:
+		return data.FieldTypeNullableFloat64, true
+	case reflect.Bool:
+		return data.FieldTypeNullableBool, true
+	case reflect.String:
+		return data.FieldTypeNullableString, true
+	}
+
+	switch scanType {
+	case reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullInt32{}):
+		return data.FieldTypeNullableInt64, true
+	case reflect.TypeOf(sql.NullFloat64{}):
+		return data.FieldTypeNullableFloat64, true
+	case reflect.TypeOf(sql.NullBool{}):
+		return data.FieldTypeNullableBool, true
+	case reflect.TypeOf(sql.NullString{}):
+		return data.FieldTypeNullableString, true
+	case reflect.TypeOf(sql.NullTime{}):
+		return data.FieldTypeNullableTime, true
+	}
+
+	return fieldType, false
+}
+
+func (c *frameColumn) append(value interface{}) {
+	value = normalizeValue(value)
+	if value == nil {
+		if c.field == nil {
+			c.nulls++
+		} else {
+			c.field.Extend(1)
+		}
+		return
+	}
+
+	if c.field == nil {
+		c.field = data.NewFieldFromFieldType(nullableFieldType(value), c.nulls)
+		c.field.Name = c.name
+	}
+
+	switch v := value.(type) {
+	case int64:
+		switch c.field.Type() {
+		case data.FieldTypeNullableInt64:
+			c.field.Append(&v)
+			return
+		case data.FieldTypeNullableFloat64:
+			f := float64(v)
+			c.field.Append(&f)
+			return
+		}
+	case float64:
+		switch c.field.Type() {
+		case data.FieldTypeNullableFloat64:
+			c.field.Append(&v)
+			return
+		case data.FieldTypeNullableInt64:
+			c.widenToFloat()
+			c.field.Append(&v)
+			return
+		}
+	case bool:
+		if c.field.Type() == data.FieldTypeNullableBool {
+			c.field.Append(&v)
+			return
+		}
+	case time.Time:
+		if c.field.Type() == data.FieldTypeNullableTime {
+			c.field.Append(&v)
+			return
+		}
+	case string:
+		if c.field.Type() == data.FieldTypeNullableString {
+			c.field.Append(&v)
+			return
+		}
+	}
+
+	c.widenToString()
+	s := formatValue(value)
+	c.field.Append(&s)
+}
+
+func (c *frameColumn) widenToFloat() {
+	field := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, c.field.Len())
+	field.Name = c.name
+	for i := 0; i < c.field.Len(); i++ {
+		if v, ok := c.field.At(i).(*int64); ok && v != nil {
+			f := float64(*v)
+			field.Set(i, &f)
+		}
+	}
+	c.field = field
+}
+
+func (c *frameColumn) widenToString() {
+	if c.field.Type() == data.FieldTypeNullableString {
+		return
+	}
+	field := data.NewFieldFromFieldType(data.FieldTypeNullableString, c.field.Len())
+	field.Name = c.name
+	for i := 0; i < c.field.Len(); i++ {
+		if v, ok := c.field.ConcreteAt(i); ok {
+			s := formatValue(v)
+			field.Set(i, &s)
+		}
+	}
+	c.field = field
+}
+
+// build returns the field of the column. Columns of unknown type without
+// values become string fields.
+func (c *frameColumn) build() *data.Field {
+	if c.field == nil {
+		c.field = data.NewFieldFromFieldType(data.FieldTypeNullableString, c.nulls)
+		c.field.Name = c.name
+	}
+	return c.field
+}
+
+// normalizeValue converts a value returned by a query result transformer to
+// nil, int64, float64, bool, string or time.Time.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case int64, float64, bool, string:
+		return v
+	case time.Time:
+		return v.UTC()
+	case []byte:
+		return string(v)
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case uint:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case float32:
+		return float64(v)
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeValue(rv.Elem().Interface())
+	}
+
+	return formatValue(value)
+}
+
+func nullableFieldType(value interface{}) data.FieldType {
+	switch value.(type) {
+	case int64:
+		return data.FieldTypeNullableInt64
+	case float64:
+		return data.FieldTypeNullableFloat64
+	case bool:
+		return data.FieldTypeNullableBool
+	case time.Time:
+		return data.FieldTypeNullableTime
+	}
+	return data.FieldTypeNullableString
+}
+
+func formatValue(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// convertFieldToTime converts a field of datetime values or epoch timestamps
+// to a time field.
+func convertFieldToTime(frame *data.Frame, fieldIndex int) error {
+	field := frame.Fields[fieldIndex]
+	if field.Type() == data.FieldTypeNullableTime {
+		return nil
+	}
+
+	timeField := data.NewFieldFromFieldType(data.FieldTypeNullableTime, field.Len())
+	timeField.Name = field.Name
+	for i := 0; i < field.Len(); i++ {
+		value, ok := field.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+
+		values := tsdb.RowValues{value}
+		ConvertSqlTimeColumnToEpochMs(values, 0)
+		var ms float64
+		switch v := values[0].(type) {
+		case int64:
+			ms = float64(v)
+		case float64:
+			ms = v
+		default:
+			return fmt.Errorf("invalid type for column %s, must be of type timestamp or unix timestamp, got: %T %v",
+				field.Name, value, value)
+		}
+		t := time.Unix(0, int64(ms*float64(time.Millisecond))).UTC()
+		timeField.Set(i, &t)
+	}
+
+	frame.Fields[fieldIndex] = timeField
+	return nil
+}
+
+// convertFieldToFloat converts a field of numeric values to a float64 field.
+func convertFieldToFloat(frame *data.Frame, fieldIndex int) error {
+	field := frame.Fields[fieldIndex]
+	if field.Type() == data.FieldTypeNullableFloat64 {
+		return nil
+	}
+
+	floatField := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, field.Len())
+	floatField.Name = field.Name
+	for i := 0; i < field.Len(); i++ {
+		value, err := ConvertSqlValueColumnToFloat(field.Name, field.At(i))
+		if err != nil {
+			return err
+		}
+		if value.Valid {
+			floatField.Set(i, &value.Float64)
+		}
+	}
+
+	frame.Fields[fieldIndex] = floatField
+	return nil
+}
+
+// labelField converts a string field to a non-nullable string field, which
+// becomes labels when converting the frame to wide format.
+func labelField(field *data.Field) *data.Field {
+	labels := make([]string, field.Len())
+	for i := range labels {
+		if v, ok := field.ConcreteAt(i); ok {
+			labels[i] = v.(string)
+		}
+	}
+	return data.NewField(field.Name, nil, labels)
+}
+
+// sortByTime converts the time field of a frame to a non-nullable time field
+// and sorts the rows of the frame by time.
+func sortByTime(frame *data.Frame, timeIndex int) error {
+	field := frame.Fields[timeIndex]
+	times := make([]time.Time, field.Len())
+	for i := range times {
+		v, ok := field.ConcreteAt(i)
+		if !ok {
+			return fmt.Errorf("invalid type for column time, must be of type timestamp or unix timestamp, got: null")
+		}
+		times[i] = v.(time.Time)
+	}
+	frame.Fields[timeIndex] = data.NewField(field.Name, field.Labels, times)
+
+	if sort.SliceIsSorted(times, func(i, j int) bool { return times[i].Before(times[j]) }) {
+		return nil
+	}
+
+	order := make([]int, len(times))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return times[order[i]].Before(times[order[j]]) })
+
+	for fieldIndex, field := range frame.Fields {
+		sorted := data.NewFieldFromFieldType(field.Type(), field.Len())
+		sorted.Name = field.Name
+		sorted.Labels = field.Labels
+		for i, rowIndex := range order {
+			sorted.Set(i, field.At(rowIndex))
+		}
+		frame.Fields[fieldIndex] = sorted
+	}
+	return nil
+}
+
+// nameSeriesByMetric names the series of a wide frame by the value of their
+// metric label, which is prefixed to the name of the value column when the
+// query has several value columns.
+func nameSeriesByMetric(frame *data.Frame, metricColumn string, multipleValues bool) {
+	for _, field := range frame.Fields {
+		metric, ok := field.Labels[metricColumn]
+		if !ok || len(field.Labels) != 1 {
+			continue
+		}
+		if multipleValues {
+			field.Name = metric + " " + field.Name
+		} else {
+			field.Name = metric
+		}
+		field.Labels = nil
+	}
+}
+
+// fillMissing fills the missing points of time series, as configured by the
+// fill argument of the $__timeGroup macro.
+type fillMissing struct {
+	// interval is the interval of the points in milliseconds.
+	interval float64
+	fillMode data.FillMode
+	value    float64
+}
+
+func fillMissingFromQuery(query *tsdb.Query) (*fillMissing, error) {
+	if !query.Model.Get("fill").MustBool(false) {
+		return nil, nil
+	}
+
+	fill := &fillMissing{
+		interval: query.Model.Get("fillInterval").MustFloat64() * 1000,
+		fillMode: data.FillModeNull,
+	}
+	if fill.interval <= 0 {
+		return nil, fmt.Errorf("invalid fill interval %v", query.Model.Get("fillInterval").Interface())
+	}
+
+	switch query.Model.Get("fillMode").MustString() {
+	case "previous":
+		fill.fillMode = data.FillModePrevious
+	case "value":
+		fill.fillMode = data.FillModeValue
+		fill.value = query.Model.Get("fillValue").MustFloat64()
+	}
+	return fill, nil
+}
+
+// mode returns the fill mode for converting long frames to wide frames.
+func (f *fillMissing) mode() *data.FillMissing {
+	if f == nil {
+		return nil
+	}
+	return &data.FillMissing{Mode: f.fillMode, Value: f.value}
+}
+
+// fill adds rows to a wide frame for the points of the fill interval within
+// the time range that have no values.
+func (f *fillMissing) fill(frame *data.Frame, timeRange *tsdb.TimeRange) (*data.Frame, error) {
+	times := frame.Fields[0].At
+	length := frame.Fields[0].Len()
+
+	from := float64(timeRange.MustGetFrom().UnixNano() / 1e6)
+	to := float64(timeRange.MustGetTo().UnixNano() / 1e6)
+
+	filled := data.NewFrame(frame.Name, data.NewField(frame.Fields[0].Name, nil, []time.Time{}))
+	filled.RefID = frame.RefID
+	filled.Meta = frame.Meta
+	for _, field := range frame.Fields[1:] {
+		valueField := data.NewFieldFromFieldType(field.Type(), 0)
+		valueField.Name = field.Name
+		valueField.Labels = field.Labels
+		valueField.Config = field.Config
+		filled.Fields = append(filled.Fields, valueField)
+	}
+
+	appendFill := func(ms float64) error {
+		filled.Fields[0].Append(time.Unix(0, int64(ms)*int64(time.Millisecond)).UTC())
+		for _, field := range filled.Fields[1:] {
+			field.Extend(1)
+			value, err := data.GetMissing(f.mode(), field, field.Len()-2)
+			if err != nil {
+				return err
+			}
+			field.Set(field.Len()-1, value)
+		}
+		return nil
+	}
+
+	next := math.Floor(from/f.interval) * f.interval
+	for row := 0; row < length; row++ {
+		t := times(row).(time.Time)
+		ms := float64(t.UnixNano() / 1e6)
+		for ; next < ms; next += f.interval {
+			if err := appendFill(next); err != nil {
+				return nil, err
+			}
+		}
+		if next == ms {
+			next += f.interval
+		}
+
+		filled.Fields[0].Append(t)
+		for i, field := range frame.Fields[1:] {
+			filled.Fields[i+1].Append(field.At(row))
+		}
+	}
+	for ; next < to; next += f.interval {
+		if err := appendFill(next); err != nil {
+			return nil, err
+		}
+	}
+
+	return filled, nil
+}
@@ -0,0 +1,67 @@
+package sqleng
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var poolStatLabels = []string{"datasource", "datasource_id"}
+
+// poolStat is a connection pool statistic exported per datasource.
+type poolStat struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	value     func(stats sql.DBStats) float64
+}
+
+func newPoolStat(name, help string, valueType prometheus.ValueType, value func(stats sql.DBStats) float64) poolStat {
+	return poolStat{
+		desc:      prometheus.NewDesc(prometheus.BuildFQName("grafana", "datasource_sql", name), help, poolStatLabels, nil),
+		valueType: valueType,
+		value:     value,
+	}
+}
+
+var poolStats = []poolStat{
+	newPoolStat("max_open_connections", "Maximum number of open connections to the database",
+		prometheus.GaugeValue, func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }),
+	newPoolStat("open_connections", "Number of established connections, both in use and idle",
+		prometheus.GaugeValue, func(s sql.DBStats) float64 { return float64(s.OpenConnections) }),
+	newPoolStat("in_use_connections", "Number of connections currently in use",
+		prometheus.GaugeValue, func(s sql.DBStats) float64 { return float64(s.InUse) }),
+	newPoolStat("idle_connections", "Number of idle connections",
+		prometheus.GaugeValue, func(s sql.DBStats) float64 { return float64(s.Idle) }),
+	newPoolStat("wait_count_total", "Total number of connections waited for",
+		prometheus.CounterValue, func(s sql.DBStats) float64 { return float64(s.WaitCount) }),
+	newPoolStat("wait_duration_seconds_total", "Total time blocked waiting for a new connection",
+		prometheus.CounterValue, func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }),
+	newPoolStat("max_idle_closed_total", "Total number of connections closed due to the maximum number of idle connections",
+		prometheus.CounterValue, func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }),
+	newPoolStat("max_lifetime_closed_total", "Total number of connections closed due to the maximum connection lifetime",
+		prometheus.CounterValue, func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }),
+}
+
+// poolStatsCollector exports the connection pool statistics of the cached
+// engines, labelled by datasource.
+type poolStatsCollector struct{}
+
+func (c *poolStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, stat := range poolStats {
+		ch <- stat.desc
+	}
+}
+
+func (c *poolStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	engineCache.Lock()
+	defer engineCache.Unlock()
+
+	for id, engine := range engineCache.cache {
+		stats := engine.DB().Stats()
+		labels := []string{engineCache.names[id], strconv.FormatInt(id, 10)}
+		for _, stat := range poolStats {
+			ch <- prometheus.MustNewConstMetric(stat.desc, stat.valueType, stat.value(stats), labels...)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+
+	"xorm.io/xorm"
+)
+
+// sessionConnector executes a statement on each new connection of a pool,
+// e.g. to set the native statement timeout of the sessions once instead of
+// before every query.
+type sessionConnector struct {
+	driver.Connector
+	statement string
+}
+
+func (c *sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		logger.Debug("Driver does not support initializing sessions", "statement", c.statement)
+		return conn, nil
+	}
+
+	// The statement may not be supported by the server, e.g. MySQL before
+	// 5.7.8, queries are still cancelled by the driver then.
+	if _, err := execer.ExecContext(ctx, c.statement, nil); err != nil {
+		logger.Debug("Failed to initialize session", "statement", c.statement, "err", err)
+	}
+	return conn, nil
+}
+
+// dsnConnector is the connector of drivers that don't implement driver.DriverContext.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// initSessions replaces the connection pool of the engine by one executing
+// statement on each new connection. It must be called before the pool is
+// configured.
+func initSessions(engine *xorm.Engine, connectionString string, statement string) error {
+	db := engine.DB()
+
+	var connector driver.Connector = dsnConnector{driver: db.Driver(), dsn: connectionString}
+	if driverContext, ok := db.Driver().(driver.DriverContext); ok {
+		var err error
+		connector, err = driverContext.OpenConnector(connectionString)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := db.DB.Close(); err != nil {
+		return err
+	}
+	db.DB = sql.OpenDB(&sessionConnector{Connector: connector, statement: statement})
+	return nil
+}
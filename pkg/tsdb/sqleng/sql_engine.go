@@ -1,26 +1,27 @@
 package sqleng
 
 import (
-	"container/list"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"math"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/grafana/grafana/pkg/setting"
-
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/tsdb"
 
 	"github.com/grafana/grafana/pkg/components/null"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/prometheus/client_golang/prometheus"
 	"xorm.io/core"
 	"xorm.io/xorm"
 )
@@ -45,17 +46,54 @@ type SqlQueryResultTransformer interface {
 type engineCacheType struct {
 	cache    map[int64]*xorm.Engine
 	versions map[int64]int
+	// names are the names of the datasources of the engines, used to label
+	// their connection pool metrics.
+	names map[int64]string
 	sync.Mutex
 }
 
 var engineCache = engineCacheType{
 	cache:    make(map[int64]*xorm.Engine),
 	versions: make(map[int64]int),
+	names:    make(map[int64]string),
+}
+
+func init() {
+	bus.AddEventListener(handleDataSourceDeleted)
+	prometheus.MustRegister(&poolStatsCollector{})
+}
+
+// handleDataSourceDeleted closes the connection pool of a deleted datasource.
+func handleDataSourceDeleted(event *events.DataSourceDeleted) error {
+	engineCache.Lock()
+	defer engineCache.Unlock()
+
+	engineCache.evict(event.Id)
+	return nil
+}
+
+// evict closes and removes the engine of a datasource. The cache must be
+// locked by the caller.
+func (c *engineCacheType) evict(datasourceID int64) {
+	engine, present := c.cache[datasourceID]
+	if !present {
+		return
+	}
+
+	if err := engine.Close(); err != nil {
+		logger.Warn("Failed to close connection pool", "datasourceId", datasourceID, "err", err)
+	}
+	delete(c.cache, datasourceID)
+	delete(c.versions, datasourceID)
+	delete(c.names, datasourceID)
 }
 
 var sqlIntervalCalculator = tsdb.NewIntervalCalculator(nil)
 
+var logger = log.New("tsdb.sqleng")
+
 // NewXormEngine is an xorm.Engine factory, that can be stubbed by tests.
+//
 //nolint:gocritic
 var NewXormEngine = func(driverName string, connectionString string) (*xorm.Engine, error) {
 	return xorm.NewEngine(driverName, connectionString)
@@ -63,12 +101,28 @@ var NewXormEngine = func(driverName string, connectionString string) (*xorm.Engi
 
 const timeEndColumnName = "timeend"
 
+// ErrQueryCancelled is set on the results of queries that were cancelled
+// before they completed, e.g. because the dashboard was closed.
+var ErrQueryCancelled = errors.New("query cancelled")
+
+// QueryTimeoutError is set on the results of queries that exceeded the query
+// timeout of the data source.
+type QueryTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *QueryTimeoutError) Error() string {
+	return fmt.Sprintf("query timed out after %s", e.Timeout)
+}
+
 type sqlQueryEndpoint struct {
 	macroEngine            SqlMacroEngine
 	queryResultTransformer SqlQueryResultTransformer
 	engine                 *xorm.Engine
 	timeColumnNames        []string
 	metricColumnTypes      []string
+	queryTimeout           time.Duration
+	rowLimit               int64
 	log                    log.Logger
 }
 
@@ -78,6 +132,10 @@ type SqlQueryEndpointConfiguration struct {
 	ConnectionString  string
 	TimeColumnNames   []string
 	MetricColumnTypes []string
+	// StatementTimeoutSQL returns the statement setting the native statement
+	// timeout of a connection, for dialects that support one. It is executed
+	// once on each new connection.
+	StatementTimeoutSQL func(timeout time.Duration) string
 }
 
 var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResultTransformer SqlQueryResultTransformer, macroEngine SqlMacroEngine, log log.Logger) (tsdb.TsdbQueryEndpoint, error) {
@@ -88,6 +146,10 @@ var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResul
 		log:                    log,
 	}
 
+	queryTimeout := config.Datasource.JsonData.Get("queryTimeout").MustInt(0)
+	queryEndpoint.queryTimeout = time.Duration(queryTimeout) * time.Second
+	queryEndpoint.rowLimit = config.Datasource.JsonData.Get("rowLimit").MustInt64(defaultRowLimit)
+
 	if len(config.TimeColumnNames) > 0 {
 		queryEndpoint.timeColumnNames = config.TimeColumnNames
 	}
@@ -111,6 +173,18 @@ var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResul
 		return nil, err
 	}
 
+	if queryEndpoint.queryTimeout > 0 && config.StatementTimeoutSQL != nil {
+		// The database cancels queries itself as well, in case the
+		// cancellation from the driver does not reach it.
+		if err := initSessions(engine, config.ConnectionString, config.StatementTimeoutSQL(queryEndpoint.queryTimeout)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Queries still running on the pool of the previous version of the
+	// datasource complete before its connections are closed.
+	engineCache.evict(config.Datasource.Id)
+
 	maxOpenConns := config.Datasource.JsonData.Get("maxOpenConns").MustInt(0)
 	engine.SetMaxOpenConns(maxOpenConns)
 	maxIdleConns := config.Datasource.JsonData.Get("maxIdleConns").MustInt(2)
@@ -119,87 +193,101 @@ var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResul
 	engine.SetConnMaxLifetime(time.Duration(connMaxLifetime) * time.Second)
 
 	engineCache.versions[config.Datasource.Id] = config.Datasource.Version
+	engineCache.names[config.Datasource.Id] = config.Datasource.Name
 	engineCache.cache[config.Datasource.Id] = engine
 	queryEndpoint.engine = engine
 
 	return &queryEndpoint, nil
 }
 
-const rowLimit = 1000000
+// defaultRowLimit is the default maximum number of rows a query returns.
+const defaultRowLimit = 1000000
 
 // Query is the main function for the SqlQueryEndpoint
 func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
-	result := &tsdb.Response{
-		Results: make(map[string]*tsdb.QueryResult),
-	}
-
-	var wg sync.WaitGroup
-
-	for _, query := range tsdbQuery.Queries {
+	return tsdb.ExecuteQueries(ctx, dsInfo, tsdbQuery, func(ctx context.Context, query *tsdb.Query) (*tsdb.QueryResult, error) {
 		rawSQL := query.Model.Get("rawSql").MustString()
 		if rawSQL == "" {
-			continue
+			return nil, nil
 		}
 
-		queryResult := &tsdb.QueryResult{Meta: simplejson.New(), RefId: query.RefId}
-		result.Results[query.RefId] = queryResult
+		return e.runQuery(ctx, query, rawSQL, tsdbQuery), nil
+	}), nil
+}
 
-		// global substitutions
-		rawSQL, err := Interpolate(query, tsdbQuery.TimeRange, rawSQL)
-		if err != nil {
-			queryResult.Error = err
-			continue
-		}
+// runQuery interpolates and executes a single query. Errors are set on the
+// returned result so that it still carries the executed query string.
+func (e *sqlQueryEndpoint) runQuery(ctx context.Context, query *tsdb.Query, rawSQL string, tsdbQuery *tsdb.TsdbQuery) *tsdb.QueryResult {
+	queryResult := &tsdb.QueryResult{Meta: simplejson.New(), RefId: query.RefId}
 
-		// datasource specific substitutions
-		rawSQL, err = e.macroEngine.Interpolate(query, tsdbQuery.TimeRange, rawSQL)
-		if err != nil {
-			queryResult.Error = err
-			continue
-		}
+	// global substitutions
+	rawSQL, err := Interpolate(query, tsdbQuery.TimeRange, rawSQL)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult
+	}
 
-		queryResult.Meta.Set(MetaKeyExecutedQueryString, rawSQL)
+	// datasource specific substitutions
+	rawSQL, err = e.macroEngine.Interpolate(query, tsdbQuery.TimeRange, rawSQL)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult
+	}
 
-		wg.Add(1)
+	queryResult.Meta.Set(MetaKeyExecutedQueryString, rawSQL)
 
-		go func(rawSQL string, query *tsdb.Query, queryResult *tsdb.QueryResult) {
-			defer wg.Done()
-			session := e.engine.NewSession()
-			defer session.Close()
-			db := session.DB()
+	queryCtx := ctx
+	if e.queryTimeout > 0 {
+		var cancel context.CancelFunc
+		queryCtx, cancel = context.WithTimeout(ctx, e.queryTimeout)
+		defer cancel()
+	}
 
-			rows, err := db.Query(rawSQL)
-			if err != nil {
-				queryResult.Error = e.queryResultTransformer.TransformQueryError(err)
-				return
-			}
-			defer func() {
-				if err := rows.Close(); err != nil {
-					e.log.Warn("Failed to close rows", "err", err)
-				}
-			}()
+	rows, err := e.engine.DB().QueryContext(queryCtx, rawSQL)
+	if err != nil {
+		queryResult.Error = e.queryError(ctx, queryCtx, err)
+		return queryResult
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			e.log.Warn("Failed to close rows", "err", err)
+		}
+	}()
 
-			format := query.Model.Get("format").MustString("time_series")
+	format := query.Model.Get("format").MustString("time_series")
 
-			switch format {
-			case "time_series":
-				err := e.transformToTimeSeries(query, rows, queryResult, tsdbQuery)
-				if err != nil {
-					queryResult.Error = err
-					return
-				}
-			case "table":
-				err := e.transformToTable(query, rows, queryResult, tsdbQuery)
-				if err != nil {
-					queryResult.Error = err
-					return
-				}
-			}
-		}(rawSQL, query, queryResult)
+	switch format {
+	case "time_series":
+		err = e.transformToTimeSeries(query, rows, queryResult, tsdbQuery)
+	case "table":
+		err = e.transformToTable(query, rows, queryResult, tsdbQuery)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	if err != nil {
+		queryResult.Error = e.queryError(ctx, queryCtx, err)
 	}
-	wg.Wait()
 
-	return result, nil
+	return queryResult
+}
+
+// queryError returns the error of a query, which is the error of the request
+// context ctx when the request was cancelled or timed out, and a
+// QueryTimeoutError when the query timeout of the data source cancelled
+// queryCtx.
+func (e *sqlQueryEndpoint) queryError(ctx context.Context, queryCtx context.Context, err error) error {
+	switch ctx.Err() {
+	case nil:
+	case context.Canceled:
+		return ErrQueryCancelled
+	default:
+		return ctx.Err()
+	}
+	if errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+		return &QueryTimeoutError{Timeout: e.queryTimeout}
+	}
+	return e.queryResultTransformer.TransformQueryError(err)
 }
 
 // Interpolate provides global macros/substitutions for all sql datasources.
@@ -219,302 +307,158 @@ var Interpolate = func(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string)
 }
 
 func (e *sqlQueryEndpoint) transformToTable(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult, tsdbQuery *tsdb.TsdbQuery) error {
-	columnNames, err := rows.Columns()
-	columnCount := len(columnNames)
-
+	frame, err := e.frameFromRows(rows)
 	if err != nil {
 		return err
 	}
 
-	rowCount := 0
-	timeIndex := -1
-	timeEndIndex := -1
-
-	table := &tsdb.Table{
-		Columns: make([]tsdb.TableColumn, columnCount),
-		Rows:    make([]tsdb.RowValues, 0),
-	}
-
-	for i, name := range columnNames {
-		table.Columns[i].Text = name
-
-		for _, tc := range e.timeColumnNames {
-			if name == tc {
-				timeIndex = i
-				break
-			}
-
-			if timeIndex >= 0 && name == timeEndColumnName {
-				timeEndIndex = i
+	// converts columns named time and timeend to time fields to make native
+	// datetime types and epoch dates work in annotation and table queries.
+	timeIndex := e.timeIndex(frame)
+	if timeIndex >= 0 {
+		if err := convertFieldToTime(frame, timeIndex); err != nil {
+			return err
+		}
+		for i, field := range frame.Fields {
+			if i > timeIndex && field.Name == timeEndColumnName {
+				if err := convertFieldToTime(frame, i); err != nil {
+					return err
+				}
 				break
 			}
 		}
 	}
 
-	columnTypes, err := rows.ColumnTypes()
-	if err != nil {
-		return err
-	}
-
-	for ; rows.Next(); rowCount++ {
-		if rowCount > rowLimit {
-			return fmt.Errorf("query row limit exceeded, limit %d", rowLimit)
-		}
-
-		values, err := e.queryResultTransformer.TransformQueryResult(columnTypes, rows)
-		if err != nil {
-			return err
-		}
-
-		// converts column named time and timeend to unix timestamp in milliseconds
-		// to make native mssql datetime types and epoch dates work in
-		// annotation and table queries.
-		ConvertSqlTimeColumnToEpochMs(values, timeIndex)
-		ConvertSqlTimeColumnToEpochMs(values, timeEndIndex)
-		table.Rows = append(table.Rows, values)
-	}
-
-	result.Tables = append(result.Tables, table)
-	result.Meta.Set("rowCount", rowCount)
+	e.setResultFrame(query, result, frame)
 	return nil
 }
 
-func newProcessCfg(query *tsdb.Query, tsdbQuery *tsdb.TsdbQuery, rows *core.Rows) (*processCfg, error) {
-	columnNames, err := rows.Columns()
-	if err != nil {
-		return nil, err
-	}
+func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult,
+	tsdbQuery *tsdb.TsdbQuery) error {
 	columnTypes, err := rows.ColumnTypes()
 	if err != nil {
-		return nil, err
-	}
-
-	fillMissing := query.Model.Get("fill").MustBool(false)
-
-	cfg := &processCfg{
-		rowCount:           0,
-		columnTypes:        columnTypes,
-		columnNames:        columnNames,
-		rows:               rows,
-		timeIndex:          -1,
-		metricIndex:        -1,
-		metricPrefix:       false,
-		fillMissing:        fillMissing,
-		seriesByQueryOrder: list.New(),
-		pointsBySeries:     make(map[string]*tsdb.TimeSeries),
-		tsdbQuery:          tsdbQuery,
+		return err
 	}
-	return cfg, nil
-}
 
-func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult,
-	tsdbQuery *tsdb.TsdbQuery) error {
-	cfg, err := newProcessCfg(query, tsdbQuery, rows)
+	frame, err := e.frameFromRows(rows)
 	if err != nil {
 		return err
 	}
 
 	// check columns of resultset: a column named time is mandatory
 	// the first text column is treated as metric name unless a column named metric is present
-	for i, col := range cfg.columnNames {
-		for _, tc := range e.timeColumnNames {
-			if col == tc {
-				cfg.timeIndex = i
-				continue
-			}
-		}
-		switch col {
-		case "metric":
-			cfg.metricIndex = i
-		default:
-			if cfg.metricIndex == -1 {
-				columnType := cfg.columnTypes[i].DatabaseTypeName()
-
-				for _, mct := range e.metricColumnTypes {
-					if columnType == mct {
-						cfg.metricIndex = i
-						continue
-					}
-				}
-			}
-		}
-	}
-
-	// use metric column as prefix with multiple value columns
-	if cfg.metricIndex != -1 && len(cfg.columnNames) > 3 {
-		cfg.metricPrefix = true
-	}
-
-	if cfg.timeIndex == -1 {
+	timeIndex := e.timeIndex(frame)
+	if timeIndex == -1 {
 		return fmt.Errorf("found no column named %q", strings.Join(e.timeColumnNames, " or "))
 	}
 
-	if cfg.fillMissing {
-		cfg.fillInterval = query.Model.Get("fillInterval").MustFloat64() * 1000
-		switch query.Model.Get("fillMode").MustString() {
-		case "null":
-		case "previous":
-			cfg.fillPrevious = true
-		case "value":
-			cfg.fillValue.Float64 = query.Model.Get("fillValue").MustFloat64()
-			cfg.fillValue.Valid = true
-		}
-	}
-
-	for rows.Next() {
-		if err := e.processRow(cfg); err != nil {
-			return err
-		}
-	}
-
-	for elem := cfg.seriesByQueryOrder.Front(); elem != nil; elem = elem.Next() {
-		key := elem.Value.(string)
-		result.Series = append(result.Series, cfg.pointsBySeries[key])
-		if !cfg.fillMissing {
+	metricIndex := -1
+	for i, field := range frame.Fields {
+		if i == timeIndex {
 			continue
 		}
-
-		series := cfg.pointsBySeries[key]
-		// fill in values from last fetched value till interval end
-		intervalStart := series.Points[len(series.Points)-1][1].Float64
-		intervalEnd := float64(tsdbQuery.TimeRange.MustGetTo().UnixNano() / 1e6)
-
-		if cfg.fillPrevious {
-			if len(series.Points) > 0 {
-				cfg.fillValue = series.Points[len(series.Points)-1][0]
-			} else {
-				cfg.fillValue.Valid = false
-			}
+		if field.Name == "metric" {
+			metricIndex = i
+			break
 		}
-
-		// align interval start
-		intervalStart = math.Floor(intervalStart/cfg.fillInterval) * cfg.fillInterval
-		for i := intervalStart + cfg.fillInterval; i < intervalEnd; i += cfg.fillInterval {
-			series.Points = append(series.Points, tsdb.TimePoint{cfg.fillValue, null.FloatFrom(i)})
-			cfg.rowCount++
+		if metricIndex == -1 {
+			columnType := columnTypes[i].DatabaseTypeName()
+			for _, mct := range e.metricColumnTypes {
+				if columnType == mct {
+					metricIndex = i
+				}
+			}
 		}
 	}
 
-	result.Meta.Set("rowCount", cfg.rowCount)
-	return nil
-}
-
-type processCfg struct {
-	rowCount           int
-	columnTypes        []*sql.ColumnType
-	columnNames        []string
-	rows               *core.Rows
-	timeIndex          int
-	metricIndex        int
-	metricPrefix       bool
-	metricPrefixValue  string
-	fillMissing        bool
-	pointsBySeries     map[string]*tsdb.TimeSeries
-	seriesByQueryOrder *list.List
-	fillValue          null.Float
-	tsdbQuery          *tsdb.TsdbQuery
-	fillInterval       float64
-	fillPrevious       bool
-}
-
-func (e *sqlQueryEndpoint) processRow(cfg *processCfg) error {
-	var timestamp float64
-	var value null.Float
-	var metric string
-
-	if cfg.rowCount > rowLimit {
-		return fmt.Errorf("query row limit exceeded, limit %d", rowLimit)
+	if metricIndex >= 0 && frame.Fields[metricIndex].Type() != data.FieldTypeNullableString {
+		return fmt.Errorf("column metric must be of type %s. metric column name: %s type: %s but datatype is %s",
+			strings.Join(e.metricColumnTypes, ", "), frame.Fields[metricIndex].Name,
+			columnTypes[metricIndex].DatabaseTypeName(), frame.Fields[metricIndex].Type().ItemTypeString())
 	}
 
-	values, err := e.queryResultTransformer.TransformQueryResult(cfg.columnTypes, cfg.rows)
-	if err != nil {
+	if err := convertFieldToTime(frame, timeIndex); err != nil {
 		return err
 	}
 
-	// converts column named time to unix timestamp in milliseconds to make
-	// native mysql datetime types and epoch dates work in
-	// annotation and table queries.
-	ConvertSqlTimeColumnToEpochMs(values, cfg.timeIndex)
-
-	switch columnValue := values[cfg.timeIndex].(type) {
-	case int64:
-		timestamp = float64(columnValue)
-	case float64:
-		timestamp = columnValue
-	default:
-		return fmt.Errorf("invalid type for column time, must be of type timestamp or unix timestamp, got: %T %v",
-			columnValue, columnValue)
-	}
-
-	if cfg.metricIndex >= 0 {
-		if columnValue, ok := values[cfg.metricIndex].(string); ok {
-			if cfg.metricPrefix {
-				cfg.metricPrefixValue = columnValue
-			} else {
-				metric = columnValue
+	// String columns become the labels of the series, other columns their
+	// values.
+	labelCount, valueCount := 0, 0
+	for i, field := range frame.Fields {
+		switch {
+		case i == timeIndex:
+		case field.Type() == data.FieldTypeNullableString:
+			frame.Fields[i] = labelField(field)
+			labelCount++
+		default:
+			if err := convertFieldToFloat(frame, i); err != nil {
+				return err
 			}
-		} else {
-			return fmt.Errorf("column metric must be of type %s. metric column name: %s type: %s but datatype is %T",
-				strings.Join(e.metricColumnTypes, ", "), cfg.columnNames[cfg.metricIndex],
-				cfg.columnTypes[cfg.metricIndex].DatabaseTypeName(), values[cfg.metricIndex])
+			valueCount++
 		}
 	}
 
-	for i, col := range cfg.columnNames {
-		if i == cfg.timeIndex || i == cfg.metricIndex {
-			continue
-		}
+	fillMissing, err := fillMissingFromQuery(query)
+	if err != nil {
+		return err
+	}
 
-		if value, err = ConvertSqlValueColumnToFloat(col, values[i]); err != nil {
+	if frame.Rows() > 0 {
+		if err := sortByTime(frame, timeIndex); err != nil {
 			return err
 		}
 
-		if cfg.metricIndex == -1 {
-			metric = col
-		} else if cfg.metricPrefix {
-			metric = cfg.metricPrefixValue + " " + col
-		}
-
-		series, exist := cfg.pointsBySeries[metric]
-		if !exist {
-			series = &tsdb.TimeSeries{Name: metric}
-			cfg.pointsBySeries[metric] = series
-			cfg.seriesByQueryOrder.PushBack(metric)
-		}
-
-		if cfg.fillMissing {
-			var intervalStart float64
-			if !exist {
-				intervalStart = float64(cfg.tsdbQuery.TimeRange.MustGetFrom().UnixNano() / 1e6)
-			} else {
-				intervalStart = series.Points[len(series.Points)-1][1].Float64 + cfg.fillInterval
+		if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeLong {
+			metricColumn := ""
+			if metricIndex >= 0 {
+				metricColumn = frame.Fields[metricIndex].Name
 			}
 
-			if cfg.fillPrevious {
-				if len(series.Points) > 0 {
-					cfg.fillValue = series.Points[len(series.Points)-1][0]
-				} else {
-					cfg.fillValue.Valid = false
-				}
+			frame, err = data.LongToWide(frame, fillMissing.mode())
+			if err != nil {
+				return err
 			}
 
-			// align interval start
-			intervalStart = math.Floor(intervalStart/cfg.fillInterval) * cfg.fillInterval
+			// Series are named by the metric column when it is the only label.
+			if metricColumn != "" && labelCount == 1 {
+				nameSeriesByMetric(frame, metricColumn, valueCount > 1)
+			}
+		}
 
-			for i := intervalStart; i < timestamp; i += cfg.fillInterval {
-				series.Points = append(series.Points, tsdb.TimePoint{cfg.fillValue, null.FloatFrom(i)})
-				cfg.rowCount++
+		if fillMissing != nil {
+			frame, err = fillMissing.fill(frame, tsdbQuery.TimeRange)
+			if err != nil {
+				return err
 			}
 		}
+	}
 
-		series.Points = append(series.Points, tsdb.TimePoint{value, null.FloatFrom(timestamp)})
+	e.setResultFrame(query, result, frame)
+	return nil
+}
 
-		if setting.Env == setting.Dev {
-			e.log.Debug("Rows", "metric", metric, "time", timestamp, "value", value)
+// timeIndex returns the index of the time column of a frame, or -1 if there
+// is no time column.
+func (e *sqlQueryEndpoint) timeIndex(frame *data.Frame) int {
+	for i, field := range frame.Fields {
+		for _, tc := range e.timeColumnNames {
+			if field.Name == tc {
+				return i
+			}
 		}
 	}
+	return -1
+}
 
-	return nil
+func (e *sqlQueryEndpoint) setResultFrame(query *tsdb.Query, result *tsdb.QueryResult, frame *data.Frame) {
+	frame.RefID = query.RefId
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.ExecutedQueryString = result.Meta.Get(MetaKeyExecutedQueryString).MustString()
+
+	result.Meta.Set("rowCount", frame.Rows())
+	result.Dataframes = tsdb.NewDecodedDataFrames(data.Frames{frame})
 }
 
 // ConvertSqlTimeColumnToEpochMs converts column named time to unix timestamp in milliseconds
@@ -1,15 +1,26 @@
 package sqleng
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
+	"xorm.io/core"
 )
 
 func TestSqlEngine(t *testing.T) {
@@ -310,3 +321,280 @@ func TestSqlEngine(t *testing.T) {
 		}
 	})
 }
+
+func TestSqlQueryCancellation(t *testing.T) {
+	// slowSQL counts to a large number, which takes sqlite a few seconds.
+	const slowSQL = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 100000000) SELECT count(*) AS value FROM c"
+
+	newEndpointWithConfig := func(t *testing.T, config *SqlQueryEndpointConfiguration) tsdb.TsdbQueryEndpoint {
+		t.Helper()
+		endpoint, err := NewSqlQueryEndpoint(config, &testQueryResultTransformer{}, &testMacroEngine{}, log.New("test"))
+		require.NoError(t, err)
+		return endpoint
+	}
+
+	newEndpoint := func(t *testing.T, id int64, queryTimeout int) tsdb.TsdbQueryEndpoint {
+		t.Helper()
+		return newEndpointWithConfig(t, &SqlQueryEndpointConfiguration{
+			DriverName:       "sqlite3",
+			ConnectionString: ":memory:",
+			Datasource: &models.DataSource{
+				Id:       id,
+				JsonData: simplejson.NewFromAny(map[string]interface{}{"queryTimeout": queryTimeout}),
+			},
+		})
+	}
+
+	query := func(ctx context.Context, t *testing.T, endpoint tsdb.TsdbQueryEndpoint, rawSQL string) *tsdb.QueryResult {
+		t.Helper()
+		resp, err := endpoint.Query(ctx, &models.DataSource{}, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("5m", "now"),
+			Queries: []*tsdb.Query{
+				{
+					RefId:      "A",
+					DataSource: &models.DataSource{},
+					Model:      simplejson.NewFromAny(map[string]interface{}{"rawSql": rawSQL, "format": "table"}),
+				},
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, resp.Results["A"])
+		return resp.Results["A"]
+	}
+
+	t.Run("runs queries within the timeout", func(t *testing.T) {
+		res := query(context.Background(), t, newEndpoint(t, 1001, 10), "SELECT 1 AS value")
+		require.NoError(t, res.Error)
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, 1, frames[0].Rows())
+	})
+
+	t.Run("cancels queries exceeding the timeout", func(t *testing.T) {
+		start := time.Now()
+		res := query(context.Background(), t, newEndpoint(t, 1002, 1), slowSQL)
+		require.Less(t, int64(time.Since(start)), int64(3*time.Second))
+
+		var timeoutErr *QueryTimeoutError
+		require.True(t, errors.As(res.Error, &timeoutErr))
+		require.Equal(t, "query timed out after 1s", res.Error.Error())
+	})
+
+	t.Run("cancels queries with the request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
+
+		res := query(ctx, t, newEndpoint(t, 1003, 0), slowSQL)
+		require.Equal(t, ErrQueryCancelled, res.Error)
+	})
+
+	t.Run("returns the error of requests timing out before the query timeout", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		res := query(ctx, t, newEndpoint(t, 1004, 10), slowSQL)
+		require.Equal(t, context.DeadlineExceeded, res.Error)
+	})
+
+	t.Run("sets the statement timeout once per connection", func(t *testing.T) {
+		var timeouts []time.Duration
+		endpoint := newEndpointWithConfig(t, &SqlQueryEndpointConfiguration{
+			DriverName:       "sqlite3",
+			ConnectionString: filepath.Join(t.TempDir(), "sessions.db"),
+			Datasource: &models.DataSource{
+				Id:       1005,
+				JsonData: simplejson.NewFromAny(map[string]interface{}{"queryTimeout": 10, "maxOpenConns": 1}),
+			},
+			// counts the initialized sessions
+			StatementTimeoutSQL: func(timeout time.Duration) string {
+				timeouts = append(timeouts, timeout)
+				return "CREATE TABLE IF NOT EXISTS sessions (id INTEGER); INSERT INTO sessions VALUES (1)"
+			},
+		})
+		require.Equal(t, []time.Duration{10 * time.Second}, timeouts)
+
+		for i := 0; i < 3; i++ {
+			res := query(context.Background(), t, endpoint, "SELECT count(*) AS value FROM sessions")
+			require.NoError(t, res.Error)
+			frames, err := res.Dataframes.Decoded()
+			require.NoError(t, err)
+			require.Equal(t, int64(1), *frames[0].Fields[0].At(0).(*int64))
+		}
+	})
+}
+
+type testQueryResultTransformer struct{}
+
+func (t *testQueryResultTransformer) TransformQueryResult(columnTypes []*sql.ColumnType, rows *core.Rows) (tsdb.RowValues, error) {
+	values := make([]interface{}, len(columnTypes))
+	valuePtrs := make([]interface{}, len(columnTypes))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func (t *testQueryResultTransformer) TransformQueryError(err error) error {
+	return err
+}
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+func TestSqlQueryFrames(t *testing.T) {
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	timeRange := tsdb.NewFakeTimeRange("3m", "now", from.Add(3*time.Minute))
+
+	config := &SqlQueryEndpointConfiguration{
+		DriverName:       "sqlite3",
+		ConnectionString: ":memory:",
+		Datasource:       &models.DataSource{Id: 1101, JsonData: simplejson.New()},
+	}
+	endpoint, err := NewSqlQueryEndpoint(config, &testQueryResultTransformer{}, &testMacroEngine{}, log.New("test"))
+	require.NoError(t, err)
+
+	query := func(t *testing.T, model map[string]interface{}) *data.Frame {
+		t.Helper()
+		resp, err := endpoint.Query(context.Background(), &models.DataSource{}, &tsdb.TsdbQuery{
+			TimeRange: timeRange,
+			Queries: []*tsdb.Query{
+				{RefId: "A", DataSource: &models.DataSource{}, Model: simplejson.NewFromAny(model)},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, resp.Results["A"].Error)
+		frames, err := resp.Results["A"].Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		require.Equal(t, "A", frames[0].RefID)
+		return frames[0]
+	}
+
+	const values = "WITH metric(time, host, dc, value) AS (VALUES " +
+		"(1523556000, 'a', 'eu', 1), (1523556000, 'b', 'us', 2), (1523556060, 'a', 'eu', 3))"
+
+	t.Run("keeps the types of table columns", func(t *testing.T) {
+		frame := query(t, map[string]interface{}{
+			"format": "table",
+			"rawSql": "SELECT 1 AS count, 1.5 AS ratio, 'a' AS name, NULL AS missing, x'6869' AS raw",
+		})
+		require.Len(t, frame.Fields, 5)
+		require.Equal(t, data.FieldTypeNullableInt64, frame.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[2].Type())
+		require.Nil(t, frame.Fields[3].At(0))
+		require.Equal(t, "hi", *frame.Fields[4].At(0).(*string))
+	})
+
+	t.Run("converts long time series to wide time series labelled by string columns", func(t *testing.T) {
+		frame := query(t, map[string]interface{}{
+			"format": "time_series",
+			"rawSql": values + " SELECT time, host, dc, value FROM metric",
+		})
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, from, frame.Fields[0].At(0))
+		require.Equal(t, data.Labels{"host": "a", "dc": "eu"}, frame.Fields[1].Labels)
+		require.Equal(t, 3.0, *frame.Fields[1].At(1).(*float64))
+		require.Equal(t, data.Labels{"host": "b", "dc": "us"}, frame.Fields[2].Labels)
+		require.Nil(t, frame.Fields[2].At(1))
+	})
+
+	t.Run("names series by the metric column", func(t *testing.T) {
+		frame := query(t, map[string]interface{}{
+			"format": "time_series",
+			"rawSql": values + " SELECT time, host AS metric, value FROM metric",
+		})
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, "a", frame.Fields[1].Name)
+		require.Empty(t, frame.Fields[1].Labels)
+		require.Equal(t, "b", frame.Fields[2].Name)
+	})
+
+	t.Run("fills missing points", func(t *testing.T) {
+		frame := query(t, map[string]interface{}{
+			"format":       "time_series",
+			"rawSql":       values + " SELECT time, host AS metric, value FROM metric",
+			"fill":         true,
+			"fillInterval": 60,
+			"fillMode":     "value",
+			"fillValue":    0,
+		})
+		require.Equal(t, 3, frame.Rows())
+		require.Equal(t, from.Add(2*time.Minute), frame.Fields[0].At(2))
+		require.Equal(t, 0.0, *frame.Fields[2].At(1).(*float64))
+		require.Equal(t, 0.0, *frame.Fields[2].At(2).(*float64))
+	})
+}
+
+func TestSqlConnectionPools(t *testing.T) {
+	newEndpoint := func(t *testing.T, ds *models.DataSource) {
+		t.Helper()
+		config := &SqlQueryEndpointConfiguration{
+			DriverName:       "sqlite3",
+			ConnectionString: ":memory:",
+			Datasource:       ds,
+		}
+		_, err := NewSqlQueryEndpoint(config, &testQueryResultTransformer{}, &testMacroEngine{}, log.New("test"))
+		require.NoError(t, err)
+	}
+
+	t.Run("configures pools from the datasource", func(t *testing.T) {
+		newEndpoint(t, &models.DataSource{
+			Id:       1201,
+			Name:     "pooled",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"maxOpenConns": 5, "maxIdleConns": 1}),
+		})
+		t.Cleanup(func() { require.NoError(t, handleDataSourceDeleted(&events.DataSourceDeleted{Id: 1201})) })
+
+		registry := prometheus.NewPedanticRegistry()
+		require.NoError(t, registry.Register(&poolStatsCollector{}))
+		families, err := registry.Gather()
+		require.NoError(t, err)
+
+		var maxOpen *dto.Metric
+		for _, family := range families {
+			if family.GetName() != "grafana_datasource_sql_max_open_connections" {
+				continue
+			}
+			for _, metric := range family.Metric {
+				for _, label := range metric.Label {
+					if label.GetName() == "datasource" && label.GetValue() == "pooled" {
+						maxOpen = metric
+					}
+				}
+			}
+		}
+		require.NotNil(t, maxOpen)
+		require.Equal(t, 5.0, maxOpen.GetGauge().GetValue())
+	})
+
+	t.Run("replaces pools when the datasource changes", func(t *testing.T) {
+		ds := &models.DataSource{Id: 1202, JsonData: simplejson.New()}
+		newEndpoint(t, ds)
+		engine := engineCache.cache[1202]
+
+		ds.Version++
+		newEndpoint(t, ds)
+		require.NotSame(t, engine, engineCache.cache[1202])
+		require.Error(t, engine.DB().Ping())
+
+		require.NoError(t, handleDataSourceDeleted(&events.DataSourceDeleted{Id: 1202}))
+	})
+
+	t.Run("closes pools of deleted datasources", func(t *testing.T) {
+		newEndpoint(t, &models.DataSource{Id: 1203, JsonData: simplejson.New()})
+		engine := engineCache.cache[1203]
+
+		require.NoError(t, handleDataSourceDeleted(&events.DataSourceDeleted{Id: 1203}))
+		require.NotContains(t, engineCache.cache, int64(1203))
+		require.Error(t, engine.DB().Ping())
+	})
+}
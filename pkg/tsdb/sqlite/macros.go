@@ -0,0 +1,129 @@
+package sqlite
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+const rsIdentifier = `([_a-zA-Z0-9]+)`
+const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
+
+type sqliteMacroEngine struct {
+	*sqleng.SqlMacroEngineBase
+}
+
+func newSqliteMacroEngine() sqleng.SqlMacroEngine {
+	return &sqliteMacroEngine{SqlMacroEngineBase: sqleng.NewSqlMacroEngineBase()}
+}
+
+func (m *sqliteMacroEngine) Interpolate(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string) (string, error) {
+	rExp, _ := regexp.Compile(sExpr)
+	var macroError error
+
+	sql = m.ReplaceAllStringSubmatchFunc(rExp, sql, func(groups []string) string {
+		args := strings.Split(groups[2], ",")
+		for i, arg := range args {
+			args[i] = strings.Trim(arg, " ")
+		}
+		res, err := m.evaluateMacro(timeRange, query, groups[1], args)
+		if err != nil && macroError == nil {
+			macroError = err
+			return "macro_error()"
+		}
+		return res
+	})
+
+	if macroError != nil {
+		return "", macroError
+	}
+
+	return sql, nil
+}
+
+// unixEpoch returns the expression converting a time column, which SQLite
+// stores as text, to seconds since the epoch.
+func unixEpoch(column string) string {
+	return fmt.Sprintf("CAST(strftime('%%s', %s) AS INTEGER)", column)
+}
+
+func (m *sqliteMacroEngine) parseInterval(query *tsdb.Query, name string, args []string) (time.Duration, error) {
+	if len(args) < 2 {
+		return 0, fmt.Errorf("macro %v needs time column and interval and optional fill value", name)
+	}
+	interval, err := gtime.ParseInterval(strings.Trim(args[1], `'"`))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing interval %v", args[1])
+	}
+	if len(args) == 3 {
+		err := sqleng.SetupFillmode(query, interval, args[2])
+		if err != nil {
+			return 0, err
+		}
+	}
+	return interval, nil
+}
+
+func (m *sqliteMacroEngine) evaluateMacro(timeRange *tsdb.TimeRange, query *tsdb.Query, name string, args []string) (string, error) {
+	switch name {
+	case "__timeEpoch", "__time":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s AS \"time\"", unixEpoch(args[0])), nil
+	case "__timeFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s BETWEEN %d AND %d", unixEpoch(args[0]), timeRange.GetFromAsSecondsEpoch(), timeRange.GetToAsSecondsEpoch()), nil
+	case "__timeFrom":
+		return fmt.Sprintf("datetime(%d, 'unixepoch')", timeRange.GetFromAsSecondsEpoch()), nil
+	case "__timeTo":
+		return fmt.Sprintf("datetime(%d, 'unixepoch')", timeRange.GetToAsSecondsEpoch()), nil
+	case "__timeGroup":
+		interval, err := m.parseInterval(query, name, args)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s / %.0f * %.0f", unixEpoch(args[0]), interval.Seconds(), interval.Seconds()), nil
+	case "__timeGroupAlias":
+		tg, err := m.evaluateMacro(timeRange, query, "__timeGroup", args)
+		if err == nil {
+			return tg + " AS \"time\"", nil
+		}
+		return "", err
+	case "__unixEpochFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.GetFromAsSecondsEpoch(), args[0], timeRange.GetToAsSecondsEpoch()), nil
+	case "__unixEpochNanoFilter":
+		if len(args) == 0 {
+			return "", fmt.Errorf("missing time column argument for macro %v", name)
+		}
+		return fmt.Sprintf("%s >= %d AND %s <= %d", args[0], timeRange.GetFromAsTimeUTC().UnixNano(), args[0], timeRange.GetToAsTimeUTC().UnixNano()), nil
+	case "__unixEpochNanoFrom":
+		return fmt.Sprintf("%d", timeRange.GetFromAsTimeUTC().UnixNano()), nil
+	case "__unixEpochNanoTo":
+		return fmt.Sprintf("%d", timeRange.GetToAsTimeUTC().UnixNano()), nil
+	case "__unixEpochGroup":
+		interval, err := m.parseInterval(query, name, args)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("CAST(%s AS INTEGER) / %.0f * %.0f", args[0], interval.Seconds(), interval.Seconds()), nil
+	case "__unixEpochGroupAlias":
+		tg, err := m.evaluateMacro(timeRange, query, "__unixEpochGroup", args)
+		if err == nil {
+			return tg + " AS \"time\"", nil
+		}
+		return "", err
+	default:
+		return "", fmt.Errorf("unknown macro %v", name)
+	}
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroEngine(t *testing.T) {
+	engine := newSqliteMacroEngine()
+	query := &tsdb.Query{}
+
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := tsdb.NewFakeTimeRange("5m", "now", to)
+
+	testCases := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{"__time", "select $__time(time_column)", `select CAST(strftime('%s', time_column) AS INTEGER) AS "time"`},
+		{"__timeFilter", "WHERE $__timeFilter(time_column)",
+			fmt.Sprintf("WHERE CAST(strftime('%%s', time_column) AS INTEGER) BETWEEN %d AND %d", from.Unix(), to.Unix())},
+		{"__timeFrom", "select $__timeFrom()", fmt.Sprintf("select datetime(%d, 'unixepoch')", from.Unix())},
+		{"__timeTo", "select $__timeTo()", fmt.Sprintf("select datetime(%d, 'unixepoch')", to.Unix())},
+		{"__timeGroup", "GROUP BY $__timeGroup(time_column,'5m')", "GROUP BY CAST(strftime('%s', time_column) AS INTEGER) / 300 * 300"},
+		{"__timeGroupAlias", "select $__timeGroupAlias(time_column, 1h)", `select CAST(strftime('%s', time_column) AS INTEGER) / 3600 * 3600 AS "time"`},
+		{"__unixEpochFilter", "select $__unixEpochFilter(time)", fmt.Sprintf("select time >= %d AND time <= %d", from.Unix(), to.Unix())},
+		{"__unixEpochNanoFilter", "select $__unixEpochNanoFilter(time)",
+			fmt.Sprintf("select time >= %d AND time <= %d", from.UnixNano(), to.UnixNano())},
+		{"__unixEpochNanoFrom", "select $__unixEpochNanoFrom()", fmt.Sprintf("select %d", from.UnixNano())},
+		{"__unixEpochNanoTo", "select $__unixEpochNanoTo()", fmt.Sprintf("select %d", to.UnixNano())},
+		{"__unixEpochGroup", "GROUP BY $__unixEpochGroup(time_column,'5m')", "GROUP BY CAST(time_column AS INTEGER) / 300 * 300"},
+		{"__unixEpochGroupAlias", "select $__unixEpochGroupAlias(time_column,'5m')", `select CAST(time_column AS INTEGER) / 300 * 300 AS "time"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, err := engine.Interpolate(query, timeRange, tc.sql)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, sql)
+		})
+	}
+
+	t.Run("__timeGroup with fill sets the fill mode of the query", func(t *testing.T) {
+		query := &tsdb.Query{Model: simplejson.New()}
+		_, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column, 5m, previous)")
+		require.NoError(t, err)
+		require.True(t, query.Model.Get("fill").MustBool())
+		require.Equal(t, "previous", query.Model.Get("fillMode").MustString())
+	})
+
+	t.Run("unknown macros fail", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "select $__unknown(time)")
+		require.EqualError(t, err, "unknown macro __unknown")
+	})
+}
@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+	"github.com/mattn/go-sqlite3"
+	"xorm.io/core"
+)
+
+// driverName is the name of the SQLite driver of the data sources, that
+// rejects attaching other databases.
+const driverName = "sqlite3_datasource"
+
+func init() {
+	// Grafana uses the same driver for its own database. Attached databases
+	// are not subject to the allowed paths nor read-only mode, so they are
+	// denied on every connection.
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			conn.RegisterAuthorizer(denyAttach)
+			return nil
+		},
+	})
+	core.RegisterDriver(driverName, core.QueryDriver("sqlite3"))
+
+	registry.Register(&registry.Descriptor{
+		Name:         "SQLiteService",
+		InitPriority: registry.Low,
+		Instance:     &sqliteService{},
+	})
+}
+
+type sqliteService struct {
+	Cfg    *setting.Cfg `inject:""`
+	logger log.Logger
+}
+
+func (s *sqliteService) Init() error {
+	s.logger = log.New("tsdb.sqlite")
+	tsdb.RegisterTsdbQueryEndpoint("sqlite", func(ds *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+		return s.newSqliteQueryEndpoint(ds)
+	})
+	return nil
+}
+
+func (s *sqliteService) newSqliteQueryEndpoint(datasource *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	path, err := allowedPath(datasource.Database, s.Cfg.SQLiteDatasourceAllowedPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	// Data sources can only write to databases if the server allows it.
+	readOnly := !s.Cfg.SQLiteDatasourceAllowWrites || datasource.JsonData.Get("readOnly").MustBool(true)
+	cnnstr := generateConnectionString(path, readOnly)
+	if s.Cfg.Env == setting.Dev {
+		s.logger.Debug("getEngine", "connection", cnnstr)
+	}
+
+	config := sqleng.SqlQueryEndpointConfiguration{
+		DriverName:        driverName,
+		ConnectionString:  cnnstr,
+		Datasource:        datasource,
+		MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR", "CLOB"},
+	}
+
+	queryResultTransformer := sqliteQueryResultTransformer{
+		log: s.logger,
+	}
+
+	return sqleng.NewSqlQueryEndpoint(&config, &queryResultTransformer, newSqliteMacroEngine(), s.logger)
+}
+
+// denyAttach is the authorizer of the connections of the data sources,
+// denying ATTACH statements.
+func denyAttach(action int, _, _, _ string) int {
+	if action == sqlite3.SQLITE_ATTACH {
+		return sqlite3.SQLITE_DENY
+	}
+	return sqlite3.SQLITE_OK
+}
+
+// generateConnectionString returns the connection string of a database file.
+// In read-only mode, the file is opened read-only and statements that write
+// to the database are rejected as well.
+func generateConnectionString(path string, readOnly bool) string {
+	params := url.Values{}
+	if readOnly {
+		params.Set("mode", "ro")
+		params.Set("_query_only", "true")
+	}
+	params.Set("_busy_timeout", "5000")
+
+	// SQLite expects URI paths with forward slashes, starting with a slash also
+	// on Windows.
+	uriPath := filepath.ToSlash(path)
+	if !strings.HasPrefix(uriPath, "/") {
+		uriPath = "/" + uriPath
+	}
+	return "file:" + (&url.URL{Path: uriPath}).EscapedPath() + "?" + params.Encode()
+}
+
+// allowedPath returns the absolute path of a database file if it is within
+// one of the allowed paths. Symbolic links are resolved, so that they cannot be
+// used to reach other files.
+func allowedPath(path string, allowedPaths []string) (string, error) {
+	if path == "" {
+		return "", errors.New("no database path configured")
+	}
+	if strings.HasPrefix(path, "file:") || strings.ContainsAny(path, "?#") {
+		return "", fmt.Errorf("database path %q must be a file path", path)
+	}
+
+	resolved, err := resolvePath(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid database path %q: %w", path, err)
+	}
+
+	for _, allowed := range allowedPaths {
+		allowedResolved, err := resolvePath(allowed)
+		if err != nil {
+			continue
+		}
+		if resolved == allowedResolved || strings.HasPrefix(resolved, allowedResolved+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+
+	return "", fmt.Errorf("database path %q is not allowed, add it to sqlite_allowed_paths in the datasources section of the configuration", path)
+}
+
+func resolvePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+type sqliteQueryResultTransformer struct {
+	log log.Logger
+}
+
+func (t *sqliteQueryResultTransformer) TransformQueryResult(columnTypes []*sql.ColumnType, rows *core.Rows) (tsdb.RowValues, error) {
+	values := make([]interface{}, len(columnTypes))
+	valuePtrs := make([]interface{}, len(columnTypes))
+
+	for i := 0; i < len(columnTypes); i++ {
+		valuePtrs[i] = &values[i]
+	}
+
+	if err := rows.Scan(valuePtrs...); err != nil {
+		return nil, err
+	}
+
+	// convert types not handled by the sql engine
+	for i := range values {
+		switch v := values[i].(type) {
+		case []byte:
+			values[i] = string(v)
+		case time.Time:
+			values[i] = v.UTC()
+		}
+	}
+
+	return values, nil
+}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(err error) error {
+	return err
+}
@@ -0,0 +1,258 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowedPath(t *testing.T) {
+	dir := t.TempDir()
+	allowedDir := filepath.Join(dir, "allowed")
+	require.NoError(t, os.Mkdir(allowedDir, 0750))
+	dbPath := filepath.Join(allowedDir, "metrics.db")
+	require.NoError(t, createFile(dbPath))
+	otherPath := filepath.Join(dir, "other.db")
+	require.NoError(t, createFile(otherPath))
+	linkPath := filepath.Join(allowedDir, "link.db")
+	require.NoError(t, os.Symlink(otherPath, linkPath))
+
+	t.Run("allows files in allowed directories", func(t *testing.T) {
+		path, err := allowedPath(dbPath, []string{allowedDir})
+		require.NoError(t, err)
+		assert.Equal(t, dbPath, path)
+	})
+
+	t.Run("allows allowed files", func(t *testing.T) {
+		path, err := allowedPath(otherPath, []string{dbPath, otherPath})
+		require.NoError(t, err)
+		assert.Equal(t, otherPath, path)
+	})
+
+	t.Run("rejects files outside of the allowed paths", func(t *testing.T) {
+		_, err := allowedPath(otherPath, []string{allowedDir})
+		require.Error(t, err)
+
+		_, err = allowedPath(filepath.Join(allowedDir, "..", "other.db"), []string{allowedDir})
+		require.Error(t, err)
+
+		_, err = allowedPath(allowedDir+"-other/metrics.db", []string{allowedDir})
+		require.Error(t, err)
+	})
+
+	t.Run("rejects links to files outside of the allowed paths", func(t *testing.T) {
+		_, err := allowedPath(linkPath, []string{allowedDir})
+		require.Error(t, err)
+	})
+
+	t.Run("rejects connection strings", func(t *testing.T) {
+		_, err := allowedPath("file:"+dbPath+"?mode=rwc", []string{allowedDir})
+		require.Error(t, err)
+	})
+
+	t.Run("rejects all paths without allowed paths", func(t *testing.T) {
+		_, err := allowedPath(dbPath, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "metrics.db")
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+		CREATE TABLE metric (time DATETIME, epoch INTEGER, host TEXT, value REAL);
+		INSERT INTO metric VALUES
+			('2018-04-12T18:00:00Z', 1523556000, 'server-1', 1.5),
+			('2018-04-12T18:01:00Z', 1523556060, 'server-1', 2.5),
+			('2018-04-12T18:00:00Z', 1523556000, 'server-2', 3),
+			('2018-04-12T19:00:00Z', 1523559600, 'server-2', 4);
+	`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	s := &sqliteService{
+		Cfg:    &setting.Cfg{SQLiteDatasourceAllowedPaths: []string{dir}},
+		logger: log.New("tsdb.sqlite"),
+	}
+
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	timeRange := tsdb.NewFakeTimeRange("5m", "now", from.Add(5*time.Minute))
+
+	newEndpoint := func(t *testing.T, id int64, jsonData map[string]interface{}) tsdb.TsdbQueryEndpoint {
+		t.Helper()
+		endpoint, err := s.newSqliteQueryEndpoint(&models.DataSource{
+			Id:       id,
+			Database: dbPath,
+			JsonData: simplejson.NewFromAny(jsonData),
+		})
+		require.NoError(t, err)
+		return endpoint
+	}
+
+	query := func(t *testing.T, endpoint tsdb.TsdbQueryEndpoint, model map[string]interface{}) *tsdb.QueryResult {
+		t.Helper()
+		resp, err := endpoint.Query(context.Background(), &models.DataSource{}, &tsdb.TsdbQuery{
+			TimeRange: timeRange,
+			Queries: []*tsdb.Query{
+				{RefId: "A", DataSource: &models.DataSource{}, Model: simplejson.NewFromAny(model)},
+			},
+		})
+		require.NoError(t, err)
+		require.NotNil(t, resp.Results["A"])
+		return resp.Results["A"]
+	}
+
+	endpoint := newEndpoint(t, 2001, map[string]interface{}{})
+
+	t.Run("returns time series grouped by the metric column", func(t *testing.T) {
+		res := query(t, endpoint, map[string]interface{}{
+			"format": "time_series",
+			"rawSql": "SELECT $__timeGroupAlias(time, 1m), host AS metric, avg(value) AS value FROM metric " +
+				"WHERE $__timeFilter(time) GROUP BY 1, 2 ORDER BY 1",
+		})
+		require.NoError(t, res.Error)
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 2, frame.Rows())
+
+		assert.Equal(t, from, frame.Fields[0].At(0))
+		assert.Equal(t, "server-1", frame.Fields[1].Name)
+		assert.Equal(t, 1.5, *frame.Fields[1].At(0).(*float64))
+		assert.Equal(t, "server-2", frame.Fields[2].Name)
+		assert.Nil(t, frame.Fields[2].At(1))
+	})
+
+	t.Run("fills each query of a request with its own fill value", func(t *testing.T) {
+		tsdbQuery := &tsdb.TsdbQuery{TimeRange: timeRange}
+		for i := 0; i < 50; i++ {
+			tsdbQuery.Queries = append(tsdbQuery.Queries, &tsdb.Query{
+				RefId:      strconv.Itoa(i),
+				DataSource: &models.DataSource{},
+				Model: simplejson.NewFromAny(map[string]interface{}{
+					"format": "time_series",
+					"rawSql": fmt.Sprintf("SELECT $__timeGroupAlias(time, 1m, %d), avg(value) AS value FROM metric "+
+						"WHERE $__timeFilter(time) AND host = 'server-1' GROUP BY 1 ORDER BY 1", i),
+				}),
+			})
+		}
+
+		resp, err := endpoint.Query(context.Background(), &models.DataSource{}, tsdbQuery)
+		require.NoError(t, err)
+		for i, q := range tsdbQuery.Queries {
+			res := resp.Results[q.RefId]
+			require.NotNil(t, res)
+			require.NoError(t, res.Error)
+			frames, err := res.Dataframes.Decoded()
+			require.NoError(t, err)
+			require.Len(t, frames, 1)
+			// the points after 18:01 are filled
+			require.Equal(t, 5, frames[0].Rows())
+			assert.Equal(t, 2.5, *frames[0].Fields[1].At(1).(*float64))
+			assert.Equal(t, float64(i), *frames[0].Fields[1].At(4).(*float64), q.RefId)
+		}
+	})
+
+	t.Run("returns tables with epoch columns", func(t *testing.T) {
+		res := query(t, endpoint, map[string]interface{}{
+			"format": "table",
+			"rawSql": "SELECT epoch AS time, host, value FROM metric WHERE $__unixEpochFilter(epoch) ORDER BY host, epoch",
+		})
+		require.NoError(t, res.Error)
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		frame := frames[0]
+		require.Equal(t, 3, frame.Rows())
+		assert.Equal(t, from, *frame.Fields[0].At(0).(*time.Time))
+		assert.Equal(t, "server-1", *frame.Fields[1].At(0).(*string))
+		assert.Equal(t, 1.5, *frame.Fields[2].At(0).(*float64))
+	})
+
+	t.Run("limits results to the row limit", func(t *testing.T) {
+		res := query(t, newEndpoint(t, 2004, map[string]interface{}{"rowLimit": 2}), map[string]interface{}{
+			"format": "table",
+			"rawSql": "SELECT host, value FROM metric",
+		})
+		require.NoError(t, res.Error)
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		assert.Equal(t, 2, frames[0].Rows())
+		require.Len(t, frames[0].Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, frames[0].Meta.Notices[0].Severity)
+	})
+
+	t.Run("rejects writes in read-only mode", func(t *testing.T) {
+		res := query(t, endpoint, map[string]interface{}{
+			"format": "table",
+			"rawSql": "DELETE FROM metric",
+		})
+		require.Error(t, res.Error)
+	})
+
+	t.Run("rejects writes when not read-only unless allowed", func(t *testing.T) {
+		res := query(t, newEndpoint(t, 2002, map[string]interface{}{"readOnly": false}), map[string]interface{}{
+			"format": "table",
+			"rawSql": "CREATE TABLE scratch (id INTEGER)",
+		})
+		require.Error(t, res.Error)
+	})
+
+	t.Run("allows writes when not read-only and allowed", func(t *testing.T) {
+		s.Cfg.SQLiteDatasourceAllowWrites = true
+		t.Cleanup(func() { s.Cfg.SQLiteDatasourceAllowWrites = false })
+
+		res := query(t, newEndpoint(t, 2005, map[string]interface{}{"readOnly": false}), map[string]interface{}{
+			"format": "table",
+			"rawSql": "CREATE TABLE IF NOT EXISTS scratch (id INTEGER)",
+		})
+		require.NoError(t, res.Error)
+	})
+
+	t.Run("rejects attaching databases", func(t *testing.T) {
+		otherPath := filepath.Join(t.TempDir(), "other.db")
+		res := query(t, endpoint, map[string]interface{}{
+			"format": "table",
+			"rawSql": "ATTACH DATABASE '" + otherPath + "' AS other",
+		})
+		require.Error(t, res.Error)
+		assert.NoFileExists(t, otherPath)
+	})
+
+	t.Run("rejects databases outside of the allowed paths", func(t *testing.T) {
+		_, err := s.newSqliteQueryEndpoint(&models.DataSource{
+			Id:       2003,
+			Database: filepath.Join(t.TempDir(), "other.db"),
+			JsonData: simplejson.New(),
+		})
+		require.Error(t, err)
+	})
+}
+
+func createFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,36 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/jaeger"
+	"github.com/grafana/grafana/pkg/tsdb/tracing"
+)
+
+func NewExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	return &tracing.Executor{Backend: backend{}, Logger: plog}, nil
+}
+
+var plog log.Logger
+
+func init() {
+	plog = log.New("tsdb.tempo")
+	tsdb.RegisterTsdbQueryEndpoint("tempo", NewExecutor)
+}
+
+// backend fetches traces by ID. Tempo serves them through the Jaeger query
+// API and has no search, so only trace lookups are supported.
+type backend struct{}
+
+func (backend) GetTrace(ctx context.Context, client *http.Client, dsInfo *models.DataSource, traceID string) ([]*tracing.Span, error) {
+	return jaeger.GetTrace(ctx, client, dsInfo, traceID)
+}
+
+func (backend) SearchTraces(ctx context.Context, client *http.Client, dsInfo *models.DataSource, query *tracing.Query) ([]tracing.TraceSummary, error) {
+	return nil, fmt.Errorf("tempo does not support searching for traces")
+}
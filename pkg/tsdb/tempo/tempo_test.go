@@ -0,0 +1,73 @@
+package tempo
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTempoExecutor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/traces/3fa414edcef6ad90" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write(loadTestFile(t, "trace.json"))
+	}))
+	t.Cleanup(server.Close)
+
+	dsInfo := &models.DataSource{
+		Url:      server.URL,
+		JsonData: simplejson.New(),
+	}
+	executor, err := NewExecutor(dsInfo)
+	require.NoError(t, err)
+
+	newQuery := func(refID, model string) *tsdb.Query {
+		jsonModel, err := simplejson.NewJson([]byte(model))
+		require.NoError(t, err)
+		return &tsdb.Query{RefId: refID, Model: jsonModel}
+	}
+
+	resp, err := executor.Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+		TimeRange: tsdb.NewTimeRange("1h", "now"),
+		Queries: []*tsdb.Query{
+			newQuery("A", `{"query": "3fa414edcef6ad90"}`),
+			newQuery("B", `{"query": "random"}`),
+			newQuery("C", `{"service": "frontend"}`),
+		},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, resp.Results["A"].Error)
+	frames, err := resp.Results["A"].Dataframes.Decoded()
+	require.NoError(t, err)
+	require.Len(t, frames, 1)
+	assert.Equal(t, data.VisType("trace"), frames[0].Meta.PreferredVisualization)
+	assert.Equal(t, 2, frames[0].Rows())
+	assert.Equal(t, "tempo-ingester", frames[0].Fields[4].At(1))
+
+	require.EqualError(t, resp.Results["B"].Error, "request failed with status 400 Bad Request")
+	require.EqualError(t, resp.Results["C"].Error, "tempo does not support searching for traces")
+}
+
+func loadTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	// Ignore gosec warning G304 since it's a test
+	// nolint:gosec
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return b
+}
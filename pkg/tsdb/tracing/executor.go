@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// Backend is the part of an executor that is specific to a tracing data
+// source: the API paths and the parsing of its responses.
+type Backend interface {
+	// GetTrace fetches the spans of a trace.
+	GetTrace(ctx context.Context, client *http.Client, dsInfo *models.DataSource, traceID string) ([]*Span, error)
+	// SearchTraces searches for the traces of a service.
+	SearchTraces(ctx context.Context, client *http.Client, dsInfo *models.DataSource, query *Query) ([]TraceSummary, error)
+}
+
+// Executor runs the queries of a tracing data source and converts the
+// traces to data frames.
+type Executor struct {
+	Backend Backend
+	Logger  log.Logger
+}
+
+// Query runs the queries of a request concurrently.
+func (e *Executor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	client, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return tsdb.ExecuteQueries(ctx, dsInfo, tsdbQuery, func(ctx context.Context, q *tsdb.Query) (*tsdb.QueryResult, error) {
+		query, err := ParseQuery(q, tsdbQuery.TimeRange)
+		if err != nil {
+			return nil, err
+		}
+
+		frame, err := e.runQuery(ctx, client, dsInfo, query)
+		if err != nil {
+			return nil, err
+		}
+
+		queryResult := tsdb.NewQueryResult()
+		queryResult.Dataframes = tsdb.NewDecodedDataFrames(data.Frames{frame})
+		return queryResult, nil
+	}), nil
+}
+
+func (e *Executor) runQuery(ctx context.Context, client *http.Client, dsInfo *models.DataSource, query *Query) (*data.Frame, error) {
+	if query.IsSearch() {
+		e.Logger.Debug("Searching traces", "service", query.Service, "operation", query.Operation, "limit", query.Limit)
+		summaries, err := e.Backend.SearchTraces(ctx, client, dsInfo, query)
+		if err != nil {
+			return nil, err
+		}
+		return SummariesToFrame(query.RefID, summaries), nil
+	}
+
+	if query.TraceID == "" {
+		return SpansToFrame(query.RefID, nil)
+	}
+
+	e.Logger.Debug("Fetching trace", "traceID", query.TraceID)
+	spans, err := e.Backend.GetTrace(ctx, client, dsInfo, query.TraceID)
+	if err != nil {
+		return nil, err
+	}
+	return SpansToFrame(query.RefID, spans)
+}
@@ -0,0 +1,311 @@
+// Package tracing contains the executor shared by the tracing data sources,
+// Jaeger, Zipkin and Tempo, and the types and helpers their backends use to
+// fetch and convert traces.
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+const (
+	// defaultSearchLimit is the number of traces returned by a search when the
+	// query sets no limit.
+	defaultSearchLimit = 20
+	// maxErrorBodyLength caps how much of an error response ends up in the
+	// error message.
+	maxErrorBodyLength = 512
+)
+
+var tlog = log.New("tsdb.tracing")
+
+// Query is a trace lookup by ID or, when TraceID is empty, a search for
+// traces of a service and an optional operation.
+type Query struct {
+	RefID     string
+	TraceID   string
+	Service   string
+	Operation string
+	Limit     int
+	Start     time.Time
+	End       time.Time
+}
+
+// IsSearch returns whether the query searches for traces instead of
+// fetching a single trace.
+func (q *Query) IsSearch() bool {
+	return q.TraceID == "" && q.Service != ""
+}
+
+// ParseQuery reads a query model. The trace ID is stored in the query field
+// like the frontend query editors do.
+func ParseQuery(query *tsdb.Query, timeRange *tsdb.TimeRange) (*Query, error) {
+	start, err := timeRange.ParseFrom()
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := timeRange.ParseTo()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Model.Get("limit").MustInt(defaultSearchLimit)
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	return &Query{
+		RefID:     query.RefId,
+		TraceID:   strings.TrimSpace(query.Model.Get("query").MustString("")),
+		Service:   query.Model.Get("service").MustString(""),
+		Operation: query.Model.Get("operation").MustString(""),
+		Limit:     limit,
+		Start:     start,
+		End:       end,
+	}, nil
+}
+
+// KeyValue is a span tag, a process tag or a log field.
+type KeyValue struct {
+	Key   string      `json:"key"`
+	Type  string      `json:"type,omitempty"`
+	Value interface{} `json:"value"`
+}
+
+// Log is an event recorded on a span. Timestamp is in microseconds since
+// the Unix epoch.
+type Log struct {
+	Timestamp int64      `json:"timestamp"`
+	Fields    []KeyValue `json:"fields"`
+}
+
+// Span is a single span of a trace. StartTime and Duration are in
+// microseconds, which is what Jaeger and Zipkin both use.
+type Span struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	OperationName string
+	ServiceName   string
+	ServiceTags   []KeyValue
+	StartTime     int64
+	Duration      int64
+	Logs          []Log
+	Tags          []KeyValue
+}
+
+// SpansToFrame converts the spans of a trace into the span table the trace
+// view renders: one row per span with times in milliseconds and tags and
+// logs encoded as JSON.
+func SpansToFrame(refID string, spans []*Span) (*data.Frame, error) {
+	n := len(spans)
+	traceIDs := make([]string, 0, n)
+	spanIDs := make([]string, 0, n)
+	parentSpanIDs := make([]string, 0, n)
+	operationNames := make([]string, 0, n)
+	serviceNames := make([]string, 0, n)
+	serviceTags := make([]string, 0, n)
+	startTimes := make([]float64, 0, n)
+	durations := make([]float64, 0, n)
+	logs := make([]string, 0, n)
+	tags := make([]string, 0, n)
+
+	for _, span := range spans {
+		encodedServiceTags, err := encodeJSON(span.ServiceTags)
+		if err != nil {
+			return nil, err
+		}
+		encodedLogs, err := encodeJSON(span.Logs)
+		if err != nil {
+			return nil, err
+		}
+		encodedTags, err := encodeJSON(span.Tags)
+		if err != nil {
+			return nil, err
+		}
+
+		traceIDs = append(traceIDs, span.TraceID)
+		spanIDs = append(spanIDs, span.SpanID)
+		parentSpanIDs = append(parentSpanIDs, span.ParentSpanID)
+		operationNames = append(operationNames, span.OperationName)
+		serviceNames = append(serviceNames, span.ServiceName)
+		serviceTags = append(serviceTags, encodedServiceTags)
+		startTimes = append(startTimes, float64(span.StartTime)/1000)
+		durations = append(durations, float64(span.Duration)/1000)
+		logs = append(logs, encodedLogs)
+		tags = append(tags, encodedTags)
+	}
+
+	frame := data.NewFrame("Trace",
+		data.NewField("traceID", nil, traceIDs),
+		data.NewField("spanID", nil, spanIDs),
+		data.NewField("parentSpanID", nil, parentSpanIDs),
+		data.NewField("operationName", nil, operationNames),
+		data.NewField("serviceName", nil, serviceNames),
+		data.NewField("serviceTags", nil, serviceTags),
+		data.NewField("startTime", nil, startTimes),
+		data.NewField("duration", nil, durations),
+		data.NewField("logs", nil, logs),
+		data.NewField("tags", nil, tags),
+	)
+	frame.RefID = refID
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisType("trace"),
+	}
+
+	return frame, nil
+}
+
+// encodeJSON encodes a slice of tags or logs. Missing values are encoded as
+// an empty list rather than null.
+func encodeJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	if string(b) == "null" {
+		return "[]", nil
+	}
+	return string(b), nil
+}
+
+// TraceSummary describes a trace in search results.
+type TraceSummary struct {
+	TraceID   string
+	TraceName string
+	StartTime time.Time
+	Duration  time.Duration
+	SpanCount int
+}
+
+// Summarize describes a trace by its root span, or by its earliest span
+// when the root span is missing, and the time covered by all its spans.
+func Summarize(traceID string, spans []*Span) TraceSummary {
+	summary := TraceSummary{TraceID: traceID, SpanCount: len(spans)}
+	if len(spans) == 0 {
+		return summary
+	}
+
+	var root *Span
+	earliest := spans[0]
+	start, end := spans[0].StartTime, spans[0].StartTime+spans[0].Duration
+	for _, span := range spans {
+		if span.StartTime < earliest.StartTime {
+			earliest = span
+		}
+		if span.StartTime < start {
+			start = span.StartTime
+		}
+		if span.StartTime+span.Duration > end {
+			end = span.StartTime + span.Duration
+		}
+		if span.ParentSpanID == "" && (root == nil || span.StartTime < root.StartTime) {
+			root = span
+		}
+	}
+	if root == nil {
+		root = earliest
+	}
+
+	summary.TraceName = root.ServiceName + ": " + root.OperationName
+	summary.StartTime = time.Unix(0, start*int64(time.Microsecond)).UTC()
+	summary.Duration = time.Duration(end-start) * time.Microsecond
+	return summary
+}
+
+// SummariesToFrame converts search results into a table with one row per
+// trace.
+func SummariesToFrame(refID string, summaries []TraceSummary) *data.Frame {
+	n := len(summaries)
+	traceIDs := make([]string, 0, n)
+	traceNames := make([]string, 0, n)
+	startTimes := make([]time.Time, 0, n)
+	durations := make([]float64, 0, n)
+	spanCounts := make([]int64, 0, n)
+
+	for _, summary := range summaries {
+		traceIDs = append(traceIDs, summary.TraceID)
+		traceNames = append(traceNames, summary.TraceName)
+		startTimes = append(startTimes, summary.StartTime)
+		durations = append(durations, float64(summary.Duration)/float64(time.Millisecond))
+		spanCounts = append(spanCounts, int64(summary.SpanCount))
+	}
+
+	frame := data.NewFrame("Traces",
+		data.NewField("traceID", nil, traceIDs),
+		data.NewField("traceName", nil, traceNames),
+		data.NewField("startTime", nil, startTimes),
+		data.NewField("duration", nil, durations),
+		data.NewField("spans", nil, spanCounts),
+	)
+	frame.RefID = refID
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeTable,
+	}
+
+	return frame
+}
+
+// Get sends a GET request to the data source, using its authentication
+// settings, and decodes the JSON response into v.
+func Get(ctx context.Context, client *http.Client, dsInfo *models.DataSource, apiPath string, params url.Values, v interface{}) error {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return err
+	}
+	u.Path = path.Join(u.Path, apiPath)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("User-Agent", "Grafana")
+	req.Header.Set("Accept", "application/json")
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			tlog.Warn("Failed to close response body", "err", err)
+		}
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(body))
+		if len(msg) > maxErrorBodyLength {
+			msg = msg[:maxErrorBodyLength] + "..."
+		}
+		if msg == "" {
+			return fmt.Errorf("request failed with status %s", resp.Status)
+		}
+		return fmt.Errorf("request failed with status %s: %s", resp.Status, msg)
+	}
+
+	return json.Unmarshal(body, v)
+}
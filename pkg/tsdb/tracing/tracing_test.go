@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	timeRange := tsdb.NewTimeRange("1h", "now")
+
+	t.Run("trace ID", func(t *testing.T) {
+		model, err := simplejson.NewJson([]byte(`{"query": " abc123 "}`))
+		require.NoError(t, err)
+
+		query, err := ParseQuery(&tsdb.Query{RefId: "A", Model: model}, timeRange)
+		require.NoError(t, err)
+		assert.Equal(t, "A", query.RefID)
+		assert.Equal(t, "abc123", query.TraceID)
+		assert.Equal(t, defaultSearchLimit, query.Limit)
+		assert.False(t, query.IsSearch())
+	})
+
+	t.Run("search", func(t *testing.T) {
+		model, err := simplejson.NewJson([]byte(`{"service": "frontend", "operation": "GET", "limit": 5}`))
+		require.NoError(t, err)
+
+		query, err := ParseQuery(&tsdb.Query{RefId: "A", Model: model}, timeRange)
+		require.NoError(t, err)
+		assert.Equal(t, "frontend", query.Service)
+		assert.Equal(t, "GET", query.Operation)
+		assert.Equal(t, 5, query.Limit)
+		assert.True(t, query.IsSearch())
+	})
+}
+
+func TestSpansToFrame(t *testing.T) {
+	frame, err := SpansToFrame("A", []*Span{
+		{
+			TraceID:       "trace",
+			SpanID:        "root",
+			OperationName: "GET /",
+			ServiceName:   "frontend",
+			ServiceTags:   []KeyValue{{Key: "hostname", Type: "string", Value: "host-1"}},
+			StartTime:     1605873894680409,
+			Duration:      1500,
+			Logs:          []Log{{Timestamp: 1605873894680500, Fields: []KeyValue{{Key: "event", Value: "done"}}}},
+		},
+		{
+			TraceID:      "trace",
+			SpanID:       "child",
+			ParentSpanID: "root",
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "A", frame.RefID)
+	assert.Equal(t, data.VisType("trace"), frame.Meta.PreferredVisualization)
+	require.Equal(t, 2, frame.Rows())
+
+	fields := map[string]*data.Field{}
+	for _, field := range frame.Fields {
+		fields[field.Name] = field
+	}
+	assert.Equal(t, "root", fields["parentSpanID"].At(1))
+	assert.Equal(t, "frontend", fields["serviceName"].At(0))
+	assert.Equal(t, 1605873894680.409, fields["startTime"].At(0))
+	assert.Equal(t, 1.5, fields["duration"].At(0))
+	assert.JSONEq(t, `[{"key": "hostname", "type": "string", "value": "host-1"}]`, fields["serviceTags"].At(0).(string))
+	assert.JSONEq(t, `[{"timestamp": 1605873894680500, "fields": [{"key": "event", "value": "done"}]}]`, fields["logs"].At(0).(string))
+	assert.Equal(t, "[]", fields["tags"].At(1))
+}
+
+func TestSummarize(t *testing.T) {
+	t.Run("uses the root span", func(t *testing.T) {
+		summary := Summarize("trace", []*Span{
+			{SpanID: "child", ParentSpanID: "root", ServiceName: "db", OperationName: "SELECT", StartTime: 2000, Duration: 5000},
+			{SpanID: "root", ServiceName: "frontend", OperationName: "GET /", StartTime: 1000, Duration: 3000},
+		})
+
+		assert.Equal(t, "frontend: GET /", summary.TraceName)
+		assert.Equal(t, time.Unix(0, 1000*int64(time.Microsecond)).UTC(), summary.StartTime)
+		assert.Equal(t, 6*time.Millisecond, summary.Duration)
+		assert.Equal(t, 2, summary.SpanCount)
+	})
+
+	t.Run("falls back to the earliest span", func(t *testing.T) {
+		summary := Summarize("trace", []*Span{
+			{SpanID: "b", ParentSpanID: "missing", ServiceName: "db", OperationName: "SELECT", StartTime: 2000},
+			{SpanID: "a", ParentSpanID: "missing", ServiceName: "api", OperationName: "GET", StartTime: 1000},
+		})
+
+		assert.Equal(t, "api: GET", summary.TraceName)
+	})
+}
+
+func TestGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, _ := r.BasicAuth()
+		if r.URL.Path != "/base/api/services" || user != "user" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors": [{"code": 404, "msg": "not found"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data": ["frontend"]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	dsInfo := &models.DataSource{Url: server.URL + "/base", BasicAuth: true, BasicAuthUser: "user"}
+
+	var response struct {
+		Data []string `json:"data"`
+	}
+	require.NoError(t, Get(context.Background(), http.DefaultClient, dsInfo, "api/services", nil, &response))
+	assert.Equal(t, []string{"frontend"}, response.Data)
+
+	err := Get(context.Background(), http.DefaultClient, dsInfo, "api/missing", nil, &response)
+	require.EqualError(t, err, `request failed with status 404 Not Found: {"errors": [{"code": 404, "msg": "not found"}]}`)
+}
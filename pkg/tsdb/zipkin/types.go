@@ -0,0 +1,104 @@
+package zipkin
+
+import (
+	"sort"
+
+	"github.com/grafana/grafana/pkg/tsdb/tracing"
+)
+
+// Span is a span in the Zipkin v2 JSON format.
+type Span struct {
+	TraceID        string            `json:"traceId"`
+	ParentID       string            `json:"parentId"`
+	ID             string            `json:"id"`
+	Kind           string            `json:"kind"`
+	Name           string            `json:"name"`
+	Timestamp      int64             `json:"timestamp"`
+	Duration       int64             `json:"duration"`
+	LocalEndpoint  *Endpoint         `json:"localEndpoint"`
+	RemoteEndpoint *Endpoint         `json:"remoteEndpoint"`
+	Annotations    []Annotation      `json:"annotations"`
+	Tags           map[string]string `json:"tags"`
+}
+
+type Endpoint struct {
+	ServiceName string `json:"serviceName"`
+	IPv4        string `json:"ipv4"`
+	IPv6        string `json:"ipv6"`
+	Port        int    `json:"port"`
+}
+
+type Annotation struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+// toSpans converts Zipkin spans the same way the frontend does so that the
+// trace view shows them like Jaeger spans: the kind becomes a tag, an error
+// tag becomes a boolean and annotations become logs.
+func toSpans(zSpans []Span) []*tracing.Span {
+	spans := make([]*tracing.Span, 0, len(zSpans))
+	for _, s := range zSpans {
+		span := &tracing.Span{
+			TraceID:       s.TraceID,
+			SpanID:        s.ID,
+			ParentSpanID:  s.ParentID,
+			OperationName: s.Name,
+			ServiceName:   "unknown",
+			StartTime:     s.Timestamp,
+			Duration:      s.Duration,
+			Logs:          []tracing.Log{},
+			Tags:          []tracing.KeyValue{},
+		}
+
+		switch {
+		case s.LocalEndpoint != nil && s.LocalEndpoint.ServiceName != "":
+			span.ServiceName = s.LocalEndpoint.ServiceName
+			span.ServiceTags = endpointTags(s.LocalEndpoint)
+		case s.RemoteEndpoint != nil && s.RemoteEndpoint.ServiceName != "":
+			span.ServiceName = s.RemoteEndpoint.ServiceName
+			span.ServiceTags = endpointTags(s.RemoteEndpoint)
+		}
+
+		if s.Kind != "" {
+			span.Tags = append(span.Tags, tracing.KeyValue{Key: "kind", Type: "string", Value: s.Kind})
+		}
+
+		keys := make([]string, 0, len(s.Tags))
+		for key := range s.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if key == "error" {
+				span.Tags = append(span.Tags, tracing.KeyValue{Key: key, Type: "bool", Value: true})
+				continue
+			}
+			span.Tags = append(span.Tags, tracing.KeyValue{Key: key, Type: "string", Value: s.Tags[key]})
+		}
+
+		for _, a := range s.Annotations {
+			span.Logs = append(span.Logs, tracing.Log{
+				Timestamp: a.Timestamp,
+				Fields:    []tracing.KeyValue{{Key: "annotation", Type: "string", Value: a.Value}},
+			})
+		}
+
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+func endpointTags(endpoint *Endpoint) []tracing.KeyValue {
+	tags := []tracing.KeyValue{}
+	if endpoint.IPv4 != "" {
+		tags = append(tags, tracing.KeyValue{Key: "ipv4", Type: "string", Value: endpoint.IPv4})
+	}
+	if endpoint.IPv6 != "" {
+		tags = append(tags, tracing.KeyValue{Key: "ipv6", Type: "string", Value: endpoint.IPv6})
+	}
+	if endpoint.Port != 0 {
+		tags = append(tags, tracing.KeyValue{Key: "port", Type: "number", Value: endpoint.Port})
+	}
+	return tags
+}
@@ -0,0 +1,61 @@
+package zipkin
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/tracing"
+)
+
+func NewExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	return &tracing.Executor{Backend: backend{}, Logger: plog}, nil
+}
+
+var plog log.Logger
+
+func init() {
+	plog = log.New("tsdb.zipkin")
+	tsdb.RegisterTsdbQueryEndpoint("zipkin", NewExecutor)
+}
+
+// backend reads traces from the Zipkin v2 API.
+type backend struct{}
+
+func (backend) GetTrace(ctx context.Context, client *http.Client, dsInfo *models.DataSource, traceID string) ([]*tracing.Span, error) {
+	var zSpans []Span
+	if err := tracing.Get(ctx, client, dsInfo, "api/v2/trace/"+url.PathEscape(traceID), url.Values{}, &zSpans); err != nil {
+		return nil, err
+	}
+	return toSpans(zSpans), nil
+}
+
+func (backend) SearchTraces(ctx context.Context, client *http.Client, dsInfo *models.DataSource, query *tracing.Query) ([]tracing.TraceSummary, error) {
+	params := url.Values{}
+	params.Set("serviceName", query.Service)
+	if query.Operation != "" {
+		params.Set("spanName", query.Operation)
+	}
+	endTs := query.End.UnixNano() / 1e6
+	params.Set("endTs", strconv.FormatInt(endTs, 10))
+	params.Set("lookback", strconv.FormatInt(endTs-query.Start.UnixNano()/1e6, 10))
+	params.Set("limit", strconv.Itoa(query.Limit))
+
+	var traces [][]Span
+	if err := tracing.Get(ctx, client, dsInfo, "api/v2/traces", params, &traces); err != nil {
+		return nil, err
+	}
+
+	summaries := make([]tracing.TraceSummary, 0, len(traces))
+	for _, trace := range traces {
+		if len(trace) == 0 {
+			continue
+		}
+		summaries = append(summaries, tracing.Summarize(trace[0].TraceID, toSpans(trace)))
+	}
+	return summaries, nil
+}
@@ -0,0 +1,135 @@
+package zipkin
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToSpans(t *testing.T) {
+	var zSpans []Span
+	require.NoError(t, json.Unmarshal(loadTestFile(t, "trace.json"), &zSpans))
+
+	spans := toSpans(zSpans)
+	require.Len(t, spans, 2)
+
+	root := spans[0]
+	assert.Equal(t, "efaa8e0dc1fa1fd5", root.SpanID)
+	assert.Equal(t, "get /api", root.OperationName)
+	assert.Equal(t, "frontend", root.ServiceName)
+	assert.Equal(t, []tracing.KeyValue{
+		{Key: "ipv4", Type: "string", Value: "172.17.0.13"},
+		{Key: "port", Type: "number", Value: 8080},
+	}, root.ServiceTags)
+	assert.Equal(t, []tracing.KeyValue{
+		{Key: "kind", Type: "string", Value: "SERVER"},
+		{Key: "http.method", Type: "string", Value: "GET"},
+		{Key: "http.path", Type: "string", Value: "/api"},
+	}, root.Tags)
+	assert.Empty(t, root.Logs)
+
+	child := spans[1]
+	assert.Equal(t, "efaa8e0dc1fa1fd5", child.ParentSpanID)
+	assert.Equal(t, "frontend", child.ServiceName)
+	assert.Equal(t, tracing.KeyValue{Key: "error", Type: "bool", Value: true}, child.Tags[1])
+	require.Len(t, child.Logs, 1)
+	assert.Equal(t, int64(1606220400003000), child.Logs[0].Timestamp)
+	assert.Equal(t, "wire send", child.Logs[0].Fields[0].Value)
+}
+
+func TestZipkinExecutor(t *testing.T) {
+	var lastRequest *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		switch r.URL.Path {
+		case "/api/v2/trace/efaa8e0dc1fa1fd5":
+			_, _ = w.Write(loadTestFile(t, "trace.json"))
+		case "/api/v2/traces":
+			_, _ = w.Write(loadTestFile(t, "search.json"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("efaa8e0dc1fa1fd6 not found"))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	dsInfo := &models.DataSource{
+		Url:      server.URL,
+		JsonData: simplejson.New(),
+	}
+	executor, err := NewExecutor(dsInfo)
+	require.NoError(t, err)
+
+	runQuery := func(t *testing.T, model string) *tsdb.QueryResult {
+		t.Helper()
+
+		jsonModel, err := simplejson.NewJson([]byte(model))
+		require.NoError(t, err)
+
+		resp, err := executor.Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("1h", "now"),
+			Queries:   []*tsdb.Query{{RefId: "A", Model: jsonModel}},
+		})
+		require.NoError(t, err)
+		return resp.Results["A"]
+	}
+
+	decode := func(t *testing.T, res *tsdb.QueryResult) *data.Frame {
+		t.Helper()
+
+		require.NoError(t, res.Error)
+		frames, err := res.Dataframes.Decoded()
+		require.NoError(t, err)
+		require.Len(t, frames, 1)
+		return frames[0]
+	}
+
+	t.Run("trace by ID", func(t *testing.T) {
+		frame := decode(t, runQuery(t, `{"query": "efaa8e0dc1fa1fd5"}`))
+		assert.Equal(t, data.VisType("trace"), frame.Meta.PreferredVisualization)
+		assert.Equal(t, 2, frame.Rows())
+	})
+
+	t.Run("search by service and span name", func(t *testing.T) {
+		frame := decode(t, runQuery(t, `{"service": "frontend", "operation": "get"}`))
+
+		params := lastRequest.URL.Query()
+		assert.Equal(t, "frontend", params.Get("serviceName"))
+		assert.Equal(t, "get", params.Get("spanName"))
+		assert.Equal(t, "3600000", params.Get("lookback"))
+		assert.Equal(t, "20", params.Get("limit"))
+
+		require.Equal(t, 2, frame.Rows())
+		assert.Equal(t, "efaa8e0dc1fa1fd5", frame.Fields[0].At(0))
+		assert.Equal(t, "frontend: get /api", frame.Fields[1].At(0))
+		assert.Equal(t, "frontend: get", frame.Fields[1].At(1))
+	})
+
+	t.Run("missing trace", func(t *testing.T) {
+		res := runQuery(t, `{"query": "efaa8e0dc1fa1fd6"}`)
+		require.EqualError(t, res.Error, "request failed with status 404 Not Found: efaa8e0dc1fa1fd6 not found")
+	})
+}
+
+func loadTestFile(t *testing.T, name string) []byte {
+	t.Helper()
+
+	path := filepath.Join("testdata", name)
+	// Ignore gosec warning G304 since it's a test
+	// nolint:gosec
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return b
+}
@@ -1,12 +1,24 @@
 import React, { FC } from 'react';
-import { Checkbox, CollapsableSection, Field, InfoBox, Input } from '@grafana/ui';
+import { Checkbox, CollapsableSection, Field, InfoBox, Input, InputControl, RadioButtonGroup } from '@grafana/ui';
 import { NotificationSettingsProps } from './NotificationChannelForm';
 
 interface Props extends NotificationSettingsProps {
   imageRendererAvailable: boolean;
 }
 
-export const NotificationSettings: FC<Props> = ({ currentFormValues, imageRendererAvailable, register }) => {
+const imageModeOptions = [
+  { value: 'url', label: 'Link' },
+  { value: 'attachment', label: 'Attachment' },
+  { value: 'none', label: 'None' },
+];
+
+const digestOptions = [
+  { value: '', label: 'Off' },
+  { value: 'hourly', label: 'Hourly' },
+  { value: 'daily', label: 'Daily' },
+];
+
+export const NotificationSettings: FC<Props> = ({ control, currentFormValues, imageRendererAvailable, register }) => {
   return (
     <CollapsableSection label="Notification settings" isOpen={false}>
       <Field>
@@ -20,6 +32,20 @@ export const NotificationSettings: FC<Props> = ({ currentFormValues, imageRender
           description="Captures an image and include it in the notification"
         />
       </Field>
+      {currentFormValues.uploadImage && (
+        <Field
+          label="Image delivery"
+          description="Link the image uploaded to the external image store, or attach it to the notification without making it public"
+        >
+          <InputControl
+            name="settings.imageMode"
+            as={RadioButtonGroup}
+            options={imageModeOptions}
+            control={control}
+            defaultValue="url"
+          />
+        </Field>
+      )}
       {currentFormValues.uploadImage && !imageRendererAvailable && (
         <InfoBox title="No image renderer available/installed">
           Grafana cannot find an image renderer to capture an image for the notification. Please make sure the Grafana
@@ -34,6 +60,21 @@ export const NotificationSettings: FC<Props> = ({ currentFormValues, imageRender
           description="Disable the resolve message [OK] that is sent when alerting state returns to false"
         />
       </Field>
+      <Field
+        label="Grouping window"
+        description="Send notifications arriving within this duration as a single message, e.g. 30s, 1m or 5m. Leave empty to send every notification separately."
+      >
+        <Input name="settings.groupingWindow" ref={register} width={8} />
+      </Field>
+      <Field label="Digest" description="Send all notifications of the channel as a single message every hour or day">
+        <InputControl
+          name="settings.digest"
+          as={RadioButtonGroup}
+          options={digestOptions}
+          control={control}
+          defaultValue=""
+        />
+      </Field>
       <Field>
         <Checkbox
           name="sendReminder"
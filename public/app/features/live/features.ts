@@ -5,29 +5,53 @@ import { LiveMeasurementsSupport } from './measurements/measurementsSupport';
 import { grafanaLiveCoreFeatures } from './scopes';
 
 export function registerLiveFeatures() {
-  const random2s = new MeasurementCollector();
-  const randomFlakey = new MeasurementCollector();
   const channels: LiveChannelConfig[] = [
     {
       path: 'random-2s-stream',
       description: 'Random stream with points every 2s',
-      getController: () => random2s,
-      processMessage: random2s.addBatch,
     },
     {
       path: 'random-flakey-stream',
       description: 'Random stream with flakey data points',
-      getController: () => randomFlakey,
-      processMessage: randomFlakey.addBatch,
+    },
+    {
+      path: 'signal',
+      description: 'Sine, square or noise signal, e.g. signal?shape=square&interval=100ms&burst=10',
+    },
+    {
+      path: 'logs',
+      description: 'Simulated log lines',
+    },
+    {
+      path: 'flakey',
+      description: 'Signal from a producer that drops points and disconnects',
     },
   ];
 
+  // Streams are configured by the settings in their path, each path has its own collector
+  const testDataConfigs: Record<string, LiveChannelConfig> = {};
+  const getTestDataConfig = (path: string): LiveChannelConfig | undefined => {
+    const kind = path.split('?')[0];
+    const channel = channels.find((c) => c.path === kind);
+    if (!channel) {
+      return undefined;
+    }
+    if (!testDataConfigs[path]) {
+      const collector = new MeasurementCollector();
+      testDataConfigs[path] = {
+        ...channel,
+        path,
+        getController: () => collector,
+        processMessage: collector.addBatch,
+      };
+    }
+    return testDataConfigs[path];
+  };
+
   grafanaLiveCoreFeatures.register({
     name: 'testdata',
     support: {
-      getChannelConfig: (path: string) => {
-        return channels.find((c) => c.path === path);
-      },
+      getChannelConfig: getTestDataConfig,
       getSupportedPaths: () => channels,
     },
     description: 'Test data generations',
@@ -263,4 +263,5 @@ export const selectors = [
   { label: 'SLI Value', value: 'select_slo_health' },
   { label: 'SLO Compliance', value: 'select_slo_compliance' },
   { label: 'SLO Error Budget Remaining', value: 'select_slo_budget_fraction' },
+  { label: 'SLO Burn Rate', value: 'select_slo_burn_rate' },
 ];
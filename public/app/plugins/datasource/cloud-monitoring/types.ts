@@ -96,6 +96,7 @@ export interface SLOQuery {
   sloId: string;
   sloName: string;
   goal?: number;
+  lookbackPeriod?: string;
 }
 
 export interface CloudMonitoringQuery extends DataQuery {
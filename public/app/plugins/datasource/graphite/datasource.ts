@@ -370,25 +370,15 @@ export class GraphiteDatasource extends DataSourceApi<GraphiteQuery, GraphiteOpt
       getSearchFilterScopedVar({ query, wildcardChar: '*', options: optionalOptions })
     );
 
-    const httpOptions: any = {
-      method: 'POST',
-      url: '/metrics/find',
-      params: {},
-      data: `query=${interpolatedQuery}`,
-      headers: {
-        'Content-Type': 'application/x-www-form-urlencoded',
-      },
-      // for cancellations
-      requestId: options.requestId,
-    };
+    const params: any = { query: interpolatedQuery };
 
     if (options.range) {
-      httpOptions.params.from = this.translateTime(options.range.from, false, options.timezone);
-      httpOptions.params.until = this.translateTime(options.range.to, true, options.timezone);
+      params.from = this.translateTime(options.range.from, false, options.timezone);
+      params.until = this.translateTime(options.range.to, true, options.timezone);
     }
 
-    return this.doGraphiteRequest(httpOptions).then((results: any) => {
-      return _.map(results.data, (metric) => {
+    return this.getResource('metrics/find', params, options.requestId).then((results: any) => {
+      return _.map(results, (metric) => {
         return {
           text: metric.text,
           expandable: metric.expandable ? true : false,
@@ -452,30 +442,24 @@ export class GraphiteDatasource extends DataSourceApi<GraphiteQuery, GraphiteOpt
   getTagsAutoComplete(expressions: any[], tagPrefix: any, optionalOptions: any) {
     const options = optionalOptions || {};
 
-    const httpOptions: any = {
-      method: 'GET',
-      url: '/tags/autoComplete/tags',
-      params: {
-        expr: _.map(expressions, (expression) => this.templateSrv.replace((expression || '').trim())),
-      },
-      // for cancellations
-      requestId: options.requestId,
+    const params: any = {
+      expr: _.map(expressions, (expression) => this.templateSrv.replace((expression || '').trim())),
     };
 
     if (tagPrefix) {
-      httpOptions.params.tagPrefix = tagPrefix;
+      params.tagPrefix = tagPrefix;
     }
     if (options.limit) {
-      httpOptions.params.limit = options.limit;
+      params.limit = options.limit;
     }
     if (options.range) {
-      httpOptions.params.from = this.translateTime(options.range.from, false, options.timezone);
-      httpOptions.params.until = this.translateTime(options.range.to, true, options.timezone);
+      params.from = this.translateTime(options.range.from, false, options.timezone);
+      params.until = this.translateTime(options.range.to, true, options.timezone);
     }
 
-    return this.doGraphiteRequest(httpOptions).then((results: any) => {
-      if (results.data) {
-        return _.map(results.data, (tag) => {
+    return this.getResource('tags/autoComplete/tags', params, options.requestId).then((results: any) => {
+      if (results) {
+        return _.map(results, (tag) => {
           return { text: tag };
         });
       } else {
@@ -487,31 +471,25 @@ export class GraphiteDatasource extends DataSourceApi<GraphiteQuery, GraphiteOpt
   getTagValuesAutoComplete(expressions: any[], tag: any, valuePrefix: any, optionalOptions: any) {
     const options = optionalOptions || {};
 
-    const httpOptions: any = {
-      method: 'GET',
-      url: '/tags/autoComplete/values',
-      params: {
-        expr: _.map(expressions, (expression) => this.templateSrv.replace((expression || '').trim())),
-        tag: this.templateSrv.replace((tag || '').trim()),
-      },
-      // for cancellations
-      requestId: options.requestId,
+    const params: any = {
+      expr: _.map(expressions, (expression) => this.templateSrv.replace((expression || '').trim())),
+      tag: this.templateSrv.replace((tag || '').trim()),
     };
 
     if (valuePrefix) {
-      httpOptions.params.valuePrefix = valuePrefix;
+      params.valuePrefix = valuePrefix;
     }
     if (options.limit) {
-      httpOptions.params.limit = options.limit;
+      params.limit = options.limit;
     }
     if (options.range) {
-      httpOptions.params.from = this.translateTime(options.range.from, false, options.timezone);
-      httpOptions.params.until = this.translateTime(options.range.to, true, options.timezone);
+      params.from = this.translateTime(options.range.from, false, options.timezone);
+      params.until = this.translateTime(options.range.to, true, options.timezone);
     }
 
-    return this.doGraphiteRequest(httpOptions).then((results: any) => {
-      if (results.data) {
-        return _.map(results.data, (value) => {
+    return this.getResource('tags/autoComplete/values', params, options.requestId).then((results: any) => {
+      if (results) {
+        return _.map(results, (value) => {
           return { text: value };
         });
       } else {
@@ -565,17 +543,12 @@ export class GraphiteDatasource extends DataSourceApi<GraphiteQuery, GraphiteOpt
       return this.funcDefsPromise;
     }
 
-    const httpOptions = {
-      method: 'GET',
-      url: '/functions',
-    };
-
-    this.funcDefsPromise = this.doGraphiteRequest(httpOptions)
+    this.funcDefsPromise = this.getResource('functions')
       .then((results: any) => {
-        if (results.status !== 200 || typeof results.data !== 'object') {
+        if (typeof results !== 'object') {
           this.funcDefs = gfunc.getFuncDefs(this.graphiteVersion);
         } else {
-          this.funcDefs = gfunc.parseFuncDefs(results.data);
+          this.funcDefs = gfunc.parseFuncDefs(results);
         }
         return this.funcDefs;
       })
@@ -625,6 +598,14 @@ export class GraphiteDatasource extends DataSourceApi<GraphiteQuery, GraphiteOpt
     return getBackendSrv().datasourceRequest(options);
   }
 
+  /**
+   * Sends a GET request to a Graphite endpoint served by the Grafana backend, see pkg/tsdb/graphite/resources.go.
+   * The backend caches the responses and the browser does not need direct access to Graphite.
+   */
+  getResource(path: string, params?: any, requestId?: string): Promise<any> {
+    return getBackendSrv().get(`/api/datasources/${this.id}/resources/${path}`, params, requestId);
+  }
+
   buildGraphiteParams(options: any, scopedVars?: ScopedVars): string[] {
     const graphiteOptions = ['from', 'until', 'rawData', 'format', 'maxDataPoints', 'cacheTimeout'];
     const cleanOptions = [],
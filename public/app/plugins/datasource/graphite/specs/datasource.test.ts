@@ -17,6 +17,7 @@ interface Context {
 
 describe('graphiteDatasource', () => {
   const datasourceRequestMock = jest.spyOn(backendSrv, 'datasourceRequest');
+  const getMock = jest.spyOn(backendSrv, 'get');
 
   let ctx = {} as Context;
 
@@ -24,6 +25,7 @@ describe('graphiteDatasource', () => {
     jest.clearAllMocks();
 
     const instanceSettings = {
+      id: 1,
       url: '/api/datasources/proxy/1',
       name: 'graphiteProd',
       jsonData: {
@@ -345,11 +347,9 @@ describe('graphiteDatasource', () => {
     let requestOptions: any;
 
     beforeEach(() => {
-      datasourceRequestMock.mockImplementation((options: any) => {
-        requestOptions = options;
-        return Promise.resolve({
-          data: ['backend_01', 'backend_02'],
-        });
+      getMock.mockImplementation((url: string, params?: any) => {
+        requestOptions = { url, params };
+        return Promise.resolve(['backend_01', 'backend_02']);
       });
     });
 
@@ -358,7 +358,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/tags');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/tags');
       expect(requestOptions.params.expr).toEqual([]);
       expect(results).not.toBe(null);
     });
@@ -368,7 +368,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/tags');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/tags');
       expect(requestOptions.params.expr).toEqual(['server=backend_01']);
       expect(results).not.toBe(null);
     });
@@ -378,7 +378,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/tags');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/tags');
       expect(requestOptions.params.expr).toEqual(['server=backend_01']);
       expect(results).not.toBe(null);
     });
@@ -388,7 +388,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/values');
       expect(requestOptions.params.tag).toBe('server');
       expect(requestOptions.params.expr).toEqual([]);
       expect(results).not.toBe(null);
@@ -399,7 +399,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/values');
       expect(requestOptions.params.tag).toBe('server');
       expect(requestOptions.params.expr).toEqual(['server=~backend*']);
       expect(results).not.toBe(null);
@@ -410,7 +410,7 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/values');
       expect(requestOptions.params.tag).toBe('server');
       expect(requestOptions.params.expr).toEqual([]);
       expect(results).not.toBe(null);
@@ -421,13 +421,13 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/tags/autoComplete/values');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/tags/autoComplete/values');
       expect(requestOptions.params.tag).toBe('server');
       expect(requestOptions.params.expr).toEqual(['server=~backend*']);
       expect(results).not.toBe(null);
     });
 
-    it('/metrics/find should be POST', () => {
+    it('should generate metrics find query', () => {
       ctx.templateSrv.init([
         {
           type: 'query',
@@ -438,11 +438,8 @@ describe('graphiteDatasource', () => {
       ctx.ds.metricFindQuery('[[foo]]').then((data: any) => {
         results = data;
       });
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/find');
-      expect(requestOptions.method).toEqual('POST');
-      expect(requestOptions.headers).toHaveProperty('Content-Type', 'application/x-www-form-urlencoded');
-      expect(requestOptions.data).toMatch(`query=bar`);
-      expect(requestOptions).toHaveProperty('params');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/metrics/find');
+      expect(requestOptions.params).toEqual({ query: 'bar' });
     });
 
     it('should interpolate $__searchFilter with searchFilter', () => {
@@ -450,9 +447,8 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/find');
-      expect(requestOptions.params).toEqual({});
-      expect(requestOptions.data).toEqual('query=app.backend*');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/metrics/find');
+      expect(requestOptions.params).toEqual({ query: 'app.backend*' });
       expect(results).not.toBe(null);
     });
 
@@ -461,9 +457,8 @@ describe('graphiteDatasource', () => {
         results = data;
       });
 
-      expect(requestOptions.url).toBe('/api/datasources/proxy/1/metrics/find');
-      expect(requestOptions.params).toEqual({});
-      expect(requestOptions.data).toEqual('query=app.*');
+      expect(requestOptions.url).toBe('/api/datasources/1/resources/metrics/find');
+      expect(requestOptions.params).toEqual({ query: 'app.*' });
       expect(results).not.toBe(null);
     });
   });
@@ -0,0 +1,40 @@
+import React from 'react';
+import { DataSourcePluginOptionsEditorProps, updateDatasourcePluginJsonDataOption } from '@grafana/data';
+import { DataSourceHttpSettings, InlineField, Input } from '@grafana/ui';
+import { HttpJsonOptions } from './types';
+
+export type Props = DataSourcePluginOptionsEditorProps<HttpJsonOptions>;
+
+export const ConfigEditor = (props: Props) => {
+  const { options, onOptionsChange } = props;
+
+  return (
+    <>
+      <DataSourceHttpSettings
+        defaultUrl="http://localhost:8080"
+        dataSourceConfig={options}
+        showAccessOptions={false}
+        onChange={onOptionsChange}
+      />
+
+      <h3 className="page-heading">Cache</h3>
+      <div className="gf-form-group">
+        <InlineField
+          label="Cache TTL"
+          labelWidth={14}
+          tooltip="How long in seconds successful responses are cached, 0 disables the cache"
+        >
+          <Input
+            className="width-10"
+            type="number"
+            placeholder="0"
+            value={options.jsonData.cacheTTL ?? ''}
+            onChange={(e) =>
+              updateDatasourcePluginJsonDataOption(props, 'cacheTTL', parseInt(e.currentTarget.value, 10) || 0)
+            }
+          />
+        </InlineField>
+      </div>
+    </>
+  );
+};
@@ -0,0 +1,145 @@
+import React from 'react';
+import { QueryEditorProps, SelectableValue } from '@grafana/data';
+import {
+  Button,
+  IconButton,
+  InlineField,
+  InlineFieldRow,
+  Input,
+  RadioButtonGroup,
+  Select,
+  TextArea,
+} from '@grafana/ui';
+import { HttpJsonDatasource } from './datasource';
+import { HttpJsonField, HttpJsonFieldType, HttpJsonOptions, HttpJsonQuery } from './types';
+
+const methods: Array<SelectableValue<HttpJsonQuery['method']>> = [
+  { label: 'GET', value: 'GET' },
+  { label: 'POST', value: 'POST' },
+];
+
+const languages: Array<SelectableValue<HttpJsonQuery['language']>> = [
+  { label: 'JSONPath', value: 'jsonpath' },
+  { label: 'JMESPath', value: 'jmespath' },
+];
+
+const fieldTypes: Array<SelectableValue<HttpJsonFieldType>> = [
+  { label: 'Auto', value: undefined },
+  { label: 'String', value: 'string' },
+  { label: 'Number', value: 'number' },
+  { label: 'Boolean', value: 'boolean' },
+  { label: 'Time', value: 'time' },
+];
+
+type Props = QueryEditorProps<HttpJsonDatasource, HttpJsonQuery, HttpJsonOptions>;
+
+export const QueryEditor = ({ query, onChange, onRunQuery }: Props) => {
+  const fields = query.fields || [];
+
+  const onFieldChange = (index: number, field: HttpJsonField) => {
+    onChange({ ...query, fields: fields.map((f, i) => (i === index ? field : f)) });
+  };
+
+  const onFieldRemove = (index: number) => {
+    onChange({ ...query, fields: fields.filter((_, i) => i !== index) });
+    onRunQuery();
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Method" labelWidth={10}>
+          <RadioButtonGroup
+            options={methods}
+            value={query.method || 'GET'}
+            onChange={(method) => {
+              onChange({ ...query, method });
+              onRunQuery();
+            }}
+          />
+        </InlineField>
+        <InlineField label="Path" labelWidth={10} grow>
+          <Input
+            placeholder="/api/metrics?from=${__from:date}"
+            value={query.path || ''}
+            onChange={(e) => onChange({ ...query, path: e.currentTarget.value })}
+            onBlur={onRunQuery}
+          />
+        </InlineField>
+      </InlineFieldRow>
+      {query.method === 'POST' && (
+        <InlineField label="Body" labelWidth={10} grow>
+          <TextArea
+            rows={3}
+            value={query.body || ''}
+            onChange={(e) => onChange({ ...query, body: e.currentTarget.value })}
+            onBlur={onRunQuery}
+          />
+        </InlineField>
+      )}
+      <InlineFieldRow>
+        <InlineField label="Language" labelWidth={10}>
+          <RadioButtonGroup
+            options={languages}
+            value={query.language || 'jsonpath'}
+            onChange={(language) => {
+              onChange({ ...query, language });
+              onRunQuery();
+            }}
+          />
+        </InlineField>
+        <InlineField label="Root" labelWidth={10} tooltip="The path of the items of the response, each item is a row">
+          <Input
+            className="width-20"
+            placeholder="$.metrics"
+            value={query.root || ''}
+            onChange={(e) => onChange({ ...query, root: e.currentTarget.value })}
+            onBlur={onRunQuery}
+          />
+        </InlineField>
+      </InlineFieldRow>
+      {fields.map((field, index) => (
+        <InlineFieldRow key={index}>
+          <InlineField label="Field" labelWidth={10}>
+            <Input
+              className="width-12"
+              placeholder="Name"
+              value={field.name || ''}
+              onChange={(e) => onFieldChange(index, { ...field, name: e.currentTarget.value })}
+              onBlur={onRunQuery}
+            />
+          </InlineField>
+          <InlineField label="Path" labelWidth={10}>
+            <Input
+              className="width-20"
+              placeholder="$.latency"
+              value={field.path}
+              onChange={(e) => onFieldChange(index, { ...field, path: e.currentTarget.value })}
+              onBlur={onRunQuery}
+            />
+          </InlineField>
+          <InlineField label="Type" labelWidth={10}>
+            <Select
+              width={14}
+              options={fieldTypes}
+              value={field.type}
+              onChange={(type) => {
+                onFieldChange(index, { ...field, type: type.value });
+                onRunQuery();
+              }}
+            />
+          </InlineField>
+          <IconButton name="trash-alt" aria-label="Remove field" onClick={() => onFieldRemove(index)} />
+        </InlineFieldRow>
+      ))}
+      <Button
+        variant="secondary"
+        size="sm"
+        icon="plus"
+        onClick={() => onChange({ ...query, fields: [...fields, { path: '' }] })}
+      >
+        Add field
+      </Button>
+    </>
+  );
+};
@@ -0,0 +1,29 @@
+import { DataSourceInstanceSettings, ScopedVars } from '@grafana/data';
+import { DataSourceWithBackend, getTemplateSrv, TemplateSrv } from '@grafana/runtime';
+import { HttpJsonOptions, HttpJsonQuery } from './types';
+
+export class HttpJsonDatasource extends DataSourceWithBackend<HttpJsonQuery, HttpJsonOptions> {
+  constructor(
+    instanceSettings: DataSourceInstanceSettings<HttpJsonOptions>,
+    private readonly templateSrv: TemplateSrv = getTemplateSrv()
+  ) {
+    super(instanceSettings);
+  }
+
+  filterQuery(query: HttpJsonQuery): boolean {
+    return !query.hide;
+  }
+
+  // The time range macros are replaced by the backend, so that they also work in alert rules.
+  applyTemplateVariables(query: HttpJsonQuery, scopedVars: ScopedVars): HttpJsonQuery {
+    return {
+      ...query,
+      path: this.templateSrv.replace(query.path, scopedVars),
+      body: this.templateSrv.replace(query.body, scopedVars),
+    };
+  }
+
+  async testDatasource() {
+    return { status: 'success', message: 'Data source is working' };
+  }
+}
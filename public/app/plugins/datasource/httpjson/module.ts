@@ -0,0 +1,8 @@
+import { DataSourcePlugin } from '@grafana/data';
+import { HttpJsonDatasource } from './datasource';
+import { ConfigEditor } from './ConfigEditor';
+import { QueryEditor } from './QueryEditor';
+
+export const plugin = new DataSourcePlugin(HttpJsonDatasource)
+  .setConfigEditor(ConfigEditor)
+  .setQueryEditor(QueryEditor);
@@ -0,0 +1,23 @@
+import { DataQuery, DataSourceJsonData } from '@grafana/data';
+
+export type HttpJsonFieldType = 'string' | 'number' | 'boolean' | 'time';
+
+export interface HttpJsonField {
+  name?: string;
+  path: string;
+  type?: HttpJsonFieldType;
+}
+
+export interface HttpJsonQuery extends DataQuery {
+  method?: 'GET' | 'POST';
+  path?: string;
+  headers?: Record<string, string>;
+  body?: string;
+  language?: 'jsonpath' | 'jmespath';
+  root?: string;
+  fields?: HttpJsonField[];
+}
+
+export interface HttpJsonOptions extends DataSourceJsonData {
+  cacheTTL?: number;
+}
@@ -15,6 +15,9 @@ export interface InfluxOptions extends DataSourceJsonData {
   organization?: string;
   defaultBucket?: string;
   maxSeries?: number;
+  maxRows?: number;
+  maxBytes?: number;
+  queryTimeout?: number;
 }
 
 export interface InfluxSecureJsonData {
@@ -1,65 +1,40 @@
 import _ from 'lodash';
+import { DataFrame, Field, MetricFindValue } from '@grafana/data';
+import { toDataQueryResponse } from '@grafana/runtime';
 
-export default class ResponseParser {
-  processQueryResult(res: any) {
-    const data: any[] = [];
-
-    if (!res.data.results) {
-      return { data: data };
-    }
-
-    for (const key in res.data.results) {
-      const queryRes = res.data.results[key];
-
-      if (queryRes.series) {
-        for (const series of queryRes.series) {
-          data.push({
-            target: series.name,
-            datapoints: series.points,
-            refId: queryRes.refId,
-            meta: queryRes.meta,
-          });
-        }
-      }
-
-      if (queryRes.tables) {
-        for (const table of queryRes.tables) {
-          table.type = 'table';
-          table.refId = queryRes.refId;
-          table.meta = queryRes.meta;
-          data.push(table);
-        }
-      }
-    }
+export interface PostgresResponse {
+  data: DataFrame[];
+}
 
-    return { data: data };
+export default class ResponseParser {
+  processQueryResult(res: any): PostgresResponse {
+    return { data: toDataQueryResponse(res).data };
   }
 
-  parseMetricFindQueryResult(refId: string, results: any) {
-    if (!results || results.data.length === 0 || results.data.results[refId].meta.rowCount === 0) {
+  parseMetricFindQueryResult(refId: string, results: any): MetricFindValue[] {
+    const frame = this.findFrame(refId, results);
+    if (!frame || frame.length === 0) {
       return [];
     }
 
-    const columns = results.data.results[refId].tables[0].columns;
-    const rows = results.data.results[refId].tables[0].rows;
-    const textColIndex = this.findColIndex(columns, '__text');
-    const valueColIndex = this.findColIndex(columns, '__value');
+    const textField = this.findField(frame, '__text');
+    const valueField = this.findField(frame, '__value');
 
-    if (columns.length === 2 && textColIndex !== -1 && valueColIndex !== -1) {
-      return this.transformToKeyValueList(rows, textColIndex, valueColIndex);
+    if (frame.fields.length === 2 && textField && valueField) {
+      return this.transformToKeyValueList(frame, textField, valueField);
     }
 
-    return this.transformToSimpleList(rows);
+    return this.transformToSimpleList(frame);
   }
 
-  transformToKeyValueList(rows: any, textColIndex: number, valueColIndex: number) {
-    const res = [];
+  transformToKeyValueList(frame: DataFrame, textField: Field, valueField: Field) {
+    const res: MetricFindValue[] = [];
 
-    for (let i = 0; i < rows.length; i++) {
-      if (!this.containsKey(res, rows[i][textColIndex])) {
+    for (let i = 0; i < frame.length; i++) {
+      if (!this.containsKey(res, textField.values.get(i))) {
         res.push({
-          text: rows[i][textColIndex],
-          value: rows[i][valueColIndex],
+          text: textField.values.get(i),
+          value: valueField.values.get(i),
         });
       }
     }
@@ -67,12 +42,12 @@ export default class ResponseParser {
     return res;
   }
 
-  transformToSimpleList(rows: any[][]) {
+  transformToSimpleList(frame: DataFrame) {
     const res = [];
 
-    for (let i = 0; i < rows.length; i++) {
-      for (let j = 0; j < rows[i].length; j++) {
-        res.push(rows[i][j]);
+    for (let i = 0; i < frame.length; i++) {
+      for (const field of frame.fields) {
+        res.push(field.values.get(i));
       }
     }
 
@@ -83,17 +58,15 @@ export default class ResponseParser {
     });
   }
 
-  findColIndex(columns: any[], colName: string) {
-    for (let i = 0; i < columns.length; i++) {
-      if (columns[i].text === colName) {
-        return i;
-      }
-    }
+  findFrame(refId: string, results: any): DataFrame | undefined {
+    return toDataQueryResponse(results).data.find((frame: DataFrame) => frame.refId === refId);
+  }
 
-    return -1;
+  findField(frame: DataFrame, name: string): Field | undefined {
+    return frame.fields.find((field) => field.name === name);
   }
 
-  containsKey(res: any, key: any) {
+  containsKey(res: any[], key: any) {
     for (let i = 0; i < res.length; i++) {
       if (res[i].text === key) {
         return true;
@@ -103,44 +76,44 @@ export default class ResponseParser {
   }
 
   transformAnnotationResponse(options: any, data: any) {
-    const table = data.data.results[options.annotation.name].tables[0];
-
-    let timeColumnIndex = -1;
-    let timeEndColumnIndex = -1;
-    const titleColumnIndex = -1;
-    let textColumnIndex = -1;
-    let tagsColumnIndex = -1;
-
-    for (let i = 0; i < table.columns.length; i++) {
-      if (table.columns[i].text === 'time') {
-        timeColumnIndex = i;
-      } else if (table.columns[i].text === 'timeend') {
-        timeEndColumnIndex = i;
-      } else if (table.columns[i].text === 'text') {
-        textColumnIndex = i;
-      } else if (table.columns[i].text === 'tags') {
-        tagsColumnIndex = i;
+    const frame = this.findFrame(options.annotation.name, data);
+    if (!frame) {
+      return [];
+    }
+
+    let timeField: Field | undefined;
+    let timeEndField: Field | undefined;
+    let textField: Field | undefined;
+    let tagsField: Field | undefined;
+
+    for (const field of frame.fields) {
+      if (field.name === 'time') {
+        timeField = field;
+      } else if (field.name === 'timeend') {
+        timeEndField = field;
+      } else if (field.name === 'text') {
+        textField = field;
+      } else if (field.name === 'tags') {
+        tagsField = field;
       }
     }
 
-    if (timeColumnIndex === -1) {
+    if (!timeField) {
       return Promise.reject({
         message: 'Missing mandatory time column in annotation query.',
       });
     }
 
     const list = [];
-    for (let i = 0; i < table.rows.length; i++) {
-      const row = table.rows[i];
-      const timeEnd =
-        timeEndColumnIndex !== -1 && row[timeEndColumnIndex] ? Math.floor(row[timeEndColumnIndex]) : undefined;
+    for (let i = 0; i < frame.length; i++) {
+      const timeEnd = timeEndField && timeEndField.values.get(i) ? Math.floor(timeEndField.values.get(i)) : undefined;
+      const tags = tagsField ? tagsField.values.get(i) : undefined;
       list.push({
         annotation: options.annotation,
-        time: Math.floor(row[timeColumnIndex]),
+        time: Math.floor(timeField.values.get(i)),
         timeEnd,
-        title: row[titleColumnIndex],
-        text: row[textColumnIndex],
-        tags: row[tagsColumnIndex] ? row[tagsColumnIndex].trim().split(/\s*,\s*/) : [],
+        text: textField ? textField.values.get(i) : undefined,
+        tags: tags ? tags.trim().split(/\s*,\s*/) : [],
       });
     }
 
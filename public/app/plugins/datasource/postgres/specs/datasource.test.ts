@@ -1,7 +1,6 @@
 import { of } from 'rxjs';
-import { TestScheduler } from 'rxjs/testing';
 import { FetchResponse } from '@grafana/runtime';
-import { dateTime, toUtc } from '@grafana/data';
+import { DataFrame, dateTime, FieldType, grafanaDataFrameToArrowTable, MutableDataFrame, toUtc } from '@grafana/data';
 
 import { PostgresDatasource } from '../datasource';
 import { backendSrv } from 'app/core/services/backend_srv'; // will use the version in __mocks__
@@ -38,33 +37,8 @@ describe('PostgreSQLDatasource', () => {
     return { ds, templateSrv, timeSrvMock, variable };
   };
 
-  // https://rxjs-dev.firebaseapp.com/guide/testing/marble-testing
-  const runMarbleTest = (args: {
-    options: any;
-    values: { [marble: string]: FetchResponse };
-    marble: string;
-    expectedValues: { [marble: string]: any };
-    expectedMarble: string;
-  }) => {
-    const { expectedValues, expectedMarble, options, values, marble } = args;
-    const scheduler: TestScheduler = new TestScheduler((actual, expected) => {
-      expect(actual).toEqual(expected);
-    });
-
-    const { ds } = setupTestContext({});
-
-    scheduler.run(({ cold, expectObservable }) => {
-      const source = cold(marble, values);
-      jest.clearAllMocks();
-      fetchMock.mockImplementation(() => source);
-
-      const result = ds.query(options);
-      expectObservable(result).toBe(expectedMarble, expectedValues);
-    });
-  };
-
   describe('When performing a time series query', () => {
-    it('should transform response correctly', () => {
+    it('should return the data frames of the response', async () => {
       const options = {
         range: {
           from: dateTime(1432288354),
@@ -81,51 +55,44 @@ describe('PostgreSQLDatasource', () => {
         ],
       };
 
-      const data = {
+      const frame = new MutableDataFrame({
+        refId: 'A',
+        meta: {
+          executedQueryString: 'select time, metric from grafana_metric',
+        },
+        fields: [
+          { name: 'time', type: FieldType.time, values: [1599643351085] },
+          { name: 'America', type: FieldType.number, values: [30.226249741223704] },
+        ],
+      });
+      const { ds } = setupTestContext({
         results: {
           A: {
             refId: 'A',
             meta: {
               executedQueryString: 'select time, metric from grafana_metric',
-              rowCount: 0,
+              rowCount: 1,
             },
-            series: [
-              {
-                name: 'America',
-                points: [[30.226249741223704, 1599643351085]],
-              },
-            ],
-            tables: null,
-            dataframes: null,
+            dataframes: [toBase64(frame)],
           },
         },
-      };
-
-      const values = { a: createFetchResponse(data) };
-      const marble = '-a|';
-      const expectedMarble = '-a|';
-      const expectedValues = {
-        a: {
-          data: [
-            {
-              datapoints: [[30.226249741223704, 1599643351085]],
-              meta: {
-                executedQueryString: 'select time, metric from grafana_metric',
-                rowCount: 0,
-              },
-              refId: 'A',
-              target: 'America',
-            },
-          ],
-        },
-      };
+      });
 
-      runMarbleTest({ options, marble, values, expectedMarble, expectedValues });
+      await expect(ds.query(options)).toEmitValuesWith((received) => {
+        const data: DataFrame[] = received[0].data;
+        expect(data.length).toBe(1);
+        expect(data[0].refId).toBe('A');
+        expect(data[0].meta?.executedQueryString).toBe('select time, metric from grafana_metric');
+        expect(data[0].fields.map((field) => field.name)).toEqual(['time', 'America']);
+        expect(data[0].fields[0].type).toBe(FieldType.time);
+        expect(data[0].fields[0].values.get(0)).toBe(1599643351085);
+        expect(data[0].fields[1].values.get(0)).toBe(30.226249741223704);
+      });
     });
   });
 
   describe('When performing a table query', () => {
-    it('should transform response correctly', () => {
+    it('should return the data frames of the response', async () => {
       const options = {
         range: {
           from: dateTime(1432288354),
@@ -142,7 +109,18 @@ describe('PostgreSQLDatasource', () => {
         ],
       };
 
-      const data = {
+      const frame = new MutableDataFrame({
+        refId: 'A',
+        meta: {
+          executedQueryString: 'select time, metric, value from grafana_metric',
+        },
+        fields: [
+          { name: 'time', type: FieldType.time, values: [1599643351085] },
+          { name: 'metric', type: FieldType.string, values: ['America'] },
+          { name: 'value', type: FieldType.number, values: [30.226249741223704] },
+        ],
+      });
+      const { ds } = setupTestContext({
         results: {
           A: {
             refId: 'A',
@@ -150,59 +128,21 @@ describe('PostgreSQLDatasource', () => {
               executedQueryString: 'select time, metric, value from grafana_metric',
               rowCount: 1,
             },
-            series: null,
-            tables: [
-              {
-                columns: [
-                  {
-                    text: 'time',
-                  },
-                  {
-                    text: 'metric',
-                  },
-                  {
-                    text: 'value',
-                  },
-                ],
-                rows: [[1599643351085, 'America', 30.226249741223704]],
-              },
-            ],
-            dataframes: null,
+            dataframes: [toBase64(frame)],
           },
         },
-      };
-
-      const values = { a: createFetchResponse(data) };
-      const marble = '-a|';
-      const expectedMarble = '-a|';
-      const expectedValues = {
-        a: {
-          data: [
-            {
-              columns: [
-                {
-                  text: 'time',
-                },
-                {
-                  text: 'metric',
-                },
-                {
-                  text: 'value',
-                },
-              ],
-              rows: [[1599643351085, 'America', 30.226249741223704]],
-              type: 'table',
-              refId: 'A',
-              meta: {
-                executedQueryString: 'select time, metric, value from grafana_metric',
-                rowCount: 1,
-              },
-            },
-          ],
-        },
-      };
+      });
 
-      runMarbleTest({ options, marble, values, expectedMarble, expectedValues });
+      await expect(ds.query(options)).toEmitValuesWith((received) => {
+        const data: DataFrame[] = received[0].data;
+        expect(data.length).toBe(1);
+        expect(data[0].refId).toBe('A');
+        expect(data[0].length).toBe(1);
+        expect(data[0].fields.map((field) => field.name)).toEqual(['time', 'metric', 'value']);
+        expect(data[0].fields[0].values.get(0)).toBe(1599643351085);
+        expect(data[0].fields[1].values.get(0)).toBe('America');
+        expect(data[0].fields[2].values.get(0)).toBe(30.226249741223704);
+      });
     });
   });
 
@@ -583,3 +523,6 @@ const createFetchResponse = <T>(data: T): FetchResponse<T> => ({
   headers: ({} as unknown) as Headers,
   ok: true,
 });
+
+const toBase64 = (frame: DataFrame): string =>
+  Buffer.from(grafanaDataFrameToArrowTable(frame, true).serialize()).toString('base64');
@@ -0,0 +1,41 @@
+import React from 'react';
+import {
+  DataSourcePluginOptionsEditorProps,
+  onUpdateDatasourceJsonDataOptionChecked,
+  onUpdateDatasourceOption,
+} from '@grafana/data';
+import { InlineField, InlineSwitch, Input } from '@grafana/ui';
+import { SQLiteOptions } from './types';
+
+export type Props = DataSourcePluginOptionsEditorProps<SQLiteOptions>;
+
+export const ConfigEditor = (props: Props) => {
+  const { options } = props;
+
+  return (
+    <div className="gf-form-group">
+      <InlineField
+        label="Database"
+        labelWidth={14}
+        tooltip="The path of the database file, it must be allowed by the sqlite_allowed_paths setting"
+      >
+        <Input
+          className="width-30"
+          placeholder="/var/lib/grafana/metrics.db"
+          value={options.database || ''}
+          onChange={onUpdateDatasourceOption(props, 'database')}
+        />
+      </InlineField>
+      <InlineField
+        label="Read only"
+        labelWidth={14}
+        tooltip="Writes are only possible if the sqlite_allow_writes setting is enabled"
+      >
+        <InlineSwitch
+          value={options.jsonData.readOnly ?? true}
+          onChange={onUpdateDatasourceJsonDataOptionChecked(props, 'readOnly')}
+        />
+      </InlineField>
+    </div>
+  );
+};
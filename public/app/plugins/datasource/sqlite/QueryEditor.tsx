@@ -0,0 +1,36 @@
+import React from 'react';
+import { QueryEditorProps, SelectableValue } from '@grafana/data';
+import { InlineField, RadioButtonGroup, TextArea } from '@grafana/ui';
+import { SQLiteDatasource } from './datasource';
+import { SQLiteFormat, SQLiteOptions, SQLiteQuery } from './types';
+
+const formats: Array<SelectableValue<SQLiteFormat>> = [
+  { label: 'Time series', value: 'time_series' },
+  { label: 'Table', value: 'table' },
+];
+
+type Props = QueryEditorProps<SQLiteDatasource, SQLiteQuery, SQLiteOptions>;
+
+export const QueryEditor = ({ query, onChange, onRunQuery }: Props) => {
+  return (
+    <>
+      <TextArea
+        rows={5}
+        placeholder="SELECT $__timeGroupAlias(time, 1m), avg(value) AS value FROM metric GROUP BY 1"
+        value={query.rawSql || ''}
+        onChange={(e) => onChange({ ...query, rawSql: e.currentTarget.value })}
+        onBlur={onRunQuery}
+      />
+      <InlineField label="Format as" labelWidth={14}>
+        <RadioButtonGroup
+          options={formats}
+          value={query.format || 'time_series'}
+          onChange={(format) => {
+            onChange({ ...query, format });
+            onRunQuery();
+          }}
+        />
+      </InlineField>
+    </>
+  );
+};
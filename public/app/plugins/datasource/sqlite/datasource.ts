@@ -0,0 +1,42 @@
+import { DataSourceInstanceSettings, ScopedVars } from '@grafana/data';
+import { DataSourceWithBackend, getBackendSrv, getTemplateSrv, TemplateSrv } from '@grafana/runtime';
+import { SQLiteOptions, SQLiteQuery } from './types';
+
+export class SQLiteDatasource extends DataSourceWithBackend<SQLiteQuery, SQLiteOptions> {
+  constructor(
+    instanceSettings: DataSourceInstanceSettings<SQLiteOptions>,
+    private readonly templateSrv: TemplateSrv = getTemplateSrv()
+  ) {
+    super(instanceSettings);
+  }
+
+  filterQuery(query: SQLiteQuery): boolean {
+    return !query.hide && !!query.rawSql;
+  }
+
+  applyTemplateVariables(query: SQLiteQuery, scopedVars: ScopedVars): SQLiteQuery {
+    return {
+      ...query,
+      rawSql: this.templateSrv.replace(query.rawSql, scopedVars),
+    };
+  }
+
+  async testDatasource() {
+    try {
+      await getBackendSrv()
+        .fetch({
+          url: '/api/ds/query',
+          method: 'POST',
+          data: {
+            from: '5m',
+            to: 'now',
+            queries: [{ refId: 'A', datasourceId: this.id, rawSql: 'SELECT 1', format: 'table' }],
+          },
+        })
+        .toPromise();
+    } catch (err) {
+      return { status: 'error', message: err?.data?.message || 'Database Connection Error' };
+    }
+    return { status: 'success', message: 'Database Connection OK' };
+  }
+}
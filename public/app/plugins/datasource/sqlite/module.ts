@@ -0,0 +1,8 @@
+import { DataSourcePlugin } from '@grafana/data';
+import { SQLiteDatasource } from './datasource';
+import { ConfigEditor } from './ConfigEditor';
+import { QueryEditor } from './QueryEditor';
+
+export const plugin = new DataSourcePlugin(SQLiteDatasource)
+  .setConfigEditor(ConfigEditor)
+  .setQueryEditor(QueryEditor);
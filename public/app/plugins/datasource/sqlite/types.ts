@@ -0,0 +1,14 @@
+import { DataQuery, DataSourceJsonData } from '@grafana/data';
+
+export type SQLiteFormat = 'time_series' | 'table';
+
+export interface SQLiteQuery extends DataQuery {
+  rawSql?: string;
+  format?: SQLiteFormat;
+}
+
+export interface SQLiteOptions extends DataSourceJsonData {
+  readOnly?: boolean;
+  queryTimeout?: number;
+  rowLimit?: number;
+}
@@ -14,6 +14,21 @@ const liveTestDataChannels = [
     value: 'random-flakey-stream',
     description: 'Stream that returns data in random intervals',
   },
+  {
+    label: 'signal',
+    value: 'signal?shape=sine&interval=100ms&period=10s',
+    description: 'Sine, square or noise signal with configurable rate and bursts',
+  },
+  {
+    label: 'logs',
+    value: 'logs?interval=1s',
+    description: 'Simulated log lines',
+  },
+  {
+    label: 'flakey',
+    value: 'flakey?uptime=30s&downtime=10s&drop=0.2',
+    description: 'Signal from a producer that drops points and disconnects',
+  },
 ];
 
 export const GrafanaLiveEditor = ({ onChange, query }: EditorProps) => {
@@ -21,6 +36,11 @@ export const GrafanaLiveEditor = ({ onChange, query }: EditorProps) => {
     onChange({ ...query, channel: value });
   };
 
+  const current = liveTestDataChannels.find((f) => f.value === query.channel) ?? {
+    label: query.channel,
+    value: query.channel,
+  };
+
   return (
     <InlineFieldRow>
       <InlineField label="Channel" labelWidth={14}>
@@ -29,7 +49,9 @@ export const GrafanaLiveEditor = ({ onChange, query }: EditorProps) => {
           onChange={onChannelChange}
           placeholder="Select channel"
           options={liveTestDataChannels}
-          value={liveTestDataChannels.find((f) => f.value === query.channel)}
+          value={query.channel ? current : undefined}
+          allowCustomValue
+          onCreateOption={(value) => onChannelChange({ value })}
         />
       </InlineField>
     </InlineFieldRow>